@@ -1,10 +1,33 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"github.com/Blxssy/AvitoTest/config"
 	"github.com/Blxssy/AvitoTest/internal/app"
+	"os"
 )
 
 func main() {
-	app.Run(config.Get())
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML config file")
+	printConfig := flag.Bool("print-config", false, "print the configuration with secrets redacted and exit")
+	flag.Parse()
+
+	cfg, err := config.Get(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if *printConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := app.Run(cfg, *configPath); err != nil {
+		os.Exit(1)
+	}
 }
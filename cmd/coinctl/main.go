@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/config"
+	"github.com/Blxssy/AvitoTest/internal/coinctl"
+	"github.com/Blxssy/AvitoTest/internal/repo/pg"
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"github.com/Blxssy/AvitoTest/pkg/password"
+	"github.com/Blxssy/AvitoTest/pkg/postgres"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	flag.Usage = func() { fmt.Fprint(flag.CommandLine.Output(), coinctl.Usage) }
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML config file")
+	output := flag.String("o", string(coinctl.FormatTable), "output format: table or json")
+	operator := flag.String("operator", os.Getenv("USER"), "who runs the command, recorded with balance adjustments")
+	flag.Parse()
+
+	err := run(*configPath, *output, *operator, flag.Args())
+	var usageErr *coinctl.UsageError
+	switch {
+	case errors.As(err, &usageErr):
+		fmt.Fprintf(os.Stderr, "%v\n\n%s", err, coinctl.Usage)
+		os.Exit(2)
+	case errors.Is(err, coinctl.ErrLedgerInconsistent):
+		os.Exit(1)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(configPath, output, operator string, args []string) error {
+	format, err := coinctl.ParseFormat(output)
+	if err != nil {
+		return err
+	}
+	if operator == "" {
+		operator = "coinctl"
+	}
+	if len(args) == 0 {
+		return &coinctl.UsageError{Message: "missing command"}
+	}
+
+	cfg, err := config.Get(configPath)
+	if err != nil {
+		return err
+	}
+
+	db, err := postgres.New(cfg.PG)
+	if err != nil {
+		return fmt.Errorf("error connecting to PostgreSQL: %w", err)
+	}
+	defer db.Close()
+
+	hasher, err := password.NewHasher(password.Config{
+		Algorithm:     cfg.Password.Algorithm,
+		BcryptCost:    cfg.Password.BcryptCost,
+		Argon2Memory:  cfg.Password.Argon2Memory,
+		Argon2Time:    cfg.Password.Argon2Time,
+		Argon2Threads: cfg.Password.Argon2Threads,
+	})
+	if err != nil {
+		return fmt.Errorf("error configuring password hashing: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cli := coinctl.New(coinctl.Config{
+		Service: services.NewOperatorService(pg.NewCoinRepo(db), services.OperatorServiceConfig{
+			Hasher:          hasher,
+			StartingBalance: cfg.Users.StartingBalance,
+		}),
+		Migrator: coinctl.NewMigrator(db, cfg.PG),
+		Operator: operator,
+		Format:   format,
+		Stdin:    os.Stdin,
+		Stdout:   os.Stdout,
+	})
+	return cli.Run(ctx, args)
+}
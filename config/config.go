@@ -1,48 +1,211 @@
 package config
 
 import (
-	"log"
-	"sync"
+	"fmt"
 	"time"
 
 	"github.com/caarlos0/env/v6"
 )
 
 type Config struct {
-	Logger Logger
-	Server ServerConfig
-	PG     PostgresConfig
-	Token  TokenConfig
+	Logger    Logger
+	Server    ServerConfig
+	GRPC      GRPCConfig
+	Metrics   MetricsConfig
+	Tracing   TracingConfig
+	Health    HealthConfig
+	Shutdown  ShutdownConfig
+	GraphQL   GraphQLConfig
+	RateLimit RateLimitConfig
+	Users     UsersConfig
+	Login     LoginConfig
+	Password  PasswordConfig
+	TwoFactor TwoFactorConfig
+	APIKey    APIKeyConfig
+	OIDC      OIDCConfig
+	PG        PostgresConfig
+	Token     TokenConfig
+	Notifier  NotifierConfig
+	Features  FeaturesConfig
 }
 
 type Logger struct {
-	Level string `env:"LOG_LEVEL,required"`
+	Level string `env:"LOG_LEVEL"`
+	// SampledRoutes are noisy routes, like "GET /api/info", of which only
+	// every SampleEvery-th successful request is logged.
+	SampledRoutes []string `env:"ACCESS_LOG_SAMPLED_ROUTES" envSeparator:"," envDefault:"GET /api/info"`
+	SampleEvery   int      `env:"ACCESS_LOG_SAMPLE_EVERY" envDefault:"10"`
 }
 
+// ServerConfig: behind a load balancer, ProxyHeader names the header that
+// carries the client IP, such as X-Real-IP. It is only read on requests from
+// TrustedProxies, IP addresses or CIDR ranges.
 type ServerConfig struct {
-	Addr string `env:"SERVER_ADDR,required"`
+	Addr           string   `env:"SERVER_ADDR"`
+	ProxyHeader    string   `env:"SERVER_PROXY_HEADER"`
+	TrustedProxies []string `env:"SERVER_TRUSTED_PROXIES" envSeparator:","`
+}
+
+type GRPCConfig struct {
+	Addr string `env:"GRPC_ADDR" envDefault:"0.0.0.0:9090"`
+}
+
+// MetricsConfig sets where Prometheus metrics are served; an empty Addr
+// turns the metrics server off.
+type MetricsConfig struct {
+	Addr string `env:"METRICS_ADDR" envDefault:"0.0.0.0:9100"`
+	Path string `env:"METRICS_PATH" envDefault:"/metrics"`
+}
+
+// TracingConfig selects where spans go: "otlp" to an OTLP/HTTP collector at
+// OTLPEndpoint, "stdout" for local runs, or "none" to only pass the trace
+// context of incoming requests on.
+type TracingConfig struct {
+	Exporter     string  `env:"TRACING_EXPORTER" envDefault:"none"`
+	OTLPEndpoint string  `env:"TRACING_OTLP_ENDPOINT" envDefault:"localhost:4318"`
+	OTLPInsecure bool    `env:"TRACING_OTLP_INSECURE" envDefault:"false"`
+	ServiceName  string  `env:"TRACING_SERVICE_NAME" envDefault:"coin-service"`
+	SampleRatio  float64 `env:"TRACING_SAMPLE_RATIO" envDefault:"1"`
+}
+
+// HealthConfig tunes /readyz.
+type HealthConfig struct {
+	CheckTimeout time.Duration `env:"HEALTH_CHECK_TIMEOUT" envDefault:"2s"`
+}
+
+// ShutdownConfig: on shutdown readiness fails for DrainDelay before the
+// servers stop, so load balancers take the instance out first. Timeout bounds
+// the whole shutdown, drain included; requests still running after it are cut.
+type ShutdownConfig struct {
+	Timeout    time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"30s"`
+	DrainDelay time.Duration `env:"SHUTDOWN_DRAIN_DELAY" envDefault:"5s"`
+}
+
+// GraphQLConfig bounds the queries accepted by the /graphql endpoint.
+type GraphQLConfig struct {
+	MaxDepth      int `env:"GRAPHQL_MAX_DEPTH" envDefault:"6"`
+	MaxComplexity int `env:"GRAPHQL_MAX_COMPLEXITY" envDefault:"200"`
+}
+
+// RateLimitConfig sets per-route request limits like "10/m" (bursts of 10,
+// refilled at 10 per minute); an empty limit disables it. Store is "memory"
+// for a single instance or "postgres" to share limits between replicas.
+type RateLimitConfig struct {
+	Store    string `env:"RATE_LIMIT_STORE" envDefault:"memory"`
+	Auth     string `env:"RATE_LIMIT_AUTH" envDefault:"10/m"`
+	Transfer string `env:"RATE_LIMIT_TRANSFER" envDefault:"30/m"`
+	Default  string `env:"RATE_LIMIT_DEFAULT" envDefault:"600/m"`
+}
+
+// UsersConfig applies to users registered on their first login, with a
+// password or through SSO.
+type UsersConfig struct {
+	StartingBalance int `env:"STARTING_BALANCE" envDefault:"1000"`
+}
+
+// LoginConfig protects passwords from guessing. Every failed login delays the
+// next attempt for that username and client IP, starting at BaseDelay and
+// doubling up to MaxDelay. UserMaxFailures (IPMaxFailures) failures within
+// FailureWindow lock the username (IP) for LockoutDuration; zero disables
+// that lock.
+type LoginConfig struct {
+	UserMaxFailures int           `env:"LOGIN_USER_MAX_FAILURES" envDefault:"5"`
+	IPMaxFailures   int           `env:"LOGIN_IP_MAX_FAILURES" envDefault:"50"`
+	LockoutDuration time.Duration `env:"LOGIN_LOCKOUT_DURATION" envDefault:"15m"`
+	FailureWindow   time.Duration `env:"LOGIN_FAILURE_WINDOW" envDefault:"15m"`
+	BaseDelay       time.Duration `env:"LOGIN_BASE_DELAY" envDefault:"1s"`
+	MaxDelay        time.Duration `env:"LOGIN_MAX_DELAY" envDefault:"30s"`
+}
+
+// PasswordConfig selects how new password hashes are made: "argon2id" or
+// "bcrypt". Existing hashes are upgraded on the next login after the
+// algorithm or its cost changes. Argon2Memory is in KiB.
+type PasswordConfig struct {
+	Algorithm     string        `env:"PASSWORD_ALGORITHM" envDefault:"argon2id"`
+	BcryptCost    int           `env:"PASSWORD_BCRYPT_COST" envDefault:"10"`
+	Argon2Memory  uint32        `env:"PASSWORD_ARGON2_MEMORY" envDefault:"19456"`
+	Argon2Time    uint32        `env:"PASSWORD_ARGON2_TIME" envDefault:"2"`
+	Argon2Threads uint8         `env:"PASSWORD_ARGON2_THREADS" envDefault:"1"`
+	ResetTokenTTL time.Duration `env:"PASSWORD_RESET_TOKEN_TTL" envDefault:"1h"`
+}
+
+// TwoFactorConfig sets the issuer shown in authenticator apps and how long a
+// login challenge waits for the second factor.
+type TwoFactorConfig struct {
+	TOTPIssuer   string        `env:"TOTP_ISSUER" envDefault:"AvitoShop"`
+	ChallengeTTL time.Duration `env:"TWO_FACTOR_CHALLENGE_TTL" envDefault:"5m"`
+}
+
+// APIKeyConfig bounds the lifetime of service account API keys: DefaultTTL
+// applies when the admin sets no expiry, and no key may outlive MaxTTL.
+type APIKeyConfig struct {
+	DefaultTTL time.Duration `env:"API_KEY_DEFAULT_TTL" envDefault:"2160h"`
+	MaxTTL     time.Duration `env:"API_KEY_MAX_TTL" envDefault:"8760h"`
+}
+
+// OIDCConfig enables login through an external OpenID Connect provider; it
+// is off while IssuerURL is empty. UsernameClaim names the ID token claim
+// that becomes the username: "email", "preferred_username" or "sub".
+type OIDCConfig struct {
+	IssuerURL     string        `env:"OIDC_ISSUER_URL"`
+	ClientID      string        `env:"OIDC_CLIENT_ID"`
+	ClientSecret  string        `env:"OIDC_CLIENT_SECRET" secret:"true"`
+	RedirectURL   string        `env:"OIDC_REDIRECT_URL"`
+	Scopes        []string      `env:"OIDC_SCOPES" envSeparator:"," envDefault:"openid,email,profile"`
+	UsernameClaim string        `env:"OIDC_USERNAME_CLAIM" envDefault:"email"`
+	StateTTL      time.Duration `env:"OIDC_STATE_TTL" envDefault:"10m"`
 }
 
 type PostgresConfig struct {
-	DataSource       string `env:"DB_DATA_SOURCE,required"`
-	PathToMigrations string `env:"DB_PATH_TO_MIGRATIONS,required"`
+	DataSource       string `env:"DB_DATA_SOURCE" secret:"true"`
+	PathToMigrations string `env:"DB_PATH_TO_MIGRATIONS"`
 }
 
 type TokenConfig struct {
-	TokenKey string        `env:"TOKEN_KEY"`
-	TokenTTL time.Duration `env:"TOKEN_TTL"`
+	TokenKey string        `env:"TOKEN_KEY" secret:"true"`
+	TokenTTL time.Duration `env:"TOKEN_TTL" envDefault:"1h"`
 }
 
-var (
-	config Config
-	once   sync.Once
-)
+// NotifierConfig selects how notifications are delivered outside the app:
+// "smtp", "webhook" or empty to only keep them in the inbox.
+type NotifierConfig struct {
+	Kind         string        `env:"NOTIFIER_KIND"`
+	SMTPAddr     string        `env:"NOTIFIER_SMTP_ADDR"`
+	SMTPFrom     string        `env:"NOTIFIER_SMTP_FROM"`
+	SMTPUsername string        `env:"NOTIFIER_SMTP_USERNAME"`
+	SMTPPassword string        `env:"NOTIFIER_SMTP_PASSWORD" secret:"true"`
+	WebhookURL   string        `env:"NOTIFIER_WEBHOOK_URL"`
+	PollInterval time.Duration `env:"NOTIFIER_POLL_INTERVAL" envDefault:"1s"`
+	MaxAttempts  int           `env:"NOTIFIER_MAX_ATTEMPTS" envDefault:"5"`
+}
+
+// FeaturesConfig: every instance keeps the feature flags in memory and
+// reloads them every RefreshInterval to pick up changes made on others.
+type FeaturesConfig struct {
+	RefreshInterval time.Duration `env:"FEATURE_FLAGS_REFRESH_INTERVAL" envDefault:"30s"`
+}
+
+// Get reads the configuration from the YAML file at path, if not empty, and
+// the environment, which overrides the file. Every variable can also be read
+// from a file named by the same variable with a _FILE suffix. The result is
+// validated.
+func Get(path string) (*Config, error) {
+	var cfg Config
+	known := make(map[string]setting)
+	for _, s := range settings(&cfg) {
+		known[s.key] = s
+	}
+
+	values, err := environment(path, known)
+	if err != nil {
+		return nil, fmt.Errorf("reading configuration: %w", err)
+	}
+	if err := env.Parse(&cfg, env.Options{Environment: values}); err != nil {
+		return nil, fmt.Errorf("reading configuration: %w", err)
+	}
 
-func Get() *Config {
-	once.Do(func() {
-		if err := env.Parse(&config); err != nil {
-			log.Fatal(err)
-		}
-	})
-	return &config
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
 }
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Diff lists the variables whose values differ between a and b.
+func Diff(a, b *Config) []string {
+	before := make(map[string]setting)
+	for _, s := range settings(a) {
+		before[s.key] = s
+	}
+
+	var changed []string
+	for _, s := range settings(b) {
+		if !reflect.DeepEqual(before[s.key].value.Interface(), s.value.Interface()) {
+			changed = append(changed, s.key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "[REDACTED]"
+
+// Print writes the configuration as a YAML config file, with secrets
+// replaced by [REDACTED].
+func (c *Config) Print(w io.Writer) error {
+	values := make(map[string]string)
+	for _, s := range settings(c) {
+		values[s.key] = format(s)
+	}
+
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("yaml.Marshal: %w", err)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func format(s setting) string {
+	if s.secret && !s.value.IsZero() {
+		return redacted
+	}
+
+	switch v := s.value.Interface().(type) {
+	case time.Duration:
+		return v.String()
+	case []string:
+		return strings.Join(v, separator(s.field))
+	}
+	if s.value.Kind() == reflect.String {
+		return s.value.String()
+	}
+	return fmt.Sprint(s.value.Interface())
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileSuffix marks a variable holding the path of a file with the value, for
+// secrets mounted by Docker or Kubernetes: TOKEN_KEY_FILE=/run/secrets/key.
+const fileSuffix = "_FILE"
+
+// setting is a field of Config with the variable that sets it.
+type setting struct {
+	key    string
+	field  reflect.StructField
+	value  reflect.Value
+	secret bool
+}
+
+// settings lists the fields of c that are read from the environment.
+func settings(c *Config) []setting {
+	var all []setting
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			tag, ok := field.Tag.Lookup("env")
+			if !ok {
+				if field.Type.Kind() == reflect.Struct {
+					walk(v.Field(i))
+				}
+				continue
+			}
+			key, _, _ := strings.Cut(tag, ",")
+			all = append(all, setting{
+				key:    key,
+				field:  field,
+				value:  v.Field(i),
+				secret: field.Tag.Get("secret") == "true",
+			})
+		}
+	}
+	walk(reflect.ValueOf(c).Elem())
+	return all
+}
+
+func separator(field reflect.StructField) string {
+	if sep, ok := field.Tag.Lookup("envSeparator"); ok {
+		return sep
+	}
+	return ","
+}
+
+// environment gathers the settings from the config file at path, if any, and
+// the process environment. A variable set in the environment, directly or
+// with _FILE, replaces both forms of it from the file.
+func environment(path string, known map[string]setting) (map[string]string, error) {
+	values := make(map[string]string)
+	if path != "" {
+		var err error
+		if values, err = readFile(path, known); err != nil {
+			return nil, err
+		}
+	}
+
+	fromEnv := make(map[string]string)
+	for _, kv := range os.Environ() {
+		key, value, _ := strings.Cut(kv, "=")
+		if _, ok := known[strings.TrimSuffix(key, fileSuffix)]; ok {
+			fromEnv[key] = value
+		}
+	}
+	for key := range fromEnv {
+		name := strings.TrimSuffix(key, fileSuffix)
+		delete(values, name)
+		delete(values, name+fileSuffix)
+	}
+	for key, value := range fromEnv {
+		values[key] = value
+	}
+
+	if err := readSecretFiles(values, known); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// readFile reads a YAML config file. Nested keys are joined with "_" into
+// variable names, so "token: {ttl: 1h}" is the same as TOKEN_TTL=1h.
+func readFile(path string, known map[string]setting) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile: %w", err)
+	}
+
+	var tree map[string]any
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	var unknown []string
+	var flatten func(prefix string, node map[string]any)
+	flatten = func(prefix string, node map[string]any) {
+		for name, value := range node {
+			key := prefix + strings.ToUpper(name)
+			if nested, ok := value.(map[string]any); ok {
+				flatten(key+"_", nested)
+				continue
+			}
+
+			s, ok := known[strings.TrimSuffix(key, fileSuffix)]
+			if !ok {
+				unknown = append(unknown, key)
+				continue
+			}
+			values[key] = scalar(value, separator(s.field))
+		}
+	}
+	flatten("", tree)
+
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("%s: unknown settings %s", path, strings.Join(unknown, ", "))
+	}
+	return values, nil
+}
+
+func scalar(value any, sep string) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, sep)
+	}
+	return fmt.Sprint(value)
+}
+
+// readSecretFiles replaces every KEY_FILE in values with KEY set to the
+// contents of that file.
+func readSecretFiles(values map[string]string, known map[string]setting) error {
+	for key, path := range values {
+		name, ok := strings.CutSuffix(key, fileSuffix)
+		if !ok {
+			continue
+		}
+		if _, ok := known[name]; !ok {
+			continue
+		}
+		if _, ok := values[name]; ok {
+			return fmt.Errorf("both %s and %s are set", name, key)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		values[name] = strings.TrimRight(string(data), "\r\n")
+		delete(values, key)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	minTokenTTL = time.Minute
+	maxTokenTTL = 24 * time.Hour
+)
+
+// Validate reports every problem with the configuration at once, one per
+// line, each prefixed with the variable to fix.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, key, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+		}
+	}
+
+	if c.Logger.Level == "" {
+		check(false, "LOG_LEVEL", "must be set")
+	} else if _, err := zapcore.ParseLevel(c.Logger.Level); err != nil {
+		check(false, "LOG_LEVEL", "must be one of debug, info, warn, error, dpanic, panic or fatal, got %q", c.Logger.Level)
+	}
+	check(c.Server.Addr != "", "SERVER_ADDR", "must be set")
+	check(c.Server.ProxyHeader == "" || len(c.Server.TrustedProxies) > 0,
+		"SERVER_TRUSTED_PROXIES", "must be set with SERVER_PROXY_HEADER")
+	check(c.PG.PathToMigrations != "", "DB_PATH_TO_MIGRATIONS", "must be set")
+
+	if c.PG.DataSource == "" {
+		check(false, "DB_DATA_SOURCE", "must be set")
+	} else if _, err := pgx.ParseConfig(c.PG.DataSource); err != nil {
+		// The parse error quotes the connection string, password included.
+		check(false, "DB_DATA_SOURCE", "not a valid connection string")
+	}
+
+	check(c.Token.TokenKey != "", "TOKEN_KEY", "must be set")
+	check(c.Token.TokenTTL >= minTokenTTL && c.Token.TokenTTL <= maxTokenTTL,
+		"TOKEN_TTL", "must be between %s and %s, got %s", minTokenTTL, maxTokenTTL, c.Token.TokenTTL)
+
+	check(c.APIKey.DefaultTTL <= c.APIKey.MaxTTL,
+		"API_KEY_DEFAULT_TTL", "must not exceed API_KEY_MAX_TTL (%s)", c.APIKey.MaxTTL)
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1,
+		"TRACING_SAMPLE_RATIO", "must be between 0 and 1")
+
+	if c.OIDC.IssuerURL != "" {
+		check(c.OIDC.ClientID != "", "OIDC_CLIENT_ID", "must be set with OIDC_ISSUER_URL")
+		check(c.OIDC.RedirectURL != "", "OIDC_REDIRECT_URL", "must be set with OIDC_ISSUER_URL")
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
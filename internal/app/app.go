@@ -1,75 +1,387 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/Blxssy/AvitoTest/config"
+	"github.com/Blxssy/AvitoTest/internal/events"
+	"github.com/Blxssy/AvitoTest/internal/health"
+	"github.com/Blxssy/AvitoTest/internal/metrics"
+	"github.com/Blxssy/AvitoTest/internal/notifier"
+	"github.com/Blxssy/AvitoTest/internal/ratelimit"
 	"github.com/Blxssy/AvitoTest/internal/repo/pg"
 	"github.com/Blxssy/AvitoTest/internal/services"
+	"github.com/Blxssy/AvitoTest/internal/tracing"
+	"github.com/Blxssy/AvitoTest/internal/transport/grpc"
 	"github.com/Blxssy/AvitoTest/internal/transport/http"
+	"github.com/Blxssy/AvitoTest/internal/transport/http/middleware"
 	"github.com/Blxssy/AvitoTest/pkg/logger"
+	"github.com/Blxssy/AvitoTest/pkg/oidc"
+	"github.com/Blxssy/AvitoTest/pkg/password"
 	"github.com/Blxssy/AvitoTest/pkg/postgres"
 	"github.com/Blxssy/AvitoTest/pkg/token"
+	"github.com/jmoiron/sqlx"
 	"go.uber.org/zap"
-	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
-func Run(cfg *config.Config) {
-	log := logger.New(cfg.Logger.Level)
+// Run starts the application and blocks until SIGINT or SIGTERM, or until one
+// of its servers fails. SIGHUP reloads the settings that can change at runtime
+// from configPath and the environment. The error Run returns has already been
+// logged.
+func Run(cfg *config.Config, configPath string) error {
+	level := zap.NewAtomicLevelAt(logger.ParseLevel(cfg.Logger.Level))
+	log := logger.New(level)
+	defer func() { _ = log.Sync() }()
 
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+	go func() {
+		// A second signal kills the process instead of waiting for shutdown.
+		<-ctx.Done()
+		stop()
+	}()
+
+	limits, err := parseRateLimits(cfg.RateLimit)
+	if err != nil {
+		err = fmt.Errorf("error configuring rate limiting: %w", err)
+		log.Error(err.Error())
+		return err
+	}
+	reload := &reloader{
+		configPath: configPath,
+		current:    cfg,
+		level:      level,
+		rateLimits: ratelimit.NewLimits(limits),
+		logger:     log,
+	}
+
+	lifecycle := NewLifecycle(LifecycleConfig{
+		ShutdownTimeout: cfg.Shutdown.Timeout,
+		Logger:          log,
+	})
+	if err := setup(cfg, log, reload, lifecycle); err != nil {
+		err = errors.Join(err, lifecycle.Shutdown())
+		log.Error(err.Error())
+		return err
+	}
+
+	if err := lifecycle.Run(ctx); err != nil {
+		log.Error(fmt.Sprintf("shutdown finished with errors: %v", err))
+		return err
+	}
+	log.Info("shutdown complete")
+	return nil
+}
+
+// setup builds the application and adds its parts to lifecycle in the order
+// they depend on each other: they stop in reverse, servers first and the
+// database last.
+func setup(cfg *config.Config, log *zap.Logger, reload *reloader, lifecycle *Lifecycle) error {
 	pgRepo, err := postgres.New(cfg.PG)
 	if err != nil {
-		log.Fatal(fmt.Sprintf("error conntecting to PostgreSQL: %v", err))
-	} else {
-		log.Info("successfully connected to PostgreSQL")
+		return fmt.Errorf("error conntecting to PostgreSQL: %w", err)
 	}
+	log.Info("successfully connected to PostgreSQL")
+	lifecycle.Add(Component{
+		Name: "PostgreSQL connections",
+		Stop: func(context.Context) error { return pgRepo.Close() },
+	})
+
 	version, err := postgres.RunMigrations(pgRepo.DB, cfg.PG)
 	if err != nil {
-		log.Fatal(fmt.Sprintf("error while running migraions: %v", err))
+		return fmt.Errorf("error while running migraions: %w", err)
 	}
 	log.Info("Migrations version", zap.Uint("v", version))
+	expectedVersion, err := postgres.LatestMigration(cfg.PG.PathToMigrations)
+	if err != nil {
+		return fmt.Errorf("error reading migrations: %w", err)
+	}
 
 	t := token.NewTokenGen(token.TokenConfig{
 		TokenKey: cfg.Token.TokenKey,
 		TokenTTL: cfg.Token.TokenTTL,
 	})
-	_ = t
-
-	coinRepo := pg.NewCoinRepo(pgRepo)
-	coinService := services.NewCoinService(coinRepo, t)
 
-	httpServer := http.NewServer(http.ServerConfig{
-		Addr:        cfg.Server.Addr,
-		CoinService: coinService,
-		Logger:      log,
+	hasher, err := password.NewHasher(password.Config{
+		Algorithm:     cfg.Password.Algorithm,
+		BcryptCost:    cfg.Password.BcryptCost,
+		Argon2Memory:  cfg.Password.Argon2Memory,
+		Argon2Time:    cfg.Password.Argon2Time,
+		Argon2Threads: cfg.Password.Argon2Threads,
 	})
+	if err != nil {
+		return fmt.Errorf("error configuring password hashing: %w", err)
+	}
 
-	go func() {
-		if err = httpServer.Run(); err != nil {
-			log.Fatal(fmt.Sprintf("error occurred while running HTTP server: %v", err))
+	var oidcProvider oidc.Provider
+	if cfg.OIDC.IssuerURL != "" {
+		oidcProvider, err = oidc.NewProvider(context.Background(), oidc.Config{
+			IssuerURL:     cfg.OIDC.IssuerURL,
+			ClientID:      cfg.OIDC.ClientID,
+			ClientSecret:  cfg.OIDC.ClientSecret,
+			RedirectURL:   cfg.OIDC.RedirectURL,
+			Scopes:        cfg.OIDC.Scopes,
+			UsernameClaim: cfg.OIDC.UsernameClaim,
+		})
+		if err != nil {
+			return fmt.Errorf("error configuring oidc login: %w", err)
 		}
-	}()
-	log.Info(fmt.Sprintf("HTTP server successfully started on %s", cfg.Server.Addr))
+	}
 
-	// Graceful Shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT, os.Interrupt)
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:     cfg.Tracing.Exporter,
+		OTLPEndpoint: cfg.Tracing.OTLPEndpoint,
+		OTLPInsecure: cfg.Tracing.OTLPInsecure,
+		ServiceName:  cfg.Tracing.ServiceName,
+		SampleRatio:  cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		return fmt.Errorf("error configuring tracing: %w", err)
+	}
+	lifecycle.Add(Component{Name: "tracing", Stop: shutdownTracing})
 
-	<-quit
+	appMetrics := metrics.New(metrics.Config{DB: pgRepo.DB})
+	coinRepo := tracing.NewCoinRepository(metrics.NewCoinRepository(pg.NewCoinRepo(pgRepo), appMetrics))
+	loginProtection := services.LoginProtectionConfig{
+		UserMaxFailures: cfg.Login.UserMaxFailures,
+		IPMaxFailures:   cfg.Login.IPMaxFailures,
+		LockoutDuration: cfg.Login.LockoutDuration,
+		FailureWindow:   cfg.Login.FailureWindow,
+		BaseDelay:       cfg.Login.BaseDelay,
+		MaxDelay:        cfg.Login.MaxDelay,
+	}
+	// One authenticator serves the services and the transports alike.
+	authenticator := services.NewAuthenticator(coinRepo, t)
+	featureFlagService := services.NewFeatureFlagService(coinRepo, t, services.FeatureFlagServiceConfig{
+		Authenticator:   authenticator,
+		RefreshInterval: cfg.Features.RefreshInterval,
+		Logger:          log,
+	})
+	if err := featureFlagService.Refresh(context.Background()); err != nil {
+		return fmt.Errorf("error loading feature flags: %w", err)
+	}
+	reload.flags = featureFlagService
+	coinService := tracing.NewCoinService(services.NewCoinService(coinRepo, t, services.CoinServiceConfig{
+		Authenticator:   authenticator,
+		Hasher:          hasher,
+		LoginProtection: loginProtection,
+		ChallengeTTL:    cfg.TwoFactor.ChallengeTTL,
+		OIDC:            oidcProvider,
+		OIDCStateTTL:    cfg.OIDC.StateTTL,
+		StartingBalance: cfg.Users.StartingBalance,
+		Flags:           featureFlagService,
+		Metrics:         appMetrics,
+		Logger:          log,
+	}))
+	accountService := services.NewAccountService(coinRepo, t, services.AccountServiceConfig{
+		Hasher:          hasher,
+		LoginProtection: loginProtection,
+		ResetTokenTTL:   cfg.Password.ResetTokenTTL,
+		TOTPIssuer:      cfg.TwoFactor.TOTPIssuer,
+		APIKeyTTL:       cfg.APIKey.DefaultTTL,
+		APIKeyMaxTTL:    cfg.APIKey.MaxTTL,
+		Reloader:        reload,
+		Logger:          log,
+	})
+
+	hub := events.NewHub(events.HubConfig{})
+	eventService := services.NewEventService(coinRepo, hub, t, services.EventServiceConfig{
+		Authenticator: authenticator,
+	})
+	notificationService := services.NewNotificationService(coinRepo, t, services.NotificationServiceConfig{
+		Authenticator: authenticator,
+	})
 
-	// Shutdown HTTP server
-	log.Info("shutdown HTTP server...")
-	if err = httpServer.Shutdown(); err != nil {
-		log.Error(fmt.Sprintf("failed to shutdown HTTP server: %v", err))
-	} else {
-		log.Info("HTTP server successfully shutdown")
+	notify, err := newNotifier(cfg.Notifier)
+	if err != nil {
+		return fmt.Errorf("error configuring notifier: %w", err)
 	}
+	templates, err := notifier.NewTemplates()
+	if err != nil {
+		return fmt.Errorf("error loading notification templates: %w", err)
+	}
+
+	// Background workers stop after the servers, once no request can start
+	// new work for them.
+	lifecycle.Add(Component{
+		Name: "events listener",
+		Run: events.NewListener(events.ListenerConfig{
+			DataSource: cfg.PG.DataSource,
+			Channel:    pg.EventsChannel,
+			Hub:        hub,
+			Logger:     log,
+		}).Run,
+	})
+	lifecycle.Add(Component{
+		Name: "notification dispatcher",
+		Run: notifier.NewDispatcher(notifier.DispatcherConfig{
+			Repo:         coinRepo,
+			Notifier:     notify,
+			Templates:    templates,
+			Logger:       log,
+			PollInterval: cfg.Notifier.PollInterval,
+			MaxAttempts:  cfg.Notifier.MaxAttempts,
+		}).Run,
+	})
+
+	rateLimitStore, err := newRateLimitStore(cfg.RateLimit, pgRepo)
+	if err != nil {
+		return fmt.Errorf("error configuring rate limiting: %w", err)
+	}
+	lifecycle.Add(Component{Name: "config reloader", Run: reload.watch})
+	lifecycle.Add(Component{Name: "feature flags", Run: featureFlagService.Run})
+
+	if cfg.Metrics.Addr != "" {
+		metricsServer := metrics.NewServer(metrics.ServerConfig{
+			Addr:    cfg.Metrics.Addr,
+			Path:    cfg.Metrics.Path,
+			Metrics: appMetrics,
+		})
+		lifecycle.Add(Component{
+			Name: "metrics server",
+			Run: func(context.Context) error {
+				log.Info(fmt.Sprintf("metrics server started on %s%s", cfg.Metrics.Addr, cfg.Metrics.Path))
+				return metricsServer.Run()
+			},
+			Stop: metricsServer.Shutdown,
+		})
+	}
+
+	grpcServer := grpc.NewServer(grpc.ServerConfig{
+		Addr:          cfg.GRPC.Addr,
+		CoinService:   coinService,
+		Authenticator: authenticator,
+		Logger:        log,
+	})
+	lifecycle.Add(Component{
+		Name: "gRPC server",
+		Run: func(context.Context) error {
+			log.Info(fmt.Sprintf("gRPC server started on %s", cfg.GRPC.Addr))
+			return grpcServer.Run()
+		},
+		Stop: grpcServer.Shutdown,
+	})
+
+	checker := health.NewChecker(health.Config{
+		Checks: []health.Check{
+			health.DatabaseCheck(pgRepo.PingContext),
+			health.SchemaCheck(func(ctx context.Context) (uint, bool, error) {
+				return postgres.MigrationVersion(ctx, pgRepo)
+			}, expectedVersion),
+		},
+		Timeout: cfg.Health.CheckTimeout,
+	})
+
+	httpServer, err := http.NewServer(http.ServerConfig{
+		Addr:                   cfg.Server.Addr,
+		ProxyHeader:            cfg.Server.ProxyHeader,
+		TrustedProxies:         cfg.Server.TrustedProxies,
+		CoinService:            coinService,
+		EventService:           eventService,
+		NotificationService:    notificationService,
+		AccountService:         accountService,
+		FeatureFlagService:     featureFlagService,
+		Authenticator:          authenticator,
+		GraphQLMaxDepth:        cfg.GraphQL.MaxDepth,
+		GraphQLMaxComplexity:   cfg.GraphQL.MaxComplexity,
+		RateLimitStore:         rateLimitStore,
+		RateLimits:             reload.rateLimits,
+		Metrics:                appMetrics,
+		Health:                 checker,
+		AccessLogSampledRoutes: cfg.Logger.SampledRoutes,
+		AccessLogSampleEvery:   cfg.Logger.SampleEvery,
+		Logger:                 log,
+	})
+	if err != nil {
+		return fmt.Errorf("error configuring HTTP server: %w", err)
+	}
+	lifecycle.Add(Component{
+		Name: "HTTP server",
+		Run: func(context.Context) error {
+			log.Info(fmt.Sprintf("HTTP server started on %s", cfg.Server.Addr))
+			return httpServer.Run()
+		},
+		Stop: httpServer.Shutdown,
+	})
 
-	log.Info("closing PostgreSQL connections...")
-	if err = pgRepo.Close(); err != nil {
-		log.Error(fmt.Sprintf("failed to closing PostgreSQL connections: %v", err))
-	} else {
-		log.Info("PostgreSQL connections successfully closed")
+	// Event streams never finish on their own; end them so the HTTP server
+	// is not kept waiting, and let clients resume on another instance.
+	lifecycle.Add(Component{
+		Name: "event streams",
+		Stop: func(context.Context) error {
+			hub.Close()
+			return nil
+		},
+	})
+
+	// Stopped first: fail readiness and give load balancers time to notice
+	// before connections are closed.
+	lifecycle.Add(Component{
+		Name: "readiness",
+		Stop: func(ctx context.Context) error {
+			checker.Drain()
+			log.Info(fmt.Sprintf("draining traffic for %s...", cfg.Shutdown.DrainDelay))
+			select {
+			case <-time.After(cfg.Shutdown.DrainDelay):
+			case <-ctx.Done():
+			}
+			return nil
+		},
+	})
+
+	return nil
+}
+
+func newNotifier(cfg config.NotifierConfig) (notifier.Notifier, error) {
+	switch cfg.Kind {
+	case "":
+		return notifier.Discard{}, nil
+	case "smtp":
+		return notifier.NewSMTPNotifier(notifier.SMTPConfig{
+			Addr:     cfg.SMTPAddr,
+			From:     cfg.SMTPFrom,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+		}), nil
+	case "webhook":
+		return notifier.NewWebhookNotifier(notifier.WebhookConfig{
+			URL: cfg.WebhookURL,
+		}), nil
+	}
+	return nil, fmt.Errorf("unknown notifier kind %q", cfg.Kind)
+}
+
+func newRateLimitStore(cfg config.RateLimitConfig, db *sqlx.DB) (ratelimit.Store, error) {
+	switch cfg.Store {
+	case "memory":
+		return ratelimit.NewMemoryStore(), nil
+	case "postgres":
+		return ratelimit.NewPostgresStore(db), nil
+	}
+	return nil, fmt.Errorf("unknown rate limit store %q", cfg.Store)
+}
+
+// parseRateLimits returns the limits of the rate limiting rules by name.
+func parseRateLimits(cfg config.RateLimitConfig) (map[string]ratelimit.Limit, error) {
+	settings := []struct {
+		rule, key, limit string
+	}{
+		{"auth", "RATE_LIMIT_AUTH", cfg.Auth},
+		{"transfer", "RATE_LIMIT_TRANSFER", cfg.Transfer},
+		{middleware.DefaultRateLimitRule, "RATE_LIMIT_DEFAULT", cfg.Default},
+	}
+
+	limits := make(map[string]ratelimit.Limit, len(settings))
+	for _, s := range settings {
+		limit, err := ratelimit.ParseLimit(s.limit)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s.key, err)
+		}
+		limits[s.rule] = limit
 	}
+	return limits, nil
 }
@@ -0,0 +1,150 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// Component is a part of the application with a lifetime of its own: a
+// server, a background worker or a resource to release on exit.
+type Component struct {
+	Name string
+	// Run blocks until the component stops. It may be nil for resources that
+	// only need Stop.
+	Run func(ctx context.Context) error
+	// Stop shuts the component down within ctx. When nil, the context passed
+	// to Run is cancelled instead, which is how background workers stop.
+	Stop func(ctx context.Context) error
+}
+
+type component struct {
+	Component
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+}
+
+// Lifecycle starts components in the order they were added and stops them in
+// reverse, so what was set up first, like the database, is released last.
+type Lifecycle struct {
+	components      []*component
+	started         bool
+	shutdownTimeout time.Duration
+	logger          *zap.Logger
+}
+
+type LifecycleConfig struct {
+	// ShutdownTimeout bounds stopping all components together.
+	ShutdownTimeout time.Duration
+	Logger          *zap.Logger
+}
+
+func NewLifecycle(cfg LifecycleConfig) *Lifecycle {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Lifecycle{
+		shutdownTimeout: cfg.ShutdownTimeout,
+		logger:          logger,
+	}
+}
+
+func (l *Lifecycle) Add(c Component) {
+	ctx, cancel := context.WithCancel(context.Background())
+	l.components = append(l.components, &component{
+		Component: c,
+		ctx:       ctx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	})
+}
+
+// Run starts every component and waits until ctx is cancelled or one of them
+// fails, then shuts all of them down. It returns the errors components failed
+// or stopped with.
+func (l *Lifecycle) Run(ctx context.Context) error {
+	l.started = true
+	g, gctx := errgroup.WithContext(ctx)
+	for _, c := range l.components {
+		if c.Run == nil {
+			close(c.done)
+			continue
+		}
+		g.Go(func() error {
+			defer close(c.done)
+			if err := c.Run(c.ctx); err != nil {
+				c.err = fmt.Errorf("%s: %w", c.Name, err)
+			}
+			return c.err
+		})
+	}
+
+	<-gctx.Done()
+	if ctx.Err() == nil {
+		l.logger.Error("component failed, shutting down")
+	} else {
+		l.logger.Info("shutting down...")
+	}
+
+	errs := []error{l.Shutdown()}
+	for _, c := range l.components {
+		// Components that did not stop in time are reported by Shutdown.
+		select {
+		case <-c.done:
+			errs = append(errs, c.err)
+		default:
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Shutdown stops components in reverse order within the shutdown timeout.
+// Components left are still stopped after the timeout, with a context that is
+// already done. Without Run it releases what was set up before a failure.
+func (l *Lifecycle) Shutdown() error {
+	ctx := context.Background()
+	if l.shutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.shutdownTimeout)
+		defer cancel()
+	}
+
+	var errs []error
+	for i := len(l.components) - 1; i >= 0; i-- {
+		c := l.components[i]
+		l.logger.Info(fmt.Sprintf("stopping %s...", c.Name))
+		if err := l.stop(ctx, c); err != nil {
+			l.logger.Error(fmt.Sprintf("failed to stop %s: %v", c.Name, err))
+			errs = append(errs, fmt.Errorf("stop %s: %w", c.Name, err))
+			continue
+		}
+		l.logger.Info(fmt.Sprintf("%s successfully stopped", c.Name))
+	}
+	return errors.Join(errs...)
+}
+
+func (l *Lifecycle) stop(ctx context.Context, c *component) error {
+	var err error
+	if c.Stop != nil {
+		err = c.Stop(ctx)
+	}
+	c.cancel()
+	if c.Run == nil || !l.started {
+		return err
+	}
+
+	select {
+	case <-c.done:
+		return err
+	case <-ctx.Done():
+		return errors.Join(err, ctx.Err())
+	}
+}
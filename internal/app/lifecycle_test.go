@@ -0,0 +1,179 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"github.com/Blxssy/AvitoTest/internal/services/mocks"
+	httpserver "github.com/Blxssy/AvitoTest/internal/transport/http"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stopLog struct {
+	mu    sync.Mutex
+	names []string
+}
+
+func (l *stopLog) component(name string, worker bool) Component {
+	record := func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.names = append(l.names, name)
+	}
+
+	if worker {
+		return Component{Name: name, Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			record()
+			return nil
+		}}
+	}
+	return Component{Name: name, Stop: func(context.Context) error {
+		record()
+		return nil
+	}}
+}
+
+func (l *stopLog) stopped() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.names...)
+}
+
+func TestLifecycleStopsInReverseOrder(t *testing.T) {
+	var log stopLog
+	lifecycle := NewLifecycle(LifecycleConfig{ShutdownTimeout: time.Second})
+	lifecycle.Add(log.component("database", false))
+	lifecycle.Add(log.component("listener", true))
+	lifecycle.Add(log.component("dispatcher", true))
+	lifecycle.Add(log.component("readiness", false))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.NoError(t, lifecycle.Run(ctx))
+	assert.Equal(t, []string{"readiness", "dispatcher", "listener", "database"}, log.stopped())
+}
+
+func TestLifecycleComponentFails(t *testing.T) {
+	var log stopLog
+	lifecycle := NewLifecycle(LifecycleConfig{ShutdownTimeout: time.Second})
+	lifecycle.Add(log.component("database", false))
+	lifecycle.Add(Component{Name: "HTTP server", Run: func(context.Context) error {
+		return errors.New("address already in use")
+	}})
+
+	err := lifecycle.Run(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "HTTP server: address already in use")
+	assert.Equal(t, []string{"database"}, log.stopped())
+}
+
+func TestLifecycleShutdownTimeout(t *testing.T) {
+	var log stopLog
+	stuck := make(chan struct{})
+	defer close(stuck)
+
+	lifecycle := NewLifecycle(LifecycleConfig{ShutdownTimeout: 50 * time.Millisecond})
+	lifecycle.Add(log.component("database", false))
+	lifecycle.Add(Component{Name: "worker", Run: func(context.Context) error {
+		<-stuck
+		return nil
+	}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := lifecycle.Run(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, []string{"database"}, log.stopped(), "database is closed even after the timeout")
+}
+
+func TestLifecycleShutdownWithoutRun(t *testing.T) {
+	var log stopLog
+	lifecycle := NewLifecycle(LifecycleConfig{ShutdownTimeout: time.Second})
+	lifecycle.Add(log.component("database", false))
+	lifecycle.Add(log.component("listener", true))
+
+	require.NoError(t, lifecycle.Shutdown())
+	assert.Equal(t, []string{"database"}, log.stopped())
+}
+
+func TestInFlightTransferCompletesOnShutdown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	coinService := mocks.NewMockCoinService(ctrl)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	coinService.EXPECT().SendCoins(gomock.Any(), services.TransactionParams{
+		Token:            "valid_token",
+		ReceiverUsername: "bob",
+		Amount:           100,
+	}).DoAndReturn(func(context.Context, services.TransactionParams) (int64, error) {
+		close(started)
+		<-release
+		return 1, nil
+	})
+
+	server, err := httpserver.NewServer(httpserver.ServerConfig{CoinService: coinService})
+	require.NoError(t, err)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	var databaseClosed sync.WaitGroup
+	databaseClosed.Add(1)
+	lifecycle := NewLifecycle(LifecycleConfig{ShutdownTimeout: 5 * time.Second})
+	lifecycle.Add(Component{Name: "database", Stop: func(context.Context) error {
+		databaseClosed.Done()
+		return nil
+	}})
+	lifecycle.Add(Component{
+		Name: "HTTP server",
+		Run:  func(context.Context) error { return server.Serve(listener) },
+		Stop: server.Shutdown,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+	go func() { stopped <- lifecycle.Run(ctx) }()
+
+	url := "http://" + listener.Addr().String() + "/api/sendCoin"
+	responses := make(chan *http.Response, 1)
+	go func() {
+		req, _ := http.NewRequest(http.MethodPost, url, strings.NewReader(`{"toUser": "bob", "amount": 100}`))
+		req.Header.Set("Authorization", "Bearer valid_token")
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		responses <- resp
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case <-stopped:
+		t.Fatal("shutdown finished before the transfer")
+	case <-time.After(100 * time.Millisecond):
+	}
+	_, err = net.DialTimeout("tcp", listener.Addr().String(), time.Second)
+	assert.Error(t, err, "new connections are refused during shutdown")
+
+	close(release)
+	resp := <-responses
+	require.NotNil(t, resp)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.NoError(t, <-stopped)
+	databaseClosed.Wait()
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/Blxssy/AvitoTest/config"
+	"github.com/Blxssy/AvitoTest/internal/ratelimit"
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"github.com/Blxssy/AvitoTest/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// reloader applies the settings that can change at runtime: the log level
+// and rate limits. It also re-reads the feature flags, so a reload doesn't
+// wait for their next refresh. Other transfer limits, the 2FA thresholds and delegation
+// limits, are kept in the database rather than in the configuration. The new configuration is parsed in full before anything is
+// applied, so an invalid one leaves the running configuration as it was.
+type reloader struct {
+	mu         sync.Mutex
+	configPath string
+	current    *config.Config
+	level      zap.AtomicLevel
+	rateLimits *ratelimit.Limits
+	flags      flagRefresher
+	logger     *zap.Logger
+}
+
+// flagRefresher re-reads the feature flags.
+type flagRefresher interface {
+	Refresh(ctx context.Context) error
+}
+
+func (r *reloader) Reload(ctx context.Context) (services.ReloadedConfig, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	log := logger.FromContext(ctx, r.logger)
+
+	cfg, err := config.Get(r.configPath)
+	if err != nil {
+		log.Error("configuration reload rejected", zap.Error(err))
+		return services.ReloadedConfig{}, err
+	}
+	limits, err := parseRateLimits(cfg.RateLimit)
+	if err != nil {
+		log.Error("configuration reload rejected", zap.Error(err))
+		return services.ReloadedConfig{}, err
+	}
+
+	next := *r.current
+	next.Logger.Level = cfg.Logger.Level
+	next.RateLimit.Auth = cfg.RateLimit.Auth
+	next.RateLimit.Transfer = cfg.RateLimit.Transfer
+	next.RateLimit.Default = cfg.RateLimit.Default
+
+	r.level.SetLevel(logger.ParseLevel(next.Logger.Level))
+	r.rateLimits.Set(limits)
+
+	reloaded := services.ReloadedConfig{
+		Applied:         config.Diff(r.current, &next),
+		RestartRequired: config.Diff(&next, cfg),
+	}
+	r.current = &next
+
+	// The flags are not part of the configuration: failing to read them
+	// keeps the ones in memory until the next refresh.
+	if r.flags != nil {
+		if err = r.flags.Refresh(ctx); err != nil {
+			log.Error("feature flags not refreshed", zap.Error(err))
+		}
+	}
+
+	log.Info("configuration reloaded", zap.Strings("applied", reloaded.Applied))
+	if len(reloaded.RestartRequired) > 0 {
+		log.Warn("changed settings apply after a restart", zap.Strings("settings", reloaded.RestartRequired))
+	}
+	return reloaded, nil
+}
+
+// watch reloads the configuration on every SIGHUP until ctx is done.
+func (r *reloader) watch(ctx context.Context) error {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-hangup:
+			// Failures are logged and the running configuration is kept.
+			_, _ = r.Reload(ctx)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+// Package coinctl implements the commands of coinctl, the tool operators use
+// for maintenance that has no API.
+package coinctl
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"io"
+	"slices"
+	"strings"
+)
+
+// ErrLedgerInconsistent is returned by "ledger verify" after it printed the
+// accounts whose balance doesn't match their history.
+var ErrLedgerInconsistent = errors.New("ledger is inconsistent")
+
+// UsageError is a mistake in the command line rather than a failed command.
+type UsageError struct {
+	Message string
+}
+
+func (e *UsageError) Error() string {
+	return e.Message
+}
+
+func usageErrorf(format string, args ...any) error {
+	return &UsageError{Message: fmt.Sprintf(format, args...)}
+}
+
+// Usage lists the commands.
+const Usage = `Usage: coinctl [-config file] [-o table|json] [-operator name] <command> [flags] [args]
+
+Commands:
+  user create [-balance n] [-admin] <username>   create a user; the password is read from stdin
+  user show <username>                           show a user and the adjustments of their balance
+  user freeze <username>                         stop a user from logging in and moving coins out
+  user unfreeze <username>
+  balance adjust -amount n -reason text <username>
+                                                 add n coins (negative to take) to a balance
+  tx list [-user username] [-before id] [-limit n]
+                                                 list transfers from the newest
+  tx show <id>
+  item list                                      list the catalog
+  item set <name> <price>                        add an item or change its price
+  item delete <name>
+  migrate up                                     apply all migrations
+  migrate down [-steps n]                        roll back the last n migrations, 1 by default
+  migrate version
+  ledger verify                                  check every balance against its history
+
+Flags go before arguments.
+`
+
+// Migrator runs the database migrations.
+type Migrator interface {
+	Up(ctx context.Context) (uint, error)
+	Down(ctx context.Context, steps int) (uint, error)
+	Version(ctx context.Context) (MigrationStatus, error)
+}
+
+// MigrationStatus is the version the database is at and the newest one this
+// build has.
+type MigrationStatus struct {
+	Version uint
+	Dirty   bool
+	Latest  uint
+}
+
+type CLI struct {
+	service  services.OperatorService
+	migrator Migrator
+	operator string
+	format   Format
+	stdin    io.Reader
+	stdout   io.Writer
+}
+
+type Config struct {
+	Service  services.OperatorService
+	Migrator Migrator
+	// Operator names whoever runs the command in the records it makes.
+	Operator string
+	Format   Format
+	Stdin    io.Reader
+	Stdout   io.Writer
+}
+
+func New(cfg Config) *CLI {
+	return &CLI{
+		service:  cfg.Service,
+		migrator: cfg.Migrator,
+		operator: cfg.Operator,
+		format:   cfg.Format,
+		stdin:    cfg.Stdin,
+		stdout:   cfg.Stdout,
+	}
+}
+
+type command struct {
+	name string
+	run  func(c *CLI, ctx context.Context, args []string) error
+}
+
+var commands = []command{
+	{"user create", (*CLI).createUser},
+	{"user show", (*CLI).showUser},
+	{"user freeze", (*CLI).freezeUser},
+	{"user unfreeze", (*CLI).unfreezeUser},
+	{"balance adjust", (*CLI).adjustBalance},
+	{"tx list", (*CLI).listTransactions},
+	{"tx show", (*CLI).showTransaction},
+	{"item list", (*CLI).listItems},
+	{"item set", (*CLI).setItem},
+	{"item delete", (*CLI).deleteItem},
+	{"migrate up", (*CLI).migrateUp},
+	{"migrate down", (*CLI).migrateDown},
+	{"migrate version", (*CLI).migrateVersion},
+	{"ledger verify", (*CLI).verifyLedger},
+}
+
+// Run runs the command named by the first two args.
+func (c *CLI) Run(ctx context.Context, args []string) error {
+	if len(args) < 2 {
+		return usageErrorf("missing command")
+	}
+
+	name := args[0] + " " + args[1]
+	i := slices.IndexFunc(commands, func(cmd command) bool { return cmd.name == name })
+	if i < 0 {
+		return usageErrorf("unknown command %q", name)
+	}
+	return commands[i].run(c, ctx, args[2:])
+}
+
+// parse parses the flags of a command and checks it got want arguments.
+func parse(fs *flag.FlagSet, args []string, want ...string) ([]string, error) {
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return nil, usageErrorf("%s: %v", fs.Name(), err)
+	}
+	if fs.NArg() != len(want) {
+		if len(want) == 0 {
+			return nil, usageErrorf("%s: takes no arguments, got %q", fs.Name(), fs.Args())
+		}
+		return nil, usageErrorf("%s: want arguments <%s>, got %q", fs.Name(), strings.Join(want, "> <"), fs.Args())
+	}
+	return fs.Args(), nil
+}
@@ -0,0 +1,156 @@
+package coinctl_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/Blxssy/AvitoTest/internal/coinctl"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/services"
+	servicemocks "github.com/Blxssy/AvitoTest/internal/services/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+type fakeMigrator struct {
+	status coinctl.MigrationStatus
+}
+
+func (m *fakeMigrator) Up(context.Context) (uint, error) {
+	m.status.Version = m.status.Latest
+	return m.status.Version, nil
+}
+
+func (m *fakeMigrator) Down(_ context.Context, steps int) (uint, error) {
+	m.status.Version -= uint(steps)
+	return m.status.Version, nil
+}
+
+func (m *fakeMigrator) Version(context.Context) (coinctl.MigrationStatus, error) {
+	return m.status, nil
+}
+
+func newCLI(t *testing.T, format coinctl.Format, stdin string) (*coinctl.CLI, *servicemocks.MockOperatorService, *bytes.Buffer) {
+	ctrl := gomock.NewController(t)
+	service := servicemocks.NewMockOperatorService(ctrl)
+	var stdout bytes.Buffer
+
+	cli := coinctl.New(coinctl.Config{
+		Service:  service,
+		Migrator: &fakeMigrator{status: coinctl.MigrationStatus{Version: 13, Latest: 13}},
+		Operator: "ops",
+		Format:   format,
+		Stdin:    strings.NewReader(stdin),
+		Stdout:   &stdout,
+	})
+	return cli, service, &stdout
+}
+
+func TestCreateUser(t *testing.T) {
+	ctx := context.Background()
+	cli, service, stdout := newCLI(t, coinctl.FormatJSON, "s3cret-password\n")
+
+	service.EXPECT().CreateUser(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, params services.CreateUserParams) (models.User, error) {
+		assert.Equal(t, "alice", params.Username)
+		assert.Equal(t, "s3cret-password", params.Password)
+		assert.Equal(t, 500, *params.Balance)
+		assert.True(t, params.IsAdmin)
+		return models.User{Username: "alice", Balance: 500, IsAdmin: true}, nil
+	})
+
+	err := cli.Run(ctx, []string{"user", "create", "-balance", "500", "-admin", "alice"})
+	assert.NoError(t, err)
+
+	var user coinctl.User
+	assert.NoError(t, json.Unmarshal(stdout.Bytes(), &user))
+	assert.Equal(t, coinctl.User{Username: "alice", Balance: 500, IsAdmin: true}, user)
+}
+
+func TestAdjustBalance(t *testing.T) {
+	ctx := context.Background()
+	cli, service, stdout := newCLI(t, coinctl.FormatTable, "")
+
+	service.EXPECT().AdjustBalance(ctx, services.AdjustBalanceParams{
+		Operator: "ops",
+		Username: "alice",
+		Amount:   -200,
+		Reason:   "refund of a duplicate transfer",
+	}).Return(800, nil)
+
+	err := cli.Run(ctx, []string{"balance", "adjust", "-amount", "-200", "-reason", "refund of a duplicate transfer", "alice"})
+	assert.NoError(t, err)
+	assert.Equal(t, "USERNAME  BALANCE\nalice     800\n", stdout.String())
+}
+
+func TestListTransactions(t *testing.T) {
+	ctx := context.Background()
+	cli, service, stdout := newCLI(t, coinctl.FormatTable, "")
+
+	service.EXPECT().ListTransactions(ctx, services.ListTransactionsParams{Username: "alice", Limit: 2}).Return([]models.Transaction{
+		{ID: 2, SenderUsername: "bob", ReceiverUsername: "alice", Amount: 10},
+		{ID: 1, SenderUsername: "alice", ReceiverUsername: "bob", Amount: 5, ActorUsername: "bot"},
+	}, nil)
+
+	err := cli.Run(ctx, []string{"tx", "list", "-user", "alice", "-limit", "2"})
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.Equal(t, []string{"ID", "FROM", "TO", "AMOUNT", "ACTOR", "AT"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"1", "alice", "bob", "5", "bot"}, strings.Fields(lines[2])[:5])
+}
+
+func TestVerifyLedger(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("consistent", func(t *testing.T) {
+		cli, service, stdout := newCLI(t, coinctl.FormatTable, "")
+		service.EXPECT().VerifyLedger(ctx).Return(nil, nil)
+
+		assert.NoError(t, cli.Run(ctx, []string{"ledger", "verify"}))
+		assert.Contains(t, stdout.String(), "every balance matches")
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		cli, service, stdout := newCLI(t, coinctl.FormatJSON, "")
+		service.EXPECT().VerifyLedger(ctx).Return([]models.LedgerMismatch{{Username: "alice", Balance: 1100, Expected: 1000}}, nil)
+
+		err := cli.Run(ctx, []string{"ledger", "verify"})
+		assert.ErrorIs(t, err, coinctl.ErrLedgerInconsistent)
+
+		var mismatches []coinctl.Mismatch
+		assert.NoError(t, json.Unmarshal(stdout.Bytes(), &mismatches))
+		assert.Equal(t, []coinctl.Mismatch{{Username: "alice", Balance: 1100, Expected: 1000}}, mismatches)
+	})
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	cli, _, stdout := newCLI(t, coinctl.FormatJSON, "")
+
+	assert.NoError(t, cli.Run(ctx, []string{"migrate", "down", "-steps", "2"}))
+
+	var migrations coinctl.Migrations
+	assert.NoError(t, json.Unmarshal(stdout.Bytes(), &migrations))
+	assert.Equal(t, coinctl.Migrations{Version: 11, Latest: 13}, migrations)
+}
+
+func TestUsageErrors(t *testing.T) {
+	ctx := context.Background()
+	cli, _, _ := newCLI(t, coinctl.FormatTable, "")
+
+	for _, args := range [][]string{
+		{"user"},
+		{"user", "delete", "alice"},
+		{"user", "show"},
+		{"tx", "show", "abc"},
+		{"item", "set", "cup", "cheap"},
+		{"migrate", "down", "-steps", "0"},
+		{"ledger", "verify", "now"},
+	} {
+		var usageErr *coinctl.UsageError
+		assert.ErrorAs(t, cli.Run(ctx, args), &usageErr, args)
+	}
+}
@@ -0,0 +1,299 @@
+package coinctl
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"io"
+	"strconv"
+	"strings"
+)
+
+func (c *CLI) createUser(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("user create", flag.ContinueOnError)
+	balance := fs.Int("balance", -1, "starting balance, the configured one by default")
+	admin := fs.Bool("admin", false, "make the user an admin")
+	args, err := parse(fs, args, "username")
+	if err != nil {
+		return err
+	}
+
+	// Not a flag, so it doesn't end up in the shell history.
+	password, err := c.readLine()
+	if err != nil {
+		return fmt.Errorf("reading the password from stdin: %w", err)
+	}
+
+	params := services.CreateUserParams{
+		Username: args[0],
+		Password: password,
+		IsAdmin:  *admin,
+	}
+	if *balance >= 0 {
+		params.Balance = balance
+	}
+	user, err := c.service.CreateUser(ctx, params)
+	if err != nil {
+		return fmt.Errorf("c.service.CreateUser: %w", err)
+	}
+
+	u := newUser(user)
+	return c.print(u, usersTable(u))
+}
+
+func (c *CLI) readLine() (string, error) {
+	line, err := bufio.NewReader(c.stdin).ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+type Account struct {
+	User        User         `json:"user"`
+	Adjustments []Adjustment `json:"adjustments"`
+}
+
+func (c *CLI) showUser(ctx context.Context, args []string) error {
+	args, err := parse(flag.NewFlagSet("user show", flag.ContinueOnError), args, "username")
+	if err != nil {
+		return err
+	}
+	return c.printAccount(ctx, args[0])
+}
+
+func (c *CLI) printAccount(ctx context.Context, username string) error {
+	account, err := c.service.GetAccount(ctx, username)
+	if err != nil {
+		return fmt.Errorf("c.service.GetAccount: %w", err)
+	}
+
+	a := Account{
+		User:        newUser(account.User),
+		Adjustments: make([]Adjustment, len(account.Adjustments)),
+	}
+	for i, adjustment := range account.Adjustments {
+		a.Adjustments[i] = newAdjustment(adjustment)
+	}
+	return c.print(a, usersTable(a.User), adjustmentsTable(a.Adjustments))
+}
+
+func (c *CLI) freezeUser(ctx context.Context, args []string) error {
+	return c.setFrozen(ctx, "user freeze", args, true)
+}
+
+func (c *CLI) unfreezeUser(ctx context.Context, args []string) error {
+	return c.setFrozen(ctx, "user unfreeze", args, false)
+}
+
+func (c *CLI) setFrozen(ctx context.Context, name string, args []string, frozen bool) error {
+	args, err := parse(flag.NewFlagSet(name, flag.ContinueOnError), args, "username")
+	if err != nil {
+		return err
+	}
+
+	if err = c.service.SetAccountFrozen(ctx, services.SetAccountFrozenParams{
+		Username: args[0],
+		Frozen:   frozen,
+	}); err != nil {
+		return fmt.Errorf("c.service.SetAccountFrozen: %w", err)
+	}
+	return c.printAccount(ctx, args[0])
+}
+
+type Balance struct {
+	Username string `json:"username"`
+	Balance  int    `json:"balance"`
+}
+
+func (c *CLI) adjustBalance(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("balance adjust", flag.ContinueOnError)
+	amount := fs.Int("amount", 0, "coins to add, negative to take")
+	reason := fs.String("reason", "", "why the balance is changed")
+	args, err := parse(fs, args, "username")
+	if err != nil {
+		return err
+	}
+
+	balance, err := c.service.AdjustBalance(ctx, services.AdjustBalanceParams{
+		Operator: c.operator,
+		Username: args[0],
+		Amount:   *amount,
+		Reason:   *reason,
+	})
+	if err != nil {
+		return fmt.Errorf("c.service.AdjustBalance: %w", err)
+	}
+
+	b := Balance{Username: args[0], Balance: balance}
+	return c.print(b, table{
+		header: []string{"USERNAME", "BALANCE"},
+		rows:   [][]string{{b.Username, strconv.Itoa(b.Balance)}},
+	})
+}
+
+func (c *CLI) listTransactions(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("tx list", flag.ContinueOnError)
+	username := fs.String("user", "", "only transfers from or to this user")
+	before := fs.Int64("before", 0, "only transfers older than this id")
+	limit := fs.Int("limit", 0, "how many transfers to list, 50 by default")
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+
+	transactions, err := c.service.ListTransactions(ctx, services.ListTransactionsParams{
+		Username: *username,
+		BeforeID: *before,
+		Limit:    *limit,
+	})
+	if err != nil {
+		return fmt.Errorf("c.service.ListTransactions: %w", err)
+	}
+
+	list := make([]Transaction, len(transactions))
+	for i, transaction := range transactions {
+		list[i] = newTransaction(transaction)
+	}
+	return c.print(list, transactionsTable(list...))
+}
+
+func (c *CLI) showTransaction(ctx context.Context, args []string) error {
+	args, err := parse(flag.NewFlagSet("tx show", flag.ContinueOnError), args, "id")
+	if err != nil {
+		return err
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || id <= 0 {
+		return usageErrorf("tx show: invalid transaction id %q", args[0])
+	}
+
+	transaction, err := c.service.GetTransaction(ctx, id)
+	if err != nil {
+		return fmt.Errorf("c.service.GetTransaction: %w", err)
+	}
+
+	t := newTransaction(transaction)
+	return c.print(t, transactionsTable(t))
+}
+
+func (c *CLI) listItems(ctx context.Context, args []string) error {
+	if _, err := parse(flag.NewFlagSet("item list", flag.ContinueOnError), args); err != nil {
+		return err
+	}
+
+	items, err := c.service.ListItems(ctx)
+	if err != nil {
+		return fmt.Errorf("c.service.ListItems: %w", err)
+	}
+
+	list := make([]Item, len(items))
+	for i, item := range items {
+		list[i] = Item{Name: item.Name, Price: item.Price}
+	}
+	return c.print(list, itemsTable(list...))
+}
+
+func (c *CLI) setItem(ctx context.Context, args []string) error {
+	args, err := parse(flag.NewFlagSet("item set", flag.ContinueOnError), args, "name", "price")
+	if err != nil {
+		return err
+	}
+	price, err := strconv.Atoi(args[1])
+	if err != nil {
+		return usageErrorf("item set: invalid price %q", args[1])
+	}
+
+	if err = c.service.SaveItem(ctx, models.Item{Name: args[0], Price: price}); err != nil {
+		return fmt.Errorf("c.service.SaveItem: %w", err)
+	}
+
+	item := Item{Name: args[0], Price: price}
+	return c.print(item, itemsTable(item))
+}
+
+func (c *CLI) deleteItem(ctx context.Context, args []string) error {
+	args, err := parse(flag.NewFlagSet("item delete", flag.ContinueOnError), args, "name")
+	if err != nil {
+		return err
+	}
+
+	if err = c.service.DeleteItem(ctx, args[0]); err != nil {
+		return fmt.Errorf("c.service.DeleteItem: %w", err)
+	}
+	return nil
+}
+
+func (c *CLI) migrateUp(ctx context.Context, args []string) error {
+	if _, err := parse(flag.NewFlagSet("migrate up", flag.ContinueOnError), args); err != nil {
+		return err
+	}
+
+	if _, err := c.migrator.Up(ctx); err != nil {
+		return fmt.Errorf("c.migrator.Up: %w", err)
+	}
+	return c.printMigrations(ctx)
+}
+
+func (c *CLI) migrateDown(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+	steps := fs.Int("steps", 1, "how many migrations to roll back")
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+	if *steps <= 0 {
+		return usageErrorf("migrate down: -steps must be positive")
+	}
+
+	if _, err := c.migrator.Down(ctx, *steps); err != nil {
+		return fmt.Errorf("c.migrator.Down: %w", err)
+	}
+	return c.printMigrations(ctx)
+}
+
+func (c *CLI) migrateVersion(ctx context.Context, args []string) error {
+	if _, err := parse(flag.NewFlagSet("migrate version", flag.ContinueOnError), args); err != nil {
+		return err
+	}
+	return c.printMigrations(ctx)
+}
+
+func (c *CLI) printMigrations(ctx context.Context) error {
+	status, err := c.migrator.Version(ctx)
+	if err != nil {
+		return fmt.Errorf("c.migrator.Version: %w", err)
+	}
+
+	m := Migrations(status)
+	return c.print(m, migrationsTable(m))
+}
+
+func (c *CLI) verifyLedger(ctx context.Context, args []string) error {
+	if _, err := parse(flag.NewFlagSet("ledger verify", flag.ContinueOnError), args); err != nil {
+		return err
+	}
+
+	mismatches, err := c.service.VerifyLedger(ctx)
+	if err != nil {
+		return fmt.Errorf("c.service.VerifyLedger: %w", err)
+	}
+
+	list := make([]Mismatch, len(mismatches))
+	for i, m := range mismatches {
+		list[i] = Mismatch(m)
+	}
+	if len(list) == 0 && c.format == FormatTable {
+		_, err = fmt.Fprintln(c.stdout, "every balance matches its history")
+		return err
+	}
+	if err = c.print(list, mismatchesTable(list)); err != nil {
+		return err
+	}
+	if len(list) > 0 {
+		return ErrLedgerInconsistent
+	}
+	return nil
+}
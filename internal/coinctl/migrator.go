@@ -0,0 +1,48 @@
+package coinctl
+
+import (
+	"context"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/config"
+	"github.com/Blxssy/AvitoTest/pkg/postgres"
+	"github.com/jmoiron/sqlx"
+)
+
+type migrator struct {
+	db  *sqlx.DB
+	cfg config.PostgresConfig
+}
+
+// NewMigrator runs the migrations in cfg.PathToMigrations, the same ones the
+// app applies on start.
+func NewMigrator(db *sqlx.DB, cfg config.PostgresConfig) Migrator {
+	return &migrator{db: db, cfg: cfg}
+}
+
+func (m *migrator) Up(context.Context) (uint, error) {
+	version, err := postgres.RunMigrations(m.db.DB, m.cfg)
+	if err != nil {
+		return 0, fmt.Errorf("postgres.RunMigrations: %w", err)
+	}
+	return version, nil
+}
+
+func (m *migrator) Down(_ context.Context, steps int) (uint, error) {
+	version, err := postgres.RollbackMigrations(m.db.DB, m.cfg, steps)
+	if err != nil {
+		return 0, fmt.Errorf("postgres.RollbackMigrations: %w", err)
+	}
+	return version, nil
+}
+
+func (m *migrator) Version(ctx context.Context) (MigrationStatus, error) {
+	version, dirty, err := postgres.MigrationVersion(ctx, m.db)
+	if err != nil {
+		return MigrationStatus{}, fmt.Errorf("postgres.MigrationVersion: %w", err)
+	}
+	latest, err := postgres.LatestMigration(m.cfg.PathToMigrations)
+	if err != nil {
+		return MigrationStatus{}, fmt.Errorf("postgres.LatestMigration: %w", err)
+	}
+	return MigrationStatus{Version: version, Dirty: dirty, Latest: latest}, nil
+}
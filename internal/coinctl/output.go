@@ -0,0 +1,229 @@
+package coinctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Format is how command results are printed.
+type Format string
+
+const (
+	FormatTable Format = "table"
+	FormatJSON  Format = "json"
+)
+
+// ParseFormat accepts "table" and "json".
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FormatTable, FormatJSON:
+		return f, nil
+	}
+	return "", usageErrorf("unknown output format %q, want table or json", s)
+}
+
+// table is the form of a result printed as a table.
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+// print writes v as JSON, or the tables as text separated by blank lines.
+// Empty tables are skipped, apart from the first one.
+func (c *CLI) print(v any, tables ...table) error {
+	if c.format == FormatJSON {
+		enc := json.NewEncoder(c.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	for i, t := range tables {
+		if i > 0 && len(t.rows) == 0 {
+			continue
+		}
+		if i > 0 {
+			if _, err := fmt.Fprintln(c.stdout); err != nil {
+				return err
+			}
+		}
+		if err := writeTable(c.stdout, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeTable(w io.Writer, t table) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(t.header, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+type User struct {
+	Username         string     `json:"username"`
+	Balance          int        `json:"balance"`
+	IsAdmin          bool       `json:"isAdmin"`
+	IsServiceAccount bool       `json:"isServiceAccount"`
+	FrozenAt         *time.Time `json:"frozenAt"`
+}
+
+func newUser(user models.User) User {
+	return User{
+		Username:         user.Username,
+		Balance:          user.Balance,
+		IsAdmin:          user.IsAdmin,
+		IsServiceAccount: user.IsServiceAccount,
+		FrozenAt:         user.FrozenAt,
+	}
+}
+
+func usersTable(users ...User) table {
+	t := table{header: []string{"USERNAME", "BALANCE", "ADMIN", "SERVICE", "FROZEN"}}
+	for _, u := range users {
+		frozen := "-"
+		if u.FrozenAt != nil {
+			frozen = formatTime(*u.FrozenAt)
+		}
+		t.rows = append(t.rows, []string{
+			u.Username,
+			strconv.Itoa(u.Balance),
+			strconv.FormatBool(u.IsAdmin),
+			strconv.FormatBool(u.IsServiceAccount),
+			frozen,
+		})
+	}
+	return t
+}
+
+type Adjustment struct {
+	ID        int64     `json:"id"`
+	Amount    int       `json:"amount"`
+	Reason    string    `json:"reason"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func newAdjustment(adjustment models.BalanceAdjustment) Adjustment {
+	return Adjustment{
+		ID:        adjustment.ID,
+		Amount:    adjustment.Amount,
+		Reason:    adjustment.Reason,
+		CreatedBy: adjustment.CreatedBy,
+		CreatedAt: adjustment.CreatedAt,
+	}
+}
+
+func adjustmentsTable(adjustments []Adjustment) table {
+	t := table{header: []string{"ADJUSTMENT", "AMOUNT", "REASON", "BY", "AT"}}
+	for _, a := range adjustments {
+		t.rows = append(t.rows, []string{
+			strconv.FormatInt(a.ID, 10),
+			strconv.Itoa(a.Amount),
+			a.Reason,
+			a.CreatedBy,
+			formatTime(a.CreatedAt),
+		})
+	}
+	return t
+}
+
+type Transaction struct {
+	ID        uint32    `json:"id"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Amount    int       `json:"amount"`
+	Actor     string    `json:"actor,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func newTransaction(transaction models.Transaction) Transaction {
+	return Transaction{
+		ID:        transaction.ID,
+		From:      transaction.SenderUsername,
+		To:        transaction.ReceiverUsername,
+		Amount:    transaction.Amount,
+		Actor:     transaction.ActorUsername,
+		CreatedAt: transaction.CreatedAt,
+	}
+}
+
+func transactionsTable(transactions ...Transaction) table {
+	t := table{header: []string{"ID", "FROM", "TO", "AMOUNT", "ACTOR", "AT"}}
+	for _, tr := range transactions {
+		actor := tr.Actor
+		if actor == "" {
+			actor = "-"
+		}
+		t.rows = append(t.rows, []string{
+			strconv.FormatUint(uint64(tr.ID), 10),
+			tr.From,
+			tr.To,
+			strconv.Itoa(tr.Amount),
+			actor,
+			formatTime(tr.CreatedAt),
+		})
+	}
+	return t
+}
+
+type Item struct {
+	Name  string `json:"name"`
+	Price int    `json:"price"`
+}
+
+func itemsTable(items ...Item) table {
+	t := table{header: []string{"NAME", "PRICE"}}
+	for _, item := range items {
+		t.rows = append(t.rows, []string{item.Name, strconv.Itoa(item.Price)})
+	}
+	return t
+}
+
+type Migrations struct {
+	Version uint `json:"version"`
+	Dirty   bool `json:"dirty"`
+	Latest  uint `json:"latest"`
+}
+
+func migrationsTable(m Migrations) table {
+	return table{
+		header: []string{"VERSION", "DIRTY", "LATEST"},
+		rows: [][]string{{
+			strconv.FormatUint(uint64(m.Version), 10),
+			strconv.FormatBool(m.Dirty),
+			strconv.FormatUint(uint64(m.Latest), 10),
+		}},
+	}
+}
+
+type Mismatch struct {
+	Username string `json:"username"`
+	Balance  int    `json:"balance"`
+	Expected int    `json:"expected"`
+}
+
+func mismatchesTable(mismatches []Mismatch) table {
+	t := table{header: []string{"USERNAME", "BALANCE", "EXPECTED", "DIFFERENCE"}}
+	for _, m := range mismatches {
+		t.rows = append(t.rows, []string{
+			m.Username,
+			strconv.Itoa(m.Balance),
+			strconv.Itoa(m.Expected),
+			strconv.Itoa(m.Balance - m.Expected),
+		})
+	}
+	return t
+}
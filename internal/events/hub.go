@@ -0,0 +1,122 @@
+package events
+
+import (
+	"sync"
+
+	"github.com/Blxssy/AvitoTest/internal/models"
+)
+
+const defaultBufferSize = 64
+
+// Hub fans events out to the subscribers of this process. A subscriber that
+// falls behind by more than its buffer is dropped: its channel is closed and
+// the client is expected to reconnect and resume from the last event it saw.
+type Hub struct {
+	mu         sync.RWMutex
+	subs       map[string]map[*Subscription]struct{}
+	bufferSize int
+	closed     bool
+}
+
+type HubConfig struct {
+	BufferSize int
+}
+
+func NewHub(cfg HubConfig) *Hub {
+	bufferSize := cfg.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+
+	return &Hub{
+		subs:       make(map[string]map[*Subscription]struct{}),
+		bufferSize: bufferSize,
+	}
+}
+
+type Subscription struct {
+	hub      *Hub
+	username string
+	events   chan models.Event
+	once     sync.Once
+}
+
+// Subscribe registers a subscriber for the events of username. After Close
+// the subscription comes back already closed.
+func (h *Hub) Subscribe(username string) *Subscription {
+	sub := &Subscription{
+		hub:      h,
+		username: username,
+		events:   make(chan models.Event, h.bufferSize),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		sub.once.Do(func() { close(sub.events) })
+		return sub
+	}
+
+	if h.subs[username] == nil {
+		h.subs[username] = make(map[*Subscription]struct{})
+	}
+	h.subs[username][sub] = struct{}{}
+
+	return sub
+}
+
+func (h *Hub) Publish(event models.Event) {
+	h.mu.RLock()
+	var slow []*Subscription
+	for sub := range h.subs[event.Username] {
+		select {
+		case sub.events <- event:
+		default:
+			slow = append(slow, sub)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, sub := range slow {
+		sub.Close()
+	}
+}
+
+// Close ends every subscription, as if all subscribers fell behind, so that
+// their clients reconnect to another instance and resume there. Later
+// subscriptions are closed right away.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	h.closed = true
+	var subs []*Subscription
+	for _, userSubs := range h.subs {
+		for sub := range userSubs {
+			subs = append(subs, sub)
+		}
+	}
+	h.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.Close()
+	}
+}
+
+// Events is closed once the subscription is closed, either by the owner or by
+// the hub after the subscriber fell behind.
+func (s *Subscription) Events() <-chan models.Event {
+	return s.events
+}
+
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.hub.mu.Lock()
+		defer s.hub.mu.Unlock()
+
+		delete(s.hub.subs[s.username], s)
+		if len(s.hub.subs[s.username]) == 0 {
+			delete(s.hub.subs, s.username)
+		}
+		close(s.events)
+	})
+}
@@ -0,0 +1,65 @@
+package events_test
+
+import (
+	"testing"
+
+	"github.com/Blxssy/AvitoTest/internal/events"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHubPublish(t *testing.T) {
+	hub := events.NewHub(events.HubConfig{})
+
+	alice := hub.Subscribe("alice")
+	defer alice.Close()
+	bob := hub.Subscribe("bob")
+	defer bob.Close()
+
+	hub.Publish(models.Event{ID: 1, Username: "alice", Type: models.EventCoinsReceived})
+
+	assert.Equal(t, int64(1), (<-alice.Events()).ID)
+	assert.Empty(t, bob.Events())
+}
+
+func TestHubDropsSlowSubscriber(t *testing.T) {
+	hub := events.NewHub(events.HubConfig{BufferSize: 1})
+
+	sub := hub.Subscribe("alice")
+
+	hub.Publish(models.Event{ID: 1, Username: "alice"})
+	hub.Publish(models.Event{ID: 2, Username: "alice"})
+
+	event, ok := <-sub.Events()
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), event.ID)
+
+	_, ok = <-sub.Events()
+	assert.False(t, ok)
+
+	sub.Close()
+}
+
+func TestHubClose(t *testing.T) {
+	hub := events.NewHub(events.HubConfig{})
+
+	alice := hub.Subscribe("alice")
+	bob := hub.Subscribe("bob")
+
+	hub.Close()
+
+	_, ok := <-alice.Events()
+	assert.False(t, ok)
+	_, ok = <-bob.Events()
+	assert.False(t, ok)
+
+	// Owners still close their subscriptions afterwards.
+	alice.Close()
+	bob.Close()
+
+	// Clients connecting while the server shuts down get no live stream.
+	late := hub.Subscribe("alice")
+	_, ok = <-late.Events()
+	assert.False(t, ok)
+	late.Close()
+}
@@ -0,0 +1,93 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+)
+
+const reconnectDelay = 5 * time.Second
+
+// Listener relays events announced through Postgres NOTIFY to the local hub, so
+// every replica delivers events committed by any other replica.
+type Listener struct {
+	dataSource string
+	channel    string
+	hub        *Hub
+	logger     *zap.Logger
+}
+
+type ListenerConfig struct {
+	DataSource string
+	Channel    string
+	Hub        *Hub
+	Logger     *zap.Logger
+}
+
+func NewListener(cfg ListenerConfig) *Listener {
+	return &Listener{
+		dataSource: cfg.DataSource,
+		channel:    cfg.Channel,
+		hub:        cfg.Hub,
+		logger:     cfg.Logger,
+	}
+}
+
+// Run listens until ctx is done, reconnecting whenever the connection is lost.
+// Events committed while disconnected are not replayed here; clients recover
+// them by resuming from their last event ID.
+func (l *Listener) Run(ctx context.Context) error {
+	for {
+		err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		l.logger.Error(fmt.Sprintf("events listener disconnected: %v", err))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+func (l *Listener) listen(ctx context.Context) error {
+	conn, err := pgx.Connect(ctx, l.dataSource)
+	if err != nil {
+		return fmt.Errorf("pgx.Connect: %w", err)
+	}
+	defer func() {
+		if err := conn.Close(context.Background()); err != nil {
+			return
+		}
+	}()
+
+	if _, err = conn.Exec(ctx, "listen "+pgx.Identifier{l.channel}.Sanitize()); err != nil {
+		return fmt.Errorf("conn.Exec (listen): %w", err)
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("conn.WaitForNotification: %w", err)
+		}
+
+		var event models.Event
+		if err = json.Unmarshal([]byte(notification.Payload), &event); err != nil {
+			l.logger.Error(fmt.Sprintf("invalid event notification: %v", err))
+			continue
+		}
+		if event.Username == "" {
+			l.logger.Error("invalid event notification: missing username")
+			continue
+		}
+
+		l.hub.Publish(event)
+	}
+}
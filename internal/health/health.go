@@ -0,0 +1,108 @@
+// Package health tells load balancers whether the service can take traffic.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// ErrDraining fails readiness once shutdown has begun.
+var ErrDraining = errors.New("shutting down")
+
+// Check is a dependency the service needs to serve requests.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+type Checker struct {
+	checks    []Check
+	timeout   time.Duration
+	startedAt time.Time
+	draining  atomic.Bool
+}
+
+// Config lists the checks of readiness; each gets Timeout to finish.
+type Config struct {
+	Checks  []Check
+	Timeout time.Duration
+}
+
+func NewChecker(cfg Config) *Checker {
+	return &Checker{
+		checks:    cfg.Checks,
+		timeout:   cfg.Timeout,
+		startedAt: time.Now(),
+	}
+}
+
+// Result is the outcome of one check; Err is nil when it passed.
+type Result struct {
+	Name string
+	Err  error
+}
+
+// Ready runs every check and reports whether all of them passed.
+func (c *Checker) Ready(ctx context.Context) (bool, []Result) {
+	if c.draining.Load() {
+		return false, []Result{{Name: "shutdown", Err: ErrDraining}}
+	}
+
+	ready := true
+	results := make([]Result, len(c.checks))
+	for i, check := range c.checks {
+		results[i] = Result{Name: check.Name, Err: c.run(ctx, check)}
+		if results[i].Err != nil {
+			ready = false
+		}
+	}
+	return ready, results
+}
+
+func (c *Checker) run(ctx context.Context, check Check) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	return check.Run(ctx)
+}
+
+// Drain makes readiness fail from now on, so load balancers stop sending
+// requests before the servers shut down.
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+func (c *Checker) StartedAt() time.Time {
+	return c.startedAt
+}
+
+// DatabaseCheck passes while the database answers ping.
+func DatabaseCheck(ping func(ctx context.Context) error) Check {
+	return Check{Name: "database", Run: ping}
+}
+
+// SchemaCheck passes while the database schema is at the version this build
+// was written for. A newer schema means another release migrated it.
+func SchemaCheck(version func(ctx context.Context) (uint, bool, error), expected uint) Check {
+	return Check{
+		Name: "migrations",
+		Run: func(ctx context.Context) error {
+			current, dirty, err := version(ctx)
+			if err != nil {
+				return err
+			}
+			if dirty {
+				return fmt.Errorf("migration %d failed halfway", current)
+			}
+			if current != expected {
+				return fmt.Errorf("schema version is %d, expected %d", current, expected)
+			}
+			return nil
+		},
+	}
+}
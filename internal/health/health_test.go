@@ -0,0 +1,97 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Blxssy/AvitoTest/internal/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func schemaAt(version uint, dirty bool) func(ctx context.Context) (uint, bool, error) {
+	return func(ctx context.Context) (uint, bool, error) {
+		return version, dirty, nil
+	}
+}
+
+func TestReady(t *testing.T) {
+	ping := func(ctx context.Context) error { return nil }
+
+	tests := []struct {
+		name   string
+		checks []health.Check
+		ready  bool
+		failed string
+	}{
+		{
+			name:   "all pass",
+			checks: []health.Check{health.DatabaseCheck(ping), health.SchemaCheck(schemaAt(11, false), 11)},
+			ready:  true,
+		},
+		{
+			name: "database down",
+			checks: []health.Check{
+				health.DatabaseCheck(func(ctx context.Context) error { return errors.New("connection refused") }),
+				health.SchemaCheck(schemaAt(11, false), 11),
+			},
+			failed: "database",
+		},
+		{
+			name:   "schema behind",
+			checks: []health.Check{health.DatabaseCheck(ping), health.SchemaCheck(schemaAt(10, false), 11)},
+			failed: "migrations",
+		},
+		{
+			name:   "dirty migration",
+			checks: []health.Check{health.DatabaseCheck(ping), health.SchemaCheck(schemaAt(11, true), 11)},
+			failed: "migrations",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := health.NewChecker(health.Config{Checks: tt.checks})
+
+			ready, results := checker.Ready(context.Background())
+			assert.Equal(t, tt.ready, ready)
+			require.Len(t, results, len(tt.checks))
+			for _, result := range results {
+				if result.Name == tt.failed {
+					assert.Error(t, result.Err)
+				} else {
+					assert.NoError(t, result.Err)
+				}
+			}
+		})
+	}
+}
+
+func TestReadyTimeout(t *testing.T) {
+	checker := health.NewChecker(health.Config{
+		Checks: []health.Check{health.DatabaseCheck(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})},
+		Timeout: 10 * time.Millisecond,
+	})
+
+	ready, results := checker.Ready(context.Background())
+	assert.False(t, ready)
+	assert.ErrorIs(t, results[0].Err, context.DeadlineExceeded)
+}
+
+func TestDrain(t *testing.T) {
+	checker := health.NewChecker(health.Config{})
+
+	ready, _ := checker.Ready(context.Background())
+	require.True(t, ready)
+
+	checker.Drain()
+	ready, results := checker.Ready(context.Background())
+	assert.False(t, ready)
+	require.Len(t, results, 1)
+	assert.ErrorIs(t, results[0].Err, health.ErrDraining)
+}
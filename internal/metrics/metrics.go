@@ -0,0 +1,125 @@
+// Package metrics exposes Prometheus metrics of the HTTP API, the coin
+// business and the database.
+package metrics
+
+import (
+	"database/sql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"strconv"
+	"time"
+)
+
+const namespace = "coin"
+
+// Metrics owns a registry with every metric of the service. Each Metrics has
+// its own registry, so tests can create as many as they need.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.HistogramVec
+
+	transfers    prometheus.Counter
+	coinsMoved   prometheus.Counter
+	purchases    *prometheus.CounterVec
+	authAttempts *prometheus.CounterVec
+
+	dbQueries     *prometheus.HistogramVec
+	dbQueryErrors *prometheus.CounterVec
+}
+
+// Config adds the pool stats of DB to the metrics when set.
+type Config struct {
+	DB *sql.DB
+}
+
+func New(cfg Config) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+
+		httpRequests: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Duration of HTTP requests by route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+
+		transfers: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transfers_total",
+			Help:      "Completed coin transfers.",
+		}),
+		coinsMoved: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transferred_coins_total",
+			Help:      "Coins moved by completed transfers.",
+		}),
+		purchases: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "purchases_total",
+			Help:      "Completed purchases by item.",
+		}, []string{"item"}),
+		authAttempts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "auth_attempts_total",
+			Help:      "Login attempts by method (password, otp, oidc) and result (success, failure).",
+		}, []string{"method", "result"}),
+
+		dbQueries: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Duration of repository calls by method.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"method"}),
+		dbQueryErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "db_query_errors_total",
+			Help:      "Failed repository calls by method; missing rows are not counted.",
+		}, []string{"method"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.transfers,
+		m.coinsMoved,
+		m.purchases,
+		m.authAttempts,
+		m.dbQueries,
+		m.dbQueryErrors,
+	)
+	if cfg.DB != nil {
+		m.registry.MustRegister(collectors.NewDBStatsCollector(cfg.DB, namespace))
+	}
+
+	return m
+}
+
+// Registry returns the registry to serve or to gather in tests.
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// ObserveHTTPRequest records a request; route is the route pattern, such as
+// "/api/buy/:item", so the number of series stays bounded.
+func (m *Metrics) ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	m.httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+func (m *Metrics) ObserveTransfer(amount int) {
+	m.transfers.Inc()
+	m.coinsMoved.Add(float64(amount))
+}
+
+func (m *Metrics) ObservePurchase(item string) {
+	m.purchases.WithLabelValues(item).Inc()
+}
+
+func (m *Metrics) ObserveLogin(method string, success bool) {
+	result := "failure"
+	if success {
+		result = "success"
+	}
+	m.authAttempts.WithLabelValues(method, result).Inc()
+}
@@ -0,0 +1,78 @@
+package metrics_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/Blxssy/AvitoTest/internal/metrics"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/repo"
+	"github.com/Blxssy/AvitoTest/internal/repo/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestCoinRepository(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	m := metrics.New(metrics.Config{})
+	coinRepo := metrics.NewCoinRepository(repoMock, m)
+
+	ctx := context.Background()
+	repoMock.EXPECT().GetBalance(ctx, repo.GetBalanceParams{Username: "alice"}).Return(1000, nil)
+	repoMock.EXPECT().GetUserByUsername(ctx, "bob").Return(nil, sql.ErrNoRows)
+	repoMock.EXPECT().GetItem(ctx, "cup").Return(models.Item{}, errors.New("connection reset"))
+
+	balance, err := coinRepo.GetBalance(ctx, repo.GetBalanceParams{Username: "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, 1000, balance)
+
+	_, err = coinRepo.GetUserByUsername(ctx, "bob")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = coinRepo.GetItem(ctx, "cup")
+	assert.Error(t, err)
+
+	count, err := testutil.GatherAndCount(m.Registry(), "coin_db_query_duration_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 3, count, "one series per method")
+
+	// Missing rows are a normal answer and are not counted as errors.
+	assert.NoError(t, testutil.GatherAndCompare(m.Registry(), strings.NewReader(`
+# HELP coin_db_query_errors_total Failed repository calls by method; missing rows are not counted.
+# TYPE coin_db_query_errors_total counter
+coin_db_query_errors_total{method="GetItem"} 1
+`), "coin_db_query_errors_total"))
+}
+
+func TestBusinessCounters(t *testing.T) {
+	m := metrics.New(metrics.Config{})
+
+	m.ObserveTransfer(100)
+	m.ObserveTransfer(50)
+	m.ObservePurchase("cup")
+	m.ObserveLogin("password", true)
+	m.ObserveLogin("password", false)
+	m.ObserveLogin("password", false)
+
+	assert.NoError(t, testutil.GatherAndCompare(m.Registry(), strings.NewReader(`
+# HELP coin_transfers_total Completed coin transfers.
+# TYPE coin_transfers_total counter
+coin_transfers_total 2
+# HELP coin_transferred_coins_total Coins moved by completed transfers.
+# TYPE coin_transferred_coins_total counter
+coin_transferred_coins_total 150
+# HELP coin_purchases_total Completed purchases by item.
+# TYPE coin_purchases_total counter
+coin_purchases_total{item="cup"} 1
+# HELP coin_auth_attempts_total Login attempts by method (password, otp, oidc) and result (success, failure).
+# TYPE coin_auth_attempts_total counter
+coin_auth_attempts_total{method="password",result="failure"} 2
+coin_auth_attempts_total{method="password",result="success"} 1
+`), "coin_transfers_total", "coin_transferred_coins_total", "coin_purchases_total", "coin_auth_attempts_total"))
+}
@@ -0,0 +1,337 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/repo"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+type coinRepository struct {
+	next    repo.CoinRepository
+	metrics *Metrics
+}
+
+// NewCoinRepository wraps next to time each of its calls.
+func NewCoinRepository(next repo.CoinRepository, m *Metrics) repo.CoinRepository {
+	return &coinRepository{
+		next:    next,
+		metrics: m,
+	}
+}
+
+func (r *coinRepository) observe(method string, start time.Time, err *error) {
+	r.metrics.dbQueries.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	// Missing rows and insufficient funds are answers, not failures.
+	if *err != nil && !errors.Is(*err, sql.ErrNoRows) && !errors.Is(*err, repo.InsufficientFundsError) {
+		r.metrics.dbQueryErrors.WithLabelValues(method).Inc()
+	}
+}
+
+func (r *coinRepository) GetBalance(ctx context.Context, params repo.GetBalanceParams) (_ int, err error) {
+	defer r.observe("GetBalance", time.Now(), &err)
+	return r.next.GetBalance(ctx, params)
+}
+
+func (r *coinRepository) CreateUser(ctx context.Context, params repo.CreateUserParams) (err error) {
+	defer r.observe("CreateUser", time.Now(), &err)
+	return r.next.CreateUser(ctx, params)
+}
+
+func (r *coinRepository) UpdatePassword(ctx context.Context, params repo.UpdatePasswordParams) (err error) {
+	defer r.observe("UpdatePassword", time.Now(), &err)
+	return r.next.UpdatePassword(ctx, params)
+}
+
+func (r *coinRepository) CreatePasswordReset(ctx context.Context, params repo.CreatePasswordResetParams) (err error) {
+	defer r.observe("CreatePasswordReset", time.Now(), &err)
+	return r.next.CreatePasswordReset(ctx, params)
+}
+
+func (r *coinRepository) ResetPassword(ctx context.Context, params repo.ResetPasswordParams) (_ string, err error) {
+	defer r.observe("ResetPassword", time.Now(), &err)
+	return r.next.ResetPassword(ctx, params)
+}
+
+func (r *coinRepository) GetUserByUsername(ctx context.Context, username string) (_ *models.User, err error) {
+	defer r.observe("GetUserByUsername", time.Now(), &err)
+	return r.next.GetUserByUsername(ctx, username)
+}
+
+func (r *coinRepository) BeginTx(ctx context.Context) (_ *sqlx.Tx, err error) {
+	defer r.observe("BeginTx", time.Now(), &err)
+	return r.next.BeginTx(ctx)
+}
+
+func (r *coinRepository) DecreaseBalance(ctx context.Context, tx *sqlx.Tx, params repo.ChangeBalanceParams) (_ int, err error) {
+	defer r.observe("DecreaseBalance", time.Now(), &err)
+	return r.next.DecreaseBalance(ctx, tx, params)
+}
+
+func (r *coinRepository) IncreaseBalance(ctx context.Context, tx *sqlx.Tx, params repo.ChangeBalanceParams) (_ int, err error) {
+	defer r.observe("IncreaseBalance", time.Now(), &err)
+	return r.next.IncreaseBalance(ctx, tx, params)
+}
+
+func (r *coinRepository) SaveTransaction(ctx context.Context, tx *sqlx.Tx, params repo.SaveTransactionParams) (_ int64, err error) {
+	defer r.observe("SaveTransaction", time.Now(), &err)
+	return r.next.SaveTransaction(ctx, tx, params)
+}
+
+func (r *coinRepository) GetTransaction(ctx context.Context, id int64) (_ models.Transaction, err error) {
+	defer r.observe("GetTransaction", time.Now(), &err)
+	return r.next.GetTransaction(ctx, id)
+}
+
+func (r *coinRepository) GetTransactions(ctx context.Context, username string) (_ []models.Transaction, err error) {
+	defer r.observe("GetTransactions", time.Now(), &err)
+	return r.next.GetTransactions(ctx, username)
+}
+
+func (r *coinRepository) ReceivedCoinsInfo(ctx context.Context, username string) (_ []models.Transaction, err error) {
+	defer r.observe("ReceivedCoinsInfo", time.Now(), &err)
+	return r.next.ReceivedCoinsInfo(ctx, username)
+}
+
+func (r *coinRepository) GetPurchases(ctx context.Context, username string) (_ []models.PurchaseItem, err error) {
+	defer r.observe("GetPurchases", time.Now(), &err)
+	return r.next.GetPurchases(ctx, username)
+}
+
+func (r *coinRepository) BuyItem(ctx context.Context, tx *sqlx.Tx, params repo.BuyItemParams) (_ int, err error) {
+	defer r.observe("BuyItem", time.Now(), &err)
+	return r.next.BuyItem(ctx, tx, params)
+}
+
+func (r *coinRepository) GetItem(ctx context.Context, itemName string) (_ models.Item, err error) {
+	defer r.observe("GetItem", time.Now(), &err)
+	return r.next.GetItem(ctx, itemName)
+}
+
+func (r *coinRepository) GetItems(ctx context.Context, params repo.GetItemsParams) (_ []models.Item, err error) {
+	defer r.observe("GetItems", time.Now(), &err)
+	return r.next.GetItems(ctx, params)
+}
+
+func (r *coinRepository) SaveEvent(ctx context.Context, tx *sqlx.Tx, params repo.SaveEventParams) (err error) {
+	defer r.observe("SaveEvent", time.Now(), &err)
+	return r.next.SaveEvent(ctx, tx, params)
+}
+
+func (r *coinRepository) GetEvents(ctx context.Context, params repo.GetEventsParams) (_ []models.Event, err error) {
+	defer r.observe("GetEvents", time.Now(), &err)
+	return r.next.GetEvents(ctx, params)
+}
+
+func (r *coinRepository) CommitTx(tx *sqlx.Tx) (err error) {
+	defer r.observe("CommitTx", time.Now(), &err)
+	return r.next.CommitTx(tx)
+}
+
+func (r *coinRepository) RollbackTx(tx *sqlx.Tx) (err error) {
+	defer r.observe("RollbackTx", time.Now(), &err)
+	return r.next.RollbackTx(tx)
+}
+
+func (r *coinRepository) CreateNotification(ctx context.Context, tx *sqlx.Tx, params repo.CreateNotificationParams) (err error) {
+	defer r.observe("CreateNotification", time.Now(), &err)
+	return r.next.CreateNotification(ctx, tx, params)
+}
+
+func (r *coinRepository) GetNotifications(ctx context.Context, params repo.GetNotificationsParams) (_ []models.Notification, err error) {
+	defer r.observe("GetNotifications", time.Now(), &err)
+	return r.next.GetNotifications(ctx, params)
+}
+
+func (r *coinRepository) CountUnreadNotifications(ctx context.Context, username string) (_ int, err error) {
+	defer r.observe("CountUnreadNotifications", time.Now(), &err)
+	return r.next.CountUnreadNotifications(ctx, username)
+}
+
+func (r *coinRepository) MarkNotificationRead(ctx context.Context, params repo.MarkNotificationReadParams) (err error) {
+	defer r.observe("MarkNotificationRead", time.Now(), &err)
+	return r.next.MarkNotificationRead(ctx, params)
+}
+
+func (r *coinRepository) MarkAllNotificationsRead(ctx context.Context, username string) (_ int64, err error) {
+	defer r.observe("MarkAllNotificationsRead", time.Now(), &err)
+	return r.next.MarkAllNotificationsRead(ctx, username)
+}
+
+func (r *coinRepository) GetNotificationPreferences(ctx context.Context, username string) (_ []models.NotificationPreference, err error) {
+	defer r.observe("GetNotificationPreferences", time.Now(), &err)
+	return r.next.GetNotificationPreferences(ctx, username)
+}
+
+func (r *coinRepository) SetNotificationPreference(ctx context.Context, tx *sqlx.Tx, params repo.SetNotificationPreferenceParams) (err error) {
+	defer r.observe("SetNotificationPreference", time.Now(), &err)
+	return r.next.SetNotificationPreference(ctx, tx, params)
+}
+
+func (r *coinRepository) SetNotificationContact(ctx context.Context, params repo.SetNotificationContactParams) (err error) {
+	defer r.observe("SetNotificationContact", time.Now(), &err)
+	return r.next.SetNotificationContact(ctx, params)
+}
+
+func (r *coinRepository) ClaimNotificationDeliveries(ctx context.Context, params repo.ClaimNotificationDeliveriesParams) (_ []models.NotificationDelivery, err error) {
+	defer r.observe("ClaimNotificationDeliveries", time.Now(), &err)
+	return r.next.ClaimNotificationDeliveries(ctx, params)
+}
+
+func (r *coinRepository) CompleteNotificationDelivery(ctx context.Context, id int64) (err error) {
+	defer r.observe("CompleteNotificationDelivery", time.Now(), &err)
+	return r.next.CompleteNotificationDelivery(ctx, id)
+}
+
+func (r *coinRepository) FailNotificationDelivery(ctx context.Context, params repo.FailNotificationDeliveryParams) (err error) {
+	defer r.observe("FailNotificationDelivery", time.Now(), &err)
+	return r.next.FailNotificationDelivery(ctx, params)
+}
+
+func (r *coinRepository) GetLoginAttempts(ctx context.Context, keys []string) (_ []models.LoginAttempt, err error) {
+	defer r.observe("GetLoginAttempts", time.Now(), &err)
+	return r.next.GetLoginAttempts(ctx, keys)
+}
+
+func (r *coinRepository) RecordLoginFailure(ctx context.Context, params repo.RecordLoginFailureParams) (_ models.LoginAttempt, err error) {
+	defer r.observe("RecordLoginFailure", time.Now(), &err)
+	return r.next.RecordLoginFailure(ctx, params)
+}
+
+func (r *coinRepository) ResetLoginAttempts(ctx context.Context, keys []string) (err error) {
+	defer r.observe("ResetLoginAttempts", time.Now(), &err)
+	return r.next.ResetLoginAttempts(ctx, keys)
+}
+
+func (r *coinRepository) SetTOTPSecret(ctx context.Context, params repo.SetTOTPSecretParams) (err error) {
+	defer r.observe("SetTOTPSecret", time.Now(), &err)
+	return r.next.SetTOTPSecret(ctx, params)
+}
+
+func (r *coinRepository) EnableTOTP(ctx context.Context, params repo.EnableTOTPParams) (err error) {
+	defer r.observe("EnableTOTP", time.Now(), &err)
+	return r.next.EnableTOTP(ctx, params)
+}
+
+func (r *coinRepository) DisableTOTP(ctx context.Context, username string) (err error) {
+	defer r.observe("DisableTOTP", time.Now(), &err)
+	return r.next.DisableTOTP(ctx, username)
+}
+
+func (r *coinRepository) UseTOTPCounter(ctx context.Context, params repo.UseTOTPCounterParams) (_ bool, err error) {
+	defer r.observe("UseTOTPCounter", time.Now(), &err)
+	return r.next.UseTOTPCounter(ctx, params)
+}
+
+func (r *coinRepository) UseRecoveryCode(ctx context.Context, params repo.UseRecoveryCodeParams) (_ bool, err error) {
+	defer r.observe("UseRecoveryCode", time.Now(), &err)
+	return r.next.UseRecoveryCode(ctx, params)
+}
+
+func (r *coinRepository) CreateAuthChallenge(ctx context.Context, params repo.CreateAuthChallengeParams) (err error) {
+	defer r.observe("CreateAuthChallenge", time.Now(), &err)
+	return r.next.CreateAuthChallenge(ctx, params)
+}
+
+func (r *coinRepository) GetAuthChallenge(ctx context.Context, tokenHash string) (_ string, err error) {
+	defer r.observe("GetAuthChallenge", time.Now(), &err)
+	return r.next.GetAuthChallenge(ctx, tokenHash)
+}
+
+func (r *coinRepository) DeleteAuthChallenge(ctx context.Context, tokenHash string) (_ bool, err error) {
+	defer r.observe("DeleteAuthChallenge", time.Now(), &err)
+	return r.next.DeleteAuthChallenge(ctx, tokenHash)
+}
+
+func (r *coinRepository) GetTwoFactorPolicy(ctx context.Context) (_ models.TwoFactorPolicy, err error) {
+	defer r.observe("GetTwoFactorPolicy", time.Now(), &err)
+	return r.next.GetTwoFactorPolicy(ctx)
+}
+
+func (r *coinRepository) SetTwoFactorPolicy(ctx context.Context, policy models.TwoFactorPolicy) (err error) {
+	defer r.observe("SetTwoFactorPolicy", time.Now(), &err)
+	return r.next.SetTwoFactorPolicy(ctx, policy)
+}
+
+func (r *coinRepository) CreateServiceAccount(ctx context.Context, params repo.CreateServiceAccountParams) (_ bool, err error) {
+	defer r.observe("CreateServiceAccount", time.Now(), &err)
+	return r.next.CreateServiceAccount(ctx, params)
+}
+
+func (r *coinRepository) CreateAPIKey(ctx context.Context, params repo.CreateAPIKeyParams) (err error) {
+	defer r.observe("CreateAPIKey", time.Now(), &err)
+	return r.next.CreateAPIKey(ctx, params)
+}
+
+func (r *coinRepository) GetAPIKey(ctx context.Context, id string) (_ models.APIKey, err error) {
+	defer r.observe("GetAPIKey", time.Now(), &err)
+	return r.next.GetAPIKey(ctx, id)
+}
+
+func (r *coinRepository) ListAPIKeys(ctx context.Context, username string) (_ []models.APIKey, err error) {
+	defer r.observe("ListAPIKeys", time.Now(), &err)
+	return r.next.ListAPIKeys(ctx, username)
+}
+
+func (r *coinRepository) RevokeAPIKey(ctx context.Context, id string) (_ bool, err error) {
+	defer r.observe("RevokeAPIKey", time.Now(), &err)
+	return r.next.RevokeAPIKey(ctx, id)
+}
+
+func (r *coinRepository) GrantDelegation(ctx context.Context, params repo.GrantDelegationParams) (_ models.Delegation, err error) {
+	defer r.observe("GrantDelegation", time.Now(), &err)
+	return r.next.GrantDelegation(ctx, params)
+}
+
+func (r *coinRepository) ListDelegations(ctx context.Context, ownerUsername string) (_ []models.Delegation, err error) {
+	defer r.observe("ListDelegations", time.Now(), &err)
+	return r.next.ListDelegations(ctx, ownerUsername)
+}
+
+func (r *coinRepository) RevokeDelegation(ctx context.Context, params repo.RevokeDelegationParams) (_ bool, err error) {
+	defer r.observe("RevokeDelegation", time.Now(), &err)
+	return r.next.RevokeDelegation(ctx, params)
+}
+
+func (r *coinRepository) LockDelegation(ctx context.Context, tx *sqlx.Tx, params repo.LockDelegationParams) (_ models.Delegation, err error) {
+	defer r.observe("LockDelegation", time.Now(), &err)
+	return r.next.LockDelegation(ctx, tx, params)
+}
+
+func (r *coinRepository) CreateOIDCLoginState(ctx context.Context, params repo.CreateOIDCLoginStateParams) (err error) {
+	defer r.observe("CreateOIDCLoginState", time.Now(), &err)
+	return r.next.CreateOIDCLoginState(ctx, params)
+}
+
+func (r *coinRepository) UseOIDCLoginState(ctx context.Context, stateHash string) (_ models.OIDCLoginState, err error) {
+	defer r.observe("UseOIDCLoginState", time.Now(), &err)
+	return r.next.UseOIDCLoginState(ctx, stateHash)
+}
+
+func (r *coinRepository) GetOIDCIdentity(ctx context.Context, params repo.GetOIDCIdentityParams) (_ string, err error) {
+	defer r.observe("GetOIDCIdentity", time.Now(), &err)
+	return r.next.GetOIDCIdentity(ctx, params)
+}
+
+func (r *coinRepository) LinkOIDCIdentity(ctx context.Context, params repo.LinkOIDCIdentityParams) (err error) {
+	defer r.observe("LinkOIDCIdentity", time.Now(), &err)
+	return r.next.LinkOIDCIdentity(ctx, params)
+}
+
+func (r *coinRepository) GetFeatureFlags(ctx context.Context) (_ []models.FeatureFlag, err error) {
+	defer r.observe("GetFeatureFlags", time.Now(), &err)
+	return r.next.GetFeatureFlags(ctx)
+}
+
+func (r *coinRepository) SaveFeatureFlag(ctx context.Context, params repo.SaveFeatureFlagParams) (_ models.FeatureFlag, err error) {
+	defer r.observe("SaveFeatureFlag", time.Now(), &err)
+	return r.next.SaveFeatureFlag(ctx, params)
+}
+
+func (r *coinRepository) DeleteFeatureFlag(ctx context.Context, name string) (_ bool, err error) {
+	defer r.observe("DeleteFeatureFlag", time.Now(), &err)
+	return r.next.DeleteFeatureFlag(ctx, name)
+}
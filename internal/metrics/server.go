@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"time"
+)
+
+// Server serves the metrics on their own port, away from the public API.
+type Server struct {
+	server *http.Server
+}
+
+type ServerConfig struct {
+	Addr    string
+	Path    string
+	Metrics *Metrics
+}
+
+func NewServer(cfg ServerConfig) *Server {
+	mux := http.NewServeMux()
+	mux.Handle(cfg.Path, promhttp.HandlerFor(cfg.Metrics.Registry(), promhttp.HandlerOpts{}))
+
+	return &Server{
+		server: &http.Server{
+			Addr:              cfg.Addr,
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+	}
+}
+
+func (s *Server) Run() error {
+	if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("listening metrics server: %w", err)
+	}
+	return nil
+}
+
+func (s *Server) Shutdown(ctx context.Context) error {
+	if err := s.server.Shutdown(ctx); err != nil {
+		return fmt.Errorf("shutdown metrics server: %w", err)
+	}
+	return nil
+}
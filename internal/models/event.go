@@ -0,0 +1,53 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventCoinsSent         EventType = "coins_sent"
+	EventCoinsReceived     EventType = "coins_received"
+	EventPurchaseCompleted EventType = "purchase_completed"
+	EventBalanceChanged    EventType = "balance_changed"
+	EventAdminMessage      EventType = "admin_message"
+)
+
+type Event struct {
+	ID        int64           `json:"id"`
+	Username  string          `json:"username"`
+	Type      EventType       `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+type CoinsSentPayload struct {
+	ToUser string `json:"toUser"`
+	Amount int    `json:"amount"`
+	// Actor is the client that sent the coins on the owner's behalf.
+	Actor string `json:"actor,omitempty"`
+	Memo  string `json:"memo,omitempty"`
+}
+
+type CoinsReceivedPayload struct {
+	FromUser string `json:"fromUser"`
+	Amount   int    `json:"amount"`
+	Memo     string `json:"memo,omitempty"`
+}
+
+type PurchaseCompletedPayload struct {
+	Item  string `json:"item"`
+	Price int    `json:"price"`
+}
+
+type BalanceChangedPayload struct {
+	Balance int `json:"balance"`
+	Delta   int `json:"delta"`
+}
+
+type AdminMessagePayload struct {
+	FromUser string `json:"fromUser"`
+	Message  string `json:"message"`
+}
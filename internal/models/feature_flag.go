@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// FeatureFlag turns a feature on for some users. A disabled flag is off for
+// everyone; an enabled one is on for the users in AllowList and for
+// RolloutPercent percent of the rest, picked by a hash of the username.
+type FeatureFlag struct {
+	Name           string
+	Description    string
+	Enabled        bool
+	RolloutPercent int
+	AllowList      []string
+	UpdatedBy      string
+	UpdatedAt      time.Time
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// BalanceAdjustment is a change of a balance made by an operator rather
+// than a transfer or a purchase.
+type BalanceAdjustment struct {
+	ID        int64
+	Username  string
+	Amount    int
+	Reason    string
+	CreatedBy string
+	CreatedAt time.Time
+}
+
+// LedgerMismatch is an account whose balance doesn't follow from its
+// history: Expected is the opening balance plus coins received and adjusted
+// minus coins sent and spent.
+type LedgerMismatch struct {
+	Username string
+	Balance  int
+	Expected int
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// NotificationType matches the type of the event a notification was created from.
+type NotificationType string
+
+const (
+	NotificationCoinsReceived     NotificationType = NotificationType(EventCoinsReceived)
+	NotificationCoinsSent         NotificationType = NotificationType(EventCoinsSent)
+	NotificationPurchaseCompleted NotificationType = NotificationType(EventPurchaseCompleted)
+	NotificationAdminMessage      NotificationType = NotificationType(EventAdminMessage)
+)
+
+var NotificationTypes = []NotificationType{
+	NotificationCoinsReceived,
+	NotificationCoinsSent,
+	NotificationPurchaseCompleted,
+	NotificationAdminMessage,
+}
+
+type Notification struct {
+	ID        int64
+	Username  string
+	Type      NotificationType
+	Payload   json.RawMessage
+	ReadAt    *time.Time
+	CreatedAt time.Time
+}
+
+type NotificationPreference struct {
+	Type  NotificationType
+	Muted bool
+}
+
+// NotificationDelivery is a queued request to deliver a notification through
+// the configured notifier, together with the recipient's contact details.
+type NotificationDelivery struct {
+	ID           int64
+	Attempts     int
+	Notification Notification
+	Email        string
+	Locale       string
+}
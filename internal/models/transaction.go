@@ -7,5 +7,21 @@ type Transaction struct {
 	SenderUsername   string
 	ReceiverUsername string
 	Amount           int
-	CreatedAt        time.Time
+	// ActorUsername is the client that sent the coins on the sender's behalf;
+	// empty when the sender made the transfer.
+	ActorUsername string
+	CreatedAt     time.Time
+}
+
+// Delegation lets a client send up to DailyLimit coins from the owner's
+// account in any 24 hours.
+type Delegation struct {
+	ID             int64
+	OwnerUsername  string
+	ClientUsername string
+	DailyLimit     int
+	// SpentToday is how much the client sent on the owner's behalf in the
+	// last 24 hours.
+	SpentToday int
+	CreatedAt  time.Time
 }
@@ -1,7 +1,57 @@
 package models
 
+import "time"
+
 type User struct {
 	Username     string
 	PasswordHash string
-	Balance      int
+	// PasswordAlgorithm is the algorithm PasswordHash was made with.
+	PasswordAlgorithm string
+	Balance           int
+	IsAdmin           bool
+	Email             string
+	Locale            string
+	// TOTPSecret is set on enrollment; TOTPEnabled once a code confirmed it.
+	TOTPSecret  string
+	TOTPEnabled bool
+	// IsServiceAccount users can't log in with a password and authenticate
+	// with API keys instead.
+	IsServiceAccount bool
+	// FrozenAt is set while an operator has frozen the account.
+	FrozenAt *time.Time
+}
+
+// LoginAttempt counts recent failed logins for one key: a username or a client IP.
+type LoginAttempt struct {
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   *time.Time
+}
+
+// TwoFactorPolicy lists the operations that need a TOTP code. A threshold of
+// zero turns the requirement off; otherwise amounts above it need a code.
+type TwoFactorPolicy struct {
+	TransferThreshold int
+	PurchaseThreshold int
+}
+
+// APIKey lets a service account call the API with the listed scopes. Only a
+// hash of the secret part of the key is stored.
+type APIKey struct {
+	ID         string
+	Username   string
+	SecretHash string
+	Scopes     []string
+	CreatedBy  string
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	RevokedAt  *time.Time
+}
+
+// OIDCLoginState is kept between sending the user to the identity provider
+// and the callback.
+type OIDCLoginState struct {
+	Nonce        string
+	CodeVerifier string
 }
@@ -0,0 +1,161 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/repo"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultPollInterval = time.Second
+	defaultBatchSize    = 20
+	defaultMaxAttempts  = 5
+	sendTimeout         = 30 * time.Second
+	// claimLease must outlast a send, or the delivery may be sent twice.
+	claimLease = 2 * sendTimeout
+)
+
+// Dispatcher drains the notification delivery queue kept in Postgres. Every
+// replica may run one: deliveries are claimed with SKIP LOCKED, so each one is
+// handed to a single dispatcher.
+type Dispatcher struct {
+	repo         repo.NotificationRepository
+	notifier     Notifier
+	templates    *Templates
+	logger       *zap.Logger
+	pollInterval time.Duration
+	batchSize    int
+	maxAttempts  int
+}
+
+type DispatcherConfig struct {
+	Repo         repo.NotificationRepository
+	Notifier     Notifier
+	Templates    *Templates
+	Logger       *zap.Logger
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+}
+
+func NewDispatcher(cfg DispatcherConfig) *Dispatcher {
+	d := &Dispatcher{
+		repo:         cfg.Repo,
+		notifier:     cfg.Notifier,
+		templates:    cfg.Templates,
+		logger:       cfg.Logger,
+		pollInterval: cfg.PollInterval,
+		batchSize:    cfg.BatchSize,
+		maxAttempts:  cfg.MaxAttempts,
+	}
+	if d.pollInterval <= 0 {
+		d.pollInterval = defaultPollInterval
+	}
+	if d.batchSize <= 0 {
+		d.batchSize = defaultBatchSize
+	}
+	if d.maxAttempts <= 0 {
+		d.maxAttempts = defaultMaxAttempts
+	}
+	return d
+}
+
+// Run delivers queued notifications until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Keep draining while full batches come back.
+		for {
+			n, err := d.dispatchBatch(ctx)
+			if err != nil && ctx.Err() == nil {
+				d.logger.Error(fmt.Sprintf("notification dispatch failed: %v", err))
+			}
+			if err != nil || n < d.batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Dispatcher) dispatchBatch(ctx context.Context) (int, error) {
+	deliveries, err := d.repo.ClaimNotificationDeliveries(ctx, repo.ClaimNotificationDeliveriesParams{
+		Limit: d.batchSize,
+		Lease: claimLease,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("d.repo.ClaimNotificationDeliveries: %w", err)
+	}
+
+	// A failed update leaves only its own delivery leased until the lease
+	// runs out, so the rest of the batch is still handled.
+	var errs []error
+	for _, delivery := range deliveries {
+		if err = d.deliver(ctx, delivery); err != nil {
+			errs = append(errs, fmt.Errorf("delivery %d: %w", delivery.ID, err))
+		}
+	}
+
+	return len(deliveries), errors.Join(errs...)
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery models.NotificationDelivery) error {
+	sendErr := d.send(ctx, delivery)
+	if sendErr == nil {
+		if err := d.repo.CompleteNotificationDelivery(ctx, delivery.ID); err != nil {
+			return fmt.Errorf("d.repo.CompleteNotificationDelivery: %w", err)
+		}
+		return nil
+	}
+
+	// Missing addresses and templates will not fix themselves, so there is no
+	// point in retrying those.
+	giveUp := errors.Is(sendErr, ErrNoAddress) || errors.Is(sendErr, ErrNoTemplate) ||
+		delivery.Attempts >= d.maxAttempts
+	if giveUp && !errors.Is(sendErr, ErrNoAddress) {
+		d.logger.Warn(fmt.Sprintf("giving up on notification delivery %d: %v", delivery.ID, sendErr))
+	}
+
+	if err := d.repo.FailNotificationDelivery(ctx, repo.FailNotificationDeliveryParams{
+		ID:      delivery.ID,
+		Error:   sendErr.Error(),
+		RetryAt: time.Now().Add(backoff(delivery.Attempts)),
+		GiveUp:  giveUp,
+	}); err != nil {
+		return fmt.Errorf("d.repo.FailNotificationDelivery: %w", err)
+	}
+	return nil
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery models.NotificationDelivery) error {
+	msg, err := d.templates.Render(delivery.Locale, delivery.Notification)
+	if err != nil {
+		return fmt.Errorf("d.templates.Render: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	return d.notifier.Notify(ctx, Recipient{
+		Username: delivery.Notification.Username,
+		Email:    delivery.Email,
+		Locale:   delivery.Locale,
+	}, msg)
+}
+
+// backoff doubles the retry delay with every attempt, starting at 30 seconds.
+func backoff(attempts int) time.Duration {
+	return 30 * time.Second << min(max(attempts-1, 0), 10)
+}
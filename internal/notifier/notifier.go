@@ -0,0 +1,35 @@
+// Package notifier delivers user notifications outside the app, by email or
+// through a chat webhook.
+package notifier
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoAddress is returned when the recipient cannot be reached through a
+// notifier, for example an email notifier and a user without an email.
+var ErrNoAddress = errors.New("recipient has no address")
+
+type Recipient struct {
+	Username string
+	Email    string
+	Locale   string
+}
+
+type Message struct {
+	Subject string
+	Body    string
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, to Recipient, msg Message) error
+}
+
+// Discard drops every message. It stands in when no notifier is configured,
+// so queued deliveries are cleared instead of piling up.
+type Discard struct{}
+
+func (Discard) Notify(context.Context, Recipient, Message) error {
+	return nil
+}
@@ -0,0 +1,294 @@
+package notifier_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/notifier"
+	"github.com/Blxssy/AvitoTest/internal/repo"
+	"github.com/Blxssy/AvitoTest/internal/repo/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeSMTPServer speaks just enough SMTP for net/smtp and records the
+// envelope and data of every message it receives.
+type fakeSMTPServer struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	messages []smtpMessage
+}
+
+type smtpMessage struct {
+	From string
+	To   []string
+	Data string
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &fakeSMTPServer{listener: listener}
+	go s.serve()
+	t.Cleanup(func() { _ = listener.Close() })
+
+	return s
+}
+
+func (s *fakeSMTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTPServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost fake SMTP")
+	var msg smtpMessage
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(line)
+
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			msg = smtpMessage{From: strings.Trim(line[len("MAIL FROM:"):], "<>")}
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			msg.To = append(msg.To, strings.Trim(line[len("RCPT TO:"):], "<>"))
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			msg.Data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func (s *fakeSMTPServer) Messages() []smtpMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]smtpMessage(nil), s.messages...)
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	server := newFakeSMTPServer(t)
+
+	n := notifier.NewSMTPNotifier(notifier.SMTPConfig{
+		Addr: server.listener.Addr().String(),
+		From: "coins@example.com",
+	})
+
+	err := n.Notify(context.Background(), notifier.Recipient{
+		Username: "alice",
+		Email:    "alice@example.com",
+	}, notifier.Message{
+		Subject: "You received 50 coins",
+		Body:    "bob sent you 50 coins.\n",
+	})
+	require.NoError(t, err)
+
+	messages := server.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "coins@example.com", messages[0].From)
+	assert.Equal(t, []string{"alice@example.com"}, messages[0].To)
+	assert.Contains(t, messages[0].Data, "Subject: You received 50 coins")
+	assert.Contains(t, messages[0].Data, "bob sent you 50 coins.")
+
+	err = n.Notify(context.Background(), notifier.Recipient{Username: "bob"}, notifier.Message{})
+	assert.ErrorIs(t, err, notifier.ErrNoAddress)
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var got map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	n := notifier.NewWebhookNotifier(notifier.WebhookConfig{URL: server.URL})
+
+	err := n.Notify(context.Background(), notifier.Recipient{Username: "alice"}, notifier.Message{
+		Subject: "hi",
+		Body:    "hello",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"username": "alice", "subject": "hi", "text": "hello"}, got)
+}
+
+func TestTemplatesRender(t *testing.T) {
+	templates, err := notifier.NewTemplates()
+	require.NoError(t, err)
+
+	notification := models.Notification{
+		Username: "alice",
+		Type:     models.NotificationCoinsReceived,
+		Payload:  json.RawMessage(`{"fromUser":"bob","amount":50}`),
+	}
+
+	msg, err := templates.Render("ru-RU", notification)
+	require.NoError(t, err)
+	assert.Equal(t, "Вы получили монеты: 50", msg.Subject)
+	assert.Contains(t, msg.Body, "bob отправил вам 50 монет.")
+
+	msg, err = templates.Render("de", notification)
+	require.NoError(t, err)
+	assert.Equal(t, "You received 50 coins", msg.Subject)
+
+	notification.Type = models.NotificationPurchaseCompleted
+	_, err = templates.Render("en", notification)
+	assert.ErrorIs(t, err, notifier.ErrNoTemplate)
+}
+
+func TestDispatcher(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockNotificationRepository(ctrl)
+	server := newFakeSMTPServer(t)
+	templates, err := notifier.NewTemplates()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	payload := json.RawMessage(`{"fromUser":"bob","amount":50}`)
+	repoMock.EXPECT().ClaimNotificationDeliveries(gomock.Any(), gomock.Any()).Return([]models.NotificationDelivery{
+		{
+			ID:       1,
+			Attempts: 1,
+			Notification: models.Notification{
+				Username: "alice", Type: models.NotificationCoinsReceived, Payload: payload,
+			},
+			Email:  "alice@example.com",
+			Locale: "en",
+		},
+		{
+			ID:       2,
+			Attempts: 1,
+			Notification: models.Notification{
+				Username: "carol", Type: models.NotificationCoinsReceived, Payload: payload,
+			},
+			Locale: "en",
+		},
+	}, nil)
+	repoMock.EXPECT().CompleteNotificationDelivery(gomock.Any(), int64(1)).Return(nil)
+	repoMock.EXPECT().FailNotificationDelivery(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, params repo.FailNotificationDeliveryParams) error {
+			assert.Equal(t, int64(2), params.ID)
+			assert.True(t, params.GiveUp)
+			assert.Equal(t, notifier.ErrNoAddress.Error(), params.Error)
+			cancel()
+			return nil
+		})
+
+	d := notifier.NewDispatcher(notifier.DispatcherConfig{
+		Repo: repoMock,
+		Notifier: notifier.NewSMTPNotifier(notifier.SMTPConfig{
+			Addr: server.listener.Addr().String(),
+			From: "coins@example.com",
+		}),
+		Templates: templates,
+		Logger:    zap.NewNop(),
+	})
+	require.NoError(t, d.Run(ctx))
+
+	messages := server.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, []string{"alice@example.com"}, messages[0].To)
+}
+
+func TestDispatcherContinuesAfterRepoError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockNotificationRepository(ctrl)
+	server := newFakeSMTPServer(t)
+	templates, err := notifier.NewTemplates()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	payload := json.RawMessage(`{"fromUser":"bob","amount":50}`)
+	delivery := func(id int64, username string) models.NotificationDelivery {
+		return models.NotificationDelivery{
+			ID:       id,
+			Attempts: 1,
+			Notification: models.Notification{
+				Username: username, Type: models.NotificationCoinsReceived, Payload: payload,
+			},
+			Email:  username + "@example.com",
+			Locale: "en",
+		}
+	}
+	repoMock.EXPECT().ClaimNotificationDeliveries(gomock.Any(), gomock.Any()).Return([]models.NotificationDelivery{
+		delivery(1, "alice"),
+		delivery(2, "carol"),
+	}, nil)
+	repoMock.EXPECT().CompleteNotificationDelivery(gomock.Any(), int64(1)).Return(errors.New("connection reset"))
+	repoMock.EXPECT().CompleteNotificationDelivery(gomock.Any(), int64(2)).DoAndReturn(
+		func(context.Context, int64) error {
+			cancel()
+			return nil
+		})
+
+	d := notifier.NewDispatcher(notifier.DispatcherConfig{
+		Repo: repoMock,
+		Notifier: notifier.NewSMTPNotifier(notifier.SMTPConfig{
+			Addr: server.listener.Addr().String(),
+			From: "coins@example.com",
+		}),
+		Templates: templates,
+		Logger:    zap.NewNop(),
+	})
+	require.NoError(t, d.Run(ctx))
+
+	assert.Len(t, server.Messages(), 2)
+}
@@ -0,0 +1,100 @@
+package notifier
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/Blxssy/AvitoTest/internal/models"
+)
+
+const defaultLocale = "en"
+
+// ErrNoTemplate is returned for notification types that have no message template.
+var ErrNoTemplate = errors.New("no template for notification type")
+
+//go:embed templates
+var templateFS embed.FS
+
+// Templates renders notifications with templates/<locale>/<type>.tmpl. Each
+// template defines a "subject" and a "body" block and gets the recipient's
+// username and the notification payload as .Username and .Payload.
+type Templates struct {
+	locales map[string]map[models.NotificationType]*template.Template
+}
+
+func NewTemplates() (*Templates, error) {
+	t := &Templates{
+		locales: make(map[string]map[models.NotificationType]*template.Template),
+	}
+
+	files, err := fs.Glob(templateFS, "templates/*/*.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("fs.Glob: %w", err)
+	}
+
+	for _, file := range files {
+		tmpl, err := template.ParseFS(templateFS, file)
+		if err != nil {
+			return nil, fmt.Errorf("template.ParseFS: %w", err)
+		}
+
+		locale := path.Base(path.Dir(file))
+		notificationType := models.NotificationType(strings.TrimSuffix(path.Base(file), ".tmpl"))
+		if t.locales[locale] == nil {
+			t.locales[locale] = make(map[models.NotificationType]*template.Template)
+		}
+		t.locales[locale][notificationType] = tmpl
+	}
+
+	return t, nil
+}
+
+// Render falls back to the language part of the locale and then to English.
+func (t *Templates) Render(locale string, notification models.Notification) (Message, error) {
+	tmpl := t.lookup(locale, notification.Type)
+	if tmpl == nil {
+		return Message{}, ErrNoTemplate
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(notification.Payload, &payload); err != nil {
+		return Message{}, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	data := struct {
+		Username string
+		Payload  map[string]any
+	}{
+		Username: notification.Username,
+		Payload:  payload,
+	}
+
+	var subject, body bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, fmt.Errorf("execute subject: %w", err)
+	}
+	if err := tmpl.ExecuteTemplate(&body, "body", data); err != nil {
+		return Message{}, fmt.Errorf("execute body: %w", err)
+	}
+
+	return Message{
+		Subject: strings.TrimSpace(subject.String()),
+		Body:    strings.TrimSpace(body.String()) + "\n",
+	}, nil
+}
+
+func (t *Templates) lookup(locale string, notificationType models.NotificationType) *template.Template {
+	language, _, _ := strings.Cut(locale, "-")
+	for _, l := range []string{locale, language, defaultLocale} {
+		if tmpl, ok := t.locales[l][notificationType]; ok {
+			return tmpl
+		}
+	}
+	return nil
+}
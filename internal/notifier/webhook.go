@@ -0,0 +1,71 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// WebhookNotifier posts messages as JSON to a chat integration, which is
+// expected to map the username to a chat account.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+type WebhookConfig struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewWebhookNotifier(cfg WebhookConfig) *WebhookNotifier {
+	client := cfg.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &WebhookNotifier{
+		url:    cfg.URL,
+		client: client,
+	}
+}
+
+type webhookPayload struct {
+	Username string `json:"username"`
+	Subject  string `json:"subject"`
+	Text     string `json:"text"`
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, to Recipient, msg Message) error {
+	body, err := json.Marshal(webhookPayload{
+		Username: to.Username,
+		Subject:  msg.Subject,
+		Text:     msg.Body,
+	})
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("http.NewRequestWithContext: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("n.client.Do: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			return
+		}
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
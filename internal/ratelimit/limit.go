@@ -0,0 +1,137 @@
+// Package ratelimit implements token-bucket rate limiting with pluggable
+// storage for the buckets.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Limit allows Burst requests at once and refills Rate tokens per second.
+// The zero Limit means no limit.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+func (l Limit) IsZero() bool {
+	return l.Burst <= 0 || l.Rate <= 0
+}
+
+// Period is the time an empty bucket takes to refill completely.
+func (l Limit) Period() time.Duration {
+	return seconds(float64(l.Burst) / l.Rate)
+}
+
+var periods = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+}
+
+// ParseLimit parses limits like "10/m": bursts of up to 10 requests, refilled
+// at 10 requests per minute. Periods are "s", "m" and "h". An empty string
+// is the zero Limit.
+func ParseLimit(s string) (Limit, error) {
+	if s == "" {
+		return Limit{}, nil
+	}
+
+	count, unit, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid limit %q: expected <count>/<s|m|h>", s)
+	}
+	burst, err := strconv.Atoi(count)
+	if err != nil || burst <= 0 {
+		return Limit{}, fmt.Errorf("invalid limit %q: count must be a positive integer", s)
+	}
+	period, ok := periods[unit]
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid limit %q: unknown period %q", s, unit)
+	}
+
+	return Limit{
+		Rate:  float64(burst) / period.Seconds(),
+		Burst: burst,
+	}, nil
+}
+
+// Limits are the limits of named rules. They can be replaced while requests
+// read them: each read sees either the old set or the new one, without locks.
+type Limits struct {
+	current atomic.Pointer[map[string]Limit]
+}
+
+func NewLimits(limits map[string]Limit) *Limits {
+	l := &Limits{}
+	l.Set(limits)
+	return l
+}
+
+// Get returns the limit of rule name, or the zero Limit if it has none.
+func (l *Limits) Get(name string) Limit {
+	return (*l.current.Load())[name]
+}
+
+func (l *Limits) Set(limits map[string]Limit) {
+	l.current.Store(&limits)
+}
+
+// Result describes the state of a bucket after a request was counted.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next request would be allowed. It is
+	// zero for allowed requests.
+	RetryAfter time.Duration
+}
+
+// Store keeps buckets by key and takes one token from the bucket of key.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+func newBucket(limit Limit, now time.Time) bucket {
+	return bucket{tokens: float64(limit.Burst), updatedAt: now}
+}
+
+// take refills the bucket for the time passed since its last update and
+// takes one token if there is one.
+func (b bucket) take(limit Limit, now time.Time) (bucket, Result) {
+	elapsed := max(now.Sub(b.updatedAt).Seconds(), 0)
+	tokens := min(float64(limit.Burst), b.tokens+elapsed*limit.Rate)
+
+	result := Result{Limit: limit.Burst}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - tokens) / limit.Rate)
+	}
+	result.Remaining = int(math.Floor(tokens))
+	result.Reset = seconds((float64(limit.Burst) - tokens) / limit.Rate)
+
+	return bucket{tokens: tokens, updatedAt: now}, result
+}
+
+// fullAt is when the bucket refills completely and can be forgotten.
+func (b bucket) fullAt(limit Limit) time.Time {
+	return b.updatedAt.Add(seconds((float64(limit.Burst) - b.tokens) / limit.Rate))
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
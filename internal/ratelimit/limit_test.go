@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimit(t *testing.T) {
+	limit, err := ParseLimit("10/m")
+	require.NoError(t, err)
+	assert.Equal(t, 10, limit.Burst)
+	assert.InDelta(t, 10.0/60, limit.Rate, 1e-9)
+	assert.Equal(t, time.Minute, limit.Period())
+
+	limit, err = ParseLimit("")
+	require.NoError(t, err)
+	assert.True(t, limit.IsZero())
+
+	for _, s := range []string{"10", "0/m", "-1/s", "ten/m", "10/d"} {
+		_, err = ParseLimit(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestMemoryStoreTokenBucket(t *testing.T) {
+	now := time.Date(2025, 2, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	limit := Limit{Rate: 1, Burst: 3}
+
+	for i := 2; i >= 0; i-- {
+		result, err := store.Take(ctx, "user:a", limit)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, i, result.Remaining)
+	}
+
+	result, err := store.Take(ctx, "user:a", limit)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+	assert.Equal(t, 3*time.Second, result.Reset)
+
+	// Other keys have their own buckets.
+	result, err = store.Take(ctx, "user:b", limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	now = now.Add(1500 * time.Millisecond)
+	result, err = store.Take(ctx, "user:a", limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	// Full buckets are swept and start over.
+	now = now.Add(time.Hour)
+	_, err = store.Take(ctx, "user:c", limit)
+	require.NoError(t, err)
+	assert.NotContains(t, store.buckets, "user:a")
+	assert.NotContains(t, store.buckets, "user:b")
+}
+
+func TestLimits(t *testing.T) {
+	auth := Limit{Rate: 1, Burst: 10}
+	limits := NewLimits(map[string]Limit{"auth": auth})
+	assert.Equal(t, auth, limits.Get("auth"))
+	assert.True(t, limits.Get("transfer").IsZero())
+
+	transfer := Limit{Rate: 2, Burst: 30}
+	limits.Set(map[string]Limit{"transfer": transfer})
+	assert.True(t, limits.Get("auth").IsZero())
+	assert.Equal(t, transfer, limits.Get("transfer"))
+}
@@ -0,0 +1,105 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"sync"
+	"time"
+)
+
+const storeStmtCreateBucket = `
+insert into rate_limit_buckets (key, tokens, updated_at, full_at)
+values ($1, $2, $3, $3)
+on conflict (key) do nothing
+`
+
+const storeStmtLockBucket = `
+select tokens, updated_at
+from rate_limit_buckets
+where key = $1
+for update
+`
+
+const storeStmtUpdateBucket = `
+update rate_limit_buckets
+set tokens = $2, updated_at = $3, full_at = $4
+where key = $1
+`
+
+const storeStmtSweepBuckets = `
+delete from rate_limit_buckets
+where full_at < $1
+`
+
+// PostgresStore keeps buckets in the rate_limit_buckets table so that all
+// replicas share the same limits. Each Take locks the bucket row for the
+// duration of a short transaction.
+type PostgresStore struct {
+	db  *sqlx.DB
+	now func() time.Time
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+func NewPostgresStore(db *sqlx.DB) *PostgresStore {
+	return &PostgresStore{
+		db:  db,
+		now: time.Now,
+	}
+}
+
+func (s *PostgresStore) Take(ctx context.Context, key string, limit Limit) (result Result, err error) {
+	now := s.now()
+	if err = s.sweep(ctx, now); err != nil {
+		return Result{}, err
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return Result{}, fmt.Errorf("s.db.BeginTxx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, storeStmtCreateBucket, key, float64(limit.Burst), now); err != nil {
+		return Result{}, fmt.Errorf("create bucket: %w", err)
+	}
+
+	var b bucket
+	if err = tx.QueryRowxContext(ctx, storeStmtLockBucket, key).Scan(&b.tokens, &b.updatedAt); err != nil {
+		return Result{}, fmt.Errorf("lock bucket: %w", err)
+	}
+
+	b, result = b.take(limit, now)
+
+	if _, err = tx.ExecContext(ctx, storeStmtUpdateBucket, key, b.tokens, b.updatedAt, b.fullAt(limit)); err != nil {
+		return Result{}, fmt.Errorf("update bucket: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return Result{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return result, nil
+}
+
+// sweep deletes full buckets at most once per sweepInterval per replica.
+func (s *PostgresStore) sweep(ctx context.Context, now time.Time) error {
+	s.mu.Lock()
+	if now.Sub(s.lastSweep) < sweepInterval {
+		s.mu.Unlock()
+		return nil
+	}
+	s.lastSweep = now
+	s.mu.Unlock()
+
+	if _, err := s.db.ExecContext(ctx, storeStmtSweepBuckets, now); err != nil {
+		return fmt.Errorf("sweep buckets: %w", err)
+	}
+	return nil
+}
@@ -53,17 +53,33 @@ func (mr *MockCoinRepositoryMockRecorder) BeginTx(ctx interface{}) *gomock.Call
 }
 
 // BuyItem mocks base method.
-func (m *MockCoinRepository) BuyItem(ctx context.Context, params repo.BuyItemParams) error {
+func (m *MockCoinRepository) BuyItem(ctx context.Context, tx *sqlx.Tx, params repo.BuyItemParams) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BuyItem", ctx, params)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "BuyItem", ctx, tx, params)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // BuyItem indicates an expected call of BuyItem.
-func (mr *MockCoinRepositoryMockRecorder) BuyItem(ctx, params interface{}) *gomock.Call {
+func (mr *MockCoinRepositoryMockRecorder) BuyItem(ctx, tx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BuyItem", reflect.TypeOf((*MockCoinRepository)(nil).BuyItem), ctx, tx, params)
+}
+
+// ClaimNotificationDeliveries mocks base method.
+func (m *MockCoinRepository) ClaimNotificationDeliveries(ctx context.Context, params repo.ClaimNotificationDeliveriesParams) ([]models.NotificationDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimNotificationDeliveries", ctx, params)
+	ret0, _ := ret[0].([]models.NotificationDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimNotificationDeliveries indicates an expected call of ClaimNotificationDeliveries.
+func (mr *MockCoinRepositoryMockRecorder) ClaimNotificationDeliveries(ctx, params interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BuyItem", reflect.TypeOf((*MockCoinRepository)(nil).BuyItem), ctx, params)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimNotificationDeliveries", reflect.TypeOf((*MockCoinRepository)(nil).ClaimNotificationDeliveries), ctx, params)
 }
 
 // CommitTx mocks base method.
@@ -80,6 +96,120 @@ func (mr *MockCoinRepositoryMockRecorder) CommitTx(tx interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitTx", reflect.TypeOf((*MockCoinRepository)(nil).CommitTx), tx)
 }
 
+// CompleteNotificationDelivery mocks base method.
+func (m *MockCoinRepository) CompleteNotificationDelivery(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteNotificationDelivery", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteNotificationDelivery indicates an expected call of CompleteNotificationDelivery.
+func (mr *MockCoinRepositoryMockRecorder) CompleteNotificationDelivery(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteNotificationDelivery", reflect.TypeOf((*MockCoinRepository)(nil).CompleteNotificationDelivery), ctx, id)
+}
+
+// CountUnreadNotifications mocks base method.
+func (m *MockCoinRepository) CountUnreadNotifications(ctx context.Context, username string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnreadNotifications", ctx, username)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnreadNotifications indicates an expected call of CountUnreadNotifications.
+func (mr *MockCoinRepositoryMockRecorder) CountUnreadNotifications(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnreadNotifications", reflect.TypeOf((*MockCoinRepository)(nil).CountUnreadNotifications), ctx, username)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockCoinRepository) CreateAPIKey(ctx context.Context, params repo.CreateAPIKeyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockCoinRepositoryMockRecorder) CreateAPIKey(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockCoinRepository)(nil).CreateAPIKey), ctx, params)
+}
+
+// CreateAuthChallenge mocks base method.
+func (m *MockCoinRepository) CreateAuthChallenge(ctx context.Context, params repo.CreateAuthChallengeParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuthChallenge", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAuthChallenge indicates an expected call of CreateAuthChallenge.
+func (mr *MockCoinRepositoryMockRecorder) CreateAuthChallenge(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuthChallenge", reflect.TypeOf((*MockCoinRepository)(nil).CreateAuthChallenge), ctx, params)
+}
+
+// CreateNotification mocks base method.
+func (m *MockCoinRepository) CreateNotification(ctx context.Context, tx *sqlx.Tx, params repo.CreateNotificationParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNotification", ctx, tx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateNotification indicates an expected call of CreateNotification.
+func (mr *MockCoinRepositoryMockRecorder) CreateNotification(ctx, tx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNotification", reflect.TypeOf((*MockCoinRepository)(nil).CreateNotification), ctx, tx, params)
+}
+
+// CreateOIDCLoginState mocks base method.
+func (m *MockCoinRepository) CreateOIDCLoginState(ctx context.Context, params repo.CreateOIDCLoginStateParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOIDCLoginState", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOIDCLoginState indicates an expected call of CreateOIDCLoginState.
+func (mr *MockCoinRepositoryMockRecorder) CreateOIDCLoginState(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOIDCLoginState", reflect.TypeOf((*MockCoinRepository)(nil).CreateOIDCLoginState), ctx, params)
+}
+
+// CreatePasswordReset mocks base method.
+func (m *MockCoinRepository) CreatePasswordReset(ctx context.Context, params repo.CreatePasswordResetParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePasswordReset", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePasswordReset indicates an expected call of CreatePasswordReset.
+func (mr *MockCoinRepositoryMockRecorder) CreatePasswordReset(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasswordReset", reflect.TypeOf((*MockCoinRepository)(nil).CreatePasswordReset), ctx, params)
+}
+
+// CreateServiceAccount mocks base method.
+func (m *MockCoinRepository) CreateServiceAccount(ctx context.Context, params repo.CreateServiceAccountParams) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateServiceAccount", ctx, params)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateServiceAccount indicates an expected call of CreateServiceAccount.
+func (mr *MockCoinRepositoryMockRecorder) CreateServiceAccount(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateServiceAccount", reflect.TypeOf((*MockCoinRepository)(nil).CreateServiceAccount), ctx, params)
+}
+
 // CreateUser mocks base method.
 func (m *MockCoinRepository) CreateUser(ctx context.Context, params repo.CreateUserParams) error {
 	m.ctrl.T.Helper()
@@ -95,11 +225,12 @@ func (mr *MockCoinRepositoryMockRecorder) CreateUser(ctx, params interface{}) *g
 }
 
 // DecreaseBalance mocks base method.
-func (m *MockCoinRepository) DecreaseBalance(ctx context.Context, tx *sqlx.Tx, params repo.ChangeBalanceParams) error {
+func (m *MockCoinRepository) DecreaseBalance(ctx context.Context, tx *sqlx.Tx, params repo.ChangeBalanceParams) (int, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DecreaseBalance", ctx, tx, params)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // DecreaseBalance indicates an expected call of DecreaseBalance.
@@ -108,6 +239,108 @@ func (mr *MockCoinRepositoryMockRecorder) DecreaseBalance(ctx, tx, params interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecreaseBalance", reflect.TypeOf((*MockCoinRepository)(nil).DecreaseBalance), ctx, tx, params)
 }
 
+// DeleteAuthChallenge mocks base method.
+func (m *MockCoinRepository) DeleteAuthChallenge(ctx context.Context, tokenHash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAuthChallenge", ctx, tokenHash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAuthChallenge indicates an expected call of DeleteAuthChallenge.
+func (mr *MockCoinRepositoryMockRecorder) DeleteAuthChallenge(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAuthChallenge", reflect.TypeOf((*MockCoinRepository)(nil).DeleteAuthChallenge), ctx, tokenHash)
+}
+
+// DeleteFeatureFlag mocks base method.
+func (m *MockCoinRepository) DeleteFeatureFlag(ctx context.Context, name string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFeatureFlag", ctx, name)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteFeatureFlag indicates an expected call of DeleteFeatureFlag.
+func (mr *MockCoinRepositoryMockRecorder) DeleteFeatureFlag(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFeatureFlag", reflect.TypeOf((*MockCoinRepository)(nil).DeleteFeatureFlag), ctx, name)
+}
+
+// DisableTOTP mocks base method.
+func (m *MockCoinRepository) DisableTOTP(ctx context.Context, username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTP", ctx, username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTP indicates an expected call of DisableTOTP.
+func (mr *MockCoinRepositoryMockRecorder) DisableTOTP(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTP", reflect.TypeOf((*MockCoinRepository)(nil).DisableTOTP), ctx, username)
+}
+
+// EnableTOTP mocks base method.
+func (m *MockCoinRepository) EnableTOTP(ctx context.Context, params repo.EnableTOTPParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTOTP", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableTOTP indicates an expected call of EnableTOTP.
+func (mr *MockCoinRepositoryMockRecorder) EnableTOTP(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTOTP", reflect.TypeOf((*MockCoinRepository)(nil).EnableTOTP), ctx, params)
+}
+
+// FailNotificationDelivery mocks base method.
+func (m *MockCoinRepository) FailNotificationDelivery(ctx context.Context, params repo.FailNotificationDeliveryParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailNotificationDelivery", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailNotificationDelivery indicates an expected call of FailNotificationDelivery.
+func (mr *MockCoinRepositoryMockRecorder) FailNotificationDelivery(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailNotificationDelivery", reflect.TypeOf((*MockCoinRepository)(nil).FailNotificationDelivery), ctx, params)
+}
+
+// GetAPIKey mocks base method.
+func (m *MockCoinRepository) GetAPIKey(ctx context.Context, id string) (models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKey", ctx, id)
+	ret0, _ := ret[0].(models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKey indicates an expected call of GetAPIKey.
+func (mr *MockCoinRepositoryMockRecorder) GetAPIKey(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKey", reflect.TypeOf((*MockCoinRepository)(nil).GetAPIKey), ctx, id)
+}
+
+// GetAuthChallenge mocks base method.
+func (m *MockCoinRepository) GetAuthChallenge(ctx context.Context, tokenHash string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuthChallenge", ctx, tokenHash)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuthChallenge indicates an expected call of GetAuthChallenge.
+func (mr *MockCoinRepositoryMockRecorder) GetAuthChallenge(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthChallenge", reflect.TypeOf((*MockCoinRepository)(nil).GetAuthChallenge), ctx, tokenHash)
+}
+
 // GetBalance mocks base method.
 func (m *MockCoinRepository) GetBalance(ctx context.Context, params repo.GetBalanceParams) (int, error) {
 	m.ctrl.T.Helper()
@@ -123,6 +356,36 @@ func (mr *MockCoinRepositoryMockRecorder) GetBalance(ctx, params interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockCoinRepository)(nil).GetBalance), ctx, params)
 }
 
+// GetEvents mocks base method.
+func (m *MockCoinRepository) GetEvents(ctx context.Context, params repo.GetEventsParams) ([]models.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEvents", ctx, params)
+	ret0, _ := ret[0].([]models.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEvents indicates an expected call of GetEvents.
+func (mr *MockCoinRepositoryMockRecorder) GetEvents(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEvents", reflect.TypeOf((*MockCoinRepository)(nil).GetEvents), ctx, params)
+}
+
+// GetFeatureFlags mocks base method.
+func (m *MockCoinRepository) GetFeatureFlags(ctx context.Context) ([]models.FeatureFlag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeatureFlags", ctx)
+	ret0, _ := ret[0].([]models.FeatureFlag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeatureFlags indicates an expected call of GetFeatureFlags.
+func (mr *MockCoinRepositoryMockRecorder) GetFeatureFlags(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeatureFlags", reflect.TypeOf((*MockCoinRepository)(nil).GetFeatureFlags), ctx)
+}
+
 // GetItem mocks base method.
 func (m *MockCoinRepository) GetItem(ctx context.Context, itemName string) (models.Item, error) {
 	m.ctrl.T.Helper()
@@ -138,6 +401,81 @@ func (mr *MockCoinRepositoryMockRecorder) GetItem(ctx, itemName interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItem", reflect.TypeOf((*MockCoinRepository)(nil).GetItem), ctx, itemName)
 }
 
+// GetItems mocks base method.
+func (m *MockCoinRepository) GetItems(ctx context.Context, params repo.GetItemsParams) ([]models.Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItems", ctx, params)
+	ret0, _ := ret[0].([]models.Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetItems indicates an expected call of GetItems.
+func (mr *MockCoinRepositoryMockRecorder) GetItems(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItems", reflect.TypeOf((*MockCoinRepository)(nil).GetItems), ctx, params)
+}
+
+// GetLoginAttempts mocks base method.
+func (m *MockCoinRepository) GetLoginAttempts(ctx context.Context, keys []string) ([]models.LoginAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginAttempts", ctx, keys)
+	ret0, _ := ret[0].([]models.LoginAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginAttempts indicates an expected call of GetLoginAttempts.
+func (mr *MockCoinRepositoryMockRecorder) GetLoginAttempts(ctx, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginAttempts", reflect.TypeOf((*MockCoinRepository)(nil).GetLoginAttempts), ctx, keys)
+}
+
+// GetNotificationPreferences mocks base method.
+func (m *MockCoinRepository) GetNotificationPreferences(ctx context.Context, username string) ([]models.NotificationPreference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotificationPreferences", ctx, username)
+	ret0, _ := ret[0].([]models.NotificationPreference)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotificationPreferences indicates an expected call of GetNotificationPreferences.
+func (mr *MockCoinRepositoryMockRecorder) GetNotificationPreferences(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationPreferences", reflect.TypeOf((*MockCoinRepository)(nil).GetNotificationPreferences), ctx, username)
+}
+
+// GetNotifications mocks base method.
+func (m *MockCoinRepository) GetNotifications(ctx context.Context, params repo.GetNotificationsParams) ([]models.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotifications", ctx, params)
+	ret0, _ := ret[0].([]models.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotifications indicates an expected call of GetNotifications.
+func (mr *MockCoinRepositoryMockRecorder) GetNotifications(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotifications", reflect.TypeOf((*MockCoinRepository)(nil).GetNotifications), ctx, params)
+}
+
+// GetOIDCIdentity mocks base method.
+func (m *MockCoinRepository) GetOIDCIdentity(ctx context.Context, params repo.GetOIDCIdentityParams) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOIDCIdentity", ctx, params)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOIDCIdentity indicates an expected call of GetOIDCIdentity.
+func (mr *MockCoinRepositoryMockRecorder) GetOIDCIdentity(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOIDCIdentity", reflect.TypeOf((*MockCoinRepository)(nil).GetOIDCIdentity), ctx, params)
+}
+
 // GetPurchases mocks base method.
 func (m *MockCoinRepository) GetPurchases(ctx context.Context, username string) ([]models.PurchaseItem, error) {
 	m.ctrl.T.Helper()
@@ -153,6 +491,21 @@ func (mr *MockCoinRepositoryMockRecorder) GetPurchases(ctx, username interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPurchases", reflect.TypeOf((*MockCoinRepository)(nil).GetPurchases), ctx, username)
 }
 
+// GetTransaction mocks base method.
+func (m *MockCoinRepository) GetTransaction(ctx context.Context, id int64) (models.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransaction", ctx, id)
+	ret0, _ := ret[0].(models.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransaction indicates an expected call of GetTransaction.
+func (mr *MockCoinRepositoryMockRecorder) GetTransaction(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransaction", reflect.TypeOf((*MockCoinRepository)(nil).GetTransaction), ctx, id)
+}
+
 // GetTransactions mocks base method.
 func (m *MockCoinRepository) GetTransactions(ctx context.Context, username string) ([]models.Transaction, error) {
 	m.ctrl.T.Helper()
@@ -168,6 +521,21 @@ func (mr *MockCoinRepositoryMockRecorder) GetTransactions(ctx, username interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactions", reflect.TypeOf((*MockCoinRepository)(nil).GetTransactions), ctx, username)
 }
 
+// GetTwoFactorPolicy mocks base method.
+func (m *MockCoinRepository) GetTwoFactorPolicy(ctx context.Context) (models.TwoFactorPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTwoFactorPolicy", ctx)
+	ret0, _ := ret[0].(models.TwoFactorPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTwoFactorPolicy indicates an expected call of GetTwoFactorPolicy.
+func (mr *MockCoinRepositoryMockRecorder) GetTwoFactorPolicy(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTwoFactorPolicy", reflect.TypeOf((*MockCoinRepository)(nil).GetTwoFactorPolicy), ctx)
+}
+
 // GetUserByUsername mocks base method.
 func (m *MockCoinRepository) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -183,12 +551,28 @@ func (mr *MockCoinRepositoryMockRecorder) GetUserByUsername(ctx, username interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockCoinRepository)(nil).GetUserByUsername), ctx, username)
 }
 
+// GrantDelegation mocks base method.
+func (m *MockCoinRepository) GrantDelegation(ctx context.Context, params repo.GrantDelegationParams) (models.Delegation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantDelegation", ctx, params)
+	ret0, _ := ret[0].(models.Delegation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GrantDelegation indicates an expected call of GrantDelegation.
+func (mr *MockCoinRepositoryMockRecorder) GrantDelegation(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantDelegation", reflect.TypeOf((*MockCoinRepository)(nil).GrantDelegation), ctx, params)
+}
+
 // IncreaseBalance mocks base method.
-func (m *MockCoinRepository) IncreaseBalance(ctx context.Context, tx *sqlx.Tx, params repo.ChangeBalanceParams) error {
+func (m *MockCoinRepository) IncreaseBalance(ctx context.Context, tx *sqlx.Tx, params repo.ChangeBalanceParams) (int, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "IncreaseBalance", ctx, tx, params)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // IncreaseBalance indicates an expected call of IncreaseBalance.
@@ -197,45 +581,2119 @@ func (mr *MockCoinRepositoryMockRecorder) IncreaseBalance(ctx, tx, params interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncreaseBalance", reflect.TypeOf((*MockCoinRepository)(nil).IncreaseBalance), ctx, tx, params)
 }
 
-// ReceivedCoinsInfo mocks base method.
-func (m *MockCoinRepository) ReceivedCoinsInfo(ctx context.Context, username string) ([]models.Transaction, error) {
+// LinkOIDCIdentity mocks base method.
+func (m *MockCoinRepository) LinkOIDCIdentity(ctx context.Context, params repo.LinkOIDCIdentityParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ReceivedCoinsInfo", ctx, username)
-	ret0, _ := ret[0].([]models.Transaction)
+	ret := m.ctrl.Call(m, "LinkOIDCIdentity", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LinkOIDCIdentity indicates an expected call of LinkOIDCIdentity.
+func (mr *MockCoinRepositoryMockRecorder) LinkOIDCIdentity(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkOIDCIdentity", reflect.TypeOf((*MockCoinRepository)(nil).LinkOIDCIdentity), ctx, params)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockCoinRepository) ListAPIKeys(ctx context.Context, username string) ([]models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", ctx, username)
+	ret0, _ := ret[0].([]models.APIKey)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ReceivedCoinsInfo indicates an expected call of ReceivedCoinsInfo.
-func (mr *MockCoinRepositoryMockRecorder) ReceivedCoinsInfo(ctx, username interface{}) *gomock.Call {
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockCoinRepositoryMockRecorder) ListAPIKeys(ctx, username interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceivedCoinsInfo", reflect.TypeOf((*MockCoinRepository)(nil).ReceivedCoinsInfo), ctx, username)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockCoinRepository)(nil).ListAPIKeys), ctx, username)
 }
 
-// RollbackTx mocks base method.
-func (m *MockCoinRepository) RollbackTx(tx *sqlx.Tx) error {
+// ListDelegations mocks base method.
+func (m *MockCoinRepository) ListDelegations(ctx context.Context, ownerUsername string) ([]models.Delegation, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RollbackTx", tx)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "ListDelegations", ctx, ownerUsername)
+	ret0, _ := ret[0].([]models.Delegation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// RollbackTx indicates an expected call of RollbackTx.
-func (mr *MockCoinRepositoryMockRecorder) RollbackTx(tx interface{}) *gomock.Call {
+// ListDelegations indicates an expected call of ListDelegations.
+func (mr *MockCoinRepositoryMockRecorder) ListDelegations(ctx, ownerUsername interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RollbackTx", reflect.TypeOf((*MockCoinRepository)(nil).RollbackTx), tx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDelegations", reflect.TypeOf((*MockCoinRepository)(nil).ListDelegations), ctx, ownerUsername)
 }
 
-// SaveTransaction mocks base method.
-func (m *MockCoinRepository) SaveTransaction(ctx context.Context, params repo.SaveTransactionParams) error {
+// LockDelegation mocks base method.
+func (m *MockCoinRepository) LockDelegation(ctx context.Context, tx *sqlx.Tx, params repo.LockDelegationParams) (models.Delegation, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveTransaction", ctx, params)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "LockDelegation", ctx, tx, params)
+	ret0, _ := ret[0].(models.Delegation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// SaveTransaction indicates an expected call of SaveTransaction.
-func (mr *MockCoinRepositoryMockRecorder) SaveTransaction(ctx, params interface{}) *gomock.Call {
+// LockDelegation indicates an expected call of LockDelegation.
+func (mr *MockCoinRepositoryMockRecorder) LockDelegation(ctx, tx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockDelegation", reflect.TypeOf((*MockCoinRepository)(nil).LockDelegation), ctx, tx, params)
+}
+
+// MarkAllNotificationsRead mocks base method.
+func (m *MockCoinRepository) MarkAllNotificationsRead(ctx context.Context, username string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAllNotificationsRead", ctx, username)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkAllNotificationsRead indicates an expected call of MarkAllNotificationsRead.
+func (mr *MockCoinRepositoryMockRecorder) MarkAllNotificationsRead(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAllNotificationsRead", reflect.TypeOf((*MockCoinRepository)(nil).MarkAllNotificationsRead), ctx, username)
+}
+
+// MarkNotificationRead mocks base method.
+func (m *MockCoinRepository) MarkNotificationRead(ctx context.Context, params repo.MarkNotificationReadParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkNotificationRead", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkNotificationRead indicates an expected call of MarkNotificationRead.
+func (mr *MockCoinRepositoryMockRecorder) MarkNotificationRead(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkNotificationRead", reflect.TypeOf((*MockCoinRepository)(nil).MarkNotificationRead), ctx, params)
+}
+
+// ReceivedCoinsInfo mocks base method.
+func (m *MockCoinRepository) ReceivedCoinsInfo(ctx context.Context, username string) ([]models.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceivedCoinsInfo", ctx, username)
+	ret0, _ := ret[0].([]models.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReceivedCoinsInfo indicates an expected call of ReceivedCoinsInfo.
+func (mr *MockCoinRepositoryMockRecorder) ReceivedCoinsInfo(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceivedCoinsInfo", reflect.TypeOf((*MockCoinRepository)(nil).ReceivedCoinsInfo), ctx, username)
+}
+
+// RecordLoginFailure mocks base method.
+func (m *MockCoinRepository) RecordLoginFailure(ctx context.Context, params repo.RecordLoginFailureParams) (models.LoginAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordLoginFailure", ctx, params)
+	ret0, _ := ret[0].(models.LoginAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordLoginFailure indicates an expected call of RecordLoginFailure.
+func (mr *MockCoinRepositoryMockRecorder) RecordLoginFailure(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordLoginFailure", reflect.TypeOf((*MockCoinRepository)(nil).RecordLoginFailure), ctx, params)
+}
+
+// ResetLoginAttempts mocks base method.
+func (m *MockCoinRepository) ResetLoginAttempts(ctx context.Context, keys []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetLoginAttempts", ctx, keys)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetLoginAttempts indicates an expected call of ResetLoginAttempts.
+func (mr *MockCoinRepositoryMockRecorder) ResetLoginAttempts(ctx, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetLoginAttempts", reflect.TypeOf((*MockCoinRepository)(nil).ResetLoginAttempts), ctx, keys)
+}
+
+// ResetPassword mocks base method.
+func (m *MockCoinRepository) ResetPassword(ctx context.Context, params repo.ResetPasswordParams) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, params)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockCoinRepositoryMockRecorder) ResetPassword(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockCoinRepository)(nil).ResetPassword), ctx, params)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockCoinRepository) RevokeAPIKey(ctx context.Context, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockCoinRepositoryMockRecorder) RevokeAPIKey(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockCoinRepository)(nil).RevokeAPIKey), ctx, id)
+}
+
+// RevokeDelegation mocks base method.
+func (m *MockCoinRepository) RevokeDelegation(ctx context.Context, params repo.RevokeDelegationParams) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeDelegation", ctx, params)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeDelegation indicates an expected call of RevokeDelegation.
+func (mr *MockCoinRepositoryMockRecorder) RevokeDelegation(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeDelegation", reflect.TypeOf((*MockCoinRepository)(nil).RevokeDelegation), ctx, params)
+}
+
+// RollbackTx mocks base method.
+func (m *MockCoinRepository) RollbackTx(tx *sqlx.Tx) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RollbackTx", tx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RollbackTx indicates an expected call of RollbackTx.
+func (mr *MockCoinRepositoryMockRecorder) RollbackTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RollbackTx", reflect.TypeOf((*MockCoinRepository)(nil).RollbackTx), tx)
+}
+
+// SaveEvent mocks base method.
+func (m *MockCoinRepository) SaveEvent(ctx context.Context, tx *sqlx.Tx, params repo.SaveEventParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveEvent", ctx, tx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveEvent indicates an expected call of SaveEvent.
+func (mr *MockCoinRepositoryMockRecorder) SaveEvent(ctx, tx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveEvent", reflect.TypeOf((*MockCoinRepository)(nil).SaveEvent), ctx, tx, params)
+}
+
+// SaveFeatureFlag mocks base method.
+func (m *MockCoinRepository) SaveFeatureFlag(ctx context.Context, params repo.SaveFeatureFlagParams) (models.FeatureFlag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveFeatureFlag", ctx, params)
+	ret0, _ := ret[0].(models.FeatureFlag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveFeatureFlag indicates an expected call of SaveFeatureFlag.
+func (mr *MockCoinRepositoryMockRecorder) SaveFeatureFlag(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveFeatureFlag", reflect.TypeOf((*MockCoinRepository)(nil).SaveFeatureFlag), ctx, params)
+}
+
+// SaveTransaction mocks base method.
+func (m *MockCoinRepository) SaveTransaction(ctx context.Context, tx *sqlx.Tx, params repo.SaveTransactionParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTransaction", ctx, tx, params)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveTransaction indicates an expected call of SaveTransaction.
+func (mr *MockCoinRepositoryMockRecorder) SaveTransaction(ctx, tx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTransaction", reflect.TypeOf((*MockCoinRepository)(nil).SaveTransaction), ctx, tx, params)
+}
+
+// SetNotificationContact mocks base method.
+func (m *MockCoinRepository) SetNotificationContact(ctx context.Context, params repo.SetNotificationContactParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetNotificationContact", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetNotificationContact indicates an expected call of SetNotificationContact.
+func (mr *MockCoinRepositoryMockRecorder) SetNotificationContact(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNotificationContact", reflect.TypeOf((*MockCoinRepository)(nil).SetNotificationContact), ctx, params)
+}
+
+// SetNotificationPreference mocks base method.
+func (m *MockCoinRepository) SetNotificationPreference(ctx context.Context, tx *sqlx.Tx, params repo.SetNotificationPreferenceParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetNotificationPreference", ctx, tx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetNotificationPreference indicates an expected call of SetNotificationPreference.
+func (mr *MockCoinRepositoryMockRecorder) SetNotificationPreference(ctx, tx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNotificationPreference", reflect.TypeOf((*MockCoinRepository)(nil).SetNotificationPreference), ctx, tx, params)
+}
+
+// SetTOTPSecret mocks base method.
+func (m *MockCoinRepository) SetTOTPSecret(ctx context.Context, params repo.SetTOTPSecretParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTOTPSecret", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTOTPSecret indicates an expected call of SetTOTPSecret.
+func (mr *MockCoinRepositoryMockRecorder) SetTOTPSecret(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTOTPSecret", reflect.TypeOf((*MockCoinRepository)(nil).SetTOTPSecret), ctx, params)
+}
+
+// SetTwoFactorPolicy mocks base method.
+func (m *MockCoinRepository) SetTwoFactorPolicy(ctx context.Context, policy models.TwoFactorPolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTwoFactorPolicy", ctx, policy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTwoFactorPolicy indicates an expected call of SetTwoFactorPolicy.
+func (mr *MockCoinRepositoryMockRecorder) SetTwoFactorPolicy(ctx, policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTwoFactorPolicy", reflect.TypeOf((*MockCoinRepository)(nil).SetTwoFactorPolicy), ctx, policy)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockCoinRepository) UpdatePassword(ctx context.Context, params repo.UpdatePasswordParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockCoinRepositoryMockRecorder) UpdatePassword(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockCoinRepository)(nil).UpdatePassword), ctx, params)
+}
+
+// UseOIDCLoginState mocks base method.
+func (m *MockCoinRepository) UseOIDCLoginState(ctx context.Context, stateHash string) (models.OIDCLoginState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseOIDCLoginState", ctx, stateHash)
+	ret0, _ := ret[0].(models.OIDCLoginState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseOIDCLoginState indicates an expected call of UseOIDCLoginState.
+func (mr *MockCoinRepositoryMockRecorder) UseOIDCLoginState(ctx, stateHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseOIDCLoginState", reflect.TypeOf((*MockCoinRepository)(nil).UseOIDCLoginState), ctx, stateHash)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockCoinRepository) UseRecoveryCode(ctx context.Context, params repo.UseRecoveryCodeParams) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, params)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockCoinRepositoryMockRecorder) UseRecoveryCode(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockCoinRepository)(nil).UseRecoveryCode), ctx, params)
+}
+
+// UseTOTPCounter mocks base method.
+func (m *MockCoinRepository) UseTOTPCounter(ctx context.Context, params repo.UseTOTPCounterParams) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPCounter", ctx, params)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTOTPCounter indicates an expected call of UseTOTPCounter.
+func (mr *MockCoinRepositoryMockRecorder) UseTOTPCounter(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPCounter", reflect.TypeOf((*MockCoinRepository)(nil).UseTOTPCounter), ctx, params)
+}
+
+// MockNotificationRepository is a mock of NotificationRepository interface.
+type MockNotificationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationRepositoryMockRecorder
+}
+
+// MockNotificationRepositoryMockRecorder is the mock recorder for MockNotificationRepository.
+type MockNotificationRepositoryMockRecorder struct {
+	mock *MockNotificationRepository
+}
+
+// NewMockNotificationRepository creates a new mock instance.
+func NewMockNotificationRepository(ctrl *gomock.Controller) *MockNotificationRepository {
+	mock := &MockNotificationRepository{ctrl: ctrl}
+	mock.recorder = &MockNotificationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationRepository) EXPECT() *MockNotificationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimNotificationDeliveries mocks base method.
+func (m *MockNotificationRepository) ClaimNotificationDeliveries(ctx context.Context, params repo.ClaimNotificationDeliveriesParams) ([]models.NotificationDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimNotificationDeliveries", ctx, params)
+	ret0, _ := ret[0].([]models.NotificationDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimNotificationDeliveries indicates an expected call of ClaimNotificationDeliveries.
+func (mr *MockNotificationRepositoryMockRecorder) ClaimNotificationDeliveries(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimNotificationDeliveries", reflect.TypeOf((*MockNotificationRepository)(nil).ClaimNotificationDeliveries), ctx, params)
+}
+
+// CompleteNotificationDelivery mocks base method.
+func (m *MockNotificationRepository) CompleteNotificationDelivery(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteNotificationDelivery", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteNotificationDelivery indicates an expected call of CompleteNotificationDelivery.
+func (mr *MockNotificationRepositoryMockRecorder) CompleteNotificationDelivery(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteNotificationDelivery", reflect.TypeOf((*MockNotificationRepository)(nil).CompleteNotificationDelivery), ctx, id)
+}
+
+// CountUnreadNotifications mocks base method.
+func (m *MockNotificationRepository) CountUnreadNotifications(ctx context.Context, username string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnreadNotifications", ctx, username)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnreadNotifications indicates an expected call of CountUnreadNotifications.
+func (mr *MockNotificationRepositoryMockRecorder) CountUnreadNotifications(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnreadNotifications", reflect.TypeOf((*MockNotificationRepository)(nil).CountUnreadNotifications), ctx, username)
+}
+
+// CreateNotification mocks base method.
+func (m *MockNotificationRepository) CreateNotification(ctx context.Context, tx *sqlx.Tx, params repo.CreateNotificationParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNotification", ctx, tx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateNotification indicates an expected call of CreateNotification.
+func (mr *MockNotificationRepositoryMockRecorder) CreateNotification(ctx, tx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNotification", reflect.TypeOf((*MockNotificationRepository)(nil).CreateNotification), ctx, tx, params)
+}
+
+// FailNotificationDelivery mocks base method.
+func (m *MockNotificationRepository) FailNotificationDelivery(ctx context.Context, params repo.FailNotificationDeliveryParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailNotificationDelivery", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailNotificationDelivery indicates an expected call of FailNotificationDelivery.
+func (mr *MockNotificationRepositoryMockRecorder) FailNotificationDelivery(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailNotificationDelivery", reflect.TypeOf((*MockNotificationRepository)(nil).FailNotificationDelivery), ctx, params)
+}
+
+// GetNotificationPreferences mocks base method.
+func (m *MockNotificationRepository) GetNotificationPreferences(ctx context.Context, username string) ([]models.NotificationPreference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotificationPreferences", ctx, username)
+	ret0, _ := ret[0].([]models.NotificationPreference)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotificationPreferences indicates an expected call of GetNotificationPreferences.
+func (mr *MockNotificationRepositoryMockRecorder) GetNotificationPreferences(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationPreferences", reflect.TypeOf((*MockNotificationRepository)(nil).GetNotificationPreferences), ctx, username)
+}
+
+// GetNotifications mocks base method.
+func (m *MockNotificationRepository) GetNotifications(ctx context.Context, params repo.GetNotificationsParams) ([]models.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotifications", ctx, params)
+	ret0, _ := ret[0].([]models.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotifications indicates an expected call of GetNotifications.
+func (mr *MockNotificationRepositoryMockRecorder) GetNotifications(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotifications", reflect.TypeOf((*MockNotificationRepository)(nil).GetNotifications), ctx, params)
+}
+
+// MarkAllNotificationsRead mocks base method.
+func (m *MockNotificationRepository) MarkAllNotificationsRead(ctx context.Context, username string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAllNotificationsRead", ctx, username)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkAllNotificationsRead indicates an expected call of MarkAllNotificationsRead.
+func (mr *MockNotificationRepositoryMockRecorder) MarkAllNotificationsRead(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAllNotificationsRead", reflect.TypeOf((*MockNotificationRepository)(nil).MarkAllNotificationsRead), ctx, username)
+}
+
+// MarkNotificationRead mocks base method.
+func (m *MockNotificationRepository) MarkNotificationRead(ctx context.Context, params repo.MarkNotificationReadParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkNotificationRead", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkNotificationRead indicates an expected call of MarkNotificationRead.
+func (mr *MockNotificationRepositoryMockRecorder) MarkNotificationRead(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkNotificationRead", reflect.TypeOf((*MockNotificationRepository)(nil).MarkNotificationRead), ctx, params)
+}
+
+// SetNotificationContact mocks base method.
+func (m *MockNotificationRepository) SetNotificationContact(ctx context.Context, params repo.SetNotificationContactParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetNotificationContact", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetNotificationContact indicates an expected call of SetNotificationContact.
+func (mr *MockNotificationRepositoryMockRecorder) SetNotificationContact(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNotificationContact", reflect.TypeOf((*MockNotificationRepository)(nil).SetNotificationContact), ctx, params)
+}
+
+// SetNotificationPreference mocks base method.
+func (m *MockNotificationRepository) SetNotificationPreference(ctx context.Context, tx *sqlx.Tx, params repo.SetNotificationPreferenceParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetNotificationPreference", ctx, tx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetNotificationPreference indicates an expected call of SetNotificationPreference.
+func (mr *MockNotificationRepositoryMockRecorder) SetNotificationPreference(ctx, tx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNotificationPreference", reflect.TypeOf((*MockNotificationRepository)(nil).SetNotificationPreference), ctx, tx, params)
+}
+
+// MockLoginAttemptRepository is a mock of LoginAttemptRepository interface.
+type MockLoginAttemptRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginAttemptRepositoryMockRecorder
+}
+
+// MockLoginAttemptRepositoryMockRecorder is the mock recorder for MockLoginAttemptRepository.
+type MockLoginAttemptRepositoryMockRecorder struct {
+	mock *MockLoginAttemptRepository
+}
+
+// NewMockLoginAttemptRepository creates a new mock instance.
+func NewMockLoginAttemptRepository(ctrl *gomock.Controller) *MockLoginAttemptRepository {
+	mock := &MockLoginAttemptRepository{ctrl: ctrl}
+	mock.recorder = &MockLoginAttemptRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginAttemptRepository) EXPECT() *MockLoginAttemptRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetLoginAttempts mocks base method.
+func (m *MockLoginAttemptRepository) GetLoginAttempts(ctx context.Context, keys []string) ([]models.LoginAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginAttempts", ctx, keys)
+	ret0, _ := ret[0].([]models.LoginAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginAttempts indicates an expected call of GetLoginAttempts.
+func (mr *MockLoginAttemptRepositoryMockRecorder) GetLoginAttempts(ctx, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginAttempts", reflect.TypeOf((*MockLoginAttemptRepository)(nil).GetLoginAttempts), ctx, keys)
+}
+
+// RecordLoginFailure mocks base method.
+func (m *MockLoginAttemptRepository) RecordLoginFailure(ctx context.Context, params repo.RecordLoginFailureParams) (models.LoginAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordLoginFailure", ctx, params)
+	ret0, _ := ret[0].(models.LoginAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordLoginFailure indicates an expected call of RecordLoginFailure.
+func (mr *MockLoginAttemptRepositoryMockRecorder) RecordLoginFailure(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordLoginFailure", reflect.TypeOf((*MockLoginAttemptRepository)(nil).RecordLoginFailure), ctx, params)
+}
+
+// ResetLoginAttempts mocks base method.
+func (m *MockLoginAttemptRepository) ResetLoginAttempts(ctx context.Context, keys []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetLoginAttempts", ctx, keys)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetLoginAttempts indicates an expected call of ResetLoginAttempts.
+func (mr *MockLoginAttemptRepositoryMockRecorder) ResetLoginAttempts(ctx, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetLoginAttempts", reflect.TypeOf((*MockLoginAttemptRepository)(nil).ResetLoginAttempts), ctx, keys)
+}
+
+// MockTwoFactorRepository is a mock of TwoFactorRepository interface.
+type MockTwoFactorRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTwoFactorRepositoryMockRecorder
+}
+
+// MockTwoFactorRepositoryMockRecorder is the mock recorder for MockTwoFactorRepository.
+type MockTwoFactorRepositoryMockRecorder struct {
+	mock *MockTwoFactorRepository
+}
+
+// NewMockTwoFactorRepository creates a new mock instance.
+func NewMockTwoFactorRepository(ctrl *gomock.Controller) *MockTwoFactorRepository {
+	mock := &MockTwoFactorRepository{ctrl: ctrl}
+	mock.recorder = &MockTwoFactorRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTwoFactorRepository) EXPECT() *MockTwoFactorRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateAuthChallenge mocks base method.
+func (m *MockTwoFactorRepository) CreateAuthChallenge(ctx context.Context, params repo.CreateAuthChallengeParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuthChallenge", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAuthChallenge indicates an expected call of CreateAuthChallenge.
+func (mr *MockTwoFactorRepositoryMockRecorder) CreateAuthChallenge(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuthChallenge", reflect.TypeOf((*MockTwoFactorRepository)(nil).CreateAuthChallenge), ctx, params)
+}
+
+// DeleteAuthChallenge mocks base method.
+func (m *MockTwoFactorRepository) DeleteAuthChallenge(ctx context.Context, tokenHash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAuthChallenge", ctx, tokenHash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAuthChallenge indicates an expected call of DeleteAuthChallenge.
+func (mr *MockTwoFactorRepositoryMockRecorder) DeleteAuthChallenge(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAuthChallenge", reflect.TypeOf((*MockTwoFactorRepository)(nil).DeleteAuthChallenge), ctx, tokenHash)
+}
+
+// DisableTOTP mocks base method.
+func (m *MockTwoFactorRepository) DisableTOTP(ctx context.Context, username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTP", ctx, username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTP indicates an expected call of DisableTOTP.
+func (mr *MockTwoFactorRepositoryMockRecorder) DisableTOTP(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTP", reflect.TypeOf((*MockTwoFactorRepository)(nil).DisableTOTP), ctx, username)
+}
+
+// EnableTOTP mocks base method.
+func (m *MockTwoFactorRepository) EnableTOTP(ctx context.Context, params repo.EnableTOTPParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTOTP", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableTOTP indicates an expected call of EnableTOTP.
+func (mr *MockTwoFactorRepositoryMockRecorder) EnableTOTP(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTOTP", reflect.TypeOf((*MockTwoFactorRepository)(nil).EnableTOTP), ctx, params)
+}
+
+// GetAuthChallenge mocks base method.
+func (m *MockTwoFactorRepository) GetAuthChallenge(ctx context.Context, tokenHash string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuthChallenge", ctx, tokenHash)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuthChallenge indicates an expected call of GetAuthChallenge.
+func (mr *MockTwoFactorRepositoryMockRecorder) GetAuthChallenge(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthChallenge", reflect.TypeOf((*MockTwoFactorRepository)(nil).GetAuthChallenge), ctx, tokenHash)
+}
+
+// GetTwoFactorPolicy mocks base method.
+func (m *MockTwoFactorRepository) GetTwoFactorPolicy(ctx context.Context) (models.TwoFactorPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTwoFactorPolicy", ctx)
+	ret0, _ := ret[0].(models.TwoFactorPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTwoFactorPolicy indicates an expected call of GetTwoFactorPolicy.
+func (mr *MockTwoFactorRepositoryMockRecorder) GetTwoFactorPolicy(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTwoFactorPolicy", reflect.TypeOf((*MockTwoFactorRepository)(nil).GetTwoFactorPolicy), ctx)
+}
+
+// SetTOTPSecret mocks base method.
+func (m *MockTwoFactorRepository) SetTOTPSecret(ctx context.Context, params repo.SetTOTPSecretParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTOTPSecret", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTOTPSecret indicates an expected call of SetTOTPSecret.
+func (mr *MockTwoFactorRepositoryMockRecorder) SetTOTPSecret(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTOTPSecret", reflect.TypeOf((*MockTwoFactorRepository)(nil).SetTOTPSecret), ctx, params)
+}
+
+// SetTwoFactorPolicy mocks base method.
+func (m *MockTwoFactorRepository) SetTwoFactorPolicy(ctx context.Context, policy models.TwoFactorPolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTwoFactorPolicy", ctx, policy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTwoFactorPolicy indicates an expected call of SetTwoFactorPolicy.
+func (mr *MockTwoFactorRepositoryMockRecorder) SetTwoFactorPolicy(ctx, policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTwoFactorPolicy", reflect.TypeOf((*MockTwoFactorRepository)(nil).SetTwoFactorPolicy), ctx, policy)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockTwoFactorRepository) UseRecoveryCode(ctx context.Context, params repo.UseRecoveryCodeParams) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, params)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockTwoFactorRepositoryMockRecorder) UseRecoveryCode(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockTwoFactorRepository)(nil).UseRecoveryCode), ctx, params)
+}
+
+// UseTOTPCounter mocks base method.
+func (m *MockTwoFactorRepository) UseTOTPCounter(ctx context.Context, params repo.UseTOTPCounterParams) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPCounter", ctx, params)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTOTPCounter indicates an expected call of UseTOTPCounter.
+func (mr *MockTwoFactorRepositoryMockRecorder) UseTOTPCounter(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPCounter", reflect.TypeOf((*MockTwoFactorRepository)(nil).UseTOTPCounter), ctx, params)
+}
+
+// MockAPIKeyRepository is a mock of APIKeyRepository interface.
+type MockAPIKeyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeyRepositoryMockRecorder
+}
+
+// MockAPIKeyRepositoryMockRecorder is the mock recorder for MockAPIKeyRepository.
+type MockAPIKeyRepositoryMockRecorder struct {
+	mock *MockAPIKeyRepository
+}
+
+// NewMockAPIKeyRepository creates a new mock instance.
+func NewMockAPIKeyRepository(ctrl *gomock.Controller) *MockAPIKeyRepository {
+	mock := &MockAPIKeyRepository{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPIKeyRepository) EXPECT() *MockAPIKeyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockAPIKeyRepository) CreateAPIKey(ctx context.Context, params repo.CreateAPIKeyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockAPIKeyRepositoryMockRecorder) CreateAPIKey(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockAPIKeyRepository)(nil).CreateAPIKey), ctx, params)
+}
+
+// CreateServiceAccount mocks base method.
+func (m *MockAPIKeyRepository) CreateServiceAccount(ctx context.Context, params repo.CreateServiceAccountParams) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateServiceAccount", ctx, params)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateServiceAccount indicates an expected call of CreateServiceAccount.
+func (mr *MockAPIKeyRepositoryMockRecorder) CreateServiceAccount(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateServiceAccount", reflect.TypeOf((*MockAPIKeyRepository)(nil).CreateServiceAccount), ctx, params)
+}
+
+// GetAPIKey mocks base method.
+func (m *MockAPIKeyRepository) GetAPIKey(ctx context.Context, id string) (models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKey", ctx, id)
+	ret0, _ := ret[0].(models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKey indicates an expected call of GetAPIKey.
+func (mr *MockAPIKeyRepositoryMockRecorder) GetAPIKey(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKey", reflect.TypeOf((*MockAPIKeyRepository)(nil).GetAPIKey), ctx, id)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockAPIKeyRepository) ListAPIKeys(ctx context.Context, username string) ([]models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", ctx, username)
+	ret0, _ := ret[0].([]models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockAPIKeyRepositoryMockRecorder) ListAPIKeys(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockAPIKeyRepository)(nil).ListAPIKeys), ctx, username)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockAPIKeyRepository) RevokeAPIKey(ctx context.Context, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockAPIKeyRepositoryMockRecorder) RevokeAPIKey(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockAPIKeyRepository)(nil).RevokeAPIKey), ctx, id)
+}
+
+// MockDelegationRepository is a mock of DelegationRepository interface.
+type MockDelegationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDelegationRepositoryMockRecorder
+}
+
+// MockDelegationRepositoryMockRecorder is the mock recorder for MockDelegationRepository.
+type MockDelegationRepositoryMockRecorder struct {
+	mock *MockDelegationRepository
+}
+
+// NewMockDelegationRepository creates a new mock instance.
+func NewMockDelegationRepository(ctrl *gomock.Controller) *MockDelegationRepository {
+	mock := &MockDelegationRepository{ctrl: ctrl}
+	mock.recorder = &MockDelegationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDelegationRepository) EXPECT() *MockDelegationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GrantDelegation mocks base method.
+func (m *MockDelegationRepository) GrantDelegation(ctx context.Context, params repo.GrantDelegationParams) (models.Delegation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantDelegation", ctx, params)
+	ret0, _ := ret[0].(models.Delegation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GrantDelegation indicates an expected call of GrantDelegation.
+func (mr *MockDelegationRepositoryMockRecorder) GrantDelegation(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantDelegation", reflect.TypeOf((*MockDelegationRepository)(nil).GrantDelegation), ctx, params)
+}
+
+// ListDelegations mocks base method.
+func (m *MockDelegationRepository) ListDelegations(ctx context.Context, ownerUsername string) ([]models.Delegation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDelegations", ctx, ownerUsername)
+	ret0, _ := ret[0].([]models.Delegation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDelegations indicates an expected call of ListDelegations.
+func (mr *MockDelegationRepositoryMockRecorder) ListDelegations(ctx, ownerUsername interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDelegations", reflect.TypeOf((*MockDelegationRepository)(nil).ListDelegations), ctx, ownerUsername)
+}
+
+// LockDelegation mocks base method.
+func (m *MockDelegationRepository) LockDelegation(ctx context.Context, tx *sqlx.Tx, params repo.LockDelegationParams) (models.Delegation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockDelegation", ctx, tx, params)
+	ret0, _ := ret[0].(models.Delegation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockDelegation indicates an expected call of LockDelegation.
+func (mr *MockDelegationRepositoryMockRecorder) LockDelegation(ctx, tx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockDelegation", reflect.TypeOf((*MockDelegationRepository)(nil).LockDelegation), ctx, tx, params)
+}
+
+// RevokeDelegation mocks base method.
+func (m *MockDelegationRepository) RevokeDelegation(ctx context.Context, params repo.RevokeDelegationParams) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeDelegation", ctx, params)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeDelegation indicates an expected call of RevokeDelegation.
+func (mr *MockDelegationRepositoryMockRecorder) RevokeDelegation(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeDelegation", reflect.TypeOf((*MockDelegationRepository)(nil).RevokeDelegation), ctx, params)
+}
+
+// MockOIDCRepository is a mock of OIDCRepository interface.
+type MockOIDCRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOIDCRepositoryMockRecorder
+}
+
+// MockOIDCRepositoryMockRecorder is the mock recorder for MockOIDCRepository.
+type MockOIDCRepositoryMockRecorder struct {
+	mock *MockOIDCRepository
+}
+
+// NewMockOIDCRepository creates a new mock instance.
+func NewMockOIDCRepository(ctrl *gomock.Controller) *MockOIDCRepository {
+	mock := &MockOIDCRepository{ctrl: ctrl}
+	mock.recorder = &MockOIDCRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOIDCRepository) EXPECT() *MockOIDCRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateOIDCLoginState mocks base method.
+func (m *MockOIDCRepository) CreateOIDCLoginState(ctx context.Context, params repo.CreateOIDCLoginStateParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOIDCLoginState", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOIDCLoginState indicates an expected call of CreateOIDCLoginState.
+func (mr *MockOIDCRepositoryMockRecorder) CreateOIDCLoginState(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOIDCLoginState", reflect.TypeOf((*MockOIDCRepository)(nil).CreateOIDCLoginState), ctx, params)
+}
+
+// GetOIDCIdentity mocks base method.
+func (m *MockOIDCRepository) GetOIDCIdentity(ctx context.Context, params repo.GetOIDCIdentityParams) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOIDCIdentity", ctx, params)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOIDCIdentity indicates an expected call of GetOIDCIdentity.
+func (mr *MockOIDCRepositoryMockRecorder) GetOIDCIdentity(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOIDCIdentity", reflect.TypeOf((*MockOIDCRepository)(nil).GetOIDCIdentity), ctx, params)
+}
+
+// LinkOIDCIdentity mocks base method.
+func (m *MockOIDCRepository) LinkOIDCIdentity(ctx context.Context, params repo.LinkOIDCIdentityParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinkOIDCIdentity", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LinkOIDCIdentity indicates an expected call of LinkOIDCIdentity.
+func (mr *MockOIDCRepositoryMockRecorder) LinkOIDCIdentity(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkOIDCIdentity", reflect.TypeOf((*MockOIDCRepository)(nil).LinkOIDCIdentity), ctx, params)
+}
+
+// UseOIDCLoginState mocks base method.
+func (m *MockOIDCRepository) UseOIDCLoginState(ctx context.Context, stateHash string) (models.OIDCLoginState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseOIDCLoginState", ctx, stateHash)
+	ret0, _ := ret[0].(models.OIDCLoginState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseOIDCLoginState indicates an expected call of UseOIDCLoginState.
+func (mr *MockOIDCRepositoryMockRecorder) UseOIDCLoginState(ctx, stateHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseOIDCLoginState", reflect.TypeOf((*MockOIDCRepository)(nil).UseOIDCLoginState), ctx, stateHash)
+}
+
+// MockFeatureFlagRepository is a mock of FeatureFlagRepository interface.
+type MockFeatureFlagRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeatureFlagRepositoryMockRecorder
+}
+
+// MockFeatureFlagRepositoryMockRecorder is the mock recorder for MockFeatureFlagRepository.
+type MockFeatureFlagRepositoryMockRecorder struct {
+	mock *MockFeatureFlagRepository
+}
+
+// NewMockFeatureFlagRepository creates a new mock instance.
+func NewMockFeatureFlagRepository(ctrl *gomock.Controller) *MockFeatureFlagRepository {
+	mock := &MockFeatureFlagRepository{ctrl: ctrl}
+	mock.recorder = &MockFeatureFlagRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeatureFlagRepository) EXPECT() *MockFeatureFlagRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteFeatureFlag mocks base method.
+func (m *MockFeatureFlagRepository) DeleteFeatureFlag(ctx context.Context, name string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFeatureFlag", ctx, name)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteFeatureFlag indicates an expected call of DeleteFeatureFlag.
+func (mr *MockFeatureFlagRepositoryMockRecorder) DeleteFeatureFlag(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFeatureFlag", reflect.TypeOf((*MockFeatureFlagRepository)(nil).DeleteFeatureFlag), ctx, name)
+}
+
+// GetFeatureFlags mocks base method.
+func (m *MockFeatureFlagRepository) GetFeatureFlags(ctx context.Context) ([]models.FeatureFlag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeatureFlags", ctx)
+	ret0, _ := ret[0].([]models.FeatureFlag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeatureFlags indicates an expected call of GetFeatureFlags.
+func (mr *MockFeatureFlagRepositoryMockRecorder) GetFeatureFlags(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeatureFlags", reflect.TypeOf((*MockFeatureFlagRepository)(nil).GetFeatureFlags), ctx)
+}
+
+// SaveFeatureFlag mocks base method.
+func (m *MockFeatureFlagRepository) SaveFeatureFlag(ctx context.Context, params repo.SaveFeatureFlagParams) (models.FeatureFlag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveFeatureFlag", ctx, params)
+	ret0, _ := ret[0].(models.FeatureFlag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveFeatureFlag indicates an expected call of SaveFeatureFlag.
+func (mr *MockFeatureFlagRepositoryMockRecorder) SaveFeatureFlag(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveFeatureFlag", reflect.TypeOf((*MockFeatureFlagRepository)(nil).SaveFeatureFlag), ctx, params)
+}
+
+// MockOperatorRepository is a mock of OperatorRepository interface.
+type MockOperatorRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOperatorRepositoryMockRecorder
+}
+
+// MockOperatorRepositoryMockRecorder is the mock recorder for MockOperatorRepository.
+type MockOperatorRepositoryMockRecorder struct {
+	mock *MockOperatorRepository
+}
+
+// NewMockOperatorRepository creates a new mock instance.
+func NewMockOperatorRepository(ctrl *gomock.Controller) *MockOperatorRepository {
+	mock := &MockOperatorRepository{ctrl: ctrl}
+	mock.recorder = &MockOperatorRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOperatorRepository) EXPECT() *MockOperatorRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AdjustBalance mocks base method.
+func (m *MockOperatorRepository) AdjustBalance(ctx context.Context, tx *sqlx.Tx, params repo.AdjustBalanceParams) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustBalance", ctx, tx, params)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdjustBalance indicates an expected call of AdjustBalance.
+func (mr *MockOperatorRepositoryMockRecorder) AdjustBalance(ctx, tx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustBalance", reflect.TypeOf((*MockOperatorRepository)(nil).AdjustBalance), ctx, tx, params)
+}
+
+// BeginTx mocks base method.
+func (m *MockOperatorRepository) BeginTx(ctx context.Context) (*sqlx.Tx, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginTx", ctx)
+	ret0, _ := ret[0].(*sqlx.Tx)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginTx indicates an expected call of BeginTx.
+func (mr *MockOperatorRepositoryMockRecorder) BeginTx(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginTx", reflect.TypeOf((*MockOperatorRepository)(nil).BeginTx), ctx)
+}
+
+// BuyItem mocks base method.
+func (m *MockOperatorRepository) BuyItem(ctx context.Context, tx *sqlx.Tx, params repo.BuyItemParams) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BuyItem", ctx, tx, params)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BuyItem indicates an expected call of BuyItem.
+func (mr *MockOperatorRepositoryMockRecorder) BuyItem(ctx, tx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BuyItem", reflect.TypeOf((*MockOperatorRepository)(nil).BuyItem), ctx, tx, params)
+}
+
+// ClaimNotificationDeliveries mocks base method.
+func (m *MockOperatorRepository) ClaimNotificationDeliveries(ctx context.Context, params repo.ClaimNotificationDeliveriesParams) ([]models.NotificationDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimNotificationDeliveries", ctx, params)
+	ret0, _ := ret[0].([]models.NotificationDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimNotificationDeliveries indicates an expected call of ClaimNotificationDeliveries.
+func (mr *MockOperatorRepositoryMockRecorder) ClaimNotificationDeliveries(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimNotificationDeliveries", reflect.TypeOf((*MockOperatorRepository)(nil).ClaimNotificationDeliveries), ctx, params)
+}
+
+// CommitTx mocks base method.
+func (m *MockOperatorRepository) CommitTx(tx *sqlx.Tx) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitTx", tx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CommitTx indicates an expected call of CommitTx.
+func (mr *MockOperatorRepositoryMockRecorder) CommitTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitTx", reflect.TypeOf((*MockOperatorRepository)(nil).CommitTx), tx)
+}
+
+// CompleteNotificationDelivery mocks base method.
+func (m *MockOperatorRepository) CompleteNotificationDelivery(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteNotificationDelivery", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteNotificationDelivery indicates an expected call of CompleteNotificationDelivery.
+func (mr *MockOperatorRepositoryMockRecorder) CompleteNotificationDelivery(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteNotificationDelivery", reflect.TypeOf((*MockOperatorRepository)(nil).CompleteNotificationDelivery), ctx, id)
+}
+
+// CountUnreadNotifications mocks base method.
+func (m *MockOperatorRepository) CountUnreadNotifications(ctx context.Context, username string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnreadNotifications", ctx, username)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnreadNotifications indicates an expected call of CountUnreadNotifications.
+func (mr *MockOperatorRepositoryMockRecorder) CountUnreadNotifications(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnreadNotifications", reflect.TypeOf((*MockOperatorRepository)(nil).CountUnreadNotifications), ctx, username)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockOperatorRepository) CreateAPIKey(ctx context.Context, params repo.CreateAPIKeyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockOperatorRepositoryMockRecorder) CreateAPIKey(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockOperatorRepository)(nil).CreateAPIKey), ctx, params)
+}
+
+// CreateAuthChallenge mocks base method.
+func (m *MockOperatorRepository) CreateAuthChallenge(ctx context.Context, params repo.CreateAuthChallengeParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuthChallenge", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAuthChallenge indicates an expected call of CreateAuthChallenge.
+func (mr *MockOperatorRepositoryMockRecorder) CreateAuthChallenge(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuthChallenge", reflect.TypeOf((*MockOperatorRepository)(nil).CreateAuthChallenge), ctx, params)
+}
+
+// CreateNotification mocks base method.
+func (m *MockOperatorRepository) CreateNotification(ctx context.Context, tx *sqlx.Tx, params repo.CreateNotificationParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNotification", ctx, tx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateNotification indicates an expected call of CreateNotification.
+func (mr *MockOperatorRepositoryMockRecorder) CreateNotification(ctx, tx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNotification", reflect.TypeOf((*MockOperatorRepository)(nil).CreateNotification), ctx, tx, params)
+}
+
+// CreateOIDCLoginState mocks base method.
+func (m *MockOperatorRepository) CreateOIDCLoginState(ctx context.Context, params repo.CreateOIDCLoginStateParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOIDCLoginState", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOIDCLoginState indicates an expected call of CreateOIDCLoginState.
+func (mr *MockOperatorRepositoryMockRecorder) CreateOIDCLoginState(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOIDCLoginState", reflect.TypeOf((*MockOperatorRepository)(nil).CreateOIDCLoginState), ctx, params)
+}
+
+// CreatePasswordReset mocks base method.
+func (m *MockOperatorRepository) CreatePasswordReset(ctx context.Context, params repo.CreatePasswordResetParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePasswordReset", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePasswordReset indicates an expected call of CreatePasswordReset.
+func (mr *MockOperatorRepositoryMockRecorder) CreatePasswordReset(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasswordReset", reflect.TypeOf((*MockOperatorRepository)(nil).CreatePasswordReset), ctx, params)
+}
+
+// CreateServiceAccount mocks base method.
+func (m *MockOperatorRepository) CreateServiceAccount(ctx context.Context, params repo.CreateServiceAccountParams) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateServiceAccount", ctx, params)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateServiceAccount indicates an expected call of CreateServiceAccount.
+func (mr *MockOperatorRepositoryMockRecorder) CreateServiceAccount(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateServiceAccount", reflect.TypeOf((*MockOperatorRepository)(nil).CreateServiceAccount), ctx, params)
+}
+
+// CreateUser mocks base method.
+func (m *MockOperatorRepository) CreateUser(ctx context.Context, params repo.CreateUserParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockOperatorRepositoryMockRecorder) CreateUser(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockOperatorRepository)(nil).CreateUser), ctx, params)
+}
+
+// DecreaseBalance mocks base method.
+func (m *MockOperatorRepository) DecreaseBalance(ctx context.Context, tx *sqlx.Tx, params repo.ChangeBalanceParams) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecreaseBalance", ctx, tx, params)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DecreaseBalance indicates an expected call of DecreaseBalance.
+func (mr *MockOperatorRepositoryMockRecorder) DecreaseBalance(ctx, tx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecreaseBalance", reflect.TypeOf((*MockOperatorRepository)(nil).DecreaseBalance), ctx, tx, params)
+}
+
+// DeleteAuthChallenge mocks base method.
+func (m *MockOperatorRepository) DeleteAuthChallenge(ctx context.Context, tokenHash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAuthChallenge", ctx, tokenHash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAuthChallenge indicates an expected call of DeleteAuthChallenge.
+func (mr *MockOperatorRepositoryMockRecorder) DeleteAuthChallenge(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAuthChallenge", reflect.TypeOf((*MockOperatorRepository)(nil).DeleteAuthChallenge), ctx, tokenHash)
+}
+
+// DeleteFeatureFlag mocks base method.
+func (m *MockOperatorRepository) DeleteFeatureFlag(ctx context.Context, name string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFeatureFlag", ctx, name)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteFeatureFlag indicates an expected call of DeleteFeatureFlag.
+func (mr *MockOperatorRepositoryMockRecorder) DeleteFeatureFlag(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFeatureFlag", reflect.TypeOf((*MockOperatorRepository)(nil).DeleteFeatureFlag), ctx, name)
+}
+
+// DeleteItem mocks base method.
+func (m *MockOperatorRepository) DeleteItem(ctx context.Context, name string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteItem", ctx, name)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteItem indicates an expected call of DeleteItem.
+func (mr *MockOperatorRepositoryMockRecorder) DeleteItem(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteItem", reflect.TypeOf((*MockOperatorRepository)(nil).DeleteItem), ctx, name)
+}
+
+// DisableTOTP mocks base method.
+func (m *MockOperatorRepository) DisableTOTP(ctx context.Context, username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTP", ctx, username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTP indicates an expected call of DisableTOTP.
+func (mr *MockOperatorRepositoryMockRecorder) DisableTOTP(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTP", reflect.TypeOf((*MockOperatorRepository)(nil).DisableTOTP), ctx, username)
+}
+
+// EnableTOTP mocks base method.
+func (m *MockOperatorRepository) EnableTOTP(ctx context.Context, params repo.EnableTOTPParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTOTP", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableTOTP indicates an expected call of EnableTOTP.
+func (mr *MockOperatorRepositoryMockRecorder) EnableTOTP(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTOTP", reflect.TypeOf((*MockOperatorRepository)(nil).EnableTOTP), ctx, params)
+}
+
+// FailNotificationDelivery mocks base method.
+func (m *MockOperatorRepository) FailNotificationDelivery(ctx context.Context, params repo.FailNotificationDeliveryParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailNotificationDelivery", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailNotificationDelivery indicates an expected call of FailNotificationDelivery.
+func (mr *MockOperatorRepositoryMockRecorder) FailNotificationDelivery(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailNotificationDelivery", reflect.TypeOf((*MockOperatorRepository)(nil).FailNotificationDelivery), ctx, params)
+}
+
+// GetAPIKey mocks base method.
+func (m *MockOperatorRepository) GetAPIKey(ctx context.Context, id string) (models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKey", ctx, id)
+	ret0, _ := ret[0].(models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKey indicates an expected call of GetAPIKey.
+func (mr *MockOperatorRepositoryMockRecorder) GetAPIKey(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKey", reflect.TypeOf((*MockOperatorRepository)(nil).GetAPIKey), ctx, id)
+}
+
+// GetAuthChallenge mocks base method.
+func (m *MockOperatorRepository) GetAuthChallenge(ctx context.Context, tokenHash string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuthChallenge", ctx, tokenHash)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuthChallenge indicates an expected call of GetAuthChallenge.
+func (mr *MockOperatorRepositoryMockRecorder) GetAuthChallenge(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthChallenge", reflect.TypeOf((*MockOperatorRepository)(nil).GetAuthChallenge), ctx, tokenHash)
+}
+
+// GetBalance mocks base method.
+func (m *MockOperatorRepository) GetBalance(ctx context.Context, params repo.GetBalanceParams) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalance", ctx, params)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalance indicates an expected call of GetBalance.
+func (mr *MockOperatorRepositoryMockRecorder) GetBalance(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockOperatorRepository)(nil).GetBalance), ctx, params)
+}
+
+// GetBalanceAdjustments mocks base method.
+func (m *MockOperatorRepository) GetBalanceAdjustments(ctx context.Context, username string) ([]models.BalanceAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceAdjustments", ctx, username)
+	ret0, _ := ret[0].([]models.BalanceAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceAdjustments indicates an expected call of GetBalanceAdjustments.
+func (mr *MockOperatorRepositoryMockRecorder) GetBalanceAdjustments(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceAdjustments", reflect.TypeOf((*MockOperatorRepository)(nil).GetBalanceAdjustments), ctx, username)
+}
+
+// GetEvents mocks base method.
+func (m *MockOperatorRepository) GetEvents(ctx context.Context, params repo.GetEventsParams) ([]models.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEvents", ctx, params)
+	ret0, _ := ret[0].([]models.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEvents indicates an expected call of GetEvents.
+func (mr *MockOperatorRepositoryMockRecorder) GetEvents(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEvents", reflect.TypeOf((*MockOperatorRepository)(nil).GetEvents), ctx, params)
+}
+
+// GetFeatureFlags mocks base method.
+func (m *MockOperatorRepository) GetFeatureFlags(ctx context.Context) ([]models.FeatureFlag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeatureFlags", ctx)
+	ret0, _ := ret[0].([]models.FeatureFlag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeatureFlags indicates an expected call of GetFeatureFlags.
+func (mr *MockOperatorRepositoryMockRecorder) GetFeatureFlags(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeatureFlags", reflect.TypeOf((*MockOperatorRepository)(nil).GetFeatureFlags), ctx)
+}
+
+// GetItem mocks base method.
+func (m *MockOperatorRepository) GetItem(ctx context.Context, itemName string) (models.Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItem", ctx, itemName)
+	ret0, _ := ret[0].(models.Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetItem indicates an expected call of GetItem.
+func (mr *MockOperatorRepositoryMockRecorder) GetItem(ctx, itemName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItem", reflect.TypeOf((*MockOperatorRepository)(nil).GetItem), ctx, itemName)
+}
+
+// GetItems mocks base method.
+func (m *MockOperatorRepository) GetItems(ctx context.Context, params repo.GetItemsParams) ([]models.Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItems", ctx, params)
+	ret0, _ := ret[0].([]models.Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetItems indicates an expected call of GetItems.
+func (mr *MockOperatorRepositoryMockRecorder) GetItems(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItems", reflect.TypeOf((*MockOperatorRepository)(nil).GetItems), ctx, params)
+}
+
+// GetLoginAttempts mocks base method.
+func (m *MockOperatorRepository) GetLoginAttempts(ctx context.Context, keys []string) ([]models.LoginAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginAttempts", ctx, keys)
+	ret0, _ := ret[0].([]models.LoginAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginAttempts indicates an expected call of GetLoginAttempts.
+func (mr *MockOperatorRepositoryMockRecorder) GetLoginAttempts(ctx, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginAttempts", reflect.TypeOf((*MockOperatorRepository)(nil).GetLoginAttempts), ctx, keys)
+}
+
+// GetNotificationPreferences mocks base method.
+func (m *MockOperatorRepository) GetNotificationPreferences(ctx context.Context, username string) ([]models.NotificationPreference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotificationPreferences", ctx, username)
+	ret0, _ := ret[0].([]models.NotificationPreference)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotificationPreferences indicates an expected call of GetNotificationPreferences.
+func (mr *MockOperatorRepositoryMockRecorder) GetNotificationPreferences(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationPreferences", reflect.TypeOf((*MockOperatorRepository)(nil).GetNotificationPreferences), ctx, username)
+}
+
+// GetNotifications mocks base method.
+func (m *MockOperatorRepository) GetNotifications(ctx context.Context, params repo.GetNotificationsParams) ([]models.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotifications", ctx, params)
+	ret0, _ := ret[0].([]models.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotifications indicates an expected call of GetNotifications.
+func (mr *MockOperatorRepositoryMockRecorder) GetNotifications(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotifications", reflect.TypeOf((*MockOperatorRepository)(nil).GetNotifications), ctx, params)
+}
+
+// GetOIDCIdentity mocks base method.
+func (m *MockOperatorRepository) GetOIDCIdentity(ctx context.Context, params repo.GetOIDCIdentityParams) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOIDCIdentity", ctx, params)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOIDCIdentity indicates an expected call of GetOIDCIdentity.
+func (mr *MockOperatorRepositoryMockRecorder) GetOIDCIdentity(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOIDCIdentity", reflect.TypeOf((*MockOperatorRepository)(nil).GetOIDCIdentity), ctx, params)
+}
+
+// GetPurchases mocks base method.
+func (m *MockOperatorRepository) GetPurchases(ctx context.Context, username string) ([]models.PurchaseItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPurchases", ctx, username)
+	ret0, _ := ret[0].([]models.PurchaseItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPurchases indicates an expected call of GetPurchases.
+func (mr *MockOperatorRepositoryMockRecorder) GetPurchases(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPurchases", reflect.TypeOf((*MockOperatorRepository)(nil).GetPurchases), ctx, username)
+}
+
+// GetTransaction mocks base method.
+func (m *MockOperatorRepository) GetTransaction(ctx context.Context, id int64) (models.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransaction", ctx, id)
+	ret0, _ := ret[0].(models.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransaction indicates an expected call of GetTransaction.
+func (mr *MockOperatorRepositoryMockRecorder) GetTransaction(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransaction", reflect.TypeOf((*MockOperatorRepository)(nil).GetTransaction), ctx, id)
+}
+
+// GetTransactions mocks base method.
+func (m *MockOperatorRepository) GetTransactions(ctx context.Context, username string) ([]models.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransactions", ctx, username)
+	ret0, _ := ret[0].([]models.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransactions indicates an expected call of GetTransactions.
+func (mr *MockOperatorRepositoryMockRecorder) GetTransactions(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactions", reflect.TypeOf((*MockOperatorRepository)(nil).GetTransactions), ctx, username)
+}
+
+// GetTwoFactorPolicy mocks base method.
+func (m *MockOperatorRepository) GetTwoFactorPolicy(ctx context.Context) (models.TwoFactorPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTwoFactorPolicy", ctx)
+	ret0, _ := ret[0].(models.TwoFactorPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTwoFactorPolicy indicates an expected call of GetTwoFactorPolicy.
+func (mr *MockOperatorRepositoryMockRecorder) GetTwoFactorPolicy(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTwoFactorPolicy", reflect.TypeOf((*MockOperatorRepository)(nil).GetTwoFactorPolicy), ctx)
+}
+
+// GetUserByUsername mocks base method.
+func (m *MockOperatorRepository) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByUsername", ctx, username)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByUsername indicates an expected call of GetUserByUsername.
+func (mr *MockOperatorRepositoryMockRecorder) GetUserByUsername(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockOperatorRepository)(nil).GetUserByUsername), ctx, username)
+}
+
+// GrantDelegation mocks base method.
+func (m *MockOperatorRepository) GrantDelegation(ctx context.Context, params repo.GrantDelegationParams) (models.Delegation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantDelegation", ctx, params)
+	ret0, _ := ret[0].(models.Delegation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GrantDelegation indicates an expected call of GrantDelegation.
+func (mr *MockOperatorRepositoryMockRecorder) GrantDelegation(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantDelegation", reflect.TypeOf((*MockOperatorRepository)(nil).GrantDelegation), ctx, params)
+}
+
+// IncreaseBalance mocks base method.
+func (m *MockOperatorRepository) IncreaseBalance(ctx context.Context, tx *sqlx.Tx, params repo.ChangeBalanceParams) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncreaseBalance", ctx, tx, params)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncreaseBalance indicates an expected call of IncreaseBalance.
+func (mr *MockOperatorRepositoryMockRecorder) IncreaseBalance(ctx, tx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncreaseBalance", reflect.TypeOf((*MockOperatorRepository)(nil).IncreaseBalance), ctx, tx, params)
+}
+
+// LinkOIDCIdentity mocks base method.
+func (m *MockOperatorRepository) LinkOIDCIdentity(ctx context.Context, params repo.LinkOIDCIdentityParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinkOIDCIdentity", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LinkOIDCIdentity indicates an expected call of LinkOIDCIdentity.
+func (mr *MockOperatorRepositoryMockRecorder) LinkOIDCIdentity(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkOIDCIdentity", reflect.TypeOf((*MockOperatorRepository)(nil).LinkOIDCIdentity), ctx, params)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockOperatorRepository) ListAPIKeys(ctx context.Context, username string) ([]models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", ctx, username)
+	ret0, _ := ret[0].([]models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockOperatorRepositoryMockRecorder) ListAPIKeys(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockOperatorRepository)(nil).ListAPIKeys), ctx, username)
+}
+
+// ListDelegations mocks base method.
+func (m *MockOperatorRepository) ListDelegations(ctx context.Context, ownerUsername string) ([]models.Delegation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDelegations", ctx, ownerUsername)
+	ret0, _ := ret[0].([]models.Delegation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDelegations indicates an expected call of ListDelegations.
+func (mr *MockOperatorRepositoryMockRecorder) ListDelegations(ctx, ownerUsername interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDelegations", reflect.TypeOf((*MockOperatorRepository)(nil).ListDelegations), ctx, ownerUsername)
+}
+
+// ListTransactions mocks base method.
+func (m *MockOperatorRepository) ListTransactions(ctx context.Context, params repo.ListTransactionsParams) ([]models.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransactions", ctx, params)
+	ret0, _ := ret[0].([]models.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTransactions indicates an expected call of ListTransactions.
+func (mr *MockOperatorRepositoryMockRecorder) ListTransactions(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransactions", reflect.TypeOf((*MockOperatorRepository)(nil).ListTransactions), ctx, params)
+}
+
+// LockDelegation mocks base method.
+func (m *MockOperatorRepository) LockDelegation(ctx context.Context, tx *sqlx.Tx, params repo.LockDelegationParams) (models.Delegation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockDelegation", ctx, tx, params)
+	ret0, _ := ret[0].(models.Delegation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockDelegation indicates an expected call of LockDelegation.
+func (mr *MockOperatorRepositoryMockRecorder) LockDelegation(ctx, tx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockDelegation", reflect.TypeOf((*MockOperatorRepository)(nil).LockDelegation), ctx, tx, params)
+}
+
+// MarkAllNotificationsRead mocks base method.
+func (m *MockOperatorRepository) MarkAllNotificationsRead(ctx context.Context, username string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAllNotificationsRead", ctx, username)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkAllNotificationsRead indicates an expected call of MarkAllNotificationsRead.
+func (mr *MockOperatorRepositoryMockRecorder) MarkAllNotificationsRead(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAllNotificationsRead", reflect.TypeOf((*MockOperatorRepository)(nil).MarkAllNotificationsRead), ctx, username)
+}
+
+// MarkNotificationRead mocks base method.
+func (m *MockOperatorRepository) MarkNotificationRead(ctx context.Context, params repo.MarkNotificationReadParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkNotificationRead", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkNotificationRead indicates an expected call of MarkNotificationRead.
+func (mr *MockOperatorRepositoryMockRecorder) MarkNotificationRead(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkNotificationRead", reflect.TypeOf((*MockOperatorRepository)(nil).MarkNotificationRead), ctx, params)
+}
+
+// ReceivedCoinsInfo mocks base method.
+func (m *MockOperatorRepository) ReceivedCoinsInfo(ctx context.Context, username string) ([]models.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceivedCoinsInfo", ctx, username)
+	ret0, _ := ret[0].([]models.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReceivedCoinsInfo indicates an expected call of ReceivedCoinsInfo.
+func (mr *MockOperatorRepositoryMockRecorder) ReceivedCoinsInfo(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceivedCoinsInfo", reflect.TypeOf((*MockOperatorRepository)(nil).ReceivedCoinsInfo), ctx, username)
+}
+
+// RecordLoginFailure mocks base method.
+func (m *MockOperatorRepository) RecordLoginFailure(ctx context.Context, params repo.RecordLoginFailureParams) (models.LoginAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordLoginFailure", ctx, params)
+	ret0, _ := ret[0].(models.LoginAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordLoginFailure indicates an expected call of RecordLoginFailure.
+func (mr *MockOperatorRepositoryMockRecorder) RecordLoginFailure(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordLoginFailure", reflect.TypeOf((*MockOperatorRepository)(nil).RecordLoginFailure), ctx, params)
+}
+
+// ResetLoginAttempts mocks base method.
+func (m *MockOperatorRepository) ResetLoginAttempts(ctx context.Context, keys []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetLoginAttempts", ctx, keys)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetLoginAttempts indicates an expected call of ResetLoginAttempts.
+func (mr *MockOperatorRepositoryMockRecorder) ResetLoginAttempts(ctx, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetLoginAttempts", reflect.TypeOf((*MockOperatorRepository)(nil).ResetLoginAttempts), ctx, keys)
+}
+
+// ResetPassword mocks base method.
+func (m *MockOperatorRepository) ResetPassword(ctx context.Context, params repo.ResetPasswordParams) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, params)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockOperatorRepositoryMockRecorder) ResetPassword(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockOperatorRepository)(nil).ResetPassword), ctx, params)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockOperatorRepository) RevokeAPIKey(ctx context.Context, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockOperatorRepositoryMockRecorder) RevokeAPIKey(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockOperatorRepository)(nil).RevokeAPIKey), ctx, id)
+}
+
+// RevokeDelegation mocks base method.
+func (m *MockOperatorRepository) RevokeDelegation(ctx context.Context, params repo.RevokeDelegationParams) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeDelegation", ctx, params)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeDelegation indicates an expected call of RevokeDelegation.
+func (mr *MockOperatorRepositoryMockRecorder) RevokeDelegation(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeDelegation", reflect.TypeOf((*MockOperatorRepository)(nil).RevokeDelegation), ctx, params)
+}
+
+// RollbackTx mocks base method.
+func (m *MockOperatorRepository) RollbackTx(tx *sqlx.Tx) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RollbackTx", tx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RollbackTx indicates an expected call of RollbackTx.
+func (mr *MockOperatorRepositoryMockRecorder) RollbackTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RollbackTx", reflect.TypeOf((*MockOperatorRepository)(nil).RollbackTx), tx)
+}
+
+// SaveEvent mocks base method.
+func (m *MockOperatorRepository) SaveEvent(ctx context.Context, tx *sqlx.Tx, params repo.SaveEventParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveEvent", ctx, tx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveEvent indicates an expected call of SaveEvent.
+func (mr *MockOperatorRepositoryMockRecorder) SaveEvent(ctx, tx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveEvent", reflect.TypeOf((*MockOperatorRepository)(nil).SaveEvent), ctx, tx, params)
+}
+
+// SaveFeatureFlag mocks base method.
+func (m *MockOperatorRepository) SaveFeatureFlag(ctx context.Context, params repo.SaveFeatureFlagParams) (models.FeatureFlag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveFeatureFlag", ctx, params)
+	ret0, _ := ret[0].(models.FeatureFlag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveFeatureFlag indicates an expected call of SaveFeatureFlag.
+func (mr *MockOperatorRepositoryMockRecorder) SaveFeatureFlag(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveFeatureFlag", reflect.TypeOf((*MockOperatorRepository)(nil).SaveFeatureFlag), ctx, params)
+}
+
+// SaveItem mocks base method.
+func (m *MockOperatorRepository) SaveItem(ctx context.Context, item models.Item) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveItem", ctx, item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveItem indicates an expected call of SaveItem.
+func (mr *MockOperatorRepositoryMockRecorder) SaveItem(ctx, item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveItem", reflect.TypeOf((*MockOperatorRepository)(nil).SaveItem), ctx, item)
+}
+
+// SaveTransaction mocks base method.
+func (m *MockOperatorRepository) SaveTransaction(ctx context.Context, tx *sqlx.Tx, params repo.SaveTransactionParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTransaction", ctx, tx, params)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveTransaction indicates an expected call of SaveTransaction.
+func (mr *MockOperatorRepositoryMockRecorder) SaveTransaction(ctx, tx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTransaction", reflect.TypeOf((*MockOperatorRepository)(nil).SaveTransaction), ctx, tx, params)
+}
+
+// SetNotificationContact mocks base method.
+func (m *MockOperatorRepository) SetNotificationContact(ctx context.Context, params repo.SetNotificationContactParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetNotificationContact", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetNotificationContact indicates an expected call of SetNotificationContact.
+func (mr *MockOperatorRepositoryMockRecorder) SetNotificationContact(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNotificationContact", reflect.TypeOf((*MockOperatorRepository)(nil).SetNotificationContact), ctx, params)
+}
+
+// SetNotificationPreference mocks base method.
+func (m *MockOperatorRepository) SetNotificationPreference(ctx context.Context, tx *sqlx.Tx, params repo.SetNotificationPreferenceParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetNotificationPreference", ctx, tx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetNotificationPreference indicates an expected call of SetNotificationPreference.
+func (mr *MockOperatorRepositoryMockRecorder) SetNotificationPreference(ctx, tx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNotificationPreference", reflect.TypeOf((*MockOperatorRepository)(nil).SetNotificationPreference), ctx, tx, params)
+}
+
+// SetTOTPSecret mocks base method.
+func (m *MockOperatorRepository) SetTOTPSecret(ctx context.Context, params repo.SetTOTPSecretParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTOTPSecret", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTOTPSecret indicates an expected call of SetTOTPSecret.
+func (mr *MockOperatorRepositoryMockRecorder) SetTOTPSecret(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTOTPSecret", reflect.TypeOf((*MockOperatorRepository)(nil).SetTOTPSecret), ctx, params)
+}
+
+// SetTwoFactorPolicy mocks base method.
+func (m *MockOperatorRepository) SetTwoFactorPolicy(ctx context.Context, policy models.TwoFactorPolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTwoFactorPolicy", ctx, policy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTwoFactorPolicy indicates an expected call of SetTwoFactorPolicy.
+func (mr *MockOperatorRepositoryMockRecorder) SetTwoFactorPolicy(ctx, policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTwoFactorPolicy", reflect.TypeOf((*MockOperatorRepository)(nil).SetTwoFactorPolicy), ctx, policy)
+}
+
+// SetUserFrozen mocks base method.
+func (m *MockOperatorRepository) SetUserFrozen(ctx context.Context, params repo.SetUserFrozenParams) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserFrozen", ctx, params)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetUserFrozen indicates an expected call of SetUserFrozen.
+func (mr *MockOperatorRepositoryMockRecorder) SetUserFrozen(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserFrozen", reflect.TypeOf((*MockOperatorRepository)(nil).SetUserFrozen), ctx, params)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockOperatorRepository) UpdatePassword(ctx context.Context, params repo.UpdatePasswordParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockOperatorRepositoryMockRecorder) UpdatePassword(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockOperatorRepository)(nil).UpdatePassword), ctx, params)
+}
+
+// UseOIDCLoginState mocks base method.
+func (m *MockOperatorRepository) UseOIDCLoginState(ctx context.Context, stateHash string) (models.OIDCLoginState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseOIDCLoginState", ctx, stateHash)
+	ret0, _ := ret[0].(models.OIDCLoginState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseOIDCLoginState indicates an expected call of UseOIDCLoginState.
+func (mr *MockOperatorRepositoryMockRecorder) UseOIDCLoginState(ctx, stateHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseOIDCLoginState", reflect.TypeOf((*MockOperatorRepository)(nil).UseOIDCLoginState), ctx, stateHash)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockOperatorRepository) UseRecoveryCode(ctx context.Context, params repo.UseRecoveryCodeParams) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, params)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockOperatorRepositoryMockRecorder) UseRecoveryCode(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockOperatorRepository)(nil).UseRecoveryCode), ctx, params)
+}
+
+// UseTOTPCounter mocks base method.
+func (m *MockOperatorRepository) UseTOTPCounter(ctx context.Context, params repo.UseTOTPCounterParams) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPCounter", ctx, params)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTOTPCounter indicates an expected call of UseTOTPCounter.
+func (mr *MockOperatorRepositoryMockRecorder) UseTOTPCounter(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPCounter", reflect.TypeOf((*MockOperatorRepository)(nil).UseTOTPCounter), ctx, params)
+}
+
+// VerifyLedger mocks base method.
+func (m *MockOperatorRepository) VerifyLedger(ctx context.Context) ([]models.LedgerMismatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyLedger", ctx)
+	ret0, _ := ret[0].([]models.LedgerMismatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyLedger indicates an expected call of VerifyLedger.
+func (mr *MockOperatorRepositoryMockRecorder) VerifyLedger(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTransaction", reflect.TypeOf((*MockCoinRepository)(nil).SaveTransaction), ctx, params)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyLedger", reflect.TypeOf((*MockOperatorRepository)(nil).VerifyLedger), ctx)
 }
@@ -0,0 +1,132 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/repo"
+	"strings"
+	"time"
+)
+
+type APIKey struct {
+	ID         string     `db:"id"`
+	Username   string     `db:"username"`
+	SecretHash string     `db:"secret_hash"`
+	Scopes     string     `db:"scopes"`
+	CreatedBy  string     `db:"created_by"`
+	CreatedAt  time.Time  `db:"created_at"`
+	ExpiresAt  time.Time  `db:"expires_at"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+}
+
+func (k APIKey) toModel() models.APIKey {
+	return models.APIKey{
+		ID:         k.ID,
+		Username:   k.Username,
+		SecretHash: k.SecretHash,
+		Scopes:     strings.Fields(k.Scopes),
+		CreatedBy:  k.CreatedBy,
+		CreatedAt:  k.CreatedAt,
+		ExpiresAt:  k.ExpiresAt,
+		RevokedAt:  k.RevokedAt,
+	}
+}
+
+// Service accounts have no password, so they can't log in through Auth.
+const repoStmtCreateServiceAccount = `
+insert into users (username, password_hash, balance, opening_balance, is_service_account)
+values ($1, '', $2, $2, true)
+on conflict (username) do nothing
+`
+
+const repoStmtCreateAPIKey = `
+insert into api_keys (id, username, secret_hash, scopes, created_by, expires_at)
+values ($1, $2, $3, $4, $5, $6)
+`
+
+const repoStmtGetAPIKey = `
+select *
+from api_keys
+where id = $1
+`
+
+const repoStmtListAPIKeys = `
+select *
+from api_keys
+where username = $1
+order by created_at
+`
+
+const repoStmtRevokeAPIKey = `
+update api_keys
+set revoked_at = now()
+where id = $1 and revoked_at is null
+`
+
+// CreateServiceAccount reports false if the username is already taken.
+func (r *CoinRepo) CreateServiceAccount(ctx context.Context, params repo.CreateServiceAccountParams) (bool, error) {
+	res, err := r.db.ExecContext(ctx, repoStmtCreateServiceAccount, params.Username, params.Balance)
+	if err != nil {
+		return false, fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	return affected > 0, nil
+}
+
+func (r *CoinRepo) CreateAPIKey(ctx context.Context, params repo.CreateAPIKeyParams) error {
+	if _, err := r.db.ExecContext(
+		ctx,
+		repoStmtCreateAPIKey,
+		params.ID,
+		params.Username,
+		params.SecretHash,
+		strings.Join(params.Scopes, " "),
+		params.CreatedBy,
+		params.ExpiresAt,
+	); err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	return nil
+}
+
+// GetAPIKey returns sql.ErrNoRows if the key is unknown. Revoked and expired
+// keys are returned as well; callers check them.
+func (r *CoinRepo) GetAPIKey(ctx context.Context, id string) (models.APIKey, error) {
+	var key APIKey
+	if err := r.db.GetContext(ctx, &key, repoStmtGetAPIKey, id); err != nil {
+		return models.APIKey{}, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return key.toModel(), nil
+}
+
+func (r *CoinRepo) ListAPIKeys(ctx context.Context, username string) ([]models.APIKey, error) {
+	var rows []APIKey
+	if err := r.db.SelectContext(ctx, &rows, repoStmtListAPIKeys, username); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+
+	keys := make([]models.APIKey, len(rows))
+	for i, row := range rows {
+		keys[i] = row.toModel()
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey reports false if the key is unknown or already revoked.
+func (r *CoinRepo) RevokeAPIKey(ctx context.Context, id string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, repoStmtRevokeAPIKey, id)
+	if err != nil {
+		return false, fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	return affected > 0, nil
+}
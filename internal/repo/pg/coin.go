@@ -6,6 +6,7 @@ import (
 	"github.com/Blxssy/AvitoTest/internal/models"
 	"github.com/Blxssy/AvitoTest/internal/repo"
 	"github.com/jmoiron/sqlx"
+	"time"
 )
 
 type CoinRepo struct {
@@ -17,9 +18,20 @@ func NewCoinRepo(db *sqlx.DB) *CoinRepo {
 }
 
 type User struct {
-	Username     string `db:"username"`
-	PasswordHash string `db:"password_hash"`
-	Balance      int    `db:"balance"`
+	Username          string `db:"username"`
+	PasswordHash      string `db:"password_hash"`
+	Balance           int    `db:"balance"`
+	IsAdmin           bool   `db:"is_admin"`
+	Email             string `db:"email"`
+	Locale            string `db:"locale"`
+	PasswordAlgorithm string `db:"password_algorithm"`
+	TOTPSecret        string `db:"totp_secret"`
+	TOTPEnabled       bool   `db:"totp_enabled"`
+	TOTPLastCounter   int64  `db:"totp_last_counter"`
+	IsServiceAccount  bool   `db:"is_service_account"`
+	// OpeningBalance is where the ledger of the account starts.
+	OpeningBalance int        `db:"opening_balance"`
+	FrozenAt       *time.Time `db:"frozen_at"`
 }
 
 const repoStmtFindByUsername = `
@@ -37,20 +49,29 @@ where username = $1
 const repoStmtCreateUser = `
 insert into 
     users
-    (username, password_hash, balance)
-    values ($1, $2, $3);
+    (username, password_hash, password_algorithm, balance, opening_balance, is_admin)
+    values ($1, $2, $3, $4, $4, $5);
+`
+
+const repoStmtLockSender = `
+SELECT balance, frozen_at IS NOT NULL
+FROM users
+WHERE username = $1
+FOR UPDATE
 `
 
 const repoStmtDecreaseBalance = `
 UPDATE users 
 SET balance = balance - $1 
 WHERE username = $2
+RETURNING balance
 `
 
 const repoStmtIncreaseBalance = `
 UPDATE users 
 SET balance = balance + $1 
 WHERE username = $2
+RETURNING balance
 `
 
 func (r *CoinRepo) GetBalance(ctx context.Context, params repo.GetBalanceParams) (int, error) {
@@ -77,9 +98,17 @@ func (r *CoinRepo) GetUserByUsername(ctx context.Context, username string) (*mod
 		return nil, fmt.Errorf("r.db.GetContext: %w", err)
 	}
 	return &models.User{
-		Username:     usr.Username,
-		PasswordHash: usr.PasswordHash,
-		Balance:      usr.Balance,
+		Username:          usr.Username,
+		PasswordHash:      usr.PasswordHash,
+		PasswordAlgorithm: usr.PasswordAlgorithm,
+		Balance:           usr.Balance,
+		IsAdmin:           usr.IsAdmin,
+		Email:             usr.Email,
+		Locale:            usr.Locale,
+		TOTPSecret:        usr.TOTPSecret,
+		TOTPEnabled:       usr.TOTPEnabled,
+		IsServiceAccount:  usr.IsServiceAccount,
+		FrozenAt:          usr.FrozenAt,
 	}, nil
 }
 
@@ -89,7 +118,9 @@ func (r *CoinRepo) CreateUser(ctx context.Context, params repo.CreateUserParams)
 		repoStmtCreateUser,
 		params.Username,
 		params.PassHash,
+		params.PassAlgorithm,
 		params.Balance,
+		params.IsAdmin,
 	); err != nil {
 		return fmt.Errorf("r.db.ExecContext: %w", err)
 	}
@@ -100,28 +131,43 @@ func (r *CoinRepo) BeginTx(ctx context.Context) (*sqlx.Tx, error) {
 	return r.db.BeginTxx(ctx, nil)
 }
 
-func (r *CoinRepo) DecreaseBalance(ctx context.Context, tx *sqlx.Tx, params repo.ChangeBalanceParams) error {
-	_, err := tx.ExecContext(
+func (r *CoinRepo) DecreaseBalance(ctx context.Context, tx *sqlx.Tx, params repo.ChangeBalanceParams) (int, error) {
+	// The row stays locked until the transaction ends, so concurrent
+	// transfers from the same account can't both pass the check.
+	var balance int
+	var frozen bool
+	if err := tx.QueryRowContext(ctx, repoStmtLockSender, params.Username).Scan(&balance, &frozen); err != nil {
+		return 0, fmt.Errorf("tx.QueryRowContext (lock): %w", err)
+	}
+	if frozen {
+		return 0, repo.AccountFrozenError
+	}
+	if balance < params.Amount {
+		return 0, repo.InsufficientFundsError
+	}
+
+	if err := tx.GetContext(
 		ctx,
+		&balance,
 		repoStmtDecreaseBalance,
 		params.Amount,
 		params.Username,
-	)
-	if err != nil {
-		return fmt.Errorf("tx.ExecContext (decrease): %w", err)
+	); err != nil {
+		return 0, fmt.Errorf("tx.GetContext (decrease): %w", err)
 	}
-	return nil
+	return balance, nil
 }
 
-func (r *CoinRepo) IncreaseBalance(ctx context.Context, tx *sqlx.Tx, params repo.ChangeBalanceParams) error {
-	_, err := tx.ExecContext(
+func (r *CoinRepo) IncreaseBalance(ctx context.Context, tx *sqlx.Tx, params repo.ChangeBalanceParams) (int, error) {
+	var balance int
+	if err := tx.GetContext(
 		ctx,
+		&balance,
 		repoStmtIncreaseBalance,
 		params.Amount,
 		params.Username,
-	)
-	if err != nil {
-		return fmt.Errorf("tx.ExecContext (increase): %w", err)
+	); err != nil {
+		return 0, fmt.Errorf("tx.GetContext (increase): %w", err)
 	}
-	return nil
+	return balance, nil
 }
@@ -0,0 +1,122 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/repo"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+type Delegation struct {
+	ID             int64     `db:"id"`
+	OwnerUsername  string    `db:"owner_username"`
+	ClientUsername string    `db:"client_username"`
+	DailyLimit     int       `db:"daily_limit"`
+	SpentToday     int       `db:"spent_today"`
+	CreatedAt      time.Time `db:"created_at"`
+}
+
+// spentToday sums what the client of delegation d sent on the owner's behalf
+// in the last 24 hours.
+const spentToday = `
+coalesce((
+    select sum(t.amount)
+    from transactions t
+    where t.sender_username = d.owner_username
+      and t.actor_username = d.client_username
+      and t.created_at > now() - interval '24 hours'
+), 0) as spent_today
+`
+
+const repoStmtGrantDelegation = `
+insert into delegations as d (owner_username, client_username, daily_limit)
+values ($1, $2, $3)
+on conflict (owner_username, client_username) where revoked_at is null
+do update set daily_limit = excluded.daily_limit
+returning d.id, d.owner_username, d.client_username, d.daily_limit, d.created_at,
+` + spentToday
+
+const repoStmtListDelegations = `
+select d.id, d.owner_username, d.client_username, d.daily_limit, d.created_at,
+` + spentToday + `
+from delegations d
+where d.owner_username = $1 and d.revoked_at is null
+order by d.id
+`
+
+const repoStmtRevokeDelegation = `
+update delegations
+set revoked_at = now()
+where id = $1 and owner_username = $2 and revoked_at is null
+`
+
+// The row lock serializes transfers under one delegation, so concurrent
+// transfers can't overspend the daily limit together.
+const repoStmtLockDelegation = `
+select d.id, d.owner_username, d.client_username, d.daily_limit, d.created_at,
+` + spentToday + `
+from delegations d
+where d.owner_username = $1 and d.client_username = $2 and d.revoked_at is null
+for update of d
+`
+
+func (r *CoinRepo) GrantDelegation(ctx context.Context, params repo.GrantDelegationParams) (models.Delegation, error) {
+	var delegation Delegation
+	if err := r.db.GetContext(
+		ctx,
+		&delegation,
+		repoStmtGrantDelegation,
+		params.OwnerUsername,
+		params.ClientUsername,
+		params.DailyLimit,
+	); err != nil {
+		return models.Delegation{}, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return models.Delegation(delegation), nil
+}
+
+// ListDelegations returns the owner's active delegations.
+func (r *CoinRepo) ListDelegations(ctx context.Context, ownerUsername string) ([]models.Delegation, error) {
+	var rows []Delegation
+	if err := r.db.SelectContext(ctx, &rows, repoStmtListDelegations, ownerUsername); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+
+	delegations := make([]models.Delegation, len(rows))
+	for i, row := range rows {
+		delegations[i] = models.Delegation(row)
+	}
+	return delegations, nil
+}
+
+// RevokeDelegation reports false if the owner has no active delegation with id.
+func (r *CoinRepo) RevokeDelegation(ctx context.Context, params repo.RevokeDelegationParams) (bool, error) {
+	res, err := r.db.ExecContext(ctx, repoStmtRevokeDelegation, params.ID, params.OwnerUsername)
+	if err != nil {
+		return false, fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// LockDelegation returns the active delegation between owner and client and
+// locks it until tx ends, or sql.ErrNoRows if there is none.
+func (r *CoinRepo) LockDelegation(ctx context.Context, tx *sqlx.Tx, params repo.LockDelegationParams) (models.Delegation, error) {
+	var delegation Delegation
+	if err := tx.GetContext(
+		ctx,
+		&delegation,
+		repoStmtLockDelegation,
+		params.OwnerUsername,
+		params.ClientUsername,
+	); err != nil {
+		return models.Delegation{}, fmt.Errorf("tx.GetContext: %w", err)
+	}
+	return models.Delegation(delegation), nil
+}
@@ -0,0 +1,115 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/repo"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+// EventsChannel is the LISTEN/NOTIFY channel every saved event is announced on.
+const EventsChannel = "coin_events"
+
+type Event struct {
+	ID        int64     `db:"id"`
+	Username  string    `db:"username"`
+	Type      string    `db:"type"`
+	Payload   []byte    `db:"payload"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+const repoStmtSaveEvent = `
+insert into
+events
+(username, type, payload)
+values ($1, $2, $3)
+returning *
+`
+
+const repoStmtNotifyEvent = `
+select pg_notify($1, $2)
+`
+
+const repoStmtGetEvents = `
+select *
+from events
+where username = $1 and id > $2
+order by id
+limit $3
+`
+
+// SaveEvent stores the event and queues a notification for it. Both are part of
+// tx, so listeners only hear about the event once tx is committed.
+func (r *CoinRepo) SaveEvent(ctx context.Context, tx *sqlx.Tx, params repo.SaveEventParams) error {
+	payload, err := json.Marshal(params.Payload)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	var event Event
+	if err = tx.GetContext(
+		ctx,
+		&event,
+		repoStmtSaveEvent,
+		params.Username,
+		params.Type,
+		payload,
+	); err != nil {
+		return fmt.Errorf("tx.GetContext: %w", err)
+	}
+
+	message, err := json.Marshal(event.toModel())
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	if _, err = tx.ExecContext(ctx, repoStmtNotifyEvent, EventsChannel, string(message)); err != nil {
+		return fmt.Errorf("tx.ExecContext (notify): %w", err)
+	}
+
+	return nil
+}
+
+func (r *CoinRepo) GetEvents(ctx context.Context, params repo.GetEventsParams) ([]models.Event, error) {
+	rows, err := r.db.QueryxContext(
+		ctx,
+		repoStmtGetEvents,
+		params.Username,
+		params.AfterID,
+		params.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			return
+		}
+	}()
+
+	var events []models.Event
+	for rows.Next() {
+		var event Event
+		if err := rows.StructScan(&event); err != nil {
+			return nil, err
+		}
+
+		events = append(events, event.toModel())
+	}
+
+	return events, nil
+}
+
+func (e Event) toModel() models.Event {
+	return models.Event{
+		ID:        e.ID,
+		Username:  e.Username,
+		Type:      models.EventType(e.Type),
+		Payload:   e.Payload,
+		CreatedAt: e.CreatedAt,
+	}
+}
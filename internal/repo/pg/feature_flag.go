@@ -0,0 +1,118 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/repo"
+	"time"
+)
+
+type FeatureFlag struct {
+	Name           string    `db:"name"`
+	Description    string    `db:"description"`
+	Enabled        bool      `db:"enabled"`
+	RolloutPercent int       `db:"rollout_percent"`
+	AllowList      []byte    `db:"allow_list"`
+	UpdatedBy      string    `db:"updated_by"`
+	UpdatedAt      time.Time `db:"updated_at"`
+}
+
+func (f FeatureFlag) toModel() (models.FeatureFlag, error) {
+	var allowList []string
+	if err := json.Unmarshal(f.AllowList, &allowList); err != nil {
+		return models.FeatureFlag{}, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	return models.FeatureFlag{
+		Name:           f.Name,
+		Description:    f.Description,
+		Enabled:        f.Enabled,
+		RolloutPercent: f.RolloutPercent,
+		AllowList:      allowList,
+		UpdatedBy:      f.UpdatedBy,
+		UpdatedAt:      f.UpdatedAt,
+	}, nil
+}
+
+const repoStmtGetFeatureFlags = `
+select *
+from feature_flags
+order by name
+`
+
+const repoStmtSaveFeatureFlag = `
+insert into feature_flags (name, description, enabled, rollout_percent, allow_list, updated_by)
+values ($1, $2, $3, $4, $5, $6)
+on conflict (name) do update set
+    description = excluded.description,
+    enabled = excluded.enabled,
+    rollout_percent = excluded.rollout_percent,
+    allow_list = excluded.allow_list,
+    updated_by = excluded.updated_by,
+    updated_at = now()
+returning *
+`
+
+const repoStmtDeleteFeatureFlag = `
+delete from feature_flags
+where name = $1
+`
+
+func (r *CoinRepo) GetFeatureFlags(ctx context.Context) ([]models.FeatureFlag, error) {
+	var rows []FeatureFlag
+	if err := r.db.SelectContext(ctx, &rows, repoStmtGetFeatureFlags); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+
+	flags := make([]models.FeatureFlag, len(rows))
+	for i, row := range rows {
+		flag, err := row.toModel()
+		if err != nil {
+			return nil, fmt.Errorf("flag %s: %w", row.Name, err)
+		}
+		flags[i] = flag
+	}
+	return flags, nil
+}
+
+func (r *CoinRepo) SaveFeatureFlag(ctx context.Context, params repo.SaveFeatureFlagParams) (models.FeatureFlag, error) {
+	allowList := params.AllowList
+	if allowList == nil {
+		allowList = []string{}
+	}
+	allowListJSON, err := json.Marshal(allowList)
+	if err != nil {
+		return models.FeatureFlag{}, fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	var row FeatureFlag
+	if err := r.db.GetContext(
+		ctx,
+		&row,
+		repoStmtSaveFeatureFlag,
+		params.Name,
+		params.Description,
+		params.Enabled,
+		params.RolloutPercent,
+		allowListJSON,
+		params.UpdatedBy,
+	); err != nil {
+		return models.FeatureFlag{}, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return row.toModel()
+}
+
+func (r *CoinRepo) DeleteFeatureFlag(ctx context.Context, name string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, repoStmtDeleteFeatureFlag, name)
+	if err != nil {
+		return false, fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	return affected > 0, nil
+}
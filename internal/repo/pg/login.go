@@ -0,0 +1,91 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/repo"
+	"time"
+)
+
+type LoginAttempt struct {
+	Key           string     `db:"key"`
+	Failures      int        `db:"failures"`
+	LastFailureAt time.Time  `db:"last_failure_at"`
+	LockedUntil   *time.Time `db:"locked_until"`
+}
+
+const repoStmtGetLoginAttempts = `
+select *
+from login_attempts
+where key = any($1)
+`
+
+// The count restarts once the last failure is older than the window or the
+// previous lock has expired, so a lock is only set by a fresh run of failures.
+const repoStmtRecordLoginFailure = `
+with previous as (
+    select failures, last_failure_at, locked_until
+    from login_attempts
+    where key = $1
+    for update
+), counted as (
+    select case
+        when p.failures is null
+          or ($2 > 0 and p.last_failure_at < now() - make_interval(secs => $2))
+          or p.locked_until < now() then 1
+        else p.failures + 1
+    end as failures
+    from (select 1) one
+    left join previous p on true
+)
+insert into login_attempts (key, failures, last_failure_at, locked_until)
+select $1, failures, now(), case when $3 > 0 and failures >= $3 then now() + make_interval(secs => $4) end
+from counted
+on conflict (key) do update
+set failures = excluded.failures,
+    last_failure_at = excluded.last_failure_at,
+    locked_until = excluded.locked_until
+returning *
+`
+
+const repoStmtResetLoginAttempts = `
+delete from login_attempts
+where key = any($1)
+`
+
+func (r *CoinRepo) GetLoginAttempts(ctx context.Context, keys []string) ([]models.LoginAttempt, error) {
+	var rows []LoginAttempt
+	if err := r.db.SelectContext(ctx, &rows, repoStmtGetLoginAttempts, keys); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+
+	attempts := make([]models.LoginAttempt, len(rows))
+	for i, row := range rows {
+		attempts[i] = models.LoginAttempt(row)
+	}
+	return attempts, nil
+}
+
+func (r *CoinRepo) RecordLoginFailure(ctx context.Context, params repo.RecordLoginFailureParams) (models.LoginAttempt, error) {
+	var row LoginAttempt
+	if err := r.db.QueryRowxContext(
+		ctx,
+		repoStmtRecordLoginFailure,
+		params.Key,
+		params.Window.Seconds(),
+		params.LockAfter,
+		params.LockFor.Seconds(),
+	).StructScan(&row); err != nil {
+		return models.LoginAttempt{}, fmt.Errorf("r.db.QueryRowxContext: %w", err)
+	}
+
+	return models.LoginAttempt(row), nil
+}
+
+func (r *CoinRepo) ResetLoginAttempts(ctx context.Context, keys []string) error {
+	if _, err := r.db.ExecContext(ctx, repoStmtResetLoginAttempts, keys); err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+ALTER TABLE users ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE notifications (
+    id BIGSERIAL PRIMARY KEY,
+    username TEXT NOT NULL REFERENCES users(username),
+    type TEXT NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    read_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX notifications_username_id_idx ON notifications (username, id);
+CREATE INDEX notifications_unread_idx ON notifications (username) WHERE read_at IS NULL;
+
+CREATE TABLE notification_preferences (
+    username TEXT NOT NULL REFERENCES users(username),
+    type TEXT NOT NULL,
+    muted BOOLEAN NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (username, type)
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS notification_deliveries;
+ALTER TABLE users DROP COLUMN IF EXISTS locale;
+ALTER TABLE users DROP COLUMN IF EXISTS email;
@@ -0,0 +1,11 @@
+ALTER TABLE users ADD COLUMN password_algorithm TEXT NOT NULL DEFAULT 'bcrypt';
+
+CREATE TABLE password_reset_tokens (
+    token_hash TEXT PRIMARY KEY,
+    username TEXT NOT NULL REFERENCES users(username) ON DELETE CASCADE,
+    created_by TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ
+);
+
+CREATE INDEX password_reset_tokens_username_idx ON password_reset_tokens (username);
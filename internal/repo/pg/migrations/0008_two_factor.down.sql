@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS two_factor_policy;
+DROP TABLE IF EXISTS auth_challenges;
+DROP TABLE IF EXISTS totp_recovery_codes;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS totp_last_counter,
+    DROP COLUMN IF EXISTS totp_enabled,
+    DROP COLUMN IF EXISTS totp_secret;
@@ -0,0 +1,28 @@
+ALTER TABLE users
+    ADD COLUMN totp_secret TEXT NOT NULL DEFAULT '',
+    ADD COLUMN totp_enabled BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN totp_last_counter BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE totp_recovery_codes (
+    username TEXT NOT NULL REFERENCES users(username) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMPTZ,
+    PRIMARY KEY (username, code_hash)
+);
+
+CREATE TABLE auth_challenges (
+    token_hash TEXT PRIMARY KEY,
+    username TEXT NOT NULL REFERENCES users(username) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX auth_challenges_expires_at_idx ON auth_challenges (expires_at);
+
+-- A single row: the policy admins set for operations that need a second factor.
+CREATE TABLE two_factor_policy (
+    id BOOLEAN PRIMARY KEY DEFAULT true CHECK (id),
+    transfer_threshold INT NOT NULL DEFAULT 0,
+    purchase_threshold INT NOT NULL DEFAULT 0
+);
+
+INSERT INTO two_factor_policy DEFAULT VALUES;
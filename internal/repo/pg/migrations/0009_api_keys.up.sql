@@ -0,0 +1,15 @@
+ALTER TABLE users ADD COLUMN is_service_account BOOLEAN NOT NULL DEFAULT false;
+
+-- Scopes are space-separated, like OAuth scope strings.
+CREATE TABLE api_keys (
+    id TEXT PRIMARY KEY,
+    username TEXT NOT NULL REFERENCES users(username) ON DELETE CASCADE,
+    secret_hash TEXT NOT NULL,
+    scopes TEXT NOT NULL,
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX api_keys_username_idx ON api_keys (username);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS transactions_actor_idx;
+ALTER TABLE transactions DROP COLUMN IF EXISTS actor_username;
+DROP TABLE IF EXISTS delegations;
@@ -0,0 +1,19 @@
+-- A delegation lets a service account (the client) send coins from the
+-- owner's account, up to daily_limit coins in any 24 hours.
+CREATE TABLE delegations (
+    id BIGSERIAL PRIMARY KEY,
+    owner_username TEXT NOT NULL REFERENCES users(username) ON DELETE CASCADE,
+    client_username TEXT NOT NULL REFERENCES users(username) ON DELETE CASCADE,
+    daily_limit INT NOT NULL CHECK (daily_limit > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX delegations_active_idx ON delegations (owner_username, client_username)
+    WHERE revoked_at IS NULL;
+
+-- actor_username is set when someone other than the sender made the transfer.
+ALTER TABLE transactions ADD COLUMN actor_username TEXT REFERENCES users(username);
+
+CREATE INDEX transactions_actor_idx ON transactions (actor_username, sender_username, created_at)
+    WHERE actor_username IS NOT NULL;
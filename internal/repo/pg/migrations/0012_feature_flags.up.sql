@@ -0,0 +1,11 @@
+-- A feature is on for a user when the flag is enabled and the user is in
+-- allow_list or among the first rollout_percent percent of users by hash.
+CREATE TABLE feature_flags (
+    name TEXT PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT false,
+    rollout_percent INT NOT NULL DEFAULT 0 CHECK (rollout_percent BETWEEN 0 AND 100),
+    allow_list JSONB NOT NULL DEFAULT '[]',
+    updated_by TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS opening_balance;
+DROP TABLE IF EXISTS balance_adjustments;
+ALTER TABLE users DROP COLUMN IF EXISTS frozen_at;
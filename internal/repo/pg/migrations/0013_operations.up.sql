@@ -0,0 +1,27 @@
+-- A frozen account can't log in or move coins out; it can still receive them.
+ALTER TABLE users ADD COLUMN frozen_at TIMESTAMPTZ;
+
+-- Operators correct balances through adjustments, so every coin stays
+-- accounted for: a balance is its opening balance plus coins received and
+-- adjusted minus coins sent and spent.
+CREATE TABLE balance_adjustments (
+    id BIGSERIAL PRIMARY KEY,
+    username TEXT NOT NULL REFERENCES users(username),
+    amount INT NOT NULL CHECK (amount <> 0),
+    reason TEXT NOT NULL,
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX balance_adjustments_username_idx ON balance_adjustments (username, id);
+
+-- Balances before this migration have no history to check them against, so
+-- they are taken as correct.
+ALTER TABLE users ADD COLUMN opening_balance INT;
+
+UPDATE users u SET opening_balance = u.balance
+    - coalesce((SELECT sum(amount) FROM transactions WHERE receiver_username = u.username), 0)
+    + coalesce((SELECT sum(amount) FROM transactions WHERE sender_username = u.username), 0)
+    + coalesce((SELECT sum(price) FROM purchases WHERE username = u.username), 0);
+
+ALTER TABLE users ALTER COLUMN opening_balance SET NOT NULL;
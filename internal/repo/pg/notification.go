@@ -0,0 +1,333 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/repo"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+type Notification struct {
+	ID        int64      `db:"id"`
+	Username  string     `db:"username"`
+	Type      string     `db:"type"`
+	Payload   []byte     `db:"payload"`
+	ReadAt    *time.Time `db:"read_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+type NotificationDelivery struct {
+	ID             int64     `db:"id"`
+	Attempts       int       `db:"attempts"`
+	NotificationID int64     `db:"notification_id"`
+	Username       string    `db:"username"`
+	Type           string    `db:"type"`
+	Payload        []byte    `db:"payload"`
+	CreatedAt      time.Time `db:"created_at"`
+	Email          string    `db:"email"`
+	Locale         string    `db:"locale"`
+}
+
+type NotificationPreference struct {
+	Type  string `db:"type"`
+	Muted bool   `db:"muted"`
+}
+
+// Muted notification types are skipped here, inside the transaction that
+// produced them, rather than hidden when the inbox is read. A muted
+// notification is not delivered either.
+const repoStmtCreateNotification = `
+with notification as (
+    insert into notifications (username, type, payload)
+    select $1, $2, $3
+    where not exists (
+        select 1
+        from notification_preferences
+        where username = $1 and type = $2 and muted
+    )
+    returning id
+)
+insert into notification_deliveries (notification_id)
+select id
+from notification
+where $4
+`
+
+const repoStmtGetNotifications = `
+select *
+from notifications
+where username = $1
+  and ($2::bigint = 0 or id < $2)
+  and (not $3 or read_at is null)
+order by id desc
+limit $4
+`
+
+const repoStmtCountUnreadNotifications = `
+select count(*)
+from notifications
+where username = $1 and read_at is null
+`
+
+const repoStmtMarkNotificationRead = `
+update notifications
+set read_at = coalesce(read_at, now())
+where id = $1 and username = $2
+`
+
+const repoStmtMarkAllNotificationsRead = `
+update notifications
+set read_at = now()
+where username = $1 and read_at is null
+`
+
+const repoStmtGetNotificationPreferences = `
+select type, muted
+from notification_preferences
+where username = $1
+`
+
+const repoStmtSetNotificationPreference = `
+insert into notification_preferences (username, type, muted)
+values ($1, $2, $3)
+on conflict (username, type) do update set muted = excluded.muted
+`
+
+const repoStmtSetNotificationContact = `
+update users
+set email = $2, locale = $3
+where username = $1
+`
+
+// Claimed deliveries are leased rather than locked for the duration of the
+// send, so a slow SMTP server does not keep a transaction open. A worker that
+// dies mid-send leaves the delivery to be picked up again once the lease ends.
+const repoStmtClaimNotificationDeliveries = `
+with claimed as (
+    select id
+    from notification_deliveries
+    where sent_at is null and failed_at is null and next_attempt_at <= now()
+    order by id
+    limit $1
+    for update skip locked
+)
+update notification_deliveries d
+set attempts = d.attempts + 1,
+    next_attempt_at = now() + make_interval(secs => $2)
+from claimed, notifications n, users u
+where d.id = claimed.id
+  and n.id = d.notification_id
+  and u.username = n.username
+returning d.id, d.attempts, n.id as notification_id, n.username, n.type, n.payload, n.created_at, u.email, u.locale
+`
+
+const repoStmtCompleteNotificationDelivery = `
+update notification_deliveries
+set sent_at = now(), last_error = ''
+where id = $1
+`
+
+const repoStmtFailNotificationDelivery = `
+update notification_deliveries
+set last_error = $2,
+    next_attempt_at = $3,
+    failed_at = case when $4 then now() end
+where id = $1
+`
+
+func (r *CoinRepo) CreateNotification(ctx context.Context, tx *sqlx.Tx, params repo.CreateNotificationParams) error {
+	payload, err := json.Marshal(params.Payload)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	if _, err = tx.ExecContext(
+		ctx,
+		repoStmtCreateNotification,
+		params.Username,
+		params.Type,
+		payload,
+		params.Deliver,
+	); err != nil {
+		return fmt.Errorf("tx.ExecContext: %w", err)
+	}
+	return nil
+}
+
+func (r *CoinRepo) GetNotifications(ctx context.Context, params repo.GetNotificationsParams) ([]models.Notification, error) {
+	rows, err := r.db.QueryxContext(
+		ctx,
+		repoStmtGetNotifications,
+		params.Username,
+		params.BeforeID,
+		params.UnreadOnly,
+		params.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			return
+		}
+	}()
+
+	var notifications []models.Notification
+	for rows.Next() {
+		var notification Notification
+		if err := rows.StructScan(&notification); err != nil {
+			return nil, err
+		}
+
+		notifications = append(notifications, models.Notification{
+			ID:        notification.ID,
+			Username:  notification.Username,
+			Type:      models.NotificationType(notification.Type),
+			Payload:   notification.Payload,
+			ReadAt:    notification.ReadAt,
+			CreatedAt: notification.CreatedAt,
+		})
+	}
+
+	return notifications, nil
+}
+
+func (r *CoinRepo) CountUnreadNotifications(ctx context.Context, username string) (int, error) {
+	var count int
+	if err := r.db.GetContext(ctx, &count, repoStmtCountUnreadNotifications, username); err != nil {
+		return 0, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return count, nil
+}
+
+// MarkNotificationRead returns sql.ErrNoRows when the user has no such notification.
+func (r *CoinRepo) MarkNotificationRead(ctx context.Context, params repo.MarkNotificationReadParams) error {
+	res, err := r.db.ExecContext(ctx, repoStmtMarkNotificationRead, params.ID, params.Username)
+	if err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *CoinRepo) MarkAllNotificationsRead(ctx context.Context, username string) (int64, error) {
+	res, err := r.db.ExecContext(ctx, repoStmtMarkAllNotificationsRead, username)
+	if err != nil {
+		return 0, fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	return affected, nil
+}
+
+func (r *CoinRepo) GetNotificationPreferences(ctx context.Context, username string) ([]models.NotificationPreference, error) {
+	var prefs []NotificationPreference
+	if err := r.db.SelectContext(ctx, &prefs, repoStmtGetNotificationPreferences, username); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+
+	preferences := make([]models.NotificationPreference, len(prefs))
+	for i, pref := range prefs {
+		preferences[i] = models.NotificationPreference{
+			Type:  models.NotificationType(pref.Type),
+			Muted: pref.Muted,
+		}
+	}
+	return preferences, nil
+}
+
+func (r *CoinRepo) SetNotificationPreference(ctx context.Context, tx *sqlx.Tx, params repo.SetNotificationPreferenceParams) error {
+	if _, err := tx.ExecContext(
+		ctx,
+		repoStmtSetNotificationPreference,
+		params.Username,
+		params.Type,
+		params.Muted,
+	); err != nil {
+		return fmt.Errorf("tx.ExecContext: %w", err)
+	}
+	return nil
+}
+
+func (r *CoinRepo) SetNotificationContact(ctx context.Context, params repo.SetNotificationContactParams) error {
+	res, err := r.db.ExecContext(ctx, repoStmtSetNotificationContact, params.Username, params.Email, params.Locale)
+	if err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *CoinRepo) ClaimNotificationDeliveries(ctx context.Context, params repo.ClaimNotificationDeliveriesParams) ([]models.NotificationDelivery, error) {
+	var claimed []NotificationDelivery
+	if err := r.db.SelectContext(
+		ctx,
+		&claimed,
+		repoStmtClaimNotificationDeliveries,
+		params.Limit,
+		params.Lease.Seconds(),
+	); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+
+	deliveries := make([]models.NotificationDelivery, len(claimed))
+	for i, d := range claimed {
+		deliveries[i] = models.NotificationDelivery{
+			ID:       d.ID,
+			Attempts: d.Attempts,
+			Notification: models.Notification{
+				ID:        d.NotificationID,
+				Username:  d.Username,
+				Type:      models.NotificationType(d.Type),
+				Payload:   d.Payload,
+				CreatedAt: d.CreatedAt,
+			},
+			Email:  d.Email,
+			Locale: d.Locale,
+		}
+	}
+	return deliveries, nil
+}
+
+func (r *CoinRepo) CompleteNotificationDelivery(ctx context.Context, id int64) error {
+	if _, err := r.db.ExecContext(ctx, repoStmtCompleteNotificationDelivery, id); err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	return nil
+}
+
+func (r *CoinRepo) FailNotificationDelivery(ctx context.Context, params repo.FailNotificationDeliveryParams) error {
+	if _, err := r.db.ExecContext(
+		ctx,
+		repoStmtFailNotificationDelivery,
+		params.ID,
+		params.Error,
+		params.RetryAt,
+		params.GiveUp,
+	); err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	return nil
+}
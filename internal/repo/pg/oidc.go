@@ -0,0 +1,110 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/repo"
+)
+
+type OIDCLoginState struct {
+	Nonce        string `db:"nonce"`
+	CodeVerifier string `db:"code_verifier"`
+}
+
+// Expired states are removed whenever a new login starts.
+const repoStmtCreateOIDCLoginState = `
+with expired as (
+    delete from oidc_login_states
+    where expires_at < now()
+)
+insert into oidc_login_states (state_hash, nonce, code_verifier, expires_at)
+values ($1, $2, $3, $4)
+`
+
+const repoStmtUseOIDCLoginState = `
+delete from oidc_login_states
+where state_hash = $1 and expires_at > now()
+returning nonce, code_verifier
+`
+
+const repoStmtGetOIDCIdentity = `
+select username
+from oidc_identities
+where issuer = $1 and subject = $2
+`
+
+// Users who log in with a password or an API key are never linked.
+const repoStmtLinkOIDCIdentity = `
+with provisioned as (
+    insert into users (username, password_hash, balance, opening_balance)
+    values ($3, '', $4, $4)
+    on conflict (username) do nothing
+)
+insert into oidc_identities (issuer, subject, username)
+select $1, $2, $3
+where not exists (
+    select 1
+    from users
+    where username = $3 and (password_hash <> '' or is_service_account)
+)
+on conflict (issuer, subject) do nothing
+`
+
+func (r *CoinRepo) CreateOIDCLoginState(ctx context.Context, params repo.CreateOIDCLoginStateParams) error {
+	if _, err := r.db.ExecContext(
+		ctx,
+		repoStmtCreateOIDCLoginState,
+		params.StateHash,
+		params.Nonce,
+		params.CodeVerifier,
+		params.ExpiresAt,
+	); err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	return nil
+}
+
+// UseOIDCLoginState deletes the state and returns it, or sql.ErrNoRows if it
+// is unknown, used or expired.
+func (r *CoinRepo) UseOIDCLoginState(ctx context.Context, stateHash string) (models.OIDCLoginState, error) {
+	var state OIDCLoginState
+	if err := r.db.GetContext(ctx, &state, repoStmtUseOIDCLoginState, stateHash); err != nil {
+		return models.OIDCLoginState{}, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return models.OIDCLoginState(state), nil
+}
+
+// GetOIDCIdentity returns the user an identity is linked to, or
+// sql.ErrNoRows if it isn't linked yet.
+func (r *CoinRepo) GetOIDCIdentity(ctx context.Context, params repo.GetOIDCIdentityParams) (string, error) {
+	var username string
+	if err := r.db.GetContext(ctx, &username, repoStmtGetOIDCIdentity, params.Issuer, params.Subject); err != nil {
+		return "", fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return username, nil
+}
+
+// LinkOIDCIdentity returns sql.ErrNoRows if the identity wasn't linked.
+func (r *CoinRepo) LinkOIDCIdentity(ctx context.Context, params repo.LinkOIDCIdentityParams) error {
+	res, err := r.db.ExecContext(
+		ctx,
+		repoStmtLinkOIDCIdentity,
+		params.Issuer,
+		params.Subject,
+		params.Username,
+		params.Balance,
+	)
+	if err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
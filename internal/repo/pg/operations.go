@@ -0,0 +1,232 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/repo"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+type BalanceAdjustment struct {
+	ID        int64     `db:"id"`
+	Username  string    `db:"username"`
+	Amount    int       `db:"amount"`
+	Reason    string    `db:"reason"`
+	CreatedBy string    `db:"created_by"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type LedgerMismatch struct {
+	Username string `db:"username"`
+	Balance  int    `db:"balance"`
+	Expected int    `db:"expected"`
+}
+
+const repoStmtLockBalance = `
+select balance
+from users
+where username = $1
+for update
+`
+
+const repoStmtAdjustBalance = `
+update users
+set balance = balance + $1
+where username = $2
+returning balance
+`
+
+const repoStmtSaveBalanceAdjustment = `
+insert into balance_adjustments (username, amount, reason, created_by)
+values ($1, $2, $3, $4)
+`
+
+const repoStmtGetBalanceAdjustments = `
+select *
+from balance_adjustments
+where username = $1
+order by id
+`
+
+const repoStmtSetUserFrozen = `
+update users
+set frozen_at = case when $1 then coalesce(frozen_at, now()) end
+where username = $2
+`
+
+const repoStmtListTransactions = `
+select *
+from transactions
+where ($1 = '' or sender_username = $1 or receiver_username = $1)
+    and ($2 = 0 or id < $2)
+order by id desc
+limit $3
+`
+
+const repoStmtGetTransaction = `
+select *
+from transactions
+where id = $1
+`
+
+const repoStmtSaveItem = `
+insert into items (name, price)
+values ($1, $2)
+on conflict (name) do update set price = excluded.price
+`
+
+const repoStmtDeleteItem = `
+delete from items
+where name = $1
+`
+
+const repoStmtVerifyLedger = `
+select username, balance, expected
+from (
+    select u.username, u.balance,
+        u.opening_balance
+            + coalesce(received.amount, 0)
+            - coalesce(sent.amount, 0)
+            - coalesce(spent.amount, 0)
+            + coalesce(adjusted.amount, 0) as expected
+    from users u
+    left join (
+        select receiver_username as username, sum(amount) as amount
+        from transactions
+        group by receiver_username
+    ) received on received.username = u.username
+    left join (
+        select sender_username as username, sum(amount) as amount
+        from transactions
+        group by sender_username
+    ) sent on sent.username = u.username
+    left join (
+        select username, sum(price) as amount
+        from purchases
+        group by username
+    ) spent on spent.username = u.username
+    left join (
+        select username, sum(amount) as amount
+        from balance_adjustments
+        group by username
+    ) adjusted on adjusted.username = u.username
+) ledger
+where balance <> expected
+order by username
+`
+
+func (r *CoinRepo) AdjustBalance(ctx context.Context, tx *sqlx.Tx, params repo.AdjustBalanceParams) (int, error) {
+	var balance int
+	if err := tx.GetContext(ctx, &balance, repoStmtLockBalance, params.Username); err != nil {
+		return 0, fmt.Errorf("tx.GetContext (lock): %w", err)
+	}
+	if balance+params.Amount < 0 {
+		return 0, repo.InsufficientFundsError
+	}
+
+	if err := tx.GetContext(ctx, &balance, repoStmtAdjustBalance, params.Amount, params.Username); err != nil {
+		return 0, fmt.Errorf("tx.GetContext (adjust): %w", err)
+	}
+
+	if _, err := tx.ExecContext(
+		ctx,
+		repoStmtSaveBalanceAdjustment,
+		params.Username,
+		params.Amount,
+		params.Reason,
+		params.CreatedBy,
+	); err != nil {
+		return 0, fmt.Errorf("tx.ExecContext: %w", err)
+	}
+
+	return balance, nil
+}
+
+func (r *CoinRepo) GetBalanceAdjustments(ctx context.Context, username string) ([]models.BalanceAdjustment, error) {
+	var rows []BalanceAdjustment
+	if err := r.db.SelectContext(ctx, &rows, repoStmtGetBalanceAdjustments, username); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+
+	adjustments := make([]models.BalanceAdjustment, len(rows))
+	for i, row := range rows {
+		adjustments[i] = models.BalanceAdjustment(row)
+	}
+	return adjustments, nil
+}
+
+func (r *CoinRepo) SetUserFrozen(ctx context.Context, params repo.SetUserFrozenParams) (bool, error) {
+	res, err := r.db.ExecContext(ctx, repoStmtSetUserFrozen, params.Frozen, params.Username)
+	if err != nil {
+		return false, fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	return affected > 0, nil
+}
+
+func (r *CoinRepo) ListTransactions(ctx context.Context, params repo.ListTransactionsParams) ([]models.Transaction, error) {
+	var rows []Transaction
+	if err := r.db.SelectContext(
+		ctx,
+		&rows,
+		repoStmtListTransactions,
+		params.Username,
+		params.BeforeID,
+		params.Limit,
+	); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+
+	transactions := make([]models.Transaction, len(rows))
+	for i, row := range rows {
+		transactions[i] = row.toModel()
+	}
+	return transactions, nil
+}
+
+func (r *CoinRepo) GetTransaction(ctx context.Context, id int64) (models.Transaction, error) {
+	var row Transaction
+	if err := r.db.GetContext(ctx, &row, repoStmtGetTransaction, id); err != nil {
+		return models.Transaction{}, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return row.toModel(), nil
+}
+
+func (r *CoinRepo) SaveItem(ctx context.Context, item models.Item) error {
+	if _, err := r.db.ExecContext(ctx, repoStmtSaveItem, item.Name, item.Price); err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	return nil
+}
+
+func (r *CoinRepo) DeleteItem(ctx context.Context, name string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, repoStmtDeleteItem, name)
+	if err != nil {
+		return false, fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	return affected > 0, nil
+}
+
+func (r *CoinRepo) VerifyLedger(ctx context.Context) ([]models.LedgerMismatch, error) {
+	var rows []LedgerMismatch
+	if err := r.db.SelectContext(ctx, &rows, repoStmtVerifyLedger); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+
+	mismatches := make([]models.LedgerMismatch, len(rows))
+	for i, row := range rows {
+		mismatches[i] = models.LedgerMismatch(row)
+	}
+	return mismatches, nil
+}
@@ -0,0 +1,83 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/repo"
+)
+
+const repoStmtUpdatePassword = `
+update users
+set password_hash = $2, password_algorithm = $3
+where username = $1
+`
+
+// Issuing a reset token revokes the unused ones issued before it.
+const repoStmtCreatePasswordReset = `
+with revoked as (
+    delete from password_reset_tokens
+    where username = $1 and used_at is null
+)
+insert into password_reset_tokens (username, token_hash, created_by, expires_at)
+values ($1, $2, $3, $4)
+`
+
+// The token is used up and the password replaced in one statement, so a
+// token can't be redeemed twice by concurrent requests.
+const repoStmtResetPassword = `
+with token as (
+    update password_reset_tokens
+    set used_at = now()
+    where token_hash = $1 and used_at is null and expires_at > now()
+    returning username
+)
+update users
+set password_hash = $2, password_algorithm = $3
+from token
+where users.username = token.username
+returning users.username
+`
+
+func (r *CoinRepo) UpdatePassword(ctx context.Context, params repo.UpdatePasswordParams) error {
+	if _, err := r.db.ExecContext(
+		ctx,
+		repoStmtUpdatePassword,
+		params.Username,
+		params.PassHash,
+		params.PassAlgorithm,
+	); err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	return nil
+}
+
+func (r *CoinRepo) CreatePasswordReset(ctx context.Context, params repo.CreatePasswordResetParams) error {
+	if _, err := r.db.ExecContext(
+		ctx,
+		repoStmtCreatePasswordReset,
+		params.Username,
+		params.TokenHash,
+		params.CreatedBy,
+		params.ExpiresAt,
+	); err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	return nil
+}
+
+// ResetPassword returns the username whose password was reset, or
+// sql.ErrNoRows if the token is unknown, used or expired.
+func (r *CoinRepo) ResetPassword(ctx context.Context, params repo.ResetPasswordParams) (string, error) {
+	var username string
+	if err := r.db.GetContext(
+		ctx,
+		&username,
+		repoStmtResetPassword,
+		params.TokenHash,
+		params.PassHash,
+		params.PassAlgorithm,
+	); err != nil {
+		return "", fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return username, nil
+}
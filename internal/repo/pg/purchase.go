@@ -2,9 +2,9 @@ package pg
 
 import (
 	"context"
-	"errors"
 	"github.com/Blxssy/AvitoTest/internal/models"
 	"github.com/Blxssy/AvitoTest/internal/repo"
+	"github.com/jmoiron/sqlx"
 )
 
 type Purchase struct {
@@ -36,6 +36,13 @@ FROM items
 WHERE name = $1
 `
 
+const repoStmtListItems = `
+SELECT name, price
+FROM items
+WHERE $1::text[] IS NULL OR name = ANY($1)
+ORDER BY price, name
+`
+
 func (r *CoinRepo) GetPurchases(ctx context.Context, username string) ([]models.PurchaseItem, error) {
 	rows, err := r.db.QueryxContext(ctx, repoStmtGetPurchases, username)
 	if err != nil {
@@ -55,40 +62,34 @@ func (r *CoinRepo) GetPurchases(ctx context.Context, username string) ([]models.
 	return purchases, nil
 }
 
-func (r *CoinRepo) BuyItem(ctx context.Context, params repo.BuyItemParams) error {
-	tx, err := r.db.BeginTxx(ctx, nil)
+func (r *CoinRepo) BuyItem(ctx context.Context, tx *sqlx.Tx, params repo.BuyItemParams) (int, error) {
+	var balance int
+	var frozen bool
+	err := tx.QueryRowContext(ctx, "SELECT balance, frozen_at IS NOT NULL FROM users WHERE username=$1 FOR UPDATE", params.Username).Scan(&balance, &frozen)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	defer func() {
-		if err = tx.Rollback(); err != nil {
-			return
-		}
-	}()
-
-	var balance int
-	err = tx.QueryRowContext(ctx, "SELECT balance FROM users WHERE username=$1", params.Username).Scan(&balance)
-	if err != nil {
-		return err
+	if frozen {
+		return 0, repo.AccountFrozenError
 	}
 
 	if balance < params.Price {
-		return errors.New("insufficient funds")
+		return 0, repo.InsufficientFundsError
 	}
 
-	_, err = tx.ExecContext(ctx, "UPDATE users SET balance = balance - $1 WHERE username = $2", params.Price, params.Username)
+	err = tx.QueryRowContext(ctx, "UPDATE users SET balance = balance - $1 WHERE username = $2 RETURNING balance", params.Price, params.Username).Scan(&balance)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	_, err = tx.ExecContext(ctx, repoStmtBuyItem,
 		params.Username, params.Item, params.Price)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	return tx.Commit()
+	return balance, nil
 }
 
 func (r *CoinRepo) GetItem(ctx context.Context, itemName string) (models.Item, error) {
@@ -100,3 +101,12 @@ func (r *CoinRepo) GetItem(ctx context.Context, itemName string) (models.Item, e
 
 	return item, nil
 }
+
+func (r *CoinRepo) GetItems(ctx context.Context, params repo.GetItemsParams) ([]models.Item, error) {
+	var items []models.Item
+	if err := r.db.SelectContext(ctx, &items, repoStmtListItems, params.Names); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
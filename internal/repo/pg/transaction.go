@@ -14,40 +14,60 @@ type Transaction struct {
 	ReceiverUsername string    `db:"receiver_username"`
 	Amount           int       `db:"amount"`
 	CreatedAt        time.Time `db:"created_at"`
+	ActorUsername    *string   `db:"actor_username"`
+}
+
+func (t Transaction) toModel() models.Transaction {
+	transaction := models.Transaction{
+		ID:               t.ID,
+		SenderUsername:   t.SenderUsername,
+		ReceiverUsername: t.ReceiverUsername,
+		Amount:           t.Amount,
+		CreatedAt:        t.CreatedAt,
+	}
+	if t.ActorUsername != nil {
+		transaction.ActorUsername = *t.ActorUsername
+	}
+	return transaction
 }
 
 const repoStmtSaveTransaction = `
 insert into
 transactions
-(sender_username, receiver_username, amount)
-values ($1, $2, $3);
+(sender_username, receiver_username, amount, actor_username)
+values ($1, $2, $3, nullif($4, ''))
+returning id;
 `
 
 const repoStmtGetTransactions = `
 select *
 from transactions
 where sender_username = $1
+order by id
 `
 
 const repoStmtReceivedCoins = `
 select *
 from transactions
 where receiver_username = $1
+order by id
 `
 
-func (r *CoinRepo) SaveTransaction(ctx context.Context, params repo.SaveTransactionParams) error {
-	_, err := r.db.ExecContext(
+func (r *CoinRepo) SaveTransaction(ctx context.Context, tx *sqlx.Tx, params repo.SaveTransactionParams) (int64, error) {
+	var id int64
+	err := tx.QueryRowContext(
 		ctx,
 		repoStmtSaveTransaction,
 		params.SenderUsername,
 		params.ReceiverUsername,
 		params.Amount,
-	)
+		params.ActorUsername,
+	).Scan(&id)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	return nil
+	return id, nil
 }
 
 func (r *CoinRepo) GetTransactions(ctx context.Context, username string) ([]models.Transaction, error) {
@@ -73,13 +93,7 @@ func (r *CoinRepo) GetTransactions(ctx context.Context, username string) ([]mode
 			return nil, err
 		}
 
-		transactions = append(transactions, models.Transaction{
-			ID:               transaction.ID,
-			SenderUsername:   transaction.SenderUsername,
-			ReceiverUsername: transaction.ReceiverUsername,
-			Amount:           transaction.Amount,
-			CreatedAt:        transaction.CreatedAt,
-		})
+		transactions = append(transactions, transaction.toModel())
 	}
 
 	return transactions, nil
@@ -108,13 +122,7 @@ func (r *CoinRepo) ReceivedCoinsInfo(ctx context.Context, username string) ([]mo
 			return nil, err
 		}
 
-		transactions = append(transactions, models.Transaction{
-			ID:               transaction.ID,
-			SenderUsername:   transaction.SenderUsername,
-			ReceiverUsername: transaction.ReceiverUsername,
-			Amount:           transaction.Amount,
-			CreatedAt:        transaction.CreatedAt,
-		})
+		transactions = append(transactions, transaction.toModel())
 	}
 
 	return transactions, nil
@@ -0,0 +1,195 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/repo"
+)
+
+type TwoFactorPolicy struct {
+	TransferThreshold int `db:"transfer_threshold"`
+	PurchaseThreshold int `db:"purchase_threshold"`
+}
+
+// A new secret replaces a pending enrollment but never an active one.
+const repoStmtSetTOTPSecret = `
+update users
+set totp_secret = $2, totp_last_counter = 0
+where username = $1 and not totp_enabled
+`
+
+const repoStmtEnableTOTP = `
+with enabled as (
+    update users
+    set totp_enabled = true
+    where username = $1
+    returning username
+), cleared as (
+    delete from totp_recovery_codes
+    where username = $1
+)
+insert into totp_recovery_codes (username, code_hash)
+select enabled.username, code_hash
+from enabled, unnest($2::text[]) as code_hash
+`
+
+const repoStmtDisableTOTP = `
+with cleared as (
+    delete from totp_recovery_codes
+    where username = $1
+)
+update users
+set totp_secret = '', totp_enabled = false, totp_last_counter = 0
+where username = $1
+`
+
+const repoStmtUseTOTPCounter = `
+update users
+set totp_last_counter = $2
+where username = $1 and totp_last_counter < $2
+`
+
+const repoStmtUseRecoveryCode = `
+update totp_recovery_codes
+set used_at = now()
+where username = $1 and code_hash = $2 and used_at is null
+`
+
+// Expired challenges are removed whenever a new one is created.
+const repoStmtCreateAuthChallenge = `
+with expired as (
+    delete from auth_challenges
+    where expires_at < now()
+)
+insert into auth_challenges (username, token_hash, expires_at)
+values ($1, $2, $3)
+`
+
+const repoStmtGetAuthChallenge = `
+select username
+from auth_challenges
+where token_hash = $1 and expires_at > now()
+`
+
+const repoStmtDeleteAuthChallenge = `
+delete from auth_challenges
+where token_hash = $1
+`
+
+const repoStmtGetTwoFactorPolicy = `
+select transfer_threshold, purchase_threshold
+from two_factor_policy
+`
+
+const repoStmtSetTwoFactorPolicy = `
+update two_factor_policy
+set transfer_threshold = $1, purchase_threshold = $2
+`
+
+func (r *CoinRepo) SetTOTPSecret(ctx context.Context, params repo.SetTOTPSecretParams) error {
+	if _, err := r.db.ExecContext(ctx, repoStmtSetTOTPSecret, params.Username, params.Secret); err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	return nil
+}
+
+func (r *CoinRepo) EnableTOTP(ctx context.Context, params repo.EnableTOTPParams) error {
+	if _, err := r.db.ExecContext(ctx, repoStmtEnableTOTP, params.Username, params.RecoveryCodeHashes); err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	return nil
+}
+
+func (r *CoinRepo) DisableTOTP(ctx context.Context, username string) error {
+	if _, err := r.db.ExecContext(ctx, repoStmtDisableTOTP, username); err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	return nil
+}
+
+// UseTOTPCounter reports false if a code from this or a later time step was
+// already accepted.
+func (r *CoinRepo) UseTOTPCounter(ctx context.Context, params repo.UseTOTPCounterParams) (bool, error) {
+	res, err := r.db.ExecContext(ctx, repoStmtUseTOTPCounter, params.Username, params.Counter)
+	if err != nil {
+		return false, fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// UseRecoveryCode reports false if the code is unknown or already used.
+func (r *CoinRepo) UseRecoveryCode(ctx context.Context, params repo.UseRecoveryCodeParams) (bool, error) {
+	res, err := r.db.ExecContext(ctx, repoStmtUseRecoveryCode, params.Username, params.CodeHash)
+	if err != nil {
+		return false, fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	return affected > 0, nil
+}
+
+func (r *CoinRepo) CreateAuthChallenge(ctx context.Context, params repo.CreateAuthChallengeParams) error {
+	if _, err := r.db.ExecContext(
+		ctx,
+		repoStmtCreateAuthChallenge,
+		params.Username,
+		params.TokenHash,
+		params.ExpiresAt,
+	); err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	return nil
+}
+
+// GetAuthChallenge returns the user a challenge was issued to, or
+// sql.ErrNoRows if it is unknown or expired.
+func (r *CoinRepo) GetAuthChallenge(ctx context.Context, tokenHash string) (string, error) {
+	var username string
+	if err := r.db.GetContext(ctx, &username, repoStmtGetAuthChallenge, tokenHash); err != nil {
+		return "", fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return username, nil
+}
+
+// DeleteAuthChallenge reports false if the challenge was already used.
+func (r *CoinRepo) DeleteAuthChallenge(ctx context.Context, tokenHash string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, repoStmtDeleteAuthChallenge, tokenHash)
+	if err != nil {
+		return false, fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	return affected > 0, nil
+}
+
+func (r *CoinRepo) GetTwoFactorPolicy(ctx context.Context) (models.TwoFactorPolicy, error) {
+	var policy TwoFactorPolicy
+	if err := r.db.GetContext(ctx, &policy, repoStmtGetTwoFactorPolicy); err != nil {
+		return models.TwoFactorPolicy{}, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return models.TwoFactorPolicy(policy), nil
+}
+
+func (r *CoinRepo) SetTwoFactorPolicy(ctx context.Context, policy models.TwoFactorPolicy) error {
+	if _, err := r.db.ExecContext(
+		ctx,
+		repoStmtSetTwoFactorPolicy,
+		policy.TransferThreshold,
+		policy.PurchaseThreshold,
+	); err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	return nil
+}
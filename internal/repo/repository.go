@@ -2,23 +2,143 @@ package repo
 
 import (
 	"context"
+	"errors"
 	"github.com/Blxssy/AvitoTest/internal/models"
 	"github.com/jmoiron/sqlx"
 )
 
+// InsufficientFundsError is returned when a purchase would make the balance negative.
+var InsufficientFundsError = errors.New("insufficient funds")
+
+// AccountFrozenError is returned when coins would leave a frozen account.
+var AccountFrozenError = errors.New("account frozen")
+
 type CoinRepository interface {
+	NotificationRepository
+	LoginAttemptRepository
+	TwoFactorRepository
+	APIKeyRepository
+	DelegationRepository
+	OIDCRepository
+	FeatureFlagRepository
+
 	GetBalance(ctx context.Context, params GetBalanceParams) (int, error)
 	CreateUser(ctx context.Context, params CreateUserParams) error
+	UpdatePassword(ctx context.Context, params UpdatePasswordParams) error
+	CreatePasswordReset(ctx context.Context, params CreatePasswordResetParams) error
+	ResetPassword(ctx context.Context, params ResetPasswordParams) (string, error)
 	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
 	BeginTx(ctx context.Context) (*sqlx.Tx, error)
-	DecreaseBalance(ctx context.Context, tx *sqlx.Tx, params ChangeBalanceParams) error
-	IncreaseBalance(ctx context.Context, tx *sqlx.Tx, params ChangeBalanceParams) error
-	SaveTransaction(ctx context.Context, params SaveTransactionParams) error
+	// DecreaseBalance returns InsufficientFundsError if the balance would
+	// become negative and AccountFrozenError if the account is frozen.
+	DecreaseBalance(ctx context.Context, tx *sqlx.Tx, params ChangeBalanceParams) (int, error)
+	IncreaseBalance(ctx context.Context, tx *sqlx.Tx, params ChangeBalanceParams) (int, error)
+	// SaveTransaction returns the ID of the new transaction.
+	SaveTransaction(ctx context.Context, tx *sqlx.Tx, params SaveTransactionParams) (int64, error)
 	GetTransactions(ctx context.Context, username string) ([]models.Transaction, error)
+	GetTransaction(ctx context.Context, id int64) (models.Transaction, error)
 	ReceivedCoinsInfo(ctx context.Context, username string) ([]models.Transaction, error)
 	GetPurchases(ctx context.Context, username string) ([]models.PurchaseItem, error)
-	BuyItem(ctx context.Context, params BuyItemParams) error
+	// BuyItem returns InsufficientFundsError or AccountFrozenError if the
+	// user can't pay.
+	BuyItem(ctx context.Context, tx *sqlx.Tx, params BuyItemParams) (int, error)
 	GetItem(ctx context.Context, itemName string) (models.Item, error)
+	GetItems(ctx context.Context, params GetItemsParams) ([]models.Item, error)
+	SaveEvent(ctx context.Context, tx *sqlx.Tx, params SaveEventParams) error
+	GetEvents(ctx context.Context, params GetEventsParams) ([]models.Event, error)
 	CommitTx(tx *sqlx.Tx) error
 	RollbackTx(tx *sqlx.Tx) error
 }
+
+type NotificationRepository interface {
+	CreateNotification(ctx context.Context, tx *sqlx.Tx, params CreateNotificationParams) error
+	GetNotifications(ctx context.Context, params GetNotificationsParams) ([]models.Notification, error)
+	CountUnreadNotifications(ctx context.Context, username string) (int, error)
+	MarkNotificationRead(ctx context.Context, params MarkNotificationReadParams) error
+	MarkAllNotificationsRead(ctx context.Context, username string) (int64, error)
+	GetNotificationPreferences(ctx context.Context, username string) ([]models.NotificationPreference, error)
+	SetNotificationPreference(ctx context.Context, tx *sqlx.Tx, params SetNotificationPreferenceParams) error
+	SetNotificationContact(ctx context.Context, params SetNotificationContactParams) error
+	ClaimNotificationDeliveries(ctx context.Context, params ClaimNotificationDeliveriesParams) ([]models.NotificationDelivery, error)
+	CompleteNotificationDelivery(ctx context.Context, id int64) error
+	FailNotificationDelivery(ctx context.Context, params FailNotificationDeliveryParams) error
+}
+
+// LoginAttemptRepository keeps failed login counters so lockouts survive restarts.
+type LoginAttemptRepository interface {
+	GetLoginAttempts(ctx context.Context, keys []string) ([]models.LoginAttempt, error)
+	RecordLoginFailure(ctx context.Context, params RecordLoginFailureParams) (models.LoginAttempt, error)
+	ResetLoginAttempts(ctx context.Context, keys []string) error
+}
+
+type TwoFactorRepository interface {
+	SetTOTPSecret(ctx context.Context, params SetTOTPSecretParams) error
+	EnableTOTP(ctx context.Context, params EnableTOTPParams) error
+	DisableTOTP(ctx context.Context, username string) error
+	UseTOTPCounter(ctx context.Context, params UseTOTPCounterParams) (bool, error)
+	UseRecoveryCode(ctx context.Context, params UseRecoveryCodeParams) (bool, error)
+	CreateAuthChallenge(ctx context.Context, params CreateAuthChallengeParams) error
+	GetAuthChallenge(ctx context.Context, tokenHash string) (string, error)
+	DeleteAuthChallenge(ctx context.Context, tokenHash string) (bool, error)
+	GetTwoFactorPolicy(ctx context.Context) (models.TwoFactorPolicy, error)
+	SetTwoFactorPolicy(ctx context.Context, policy models.TwoFactorPolicy) error
+}
+
+// APIKeyRepository keeps service accounts and their API keys.
+type APIKeyRepository interface {
+	CreateServiceAccount(ctx context.Context, params CreateServiceAccountParams) (bool, error)
+	CreateAPIKey(ctx context.Context, params CreateAPIKeyParams) error
+	GetAPIKey(ctx context.Context, id string) (models.APIKey, error)
+	ListAPIKeys(ctx context.Context, username string) ([]models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string) (bool, error)
+}
+
+// DelegationRepository keeps the consents users give clients to send coins
+// on their behalf.
+type DelegationRepository interface {
+	GrantDelegation(ctx context.Context, params GrantDelegationParams) (models.Delegation, error)
+	ListDelegations(ctx context.Context, ownerUsername string) ([]models.Delegation, error)
+	RevokeDelegation(ctx context.Context, params RevokeDelegationParams) (bool, error)
+	LockDelegation(ctx context.Context, tx *sqlx.Tx, params LockDelegationParams) (models.Delegation, error)
+}
+
+// OIDCRepository keeps logins through an external identity provider.
+type OIDCRepository interface {
+	CreateOIDCLoginState(ctx context.Context, params CreateOIDCLoginStateParams) error
+	UseOIDCLoginState(ctx context.Context, stateHash string) (models.OIDCLoginState, error)
+	GetOIDCIdentity(ctx context.Context, params GetOIDCIdentityParams) (string, error)
+	// LinkOIDCIdentity links an identity to a user, registering the user if
+	// needed. It returns sql.ErrNoRows if the user has a password or is a
+	// service account.
+	LinkOIDCIdentity(ctx context.Context, params LinkOIDCIdentityParams) error
+}
+
+// FeatureFlagRepository keeps the flags that roll features out to users.
+type FeatureFlagRepository interface {
+	GetFeatureFlags(ctx context.Context) ([]models.FeatureFlag, error)
+	// SaveFeatureFlag creates the flag or replaces its settings.
+	SaveFeatureFlag(ctx context.Context, params SaveFeatureFlagParams) (models.FeatureFlag, error)
+	// DeleteFeatureFlag reports false if there is no flag with name.
+	DeleteFeatureFlag(ctx context.Context, name string) (bool, error)
+}
+
+// OperatorRepository backs maintenance done by operators with coinctl,
+// which has no API.
+type OperatorRepository interface {
+	CoinRepository
+
+	// AdjustBalance changes a balance by params.Amount and records why. It
+	// returns InsufficientFundsError if the balance would become negative.
+	AdjustBalance(ctx context.Context, tx *sqlx.Tx, params AdjustBalanceParams) (int, error)
+	// SetUserFrozen reports false if there is no such user.
+	SetUserFrozen(ctx context.Context, params SetUserFrozenParams) (bool, error)
+	ListTransactions(ctx context.Context, params ListTransactionsParams) ([]models.Transaction, error)
+	GetBalanceAdjustments(ctx context.Context, username string) ([]models.BalanceAdjustment, error)
+	// SaveItem adds the item to the catalog or changes its price.
+	SaveItem(ctx context.Context, item models.Item) error
+	// DeleteItem reports false if there is no such item.
+	DeleteItem(ctx context.Context, name string) (bool, error)
+	// VerifyLedger returns the accounts whose balance doesn't follow from
+	// their history.
+	VerifyLedger(ctx context.Context) ([]models.LedgerMismatch, error)
+}
@@ -1,5 +1,10 @@
 package repo
 
+import (
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"time"
+)
+
 type GetBalanceParams struct {
 	Username string
 }
@@ -13,9 +18,32 @@ type GetUserByUsername struct {
 }
 
 type CreateUserParams struct {
-	Username string
-	PassHash string
-	Balance  int
+	Username      string
+	PassHash      string
+	PassAlgorithm string
+	Balance       int
+	IsAdmin       bool
+}
+
+type UpdatePasswordParams struct {
+	Username      string
+	PassHash      string
+	PassAlgorithm string
+}
+
+type CreatePasswordResetParams struct {
+	Username  string
+	TokenHash string
+	CreatedBy string
+	ExpiresAt time.Time
+}
+
+// ResetPasswordParams sets a new password for the owner of an unused,
+// unexpired reset token and uses the token up.
+type ResetPasswordParams struct {
+	TokenHash     string
+	PassHash      string
+	PassAlgorithm string
 }
 
 type ChangeBalanceParams struct {
@@ -27,6 +55,8 @@ type SaveTransactionParams struct {
 	SenderUsername   string
 	ReceiverUsername string
 	Amount           int
+	// ActorUsername is set when a client sends on the sender's behalf.
+	ActorUsername string
 }
 
 type GetTransactionsParams struct {
@@ -38,3 +68,186 @@ type BuyItemParams struct {
 	Item     string
 	Price    int
 }
+
+// GetItemsParams narrows the catalog down to Names; nil Names returns every item.
+type GetItemsParams struct {
+	Names []string
+}
+
+type SaveEventParams struct {
+	Username string
+	Type     models.EventType
+	Payload  any
+}
+
+type GetEventsParams struct {
+	Username string
+	AfterID  int64
+	Limit    int
+}
+
+type CreateNotificationParams struct {
+	Username string
+	Type     models.NotificationType
+	Payload  any
+	// Deliver queues the notification for delivery through the notifier.
+	Deliver bool
+}
+
+type GetNotificationsParams struct {
+	Username   string
+	BeforeID   int64
+	UnreadOnly bool
+	Limit      int
+}
+
+type MarkNotificationReadParams struct {
+	Username string
+	ID       int64
+}
+
+type SetNotificationPreferenceParams struct {
+	Username string
+	Type     models.NotificationType
+	Muted    bool
+}
+
+type SetNotificationContactParams struct {
+	Username string
+	Email    string
+	Locale   string
+}
+
+type ClaimNotificationDeliveriesParams struct {
+	Limit int
+	// Lease is how long claimed deliveries stay hidden from other workers.
+	Lease time.Duration
+}
+
+type FailNotificationDeliveryParams struct {
+	ID      int64
+	Error   string
+	RetryAt time.Time
+	// GiveUp marks the delivery as failed for good instead of retrying it.
+	GiveUp bool
+}
+
+type RecordLoginFailureParams struct {
+	Key string
+	// Window is how long a failure is remembered; the count restarts after it.
+	Window time.Duration
+	// LockAfter failures lock the key for LockFor; zero never locks it.
+	LockAfter int
+	LockFor   time.Duration
+}
+
+type SetTOTPSecretParams struct {
+	Username string
+	Secret   string
+}
+
+// EnableTOTPParams turns 2FA on and replaces the recovery codes.
+type EnableTOTPParams struct {
+	Username           string
+	RecoveryCodeHashes []string
+}
+
+// UseTOTPCounterParams records the time step of an accepted code so the same
+// code can't be replayed.
+type UseTOTPCounterParams struct {
+	Username string
+	Counter  int64
+}
+
+type UseRecoveryCodeParams struct {
+	Username string
+	CodeHash string
+}
+
+type CreateAuthChallengeParams struct {
+	Username  string
+	TokenHash string
+	ExpiresAt time.Time
+}
+
+type CreateServiceAccountParams struct {
+	Username string
+	Balance  int
+}
+
+type CreateAPIKeyParams struct {
+	ID         string
+	Username   string
+	SecretHash string
+	Scopes     []string
+	CreatedBy  string
+	ExpiresAt  time.Time
+}
+
+// GrantDelegationParams replaces the limit of an active delegation between
+// the same owner and client.
+type GrantDelegationParams struct {
+	OwnerUsername  string
+	ClientUsername string
+	DailyLimit     int
+}
+
+type RevokeDelegationParams struct {
+	OwnerUsername string
+	ID            int64
+}
+
+type LockDelegationParams struct {
+	OwnerUsername  string
+	ClientUsername string
+}
+
+type CreateOIDCLoginStateParams struct {
+	StateHash    string
+	Nonce        string
+	CodeVerifier string
+	ExpiresAt    time.Time
+}
+
+type GetOIDCIdentityParams struct {
+	Issuer  string
+	Subject string
+}
+
+// LinkOIDCIdentityParams links an identity to Username, creating the user
+// with Balance coins and no password if it doesn't exist.
+type LinkOIDCIdentityParams struct {
+	Issuer   string
+	Subject  string
+	Username string
+	Balance  int
+}
+
+type SaveFeatureFlagParams struct {
+	Name           string
+	Description    string
+	Enabled        bool
+	RolloutPercent int
+	AllowList      []string
+	UpdatedBy      string
+}
+
+type AdjustBalanceParams struct {
+	Username  string
+	Amount    int
+	Reason    string
+	CreatedBy string
+}
+
+type SetUserFrozenParams struct {
+	Username string
+	Frozen   bool
+}
+
+// ListTransactionsParams pages through transactions from the newest. An
+// empty Username lists everyone's; a zero BeforeID starts at the newest.
+type ListTransactionsParams struct {
+	Username string
+	BeforeID int64
+	Limit    int
+}
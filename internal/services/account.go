@@ -0,0 +1,313 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/repo"
+	"github.com/Blxssy/AvitoTest/pkg/password"
+	"github.com/Blxssy/AvitoTest/pkg/token"
+	"go.uber.org/zap"
+	"time"
+)
+
+// minPasswordLength applies to passwords set through ChangePassword and
+// ResetPassword. Auth registers users with any password for compatibility.
+const minPasswordLength = 8
+
+// AccountService manages credentials and user accounts.
+type AccountService interface {
+	// UnlockUser clears the failed login counter of a user, lifting a lockout early.
+	UnlockUser(ctx context.Context, params UnlockUserParams) error
+	ChangePassword(ctx context.Context, params ChangePasswordParams) error
+	// IssuePasswordReset lets an admin create a one-time token that sets a
+	// new password for a user. Earlier unused tokens for the user are revoked.
+	IssuePasswordReset(ctx context.Context, params IssuePasswordResetParams) (PasswordReset, error)
+	ResetPassword(ctx context.Context, params ResetPasswordParams) error
+	EnrollTOTP(ctx context.Context, params EnrollTOTPParams) (TOTPEnrollment, error)
+	ActivateTOTP(ctx context.Context, params ActivateTOTPParams) ([]string, error)
+	DisableTOTP(ctx context.Context, params DisableTOTPParams) error
+	GetTwoFactorPolicy(ctx context.Context, params GetTwoFactorPolicyParams) (models.TwoFactorPolicy, error)
+	SetTwoFactorPolicy(ctx context.Context, params SetTwoFactorPolicyParams) error
+	// CreateServiceAccount registers an account for a bot or integration. It
+	// can't log in with a password and uses API keys instead.
+	CreateServiceAccount(ctx context.Context, params CreateServiceAccountParams) error
+	// CreateAPIKey issues a scoped key for a service account. The key is
+	// returned only once.
+	CreateAPIKey(ctx context.Context, params CreateAPIKeyParams) (IssuedAPIKey, error)
+	ListAPIKeys(ctx context.Context, params ListAPIKeysParams) ([]models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, params RevokeAPIKeyParams) error
+	// GrantDelegation lets a service account send up to a daily limit of
+	// coins from the caller's account. Granting again changes the limit.
+	GrantDelegation(ctx context.Context, params GrantDelegationParams) (models.Delegation, error)
+	ListDelegations(ctx context.Context, params ListDelegationsParams) ([]models.Delegation, error)
+	RevokeDelegation(ctx context.Context, params RevokeDelegationParams) error
+	// ReloadConfig lets an admin apply configuration changes that don't need
+	// a restart.
+	ReloadConfig(ctx context.Context, params ReloadConfigParams) (ReloadedConfig, error)
+}
+
+// ConfigReloader re-reads the configuration and applies the settings that
+// can change at runtime. An invalid configuration changes nothing.
+type ConfigReloader interface {
+	Reload(ctx context.Context) (ReloadedConfig, error)
+}
+
+// PasswordReset is shown to the admin once; only a hash of Token is stored.
+type PasswordReset struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+type accountService struct {
+	repo          repo.CoinRepository
+	tokenGen      token.TokenGenerator
+	hasher        password.Hasher
+	logins        *loginGuard
+	resetTokenTTL time.Duration
+	totpIssuer    string
+	apiKeyTTL     time.Duration
+	apiKeyMaxTTL  time.Duration
+	reloader      ConfigReloader
+	logger        *zap.Logger
+}
+
+type AccountServiceConfig struct {
+	Hasher password.Hasher
+	// LoginProtection throttles guesses of the current password and of
+	// two-factor codes, sharing the counters of logins.
+	LoginProtection LoginProtectionConfig
+	ResetTokenTTL   time.Duration
+	// TOTPIssuer names the service in authenticator apps.
+	TOTPIssuer string
+	// APIKeyTTL is the lifetime of keys issued without an expiry; none may
+	// outlive APIKeyMaxTTL, zero means no limit.
+	APIKeyTTL    time.Duration
+	APIKeyMaxTTL time.Duration
+	// Reloader enables ReloadConfig.
+	Reloader ConfigReloader
+	Logger   *zap.Logger
+}
+
+func NewAccountService(repo repo.CoinRepository, tg token.TokenGenerator, cfg AccountServiceConfig) AccountService {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	return &accountService{
+		repo:          repo,
+		tokenGen:      tg,
+		hasher:        cfg.Hasher,
+		resetTokenTTL: cfg.ResetTokenTTL,
+		totpIssuer:    cfg.TOTPIssuer,
+		apiKeyTTL:     cfg.APIKeyTTL,
+		apiKeyMaxTTL:  cfg.APIKeyMaxTTL,
+		reloader:      cfg.Reloader,
+		logger:        logger,
+		logins: &loginGuard{
+			repo:   repo,
+			cfg:    cfg.LoginProtection,
+			logger: logger,
+			now:    time.Now,
+		},
+	}
+}
+
+func (s *accountService) UnlockUser(ctx context.Context, params UnlockUserParams) error {
+	adminUsername, err := authorizeAdmin(ctx, s.repo, s.tokenGen, params.Token)
+	if err != nil {
+		return err
+	}
+
+	if _, err = s.repo.GetUserByUsername(ctx, params.Username); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return UserNotFoundError
+		}
+		return fmt.Errorf("s.repo.GetUserByUsername: %w", err)
+	}
+
+	if err = s.repo.ResetLoginAttempts(ctx, []string{userLoginKey(params.Username)}); err != nil {
+		return fmt.Errorf("s.repo.ResetLoginAttempts: %w", err)
+	}
+
+	s.log(ctx).Info("login unlocked by admin",
+		zap.String("username", params.Username),
+		zap.String("admin", adminUsername),
+	)
+	return nil
+}
+
+func (s *accountService) ReloadConfig(ctx context.Context, params ReloadConfigParams) (ReloadedConfig, error) {
+	adminUsername, err := authorizeAdmin(ctx, s.repo, s.tokenGen, params.Token)
+	if err != nil {
+		return ReloadedConfig{}, err
+	}
+	if s.reloader == nil {
+		return ReloadedConfig{}, ConfigReloadDisabledError
+	}
+
+	s.log(ctx).Info("configuration reload requested by admin", zap.String("admin", adminUsername))
+	reloaded, err := s.reloader.Reload(ctx)
+	if err != nil {
+		return ReloadedConfig{}, InvalidConfigError.WithDetail(err.Error())
+	}
+	return reloaded, nil
+}
+
+func (s *accountService) ChangePassword(ctx context.Context, params ChangePasswordParams) error {
+	username, err := s.tokenGen.ParseToken(params.Token)
+	if err != nil {
+		return fmt.Errorf("s.tokenGen.ParseToken: %w: %w", InvalidTokenError, err)
+	}
+
+	if len(params.NewPassword) < minPasswordLength {
+		return PasswordTooShortError
+	}
+
+	user, err := s.repo.GetUserByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return InvalidTokenError
+		}
+		return fmt.Errorf("s.repo.GetUserByUsername: %w", err)
+	}
+	// Service accounts and users registered through SSO have no password to
+	// change, so there is nothing to guess either.
+	if user.IsServiceAccount || user.PasswordHash == "" {
+		return InvalidCurrentPasswordError
+	}
+
+	// A stolen access token must not allow unlimited guesses of the password.
+	keys := loginKeys(username, "")
+	attempts, err := s.logins.check(ctx, keys)
+	if err != nil {
+		return err
+	}
+
+	ok, err := s.hasher.Verify(params.CurrentPassword, user.PasswordHash, user.PasswordAlgorithm)
+	if err != nil {
+		return fmt.Errorf("s.hasher.Verify: %w", err)
+	}
+	if !ok {
+		if err = s.logins.fail(ctx, username, ""); err != nil {
+			return err
+		}
+		return InvalidCurrentPasswordError
+	}
+
+	passHash, algorithm, err := s.hasher.Hash(params.NewPassword)
+	if err != nil {
+		return fmt.Errorf("s.hasher.Hash: %w", err)
+	}
+
+	if err = s.repo.UpdatePassword(ctx, repo.UpdatePasswordParams{
+		Username:      username,
+		PassHash:      passHash,
+		PassAlgorithm: algorithm,
+	}); err != nil {
+		return fmt.Errorf("s.repo.UpdatePassword: %w", err)
+	}
+
+	if len(attempts) > 0 {
+		if err = s.logins.reset(ctx, keys); err != nil {
+			return err
+		}
+	}
+
+	s.log(ctx).Info("password changed", zap.String("username", username))
+	return nil
+}
+
+func (s *accountService) IssuePasswordReset(ctx context.Context, params IssuePasswordResetParams) (PasswordReset, error) {
+	adminUsername, err := authorizeAdmin(ctx, s.repo, s.tokenGen, params.Token)
+	if err != nil {
+		return PasswordReset{}, err
+	}
+
+	if _, err = s.repo.GetUserByUsername(ctx, params.Username); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return PasswordReset{}, UserNotFoundError
+		}
+		return PasswordReset{}, fmt.Errorf("s.repo.GetUserByUsername: %w", err)
+	}
+
+	resetToken, err := newRandomToken()
+	if err != nil {
+		return PasswordReset{}, err
+	}
+	reset := PasswordReset{
+		Token:     resetToken,
+		ExpiresAt: time.Now().Add(s.resetTokenTTL),
+	}
+
+	if err = s.repo.CreatePasswordReset(ctx, repo.CreatePasswordResetParams{
+		Username:  params.Username,
+		TokenHash: hashToken(reset.Token),
+		CreatedBy: adminUsername,
+		ExpiresAt: reset.ExpiresAt,
+	}); err != nil {
+		return PasswordReset{}, fmt.Errorf("s.repo.CreatePasswordReset: %w", err)
+	}
+
+	s.log(ctx).Info("password reset issued",
+		zap.String("username", params.Username),
+		zap.String("admin", adminUsername),
+		zap.Time("expires_at", reset.ExpiresAt),
+	)
+	return reset, nil
+}
+
+func (s *accountService) ResetPassword(ctx context.Context, params ResetPasswordParams) error {
+	if len(params.NewPassword) < minPasswordLength {
+		return PasswordTooShortError
+	}
+
+	passHash, algorithm, err := s.hasher.Hash(params.NewPassword)
+	if err != nil {
+		return fmt.Errorf("s.hasher.Hash: %w", err)
+	}
+
+	username, err := s.repo.ResetPassword(ctx, repo.ResetPasswordParams{
+		TokenHash:     hashToken(params.ResetToken),
+		PassHash:      passHash,
+		PassAlgorithm: algorithm,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return InvalidResetTokenError
+		}
+		return fmt.Errorf("s.repo.ResetPassword: %w", err)
+	}
+
+	// A new password makes earlier guesses irrelevant.
+	if err = s.repo.ResetLoginAttempts(ctx, []string{userLoginKey(username)}); err != nil {
+		return fmt.Errorf("s.repo.ResetLoginAttempts: %w", err)
+	}
+
+	s.log(ctx).Info("password reset", zap.String("username", username))
+	return nil
+}
+
+// newRandomToken returns a random URL-safe token for one-time use.
+func newRandomToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("rand.Read: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// hashToken is what the repository stores for one-time tokens and codes, so a
+// leaked table can't be used to sign in. They are random, so a plain hash is
+// enough.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/repo"
+	"github.com/Blxssy/AvitoTest/pkg/logger"
+	"github.com/Blxssy/AvitoTest/pkg/token"
+)
+
+// authorizeAdmin returns the username behind token if that user is an admin.
+func authorizeAdmin(ctx context.Context, r repo.CoinRepository, tg token.TokenGenerator, accessToken string) (string, error) {
+	username, err := tg.ParseToken(accessToken)
+	if err != nil {
+		return "", fmt.Errorf("tg.ParseToken: %w: %w", InvalidTokenError, err)
+	}
+	logger.SetUsername(ctx, username)
+
+	user, err := r.GetUserByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", InvalidTokenError
+		}
+		return "", fmt.Errorf("r.GetUserByUsername: %w", err)
+	}
+
+	if !user.IsAdmin {
+		return "", AdminRequiredError
+	}
+
+	return username, nil
+}
@@ -0,0 +1,345 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/repo"
+	"github.com/Blxssy/AvitoTest/pkg/logger"
+	"github.com/Blxssy/AvitoTest/pkg/token"
+	"go.uber.org/zap"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scope is a permission an API key can be granted. Access tokens issued by
+// Auth have every scope.
+type Scope string
+
+const (
+	ScopeBalanceRead        Scope = "balance:read"
+	ScopeTransfersRead      Scope = "transfers:read"
+	ScopeTransfersWrite     Scope = "transfers:write"
+	ScopePurchasesRead      Scope = "purchases:read"
+	ScopePurchasesWrite     Scope = "purchases:write"
+	ScopeEventsRead         Scope = "events:read"
+	ScopeNotificationsRead  Scope = "notifications:read"
+	ScopeNotificationsWrite Scope = "notifications:write"
+
+	// scopeAny is required by operations any valid credential may call, such
+	// as reading the catalog.
+	scopeAny Scope = ""
+)
+
+// Scopes lists the scopes API keys can be issued with.
+var Scopes = []Scope{
+	ScopeBalanceRead,
+	ScopeTransfersRead,
+	ScopeTransfersWrite,
+	ScopePurchasesRead,
+	ScopePurchasesWrite,
+	ScopeEventsRead,
+	ScopeNotificationsRead,
+	ScopeNotificationsWrite,
+}
+
+// APIKeyPrefix starts every API key, so keys are easy to tell from access
+// tokens and to find by secret scanners. The key ID follows it:
+// avk_<id>_<secret>.
+const APIKeyPrefix = "avk_"
+
+const apiKeyIDLength = 16
+
+// Authenticator tells who a credential belongs to: an access token issued by
+// Auth or an API key of a service account.
+type Authenticator interface {
+	// Authenticate returns the username behind credential. API keys must
+	// have scope; an empty scope accepts any valid credential.
+	Authenticate(ctx context.Context, credential string, scope Scope) (string, error)
+}
+
+type authenticator struct {
+	repo     repo.CoinRepository
+	tokenGen token.TokenGenerator
+}
+
+func NewAuthenticator(repo repo.CoinRepository, tg token.TokenGenerator) Authenticator {
+	return &authenticator{
+		repo:     repo,
+		tokenGen: tg,
+	}
+}
+
+func (a *authenticator) Authenticate(ctx context.Context, credential string, scope Scope) (string, error) {
+	cache, _ := ctx.Value(credentialCacheKey{}).(*credentialCache)
+	identity, ok := cache.get(credential)
+	if !ok {
+		var err error
+		if identity, err = a.resolve(ctx, credential); err != nil {
+			return "", err
+		}
+		cache.put(credential, identity)
+	}
+
+	logger.SetUsername(ctx, identity.username)
+	if identity.apiKey && scope != scopeAny && !slices.Contains(identity.scopes, string(scope)) {
+		return "", InsufficientScopeError
+	}
+
+	return identity.username, nil
+}
+
+func (a *authenticator) resolve(ctx context.Context, credential string) (identity, error) {
+	if !IsAPIKey(credential) {
+		username, err := a.tokenGen.ParseToken(credential)
+		if err != nil {
+			return identity{}, fmt.Errorf("a.tokenGen.ParseToken: %w: %w", InvalidTokenError, err)
+		}
+		return identity{username: username}, nil
+	}
+
+	id, secret, ok := splitAPIKey(credential)
+	if !ok {
+		return identity{}, InvalidTokenError
+	}
+
+	key, err := a.repo.GetAPIKey(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return identity{}, InvalidTokenError
+		}
+		return identity{}, fmt.Errorf("a.repo.GetAPIKey: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashToken(secret)), []byte(key.SecretHash)) != 1 {
+		return identity{}, InvalidTokenError
+	}
+	if key.RevokedAt != nil || !time.Now().Before(key.ExpiresAt) {
+		return identity{}, InvalidTokenError
+	}
+
+	return identity{username: key.Username, scopes: key.Scopes, apiKey: true}, nil
+}
+
+// identity is what a valid credential resolves to.
+type identity struct {
+	username string
+	scopes   []string
+	apiKey   bool
+}
+
+type credentialCacheKey struct{}
+
+type credentialCache struct {
+	mu         sync.Mutex
+	identities map[string]identity
+}
+
+// WithCredentialCache returns a context in which Authenticate resolves every
+// credential once, so the rate limiter and the handler of one request don't
+// both parse the token or look up the API key. Scopes are still checked on
+// every call; failures are not cached.
+func WithCredentialCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, credentialCacheKey{}, &credentialCache{identities: make(map[string]identity)})
+}
+
+func (c *credentialCache) get(credential string) (identity, bool) {
+	if c == nil {
+		return identity{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id, ok := c.identities[credential]
+	return id, ok
+}
+
+func (c *credentialCache) put(credential string, id identity) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.identities[credential] = id
+}
+
+// IsAPIKey reports whether credential is an API key rather than an access
+// token.
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
+
+func splitAPIKey(key string) (string, string, bool) {
+	id, secret, ok := strings.Cut(strings.TrimPrefix(key, APIKeyPrefix), "_")
+	if !ok || len(id) != apiKeyIDLength || secret == "" {
+		return "", "", false
+	}
+	return id, secret, true
+}
+
+func newAPIKey() (id, key string, err error) {
+	raw := make([]byte, apiKeyIDLength/2)
+	if _, err = rand.Read(raw); err != nil {
+		return "", "", fmt.Errorf("rand.Read: %w", err)
+	}
+	id = hex.EncodeToString(raw)
+
+	secret, err := newRandomToken()
+	if err != nil {
+		return "", "", err
+	}
+	return id, APIKeyPrefix + id + "_" + secret, nil
+}
+
+// IssuedAPIKey is shown to the admin once; only a hash of the secret is stored.
+type IssuedAPIKey struct {
+	Key    string
+	APIKey models.APIKey
+}
+
+func (s *accountService) CreateServiceAccount(ctx context.Context, params CreateServiceAccountParams) error {
+	adminUsername, err := authorizeAdmin(ctx, s.repo, s.tokenGen, params.Token)
+	if err != nil {
+		return err
+	}
+
+	if params.Username == "" || IsAPIKey(params.Username) {
+		return InvalidUsernameError
+	}
+	if params.Balance < 0 {
+		return InvalidBalanceError
+	}
+
+	created, err := s.repo.CreateServiceAccount(ctx, repo.CreateServiceAccountParams{
+		Username: params.Username,
+		Balance:  params.Balance,
+	})
+	if err != nil {
+		return fmt.Errorf("s.repo.CreateServiceAccount: %w", err)
+	}
+	if !created {
+		return UsernameTakenError
+	}
+
+	s.log(ctx).Info("service account created",
+		zap.String("admin", adminUsername),
+		zap.String("username", params.Username),
+	)
+	return nil
+}
+
+func (s *accountService) CreateAPIKey(ctx context.Context, params CreateAPIKeyParams) (IssuedAPIKey, error) {
+	adminUsername, err := authorizeAdmin(ctx, s.repo, s.tokenGen, params.Token)
+	if err != nil {
+		return IssuedAPIKey{}, err
+	}
+
+	if len(params.Scopes) == 0 {
+		return IssuedAPIKey{}, InvalidScopeError
+	}
+	for _, scope := range params.Scopes {
+		if !slices.Contains(Scopes, scope) {
+			return IssuedAPIKey{}, InvalidScopeError
+		}
+	}
+
+	now := time.Now()
+	expiresAt := params.ExpiresAt
+	if expiresAt.IsZero() {
+		expiresAt = now.Add(s.apiKeyTTL)
+	}
+	if !expiresAt.After(now) || (s.apiKeyMaxTTL > 0 && expiresAt.After(now.Add(s.apiKeyMaxTTL))) {
+		return IssuedAPIKey{}, InvalidExpiryError
+	}
+
+	user, err := s.repo.GetUserByUsername(ctx, params.Username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return IssuedAPIKey{}, UserNotFoundError
+		}
+		return IssuedAPIKey{}, fmt.Errorf("s.repo.GetUserByUsername: %w", err)
+	}
+	// Keys of people would bypass their password and second factor.
+	if !user.IsServiceAccount {
+		return IssuedAPIKey{}, ServiceAccountRequiredError
+	}
+
+	id, key, err := newAPIKey()
+	if err != nil {
+		return IssuedAPIKey{}, err
+	}
+
+	scopes := make([]string, len(params.Scopes))
+	for i, scope := range params.Scopes {
+		scopes[i] = string(scope)
+	}
+	slices.Sort(scopes)
+	scopes = slices.Compact(scopes)
+
+	_, secret, _ := splitAPIKey(key)
+	if err = s.repo.CreateAPIKey(ctx, repo.CreateAPIKeyParams{
+		ID:         id,
+		Username:   user.Username,
+		SecretHash: hashToken(secret),
+		Scopes:     scopes,
+		CreatedBy:  adminUsername,
+		ExpiresAt:  expiresAt,
+	}); err != nil {
+		return IssuedAPIKey{}, fmt.Errorf("s.repo.CreateAPIKey: %w", err)
+	}
+
+	s.log(ctx).Info("api key issued",
+		zap.String("admin", adminUsername),
+		zap.String("username", user.Username),
+		zap.String("key_id", id),
+		zap.Strings("scopes", scopes),
+	)
+
+	return IssuedAPIKey{
+		Key: key,
+		APIKey: models.APIKey{
+			ID:        id,
+			Username:  user.Username,
+			Scopes:    scopes,
+			CreatedBy: adminUsername,
+			CreatedAt: now,
+			ExpiresAt: expiresAt,
+		},
+	}, nil
+}
+
+func (s *accountService) ListAPIKeys(ctx context.Context, params ListAPIKeysParams) ([]models.APIKey, error) {
+	if _, err := authorizeAdmin(ctx, s.repo, s.tokenGen, params.Token); err != nil {
+		return nil, err
+	}
+
+	keys, err := s.repo.ListAPIKeys(ctx, params.Username)
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.ListAPIKeys: %w", err)
+	}
+	return keys, nil
+}
+
+func (s *accountService) RevokeAPIKey(ctx context.Context, params RevokeAPIKeyParams) error {
+	adminUsername, err := authorizeAdmin(ctx, s.repo, s.tokenGen, params.Token)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := s.repo.RevokeAPIKey(ctx, params.ID)
+	if err != nil {
+		return fmt.Errorf("s.repo.RevokeAPIKey: %w", err)
+	}
+	if !revoked {
+		return APIKeyNotFoundError
+	}
+
+	s.log(ctx).Info("api key revoked", zap.String("admin", adminUsername), zap.String("key_id", params.ID))
+	return nil
+}
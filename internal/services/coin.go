@@ -7,38 +7,131 @@ import (
 	"fmt"
 	"github.com/Blxssy/AvitoTest/internal/models"
 	"github.com/Blxssy/AvitoTest/internal/repo"
+	"github.com/Blxssy/AvitoTest/pkg/logger"
+	"github.com/Blxssy/AvitoTest/pkg/oidc"
+	"github.com/Blxssy/AvitoTest/pkg/password"
 	"github.com/Blxssy/AvitoTest/pkg/token"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+	"time"
+	"unicode/utf8"
 )
 
-var UnauthorizedError = errors.New("unauthorized")
+// maxMemoLength is the longest transfer memo in characters.
+const maxMemoLength = 200
 
 type CoinService interface {
 	GetBalance(ctx context.Context, params GetBalanceParams) (int, error)
-	Auth(ctx context.Context, params AuthParams) (string, error)
-	SendCoins(ctx context.Context, params TransactionParams) error
+	// Auth logs the user in, registering unknown usernames. Users with 2FA
+	// get a challenge instead of an access token.
+	Auth(ctx context.Context, params AuthParams) (AuthResult, error)
+	// CompleteTwoFactorAuth exchanges a challenge and a TOTP or recovery code
+	// for an access token.
+	CompleteTwoFactorAuth(ctx context.Context, params TwoFactorAuthParams) (string, error)
+	// OIDCLoginURL starts a login through the identity provider and returns
+	// the page to send the user to.
+	OIDCLoginURL(ctx context.Context) (string, error)
+	// OIDCCallback finishes an identity provider login like Auth does,
+	// registering users on their first login.
+	OIDCCallback(ctx context.Context, params OIDCCallbackParams) (AuthResult, error)
+	// SendCoins returns the ID of the transfer.
+	SendCoins(ctx context.Context, params TransactionParams) (int64, error)
+	// GetTransfer returns a transfer the caller sent, received or made on
+	// someone's behalf.
+	GetTransfer(ctx context.Context, params GetTransferParams) (Transfer, error)
 	SendCoinsInfo(ctx context.Context, params GetTransactionsParams) ([]models.Transaction, error)
 	ReceivedCoinsInfo(ctx context.Context, params GetTransactionsParams) ([]models.Transaction, error)
 	GetPurchases(ctx context.Context, params GetPurchasesParams) ([]models.PurchaseItem, error)
 	BuyItem(ctx context.Context, params BuyItemParams) error
+	GetItems(ctx context.Context, params GetItemsParams) ([]models.Item, error)
+}
+
+// AuthResult holds either an access token or, for users with 2FA enabled, a
+// challenge to pass to CompleteTwoFactorAuth.
+type AuthResult struct {
+	Token              string
+	ChallengeToken     string
+	ChallengeExpiresAt time.Time
 }
 
 type coinService struct {
-	repo     repo.CoinRepository
-	tokenGen token.TokenGenerator
+	repo         repo.CoinRepository
+	tokenGen     token.TokenGenerator
+	auth         Authenticator
+	hasher       password.Hasher
+	logins       *loginGuard
+	challengeTTL time.Duration
+	oidc         oidc.Provider
+	oidcStateTTL time.Duration
+	startBalance int
+	flags        FeatureFlags
+	metrics      Metrics
+	logger       *zap.Logger
 }
 
-func NewCoinService(repo repo.CoinRepository, tg token.TokenGenerator) CoinService {
+type CoinServiceConfig struct {
+	// Authenticator resolves access tokens and API keys; by default one over
+	// repo and tg.
+	Authenticator   Authenticator
+	Hasher          password.Hasher
+	LoginProtection LoginProtectionConfig
+	// ChallengeTTL is how long a 2FA login challenge stays valid.
+	ChallengeTTL time.Duration
+	// OIDC enables login through an identity provider; nil disables it.
+	// OIDCStateTTL is how long the user has to finish such a login.
+	OIDC         oidc.Provider
+	OIDCStateTTL time.Duration
+	// StartingBalance is given to users registered on their first login.
+	StartingBalance int
+	// Flags turns on features being rolled out; nil keeps them all off.
+	Flags   FeatureFlags
+	Metrics Metrics
+	Logger  *zap.Logger
+}
+
+func NewCoinService(repo repo.CoinRepository, tg token.TokenGenerator, cfg CoinServiceConfig) CoinService {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	metrics := cfg.Metrics
+	if metrics == nil {
+		metrics = nopMetrics{}
+	}
+	auth := cfg.Authenticator
+	if auth == nil {
+		auth = NewAuthenticator(repo, tg)
+	}
+	flags := cfg.Flags
+	if flags == nil {
+		flags = noFeatureFlags{}
+	}
+
 	return &coinService{
-		repo:     repo,
-		tokenGen: tg,
+		repo:         repo,
+		tokenGen:     tg,
+		auth:         auth,
+		hasher:       cfg.Hasher,
+		challengeTTL: cfg.ChallengeTTL,
+		oidc:         cfg.OIDC,
+		oidcStateTTL: cfg.OIDCStateTTL,
+		startBalance: cfg.StartingBalance,
+		flags:        flags,
+		metrics:      metrics,
+		logger:       logger,
+		logins: &loginGuard{
+			repo:   repo,
+			cfg:    cfg.LoginProtection,
+			logger: logger,
+			now:    time.Now,
+		},
 	}
 }
 
 func (s *coinService) GetBalance(ctx context.Context, params GetBalanceParams) (int, error) {
-	username, err := s.tokenGen.ParseToken(params.Token)
+	username, err := s.auth.Authenticate(ctx, params.Token, ScopeBalanceRead)
 	if err != nil {
-		return 0, fmt.Errorf("s.tokenGen.ParseToken: %w", err)
+		return 0, fmt.Errorf("s.auth.Authenticate: %w", err)
 	}
 
 	balance, err := s.repo.GetBalance(ctx, repo.GetBalanceParams{
@@ -51,76 +144,240 @@ func (s *coinService) GetBalance(ctx context.Context, params GetBalanceParams) (
 	return balance, nil
 }
 
-func (s *coinService) Auth(ctx context.Context, params AuthParams) (string, error) {
+func (s *coinService) Auth(ctx context.Context, params AuthParams) (AuthResult, error) {
+	logger.SetUsername(ctx, params.Username)
+
+	keys := loginKeys(params.Username, params.ClientIP)
+	attempts, err := s.logins.check(ctx, keys)
+	if err != nil {
+		return AuthResult{}, err
+	}
+
 	user, err := s.repo.GetUserByUsername(ctx, params.Username)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		return "", fmt.Errorf("s.repo.GetUserByUsername: %w", err)
+		return AuthResult{}, fmt.Errorf("s.repo.GetUserByUsername: %w", err)
 	}
 
 	if user == nil {
-		passHash, passErr := bcrypt.GenerateFromPassword([]byte(params.Password), bcrypt.MinCost)
+		passHash, algorithm, passErr := s.hasher.Hash(params.Password)
 		if passErr != nil {
-			return "", UnauthorizedError
+			return AuthResult{}, fmt.Errorf("s.hasher.Hash: %w", passErr)
 		}
 
 		err = s.repo.CreateUser(ctx, repo.CreateUserParams{
-			Username: params.Username,
-			PassHash: string(passHash),
-			Balance:  1000,
+			Username:      params.Username,
+			PassHash:      passHash,
+			PassAlgorithm: algorithm,
+			Balance:       s.startBalance,
 		})
 		if err != nil {
-			return "", fmt.Errorf("s.repo.CreateUser: %w", err)
+			return AuthResult{}, fmt.Errorf("s.repo.CreateUser: %w", err)
 		}
 
 		accessToken, tErr := s.tokenGen.NewToken(params.Username)
 		if tErr != nil {
-			return "", fmt.Errorf("s.tokenGen.NewToken: %w", err)
+			return AuthResult{}, fmt.Errorf("s.tokenGen.NewToken: %w", err)
 		}
 
-		return accessToken, nil
+		s.metrics.ObserveLogin("password", true)
+		return AuthResult{Token: accessToken}, nil
+	}
+
+	// Service accounts authenticate with API keys and users registered
+	// through SSO log in there; neither has a password.
+	if user.IsServiceAccount || user.PasswordHash == "" {
+		s.metrics.ObserveLogin("password", false)
+		if err = s.logins.fail(ctx, params.Username, params.ClientIP); err != nil {
+			return AuthResult{}, err
+		}
+		return AuthResult{}, InvalidCredentialsError
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(params.Password))
+	ok, err := s.hasher.Verify(params.Password, user.PasswordHash, user.PasswordAlgorithm)
 	if err != nil {
-		return "", fmt.Errorf("invalid password")
+		return AuthResult{}, fmt.Errorf("s.hasher.Verify: %w", err)
+	}
+	if !ok {
+		s.metrics.ObserveLogin("password", false)
+		if err = s.logins.fail(ctx, params.Username, params.ClientIP); err != nil {
+			return AuthResult{}, err
+		}
+		return AuthResult{}, InvalidCredentialsError
+	}
+
+	if s.hasher.NeedsRehash(user.PasswordHash, user.PasswordAlgorithm) {
+		s.rehash(ctx, user.Username, params.Password)
+	}
+	s.metrics.ObserveLogin("password", true)
+
+	// Only told to whoever knows the password.
+	if user.FrozenAt != nil {
+		return AuthResult{}, AccountFrozenError
+	}
+
+	// Counters are kept until the second factor is passed too, otherwise
+	// knowing the password would allow unlimited guesses of the code.
+	if user.TOTPEnabled {
+		return s.newChallenge(ctx, user.Username)
+	}
+
+	if len(attempts) > 0 {
+		if err = s.logins.reset(ctx, keys); err != nil {
+			return AuthResult{}, err
+		}
 	}
 
 	accessToken, err := s.tokenGen.NewToken(user.Username)
 	if err != nil {
-		return "", fmt.Errorf("s.tokenGen.NewToken: %w", err)
+		return AuthResult{}, fmt.Errorf("s.tokenGen.NewToken: %w", err)
 	}
 
-	return accessToken, nil
+	return AuthResult{Token: accessToken}, nil
 }
 
-func (s *coinService) SendCoins(ctx context.Context, params TransactionParams) error {
-	senderUsername, err := s.tokenGen.ParseToken(params.Token)
+func (s *coinService) newChallenge(ctx context.Context, username string) (AuthResult, error) {
+	challengeToken, err := newRandomToken()
 	if err != nil {
-		return fmt.Errorf("s.tokenGen.ParseToken: %w", err)
+		return AuthResult{}, err
 	}
+	expiresAt := time.Now().Add(s.challengeTTL)
 
-	_, err = s.repo.GetUserByUsername(ctx, params.ReceiverUsername)
+	if err = s.repo.CreateAuthChallenge(ctx, repo.CreateAuthChallengeParams{
+		Username:  username,
+		TokenHash: hashToken(challengeToken),
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return AuthResult{}, fmt.Errorf("s.repo.CreateAuthChallenge: %w", err)
+	}
+
+	return AuthResult{ChallengeToken: challengeToken, ChallengeExpiresAt: expiresAt}, nil
+}
+
+func (s *coinService) CompleteTwoFactorAuth(ctx context.Context, params TwoFactorAuthParams) (string, error) {
+	challengeHash := hashToken(params.ChallengeToken)
+	username, err := s.repo.GetAuthChallenge(ctx, challengeHash)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return fmt.Errorf("receiver not found")
+			return "", InvalidChallengeError
 		}
-		return fmt.Errorf("s.repo.GetUserByUsername: %w", err)
+		return "", fmt.Errorf("s.repo.GetAuthChallenge: %w", err)
 	}
+	logger.SetUsername(ctx, username)
 
-	balance, err := s.repo.GetBalance(ctx, repo.GetBalanceParams{
-		Username: senderUsername,
-	})
+	keys := loginKeys(username, params.ClientIP)
+	attempts, err := s.logins.check(ctx, keys)
 	if err != nil {
-		return fmt.Errorf("s.repo.GetBalance: %w", err)
+		return "", err
+	}
+
+	user, err := s.repo.GetUserByUsername(ctx, username)
+	if err != nil {
+		return "", fmt.Errorf("s.repo.GetUserByUsername: %w", err)
+	}
+
+	ok, err := useSecondFactor(ctx, s.repo, user, params.Code, time.Now())
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		s.metrics.ObserveLogin("otp", false)
+		if err = s.logins.fail(ctx, username, params.ClientIP); err != nil {
+			return "", err
+		}
+		return "", InvalidOTPCodeError
+	}
+
+	// Deleting is what makes the challenge single-use.
+	deleted, err := s.repo.DeleteAuthChallenge(ctx, challengeHash)
+	if err != nil {
+		return "", fmt.Errorf("s.repo.DeleteAuthChallenge: %w", err)
+	}
+	if !deleted {
+		return "", InvalidChallengeError
+	}
+	s.metrics.ObserveLogin("otp", true)
+
+	if len(attempts) > 0 {
+		if err = s.logins.reset(ctx, keys); err != nil {
+			return "", err
+		}
+	}
+
+	accessToken, err := s.tokenGen.NewToken(username)
+	if err != nil {
+		return "", fmt.Errorf("s.tokenGen.NewToken: %w", err)
+	}
+
+	return accessToken, nil
+}
+
+// rehash upgrades a password hash made with an old algorithm or cost. The
+// plain password is only known at login, so this is the only chance to do
+// it; a failure is logged and retried on the next login.
+func (s *coinService) rehash(ctx context.Context, username, plain string) {
+	passHash, algorithm, err := s.hasher.Hash(plain)
+	if err == nil {
+		err = s.repo.UpdatePassword(ctx, repo.UpdatePasswordParams{
+			Username:      username,
+			PassHash:      passHash,
+			PassAlgorithm: algorithm,
+		})
+	}
+	if err != nil {
+		s.log(ctx).Warn("failed to upgrade password hash", zap.String("username", username), zap.Error(err))
+	}
+}
+
+func (s *coinService) SendCoins(ctx context.Context, params TransactionParams) (int64, error) {
+	senderUsername, err := s.auth.Authenticate(ctx, params.Token, ScopeTransfersWrite)
+	if err != nil {
+		return 0, fmt.Errorf("s.auth.Authenticate: %w", err)
+	}
+
+	// A client sending on the owner's behalf moves the owner's coins and is
+	// recorded as the actor.
+	var actorUsername string
+	if params.OnBehalfOf != "" && params.OnBehalfOf != senderUsername {
+		actorUsername, senderUsername = senderUsername, params.OnBehalfOf
+	}
+
+	if params.Amount <= 0 {
+		return 0, InvalidAmountError
+	}
+	if params.ReceiverUsername == senderUsername {
+		return 0, SelfTransferError
+	}
+	if params.Memo != "" {
+		caller := senderUsername
+		if actorUsername != "" {
+			caller = actorUsername
+		}
+		if !s.flags.Enabled(FlagTransferMemos, caller) {
+			return 0, FeatureDisabledError
+		}
+		if utf8.RuneCountInString(params.Memo) > maxMemoLength {
+			return 0, InvalidMemoError
+		}
 	}
 
-	if balance < params.Amount {
-		return fmt.Errorf("insufficient funds")
+	// The owner's consent and the daily limit stand in for their second factor.
+	if actorUsername == "" {
+		if err = s.requireSecondFactor(ctx, senderUsername, params.Amount, transferThreshold, params.OTPCode); err != nil {
+			return 0, err
+		}
+	}
+
+	_, err = s.repo.GetUserByUsername(ctx, params.ReceiverUsername)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ReceiverNotFoundError
+		}
+		return 0, fmt.Errorf("s.repo.GetUserByUsername: %w", err)
 	}
 
 	tx, err := s.repo.BeginTx(ctx)
 	if err != nil {
-		return fmt.Errorf("s.repo.BeginTx: %w", err)
+		return 0, fmt.Errorf("s.repo.BeginTx: %w", err)
 	}
 
 	defer func() {
@@ -132,35 +389,101 @@ func (s *coinService) SendCoins(ctx context.Context, params TransactionParams) e
 		}
 	}()
 
-	if err = s.repo.DecreaseBalance(ctx, tx, repo.ChangeBalanceParams{
+	if actorUsername != "" {
+		if err = useDelegation(ctx, s.repo, tx, senderUsername, actorUsername, params.Amount); err != nil {
+			return 0, err
+		}
+	}
+
+	senderBalance, err := s.repo.DecreaseBalance(ctx, tx, repo.ChangeBalanceParams{
 		Username: senderUsername, Amount: params.Amount,
-	}); err != nil {
-		return fmt.Errorf("s.repo.DecreaseBalance: %w", err)
+	})
+	if err != nil {
+		if errors.Is(err, repo.InsufficientFundsError) {
+			return 0, InsufficientFundsError
+		}
+		if errors.Is(err, repo.AccountFrozenError) {
+			return 0, AccountFrozenError
+		}
+		return 0, fmt.Errorf("s.repo.DecreaseBalance: %w", err)
 	}
 
-	if err = s.repo.IncreaseBalance(ctx, tx, repo.ChangeBalanceParams{
+	receiverBalance, err := s.repo.IncreaseBalance(ctx, tx, repo.ChangeBalanceParams{
 		Username: params.ReceiverUsername, Amount: params.Amount,
-	}); err != nil {
-		return fmt.Errorf("s.repo.IncreaseBalance: %w", err)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("s.repo.IncreaseBalance: %w", err)
 	}
 
-	if err = s.repo.SaveTransaction(ctx, repo.SaveTransactionParams{
-		SenderUsername: senderUsername, ReceiverUsername: params.ReceiverUsername, Amount: params.Amount,
-	}); err != nil {
-		return fmt.Errorf("s.repo.SaveTransaction: %w", err)
+	id, err := s.repo.SaveTransaction(ctx, tx, repo.SaveTransactionParams{
+		SenderUsername:   senderUsername,
+		ReceiverUsername: params.ReceiverUsername,
+		Amount:           params.Amount,
+		ActorUsername:    actorUsername,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("s.repo.SaveTransaction: %w", err)
+	}
+
+	if err = saveEvents(ctx, s.repo, tx,
+		repo.SaveEventParams{
+			Username: senderUsername,
+			Type:     models.EventCoinsSent,
+			Payload:  models.CoinsSentPayload{ToUser: params.ReceiverUsername, Amount: params.Amount, Actor: actorUsername, Memo: params.Memo},
+		},
+		repo.SaveEventParams{
+			Username: senderUsername,
+			Type:     models.EventBalanceChanged,
+			Payload:  models.BalanceChangedPayload{Balance: senderBalance, Delta: -params.Amount},
+		},
+		repo.SaveEventParams{
+			Username: params.ReceiverUsername,
+			Type:     models.EventCoinsReceived,
+			Payload:  models.CoinsReceivedPayload{FromUser: senderUsername, Amount: params.Amount, Memo: params.Memo},
+		},
+		repo.SaveEventParams{
+			Username: params.ReceiverUsername,
+			Type:     models.EventBalanceChanged,
+			Payload:  models.BalanceChangedPayload{Balance: receiverBalance, Delta: params.Amount},
+		},
+	); err != nil {
+		return 0, err
 	}
 
 	if err = s.repo.CommitTx(tx); err != nil {
-		return fmt.Errorf("s.repo.CommitTx: %w", err)
+		return 0, fmt.Errorf("s.repo.CommitTx: %w", err)
 	}
 
-	return nil
+	s.metrics.ObserveTransfer(params.Amount)
+	return id, nil
+}
+
+func (s *coinService) GetTransfer(ctx context.Context, params GetTransferParams) (Transfer, error) {
+	username, err := s.auth.Authenticate(ctx, params.Token, ScopeTransfersRead)
+	if err != nil {
+		return Transfer{}, fmt.Errorf("s.auth.Authenticate: %w", err)
+	}
+
+	transaction, err := s.repo.GetTransaction(ctx, params.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Transfer{}, TransactionNotFoundError
+		}
+		return Transfer{}, fmt.Errorf("s.repo.GetTransaction: %w", err)
+	}
+
+	// Other users' transfers are reported as missing rather than forbidden.
+	if username != transaction.SenderUsername && username != transaction.ReceiverUsername &&
+		username != transaction.ActorUsername {
+		return Transfer{}, TransactionNotFoundError
+	}
+	return Transfer{Transaction: transaction, Received: username == transaction.ReceiverUsername}, nil
 }
 
 func (s *coinService) SendCoinsInfo(ctx context.Context, params GetTransactionsParams) ([]models.Transaction, error) {
-	username, err := s.tokenGen.ParseToken(params.Token)
+	username, err := s.auth.Authenticate(ctx, params.Token, ScopeTransfersRead)
 	if err != nil {
-		return nil, fmt.Errorf("s.tokenGen.ParseToken: %w", err)
+		return nil, fmt.Errorf("s.auth.Authenticate: %w", err)
 	}
 
 	transactions, err := s.repo.GetTransactions(ctx, username)
@@ -172,9 +495,9 @@ func (s *coinService) SendCoinsInfo(ctx context.Context, params GetTransactionsP
 }
 
 func (s *coinService) ReceivedCoinsInfo(ctx context.Context, params GetTransactionsParams) ([]models.Transaction, error) {
-	username, err := s.tokenGen.ParseToken(params.Token)
+	username, err := s.auth.Authenticate(ctx, params.Token, ScopeTransfersRead)
 	if err != nil {
-		return nil, fmt.Errorf("s.tokenGen.ParseToken: %w", err)
+		return nil, fmt.Errorf("s.auth.Authenticate: %w", err)
 	}
 
 	transactions, err := s.repo.ReceivedCoinsInfo(ctx, username)
@@ -186,9 +509,9 @@ func (s *coinService) ReceivedCoinsInfo(ctx context.Context, params GetTransacti
 }
 
 func (s *coinService) GetPurchases(ctx context.Context, params GetPurchasesParams) ([]models.PurchaseItem, error) {
-	username, err := s.tokenGen.ParseToken(params.Token)
+	username, err := s.auth.Authenticate(ctx, params.Token, ScopePurchasesRead)
 	if err != nil {
-		return nil, fmt.Errorf("s.tokenGen.ParseToken: %w", err)
+		return nil, fmt.Errorf("s.auth.Authenticate: %w", err)
 	}
 
 	purchases, err := s.repo.GetPurchases(ctx, username)
@@ -200,24 +523,111 @@ func (s *coinService) GetPurchases(ctx context.Context, params GetPurchasesParam
 }
 
 func (s *coinService) BuyItem(ctx context.Context, params BuyItemParams) error {
-	username, err := s.tokenGen.ParseToken(params.Token)
+	username, err := s.auth.Authenticate(ctx, params.Token, ScopePurchasesWrite)
 	if err != nil {
-		return fmt.Errorf("s.tokenGen.ParseToken: %w", err)
+		return fmt.Errorf("s.auth.Authenticate: %w", err)
 	}
 
 	item, err := s.repo.GetItem(ctx, params.Item)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ItemNotFoundError
+		}
 		return fmt.Errorf("s.repo.GetItem: %w", err)
 	}
 
-	err = s.repo.BuyItem(ctx, repo.BuyItemParams{
+	if err = s.requireSecondFactor(ctx, username, item.Price, purchaseThreshold, params.OTPCode); err != nil {
+		return err
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			err = s.repo.RollbackTx(tx)
+			if err != nil {
+				err = fmt.Errorf("s.repo.RollbackTx: %w", err)
+			}
+		}
+	}()
+
+	balance, err := s.repo.BuyItem(ctx, tx, repo.BuyItemParams{
 		Username: username,
 		Item:     item.Name,
 		Price:    item.Price,
 	})
 	if err != nil {
+		if errors.Is(err, repo.InsufficientFundsError) {
+			return InsufficientFundsError
+		}
+		if errors.Is(err, repo.AccountFrozenError) {
+			return AccountFrozenError
+		}
 		return fmt.Errorf("s.repo.BuyItem: %w", err)
 	}
 
+	if err = saveEvents(ctx, s.repo, tx,
+		repo.SaveEventParams{
+			Username: username,
+			Type:     models.EventPurchaseCompleted,
+			Payload:  models.PurchaseCompletedPayload{Item: item.Name, Price: item.Price},
+		},
+		repo.SaveEventParams{
+			Username: username,
+			Type:     models.EventBalanceChanged,
+			Payload:  models.BalanceChangedPayload{Balance: balance, Delta: -item.Price},
+		},
+	); err != nil {
+		return err
+	}
+
+	if err = s.repo.CommitTx(tx); err != nil {
+		return fmt.Errorf("s.repo.CommitTx: %w", err)
+	}
+
+	s.metrics.ObservePurchase(item.Name)
+	return nil
+}
+
+func (s *coinService) GetItems(ctx context.Context, params GetItemsParams) ([]models.Item, error) {
+	if _, err := s.auth.Authenticate(ctx, params.Token, scopeAny); err != nil {
+		return nil, fmt.Errorf("s.auth.Authenticate: %w", err)
+	}
+
+	items, err := s.repo.GetItems(ctx, repo.GetItemsParams{
+		Names: params.Names,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.GetItems: %w", err)
+	}
+
+	return items, nil
+}
+
+// saveEvents stores events as part of tx and creates the matching inbox
+// notifications for the event types users are notified about. Some of those
+// are also queued for delivery by email or chat, which happens in the
+// background once tx is committed.
+func saveEvents(ctx context.Context, r repo.CoinRepository, tx *sqlx.Tx, events ...repo.SaveEventParams) error {
+	for _, event := range events {
+		if err := r.SaveEvent(ctx, tx, event); err != nil {
+			return fmt.Errorf("r.SaveEvent: %w", err)
+		}
+
+		if !isNotificationType(models.NotificationType(event.Type)) {
+			continue
+		}
+		if err := r.CreateNotification(ctx, tx, repo.CreateNotificationParams{
+			Username: event.Username,
+			Type:     models.NotificationType(event.Type),
+			Payload:  event.Payload,
+			Deliver:  isDeliveredNotificationType(models.NotificationType(event.Type)),
+		}); err != nil {
+			return fmt.Errorf("r.CreateNotification: %w", err)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/repo"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+func (s *accountService) GrantDelegation(ctx context.Context, params GrantDelegationParams) (models.Delegation, error) {
+	owner, err := s.userFromToken(ctx, params.Token)
+	if err != nil {
+		return models.Delegation{}, err
+	}
+
+	if params.DailyLimit <= 0 {
+		return models.Delegation{}, InvalidLimitError
+	}
+
+	client, err := s.repo.GetUserByUsername(ctx, params.ClientUsername)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Delegation{}, UserNotFoundError
+		}
+		return models.Delegation{}, fmt.Errorf("s.repo.GetUserByUsername: %w", err)
+	}
+	// Only bots act for others; a person would need the owner's password.
+	if !client.IsServiceAccount {
+		return models.Delegation{}, ServiceAccountRequiredError
+	}
+
+	delegation, err := s.repo.GrantDelegation(ctx, repo.GrantDelegationParams{
+		OwnerUsername:  owner.Username,
+		ClientUsername: client.Username,
+		DailyLimit:     params.DailyLimit,
+	})
+	if err != nil {
+		return models.Delegation{}, fmt.Errorf("s.repo.GrantDelegation: %w", err)
+	}
+
+	s.log(ctx).Info("transfers delegated",
+		zap.String("owner", owner.Username),
+		zap.String("client", client.Username),
+		zap.Int("daily_limit", params.DailyLimit),
+	)
+	return delegation, nil
+}
+
+func (s *accountService) ListDelegations(ctx context.Context, params ListDelegationsParams) ([]models.Delegation, error) {
+	owner, err := s.userFromToken(ctx, params.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	delegations, err := s.repo.ListDelegations(ctx, owner.Username)
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.ListDelegations: %w", err)
+	}
+	return delegations, nil
+}
+
+func (s *accountService) RevokeDelegation(ctx context.Context, params RevokeDelegationParams) error {
+	owner, err := s.userFromToken(ctx, params.Token)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := s.repo.RevokeDelegation(ctx, repo.RevokeDelegationParams{
+		OwnerUsername: owner.Username,
+		ID:            params.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("s.repo.RevokeDelegation: %w", err)
+	}
+	if !revoked {
+		return DelegationNotFoundError
+	}
+
+	s.log(ctx).Info("delegation revoked", zap.String("owner", owner.Username), zap.Int64("delegation_id", params.ID))
+	return nil
+}
+
+// useDelegation checks that client may send amount more coins from the
+// owner's account. It locks the delegation until tx ends.
+func useDelegation(ctx context.Context, r repo.CoinRepository, tx *sqlx.Tx, ownerUsername, clientUsername string, amount int) error {
+	delegation, err := r.LockDelegation(ctx, tx, repo.LockDelegationParams{
+		OwnerUsername:  ownerUsername,
+		ClientUsername: clientUsername,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return DelegationRequiredError
+		}
+		return fmt.Errorf("r.LockDelegation: %w", err)
+	}
+
+	if delegation.SpentToday+amount > delegation.DailyLimit {
+		return DelegationLimitExceededError
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"errors"
+	"time"
+)
+
+// Error categories. Transports map them to status codes; every domain error
+// belongs to exactly one of them.
+var (
+	UnauthorizedError    = errors.New("unauthorized")
+	ForbiddenError       = errors.New("forbidden")
+	NotFoundError        = errors.New("not found")
+	InvalidParamsError   = errors.New("invalid params")
+	ConflictError        = errors.New("conflict")
+	UnprocessableError   = errors.New("unprocessable")
+	TooManyRequestsError = errors.New("too many requests")
+)
+
+// Error is a domain error with a stable code clients can rely on. It unwraps
+// to its category, so errors.Is(ReceiverNotFoundError, NotFoundError) holds.
+type Error struct {
+	Code    string
+	Message string
+	// RetryAfter tells the client when the request may succeed; zero if unknown.
+	RetryAfter time.Duration
+	category   error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.category
+}
+
+// Is matches errors by code, so a copy made by WithRetryAfter still matches
+// the variable it was made from.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithDetail returns a copy of e with detail appended to its message.
+func (e *Error) WithDetail(detail string) *Error {
+	c := *e
+	c.Message = e.Message + ": " + detail
+	return &c
+}
+
+// WithRetryAfter returns a copy of e that tells the client to retry after d.
+func (e *Error) WithRetryAfter(d time.Duration) *Error {
+	c := *e
+	c.RetryAfter = d
+	return &c
+}
+
+var (
+	InvalidCredentialsError      = &Error{Code: "invalid_credentials", Message: "invalid username or password", category: UnauthorizedError}
+	InvalidTokenError            = &Error{Code: "invalid_token", Message: "invalid or expired token", category: UnauthorizedError}
+	InvalidResetTokenError       = &Error{Code: "invalid_reset_token", Message: "reset token is invalid, used or expired", category: UnauthorizedError}
+	InvalidCurrentPasswordError  = &Error{Code: "invalid_current_password", Message: "current password is wrong", category: ForbiddenError}
+	InvalidChallengeError        = &Error{Code: "invalid_challenge", Message: "challenge is invalid or expired", category: UnauthorizedError}
+	InvalidOIDCStateError        = &Error{Code: "invalid_oidc_state", Message: "sso login is invalid or expired, start it again", category: UnauthorizedError}
+	OIDCLoginFailedError         = &Error{Code: "oidc_login_failed", Message: "identity provider login failed", category: UnauthorizedError}
+	InvalidOTPCodeError          = &Error{Code: "invalid_otp_code", Message: "invalid two-factor code", category: UnauthorizedError}
+	OTPRequiredError             = &Error{Code: "otp_required", Message: "two-factor code required for this operation", category: ForbiddenError}
+	TwoFactorSetupRequiredError  = &Error{Code: "two_factor_setup_required", Message: "enable two-factor authentication to perform this operation", category: ForbiddenError}
+	InsufficientScopeError       = &Error{Code: "insufficient_scope", Message: "api key lacks the scope required for this operation", category: ForbiddenError}
+	DelegationRequiredError      = &Error{Code: "delegation_required", Message: "the account owner has not delegated transfers to you", category: ForbiddenError}
+	AccountFrozenError           = &Error{Code: "account_frozen", Message: "account is frozen, contact support", category: ForbiddenError}
+	AdminRequiredError           = &Error{Code: "admin_required", Message: "admin rights required", category: ForbiddenError}
+	FeatureDisabledError         = &Error{Code: "feature_disabled", Message: "this feature is not available to you yet", category: ForbiddenError}
+	OIDCDisabledError            = &Error{Code: "oidc_disabled", Message: "sso login is not configured", category: NotFoundError}
+	ConfigReloadDisabledError    = &Error{Code: "config_reload_disabled", Message: "configuration reload is not available", category: NotFoundError}
+	ReceiverNotFoundError        = &Error{Code: "receiver_not_found", Message: "receiver not found", category: NotFoundError}
+	UserNotFoundError            = &Error{Code: "user_not_found", Message: "user not found", category: NotFoundError}
+	ItemNotFoundError            = &Error{Code: "item_not_found", Message: "item not found", category: NotFoundError}
+	NotificationNotFoundError    = &Error{Code: "notification_not_found", Message: "notification not found", category: NotFoundError}
+	APIKeyNotFoundError          = &Error{Code: "api_key_not_found", Message: "api key not found", category: NotFoundError}
+	TransactionNotFoundError     = &Error{Code: "transaction_not_found", Message: "transaction not found", category: NotFoundError}
+	DelegationNotFoundError      = &Error{Code: "delegation_not_found", Message: "delegation not found", category: NotFoundError}
+	FeatureFlagNotFoundError     = &Error{Code: "feature_flag_not_found", Message: "feature flag not found", category: NotFoundError}
+	InvalidAmountError           = &Error{Code: "invalid_amount", Message: "amount must be positive", category: InvalidParamsError}
+	InvalidEmailError            = &Error{Code: "invalid_email", Message: "invalid email", category: InvalidParamsError}
+	InvalidLocaleError           = &Error{Code: "invalid_locale", Message: "invalid locale", category: InvalidParamsError}
+	UnknownNotificationTypeError = &Error{Code: "unknown_notification_type", Message: "unknown notification type", category: InvalidParamsError}
+	PasswordTooShortError        = &Error{Code: "password_too_short", Message: "password must be at least 8 characters", category: InvalidParamsError}
+	InvalidThresholdError        = &Error{Code: "invalid_threshold", Message: "threshold must not be negative", category: InvalidParamsError}
+	InvalidUsernameError         = &Error{Code: "invalid_username", Message: "invalid username", category: InvalidParamsError}
+	InvalidBalanceError          = &Error{Code: "invalid_balance", Message: "balance must not be negative", category: InvalidParamsError}
+	InvalidScopeError            = &Error{Code: "invalid_scope", Message: "unknown or missing scope", category: InvalidParamsError}
+	InvalidExpiryError           = &Error{Code: "invalid_expiry", Message: "expiry must be in the future and within the allowed key lifetime", category: InvalidParamsError}
+	InvalidLimitError            = &Error{Code: "invalid_limit", Message: "daily limit must be positive", category: InvalidParamsError}
+	InvalidAdjustmentError       = &Error{Code: "invalid_adjustment", Message: "adjustment must not be zero", category: InvalidParamsError}
+	ReasonRequiredError          = &Error{Code: "reason_required", Message: "reason is required", category: InvalidParamsError}
+	InvalidItemNameError         = &Error{Code: "invalid_item_name", Message: "item name must not be empty", category: InvalidParamsError}
+	InvalidPriceError            = &Error{Code: "invalid_price", Message: "price must not be negative", category: InvalidParamsError}
+	EmptyMessageError            = &Error{Code: "empty_message", Message: "empty message", category: InvalidParamsError}
+	InvalidFlagNameError         = &Error{Code: "invalid_flag_name", Message: "flag name must be lowercase letters, digits, '_', '-' or '.', up to 64 characters", category: InvalidParamsError}
+	InvalidRolloutError          = &Error{Code: "invalid_rollout", Message: "rollout percent must be between 0 and 100", category: InvalidParamsError}
+	InvalidMemoError             = &Error{Code: "invalid_memo", Message: "memo must be at most 200 characters", category: InvalidParamsError}
+	TwoFactorEnabledError        = &Error{Code: "two_factor_enabled", Message: "two-factor authentication is already enabled", category: ConflictError}
+	TwoFactorNotEnrolledError    = &Error{Code: "two_factor_not_enrolled", Message: "two-factor authentication is not set up", category: ConflictError}
+	UsernameTakenError           = &Error{Code: "username_taken", Message: "username is already taken", category: ConflictError}
+	InsufficientFundsError       = &Error{Code: "insufficient_funds", Message: "insufficient funds", category: ConflictError}
+	DelegationLimitExceededError = &Error{Code: "delegation_limit_exceeded", Message: "transfer exceeds the daily limit of the delegation", category: ConflictError}
+	SelfTransferError            = &Error{Code: "self_transfer", Message: "cannot send coins to yourself", category: UnprocessableError}
+	InvalidConfigError           = &Error{Code: "invalid_config", Message: "configuration is invalid, nothing was reloaded", category: UnprocessableError}
+	ServiceAccountRequiredError  = &Error{Code: "service_account_required", Message: "only service accounts can hold api keys and delegations", category: UnprocessableError}
+	LoginThrottledError          = &Error{Code: "login_throttled", Message: "too many failed login attempts, try again later", category: TooManyRequestsError}
+	AccountLockedError           = &Error{Code: "account_locked", Message: "account is temporarily locked after too many failed login attempts", category: TooManyRequestsError}
+)
+
+var categoryCodes = map[error]string{
+	UnauthorizedError:    "unauthorized",
+	ForbiddenError:       "forbidden",
+	NotFoundError:        "not_found",
+	InvalidParamsError:   "invalid_params",
+	ConflictError:        "conflict",
+	UnprocessableError:   "unprocessable",
+	TooManyRequestsError: "too_many_requests",
+}
+
+// Classify returns the category of err together with the code and message
+// that are safe to show to clients. The category is nil for unexpected
+// errors: their text may contain SQL or other internals and must not leave
+// the service.
+func Classify(err error) (category error, code, message string) {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr.category, domainErr.Code, domainErr.Message
+	}
+
+	for category, code := range categoryCodes {
+		if errors.Is(err, category) {
+			return category, code, category.Error()
+		}
+	}
+	return nil, "", ""
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/events"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/repo"
+	"github.com/Blxssy/AvitoTest/pkg/token"
+)
+
+// maxReplayedEvents bounds how much history a reconnecting client gets back.
+// Clients that were away for longer should reload their state from /api/info.
+const maxReplayedEvents = 1000
+
+type EventService interface {
+	Subscribe(ctx context.Context, params SubscribeParams) (*EventStream, error)
+}
+
+type eventService struct {
+	repo repo.CoinRepository
+	hub  *events.Hub
+	auth Authenticator
+}
+
+type EventServiceConfig struct {
+	// Authenticator resolves access tokens and API keys; by default one over
+	// repo and tg.
+	Authenticator Authenticator
+}
+
+func NewEventService(repo repo.CoinRepository, hub *events.Hub, tg token.TokenGenerator, cfg EventServiceConfig) EventService {
+	auth := cfg.Authenticator
+	if auth == nil {
+		auth = NewAuthenticator(repo, tg)
+	}
+
+	return &eventService{
+		repo: repo,
+		hub:  hub,
+		auth: auth,
+	}
+}
+
+// EventStream delivers the events a client missed since its last seen event
+// followed by live events.
+type EventStream struct {
+	Backlog []models.Event
+
+	sub      *events.Subscription
+	replayed map[int64]struct{}
+}
+
+func (s *eventService) Subscribe(ctx context.Context, params SubscribeParams) (*EventStream, error) {
+	username, err := s.auth.Authenticate(ctx, params.Token, ScopeEventsRead)
+	if err != nil {
+		return nil, fmt.Errorf("s.auth.Authenticate: %w", err)
+	}
+
+	// Subscribe before reading the backlog so nothing committed in between is lost.
+	sub := s.hub.Subscribe(username)
+
+	var backlog []models.Event
+	if params.LastEventID > 0 {
+		backlog, err = s.repo.GetEvents(ctx, repo.GetEventsParams{
+			Username: username,
+			AfterID:  params.LastEventID,
+			Limit:    maxReplayedEvents,
+		})
+		if err != nil {
+			sub.Close()
+			return nil, fmt.Errorf("s.repo.GetEvents: %w", err)
+		}
+	}
+
+	stream := &EventStream{
+		Backlog:  backlog,
+		sub:      sub,
+		replayed: make(map[int64]struct{}, len(backlog)),
+	}
+	for _, event := range backlog {
+		stream.replayed[event.ID] = struct{}{}
+	}
+
+	return stream, nil
+}
+
+// Events is closed when the stream is closed or the client fell too far behind.
+func (s *EventStream) Events() <-chan models.Event {
+	return s.sub.Events()
+}
+
+// Replayed reports whether a live event was already delivered in the backlog.
+func (s *EventStream) Replayed(event models.Event) bool {
+	_, ok := s.replayed[event.ID]
+	return ok
+}
+
+func (s *EventStream) Close() {
+	s.sub.Close()
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/repo"
+	"github.com/Blxssy/AvitoTest/pkg/token"
+	"go.uber.org/zap"
+	"hash/fnv"
+	"maps"
+	"regexp"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const defaultFlagRefreshInterval = 30 * time.Second
+
+// FlagTransferMemos lets users attach a memo to a transfer.
+const FlagTransferMemos = "transfer_memos"
+
+var flagNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,63}$`)
+
+// FeatureFlags tells whether a feature is on for a user. Unknown flags are
+// off, so code can check a flag before an admin has created it.
+type FeatureFlags interface {
+	Enabled(flag, username string) bool
+}
+
+// noFeatureFlags keeps every feature off for services built without flags.
+type noFeatureFlags struct{}
+
+func (noFeatureFlags) Enabled(string, string) bool { return false }
+
+// FeatureFlagService evaluates flags from an in-memory copy of the
+// feature_flags table and lets admins change them.
+type FeatureFlagService interface {
+	FeatureFlags
+	// GetFeatures returns the names of the flags that are on for the caller.
+	GetFeatures(ctx context.Context, params GetFeaturesParams) ([]string, error)
+	ListFeatureFlags(ctx context.Context, params ListFeatureFlagsParams) ([]models.FeatureFlag, error)
+	// SetFeatureFlag creates the flag or replaces its settings.
+	SetFeatureFlag(ctx context.Context, params SetFeatureFlagParams) (models.FeatureFlag, error)
+	DeleteFeatureFlag(ctx context.Context, params DeleteFeatureFlagParams) error
+	// Refresh reloads the flags from the database.
+	Refresh(ctx context.Context) error
+	// Run refreshes the flags periodically until ctx is done, picking up
+	// changes made through other replicas.
+	Run(ctx context.Context) error
+}
+
+type featureFlagService struct {
+	repo            repo.CoinRepository
+	tokenGen        token.TokenGenerator
+	auth            Authenticator
+	refreshInterval time.Duration
+	logger          *zap.Logger
+
+	// mu serializes updates of flags; reads go through the pointer alone.
+	mu    sync.Mutex
+	flags atomic.Pointer[map[string]models.FeatureFlag]
+}
+
+type FeatureFlagServiceConfig struct {
+	// Authenticator resolves access tokens and API keys; by default one over
+	// repo and tg.
+	Authenticator Authenticator
+	// RefreshInterval is how often Run reloads the flags.
+	RefreshInterval time.Duration
+	Logger          *zap.Logger
+}
+
+func NewFeatureFlagService(repo repo.CoinRepository, tg token.TokenGenerator, cfg FeatureFlagServiceConfig) FeatureFlagService {
+	s := &featureFlagService{
+		repo:            repo,
+		tokenGen:        tg,
+		auth:            cfg.Authenticator,
+		refreshInterval: cfg.RefreshInterval,
+		logger:          cfg.Logger,
+	}
+	if s.refreshInterval <= 0 {
+		s.refreshInterval = defaultFlagRefreshInterval
+	}
+	if s.logger == nil {
+		s.logger = zap.NewNop()
+	}
+	if s.auth == nil {
+		s.auth = NewAuthenticator(repo, tg)
+	}
+	s.flags.Store(&map[string]models.FeatureFlag{})
+	return s
+}
+
+func (s *featureFlagService) Enabled(flag, username string) bool {
+	f, ok := (*s.flags.Load())[flag]
+	return ok && flagEnabled(f, username)
+}
+
+// flagEnabled puts a user in the rollout by a hash of the flag and username,
+// so a user keeps the feature as the percentage grows and different flags
+// reach different users.
+func flagEnabled(flag models.FeatureFlag, username string) bool {
+	if !flag.Enabled {
+		return false
+	}
+	if slices.Contains(flag.AllowList, username) {
+		return true
+	}
+	if flag.RolloutPercent <= 0 {
+		return false
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(flag.Name))
+	h.Write([]byte{0})
+	h.Write([]byte(username))
+	return int(h.Sum32()%100) < flag.RolloutPercent
+}
+
+func (s *featureFlagService) GetFeatures(ctx context.Context, params GetFeaturesParams) ([]string, error) {
+	username, err := s.auth.Authenticate(ctx, params.Token, scopeAny)
+	if err != nil {
+		return nil, fmt.Errorf("s.auth.Authenticate: %w", err)
+	}
+
+	features := []string{}
+	for name, flag := range *s.flags.Load() {
+		if flagEnabled(flag, username) {
+			features = append(features, name)
+		}
+	}
+	slices.Sort(features)
+	return features, nil
+}
+
+func (s *featureFlagService) ListFeatureFlags(ctx context.Context, params ListFeatureFlagsParams) ([]models.FeatureFlag, error) {
+	if _, err := authorizeAdmin(ctx, s.repo, s.tokenGen, params.Token); err != nil {
+		return nil, err
+	}
+
+	flags, err := s.repo.GetFeatureFlags(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.GetFeatureFlags: %w", err)
+	}
+	return flags, nil
+}
+
+func (s *featureFlagService) SetFeatureFlag(ctx context.Context, params SetFeatureFlagParams) (models.FeatureFlag, error) {
+	adminUsername, err := authorizeAdmin(ctx, s.repo, s.tokenGen, params.Token)
+	if err != nil {
+		return models.FeatureFlag{}, err
+	}
+
+	if !flagNamePattern.MatchString(params.Name) {
+		return models.FeatureFlag{}, InvalidFlagNameError
+	}
+	if params.RolloutPercent < 0 || params.RolloutPercent > 100 {
+		return models.FeatureFlag{}, InvalidRolloutError
+	}
+	allowList := []string{}
+	for _, username := range params.AllowList {
+		if username == "" {
+			return models.FeatureFlag{}, InvalidUsernameError
+		}
+		if !slices.Contains(allowList, username) {
+			allowList = append(allowList, username)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	flag, err := s.repo.SaveFeatureFlag(ctx, repo.SaveFeatureFlagParams{
+		Name:           params.Name,
+		Description:    params.Description,
+		Enabled:        params.Enabled,
+		RolloutPercent: params.RolloutPercent,
+		AllowList:      allowList,
+		UpdatedBy:      adminUsername,
+	})
+	if err != nil {
+		return models.FeatureFlag{}, fmt.Errorf("s.repo.SaveFeatureFlag: %w", err)
+	}
+
+	flags := maps.Clone(*s.flags.Load())
+	flags[flag.Name] = flag
+	s.flags.Store(&flags)
+
+	s.log(ctx).Info("feature flag changed",
+		zap.String("admin", adminUsername),
+		zap.String("flag", flag.Name),
+		zap.Bool("enabled", flag.Enabled),
+		zap.Int("rollout_percent", flag.RolloutPercent),
+		zap.Int("allow_list", len(flag.AllowList)),
+	)
+	return flag, nil
+}
+
+func (s *featureFlagService) DeleteFeatureFlag(ctx context.Context, params DeleteFeatureFlagParams) error {
+	adminUsername, err := authorizeAdmin(ctx, s.repo, s.tokenGen, params.Token)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deleted, err := s.repo.DeleteFeatureFlag(ctx, params.Name)
+	if err != nil {
+		return fmt.Errorf("s.repo.DeleteFeatureFlag: %w", err)
+	}
+	if !deleted {
+		return FeatureFlagNotFoundError
+	}
+
+	flags := maps.Clone(*s.flags.Load())
+	delete(flags, params.Name)
+	s.flags.Store(&flags)
+
+	s.log(ctx).Info("feature flag deleted",
+		zap.String("admin", adminUsername),
+		zap.String("flag", params.Name),
+	)
+	return nil
+}
+
+func (s *featureFlagService) Refresh(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.repo.GetFeatureFlags(ctx)
+	if err != nil {
+		return fmt.Errorf("s.repo.GetFeatureFlags: %w", err)
+	}
+
+	flags := make(map[string]models.FeatureFlag, len(list))
+	for _, flag := range list {
+		flags[flag.Name] = flag
+	}
+	s.flags.Store(&flags)
+	return nil
+}
+
+func (s *featureFlagService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		// Keep serving the last flags we saw until the database is back.
+		if err := s.Refresh(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Error(fmt.Sprintf("feature flags refresh failed: %v", err))
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package services
+
+import (
+	"context"
+	"github.com/Blxssy/AvitoTest/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// log returns the logger of the request being served, so service logs carry
+// its ID.
+func (s *coinService) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, s.logger)
+}
+
+func (s *accountService) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, s.logger)
+}
+
+func (g *loginGuard) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, g.logger)
+}
+
+func (s *featureFlagService) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, s.logger)
+}
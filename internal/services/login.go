@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/repo"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+// LoginProtectionConfig throttles password guessing. After a failed login the
+// same username or client IP has to wait BaseDelay before the next attempt,
+// twice as long after every further failure, up to MaxDelay. Failures older
+// than FailureWindow are forgotten. UserMaxFailures (IPMaxFailures) failures
+// in a row lock the username (IP) for LockoutDuration. The zero value turns
+// the protection off.
+type LoginProtectionConfig struct {
+	UserMaxFailures int
+	IPMaxFailures   int
+	LockoutDuration time.Duration
+	FailureWindow   time.Duration
+	BaseDelay       time.Duration
+	MaxDelay        time.Duration
+}
+
+func (c LoginProtectionConfig) enabled() bool {
+	return c.UserMaxFailures > 0 || c.IPMaxFailures > 0 || c.BaseDelay > 0
+}
+
+// delay is how long to wait after the given number of failures in a row.
+func (c LoginProtectionConfig) delay(failures int) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+
+	delay := c.BaseDelay
+	for i := 1; i < failures; i++ {
+		if c.MaxDelay > 0 && delay >= c.MaxDelay {
+			break
+		}
+		delay *= 2
+	}
+	if c.MaxDelay > 0 && delay > c.MaxDelay {
+		delay = c.MaxDelay
+	}
+	return delay
+}
+
+const (
+	userLoginKeyPrefix = "user:"
+	ipLoginKeyPrefix   = "ip:"
+)
+
+func userLoginKey(username string) string {
+	return userLoginKeyPrefix + username
+}
+
+func loginKeys(username, clientIP string) []string {
+	keys := []string{userLoginKey(username)}
+	if clientIP != "" {
+		keys = append(keys, ipLoginKeyPrefix+clientIP)
+	}
+	return keys
+}
+
+// loginGuard keeps failed login counters in the repository, so lockouts
+// survive restarts and are shared between replicas.
+type loginGuard struct {
+	repo   repo.LoginAttemptRepository
+	cfg    LoginProtectionConfig
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// check fails with AccountLockedError or LoginThrottledError if any of keys
+// may not log in yet. It returns the recorded attempts so a successful login
+// only resets counters that exist.
+func (g *loginGuard) check(ctx context.Context, keys []string) ([]models.LoginAttempt, error) {
+	if !g.cfg.enabled() {
+		return nil, nil
+	}
+
+	attempts, err := g.repo.GetLoginAttempts(ctx, keys)
+	if err != nil {
+		return nil, fmt.Errorf("g.repo.GetLoginAttempts: %w", err)
+	}
+
+	now := g.now()
+	var lockedFor, throttledFor time.Duration
+	for _, attempt := range attempts {
+		var wait time.Duration
+		switch {
+		case attempt.LockedUntil != nil:
+			// An expired lock restarts the count on the next failure.
+			wait = attempt.LockedUntil.Sub(now)
+		case g.cfg.FailureWindow > 0 && now.Sub(attempt.LastFailureAt) > g.cfg.FailureWindow:
+			continue
+		default:
+			wait = attempt.LastFailureAt.Add(g.cfg.delay(attempt.Failures)).Sub(now)
+		}
+		if wait <= 0 {
+			continue
+		}
+
+		if attempt.LockedUntil != nil && strings.HasPrefix(attempt.Key, userLoginKeyPrefix) {
+			lockedFor = max(lockedFor, wait)
+		} else {
+			throttledFor = max(throttledFor, wait)
+		}
+	}
+
+	if lockedFor > 0 {
+		return nil, AccountLockedError.WithRetryAfter(lockedFor)
+	}
+	if throttledFor > 0 {
+		return nil, LoginThrottledError.WithRetryAfter(throttledFor)
+	}
+	return attempts, nil
+}
+
+// fail counts a wrong password for username and clientIP and logs the
+// lockouts it causes.
+func (g *loginGuard) fail(ctx context.Context, username, clientIP string) error {
+	if !g.cfg.enabled() {
+		return nil
+	}
+
+	for _, key := range loginKeys(username, clientIP) {
+		lockAfter := g.cfg.UserMaxFailures
+		if strings.HasPrefix(key, ipLoginKeyPrefix) {
+			lockAfter = g.cfg.IPMaxFailures
+		}
+
+		attempt, err := g.repo.RecordLoginFailure(ctx, repo.RecordLoginFailureParams{
+			Key:       key,
+			Window:    g.cfg.FailureWindow,
+			LockAfter: lockAfter,
+			LockFor:   g.cfg.LockoutDuration,
+		})
+		if err != nil {
+			return fmt.Errorf("g.repo.RecordLoginFailure: %w", err)
+		}
+
+		if attempt.LockedUntil != nil && attempt.Failures == lockAfter {
+			g.log(ctx).Warn("login locked after failed attempts",
+				zap.String("key", key),
+				zap.String("username", username),
+				zap.String("client_ip", clientIP),
+				zap.Int("failures", attempt.Failures),
+				zap.Time("locked_until", *attempt.LockedUntil),
+			)
+		}
+	}
+	return nil
+}
+
+func (g *loginGuard) reset(ctx context.Context, keys []string) error {
+	if err := g.repo.ResetLoginAttempts(ctx, keys); err != nil {
+		return fmt.Errorf("g.repo.ResetLoginAttempts: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package services
+
+// Metrics counts business events for monitoring.
+type Metrics interface {
+	ObserveTransfer(amount int)
+	ObservePurchase(item string)
+	// ObserveLogin records a login attempt by method: "password", "otp" for
+	// the second factor, or "oidc".
+	ObserveLogin(method string, success bool)
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) ObserveTransfer(int)       {}
+func (nopMetrics) ObservePurchase(string)    {}
+func (nopMetrics) ObserveLogin(string, bool) {}
@@ -0,0 +1,322 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/account.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	models "github.com/Blxssy/AvitoTest/internal/models"
+	services "github.com/Blxssy/AvitoTest/internal/services"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAccountService is a mock of AccountService interface.
+type MockAccountService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccountServiceMockRecorder
+}
+
+// MockAccountServiceMockRecorder is the mock recorder for MockAccountService.
+type MockAccountServiceMockRecorder struct {
+	mock *MockAccountService
+}
+
+// NewMockAccountService creates a new mock instance.
+func NewMockAccountService(ctrl *gomock.Controller) *MockAccountService {
+	mock := &MockAccountService{ctrl: ctrl}
+	mock.recorder = &MockAccountServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccountService) EXPECT() *MockAccountServiceMockRecorder {
+	return m.recorder
+}
+
+// ActivateTOTP mocks base method.
+func (m *MockAccountService) ActivateTOTP(ctx context.Context, params services.ActivateTOTPParams) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActivateTOTP", ctx, params)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ActivateTOTP indicates an expected call of ActivateTOTP.
+func (mr *MockAccountServiceMockRecorder) ActivateTOTP(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActivateTOTP", reflect.TypeOf((*MockAccountService)(nil).ActivateTOTP), ctx, params)
+}
+
+// ChangePassword mocks base method.
+func (m *MockAccountService) ChangePassword(ctx context.Context, params services.ChangePasswordParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockAccountServiceMockRecorder) ChangePassword(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockAccountService)(nil).ChangePassword), ctx, params)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockAccountService) CreateAPIKey(ctx context.Context, params services.CreateAPIKeyParams) (services.IssuedAPIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, params)
+	ret0, _ := ret[0].(services.IssuedAPIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockAccountServiceMockRecorder) CreateAPIKey(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockAccountService)(nil).CreateAPIKey), ctx, params)
+}
+
+// CreateServiceAccount mocks base method.
+func (m *MockAccountService) CreateServiceAccount(ctx context.Context, params services.CreateServiceAccountParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateServiceAccount", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateServiceAccount indicates an expected call of CreateServiceAccount.
+func (mr *MockAccountServiceMockRecorder) CreateServiceAccount(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateServiceAccount", reflect.TypeOf((*MockAccountService)(nil).CreateServiceAccount), ctx, params)
+}
+
+// DisableTOTP mocks base method.
+func (m *MockAccountService) DisableTOTP(ctx context.Context, params services.DisableTOTPParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTP", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTP indicates an expected call of DisableTOTP.
+func (mr *MockAccountServiceMockRecorder) DisableTOTP(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTP", reflect.TypeOf((*MockAccountService)(nil).DisableTOTP), ctx, params)
+}
+
+// EnrollTOTP mocks base method.
+func (m *MockAccountService) EnrollTOTP(ctx context.Context, params services.EnrollTOTPParams) (services.TOTPEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollTOTP", ctx, params)
+	ret0, _ := ret[0].(services.TOTPEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollTOTP indicates an expected call of EnrollTOTP.
+func (mr *MockAccountServiceMockRecorder) EnrollTOTP(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollTOTP", reflect.TypeOf((*MockAccountService)(nil).EnrollTOTP), ctx, params)
+}
+
+// GetTwoFactorPolicy mocks base method.
+func (m *MockAccountService) GetTwoFactorPolicy(ctx context.Context, params services.GetTwoFactorPolicyParams) (models.TwoFactorPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTwoFactorPolicy", ctx, params)
+	ret0, _ := ret[0].(models.TwoFactorPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTwoFactorPolicy indicates an expected call of GetTwoFactorPolicy.
+func (mr *MockAccountServiceMockRecorder) GetTwoFactorPolicy(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTwoFactorPolicy", reflect.TypeOf((*MockAccountService)(nil).GetTwoFactorPolicy), ctx, params)
+}
+
+// GrantDelegation mocks base method.
+func (m *MockAccountService) GrantDelegation(ctx context.Context, params services.GrantDelegationParams) (models.Delegation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantDelegation", ctx, params)
+	ret0, _ := ret[0].(models.Delegation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GrantDelegation indicates an expected call of GrantDelegation.
+func (mr *MockAccountServiceMockRecorder) GrantDelegation(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantDelegation", reflect.TypeOf((*MockAccountService)(nil).GrantDelegation), ctx, params)
+}
+
+// IssuePasswordReset mocks base method.
+func (m *MockAccountService) IssuePasswordReset(ctx context.Context, params services.IssuePasswordResetParams) (services.PasswordReset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssuePasswordReset", ctx, params)
+	ret0, _ := ret[0].(services.PasswordReset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssuePasswordReset indicates an expected call of IssuePasswordReset.
+func (mr *MockAccountServiceMockRecorder) IssuePasswordReset(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssuePasswordReset", reflect.TypeOf((*MockAccountService)(nil).IssuePasswordReset), ctx, params)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockAccountService) ListAPIKeys(ctx context.Context, params services.ListAPIKeysParams) ([]models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", ctx, params)
+	ret0, _ := ret[0].([]models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockAccountServiceMockRecorder) ListAPIKeys(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockAccountService)(nil).ListAPIKeys), ctx, params)
+}
+
+// ListDelegations mocks base method.
+func (m *MockAccountService) ListDelegations(ctx context.Context, params services.ListDelegationsParams) ([]models.Delegation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDelegations", ctx, params)
+	ret0, _ := ret[0].([]models.Delegation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDelegations indicates an expected call of ListDelegations.
+func (mr *MockAccountServiceMockRecorder) ListDelegations(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDelegations", reflect.TypeOf((*MockAccountService)(nil).ListDelegations), ctx, params)
+}
+
+// ReloadConfig mocks base method.
+func (m *MockAccountService) ReloadConfig(ctx context.Context, params services.ReloadConfigParams) (services.ReloadedConfig, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReloadConfig", ctx, params)
+	ret0, _ := ret[0].(services.ReloadedConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReloadConfig indicates an expected call of ReloadConfig.
+func (mr *MockAccountServiceMockRecorder) ReloadConfig(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReloadConfig", reflect.TypeOf((*MockAccountService)(nil).ReloadConfig), ctx, params)
+}
+
+// ResetPassword mocks base method.
+func (m *MockAccountService) ResetPassword(ctx context.Context, params services.ResetPasswordParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockAccountServiceMockRecorder) ResetPassword(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockAccountService)(nil).ResetPassword), ctx, params)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockAccountService) RevokeAPIKey(ctx context.Context, params services.RevokeAPIKeyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockAccountServiceMockRecorder) RevokeAPIKey(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockAccountService)(nil).RevokeAPIKey), ctx, params)
+}
+
+// RevokeDelegation mocks base method.
+func (m *MockAccountService) RevokeDelegation(ctx context.Context, params services.RevokeDelegationParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeDelegation", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeDelegation indicates an expected call of RevokeDelegation.
+func (mr *MockAccountServiceMockRecorder) RevokeDelegation(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeDelegation", reflect.TypeOf((*MockAccountService)(nil).RevokeDelegation), ctx, params)
+}
+
+// SetTwoFactorPolicy mocks base method.
+func (m *MockAccountService) SetTwoFactorPolicy(ctx context.Context, params services.SetTwoFactorPolicyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTwoFactorPolicy", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTwoFactorPolicy indicates an expected call of SetTwoFactorPolicy.
+func (mr *MockAccountServiceMockRecorder) SetTwoFactorPolicy(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTwoFactorPolicy", reflect.TypeOf((*MockAccountService)(nil).SetTwoFactorPolicy), ctx, params)
+}
+
+// UnlockUser mocks base method.
+func (m *MockAccountService) UnlockUser(ctx context.Context, params services.UnlockUserParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlockUser", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnlockUser indicates an expected call of UnlockUser.
+func (mr *MockAccountServiceMockRecorder) UnlockUser(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockUser", reflect.TypeOf((*MockAccountService)(nil).UnlockUser), ctx, params)
+}
+
+// MockConfigReloader is a mock of ConfigReloader interface.
+type MockConfigReloader struct {
+	ctrl     *gomock.Controller
+	recorder *MockConfigReloaderMockRecorder
+}
+
+// MockConfigReloaderMockRecorder is the mock recorder for MockConfigReloader.
+type MockConfigReloaderMockRecorder struct {
+	mock *MockConfigReloader
+}
+
+// NewMockConfigReloader creates a new mock instance.
+func NewMockConfigReloader(ctrl *gomock.Controller) *MockConfigReloader {
+	mock := &MockConfigReloader{ctrl: ctrl}
+	mock.recorder = &MockConfigReloaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockConfigReloader) EXPECT() *MockConfigReloaderMockRecorder {
+	return m.recorder
+}
+
+// Reload mocks base method.
+func (m *MockConfigReloader) Reload(ctx context.Context) (services.ReloadedConfig, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reload", ctx)
+	ret0, _ := ret[0].(services.ReloadedConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reload indicates an expected call of Reload.
+func (mr *MockConfigReloaderMockRecorder) Reload(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reload", reflect.TypeOf((*MockConfigReloader)(nil).Reload), ctx)
+}
@@ -37,10 +37,10 @@ func (m *MockCoinService) EXPECT() *MockCoinServiceMockRecorder {
 }
 
 // Auth mocks base method.
-func (m *MockCoinService) Auth(ctx context.Context, params services.AuthParams) (string, error) {
+func (m *MockCoinService) Auth(ctx context.Context, params services.AuthParams) (services.AuthResult, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Auth", ctx, params)
-	ret0, _ := ret[0].(string)
+	ret0, _ := ret[0].(services.AuthResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -65,6 +65,21 @@ func (mr *MockCoinServiceMockRecorder) BuyItem(ctx, params interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BuyItem", reflect.TypeOf((*MockCoinService)(nil).BuyItem), ctx, params)
 }
 
+// CompleteTwoFactorAuth mocks base method.
+func (m *MockCoinService) CompleteTwoFactorAuth(ctx context.Context, params services.TwoFactorAuthParams) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteTwoFactorAuth", ctx, params)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteTwoFactorAuth indicates an expected call of CompleteTwoFactorAuth.
+func (mr *MockCoinServiceMockRecorder) CompleteTwoFactorAuth(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteTwoFactorAuth", reflect.TypeOf((*MockCoinService)(nil).CompleteTwoFactorAuth), ctx, params)
+}
+
 // GetBalance mocks base method.
 func (m *MockCoinService) GetBalance(ctx context.Context, params services.GetBalanceParams) (int, error) {
 	m.ctrl.T.Helper()
@@ -80,6 +95,21 @@ func (mr *MockCoinServiceMockRecorder) GetBalance(ctx, params interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockCoinService)(nil).GetBalance), ctx, params)
 }
 
+// GetItems mocks base method.
+func (m *MockCoinService) GetItems(ctx context.Context, params services.GetItemsParams) ([]models.Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItems", ctx, params)
+	ret0, _ := ret[0].([]models.Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetItems indicates an expected call of GetItems.
+func (mr *MockCoinServiceMockRecorder) GetItems(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItems", reflect.TypeOf((*MockCoinService)(nil).GetItems), ctx, params)
+}
+
 // GetPurchases mocks base method.
 func (m *MockCoinService) GetPurchases(ctx context.Context, params services.GetPurchasesParams) ([]models.PurchaseItem, error) {
 	m.ctrl.T.Helper()
@@ -95,6 +125,51 @@ func (mr *MockCoinServiceMockRecorder) GetPurchases(ctx, params interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPurchases", reflect.TypeOf((*MockCoinService)(nil).GetPurchases), ctx, params)
 }
 
+// GetTransfer mocks base method.
+func (m *MockCoinService) GetTransfer(ctx context.Context, params services.GetTransferParams) (services.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransfer", ctx, params)
+	ret0, _ := ret[0].(services.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransfer indicates an expected call of GetTransfer.
+func (mr *MockCoinServiceMockRecorder) GetTransfer(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransfer", reflect.TypeOf((*MockCoinService)(nil).GetTransfer), ctx, params)
+}
+
+// OIDCCallback mocks base method.
+func (m *MockCoinService) OIDCCallback(ctx context.Context, params services.OIDCCallbackParams) (services.AuthResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OIDCCallback", ctx, params)
+	ret0, _ := ret[0].(services.AuthResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OIDCCallback indicates an expected call of OIDCCallback.
+func (mr *MockCoinServiceMockRecorder) OIDCCallback(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OIDCCallback", reflect.TypeOf((*MockCoinService)(nil).OIDCCallback), ctx, params)
+}
+
+// OIDCLoginURL mocks base method.
+func (m *MockCoinService) OIDCLoginURL(ctx context.Context) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OIDCLoginURL", ctx)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OIDCLoginURL indicates an expected call of OIDCLoginURL.
+func (mr *MockCoinServiceMockRecorder) OIDCLoginURL(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OIDCLoginURL", reflect.TypeOf((*MockCoinService)(nil).OIDCLoginURL), ctx)
+}
+
 // ReceivedCoinsInfo mocks base method.
 func (m *MockCoinService) ReceivedCoinsInfo(ctx context.Context, params services.GetTransactionsParams) ([]models.Transaction, error) {
 	m.ctrl.T.Helper()
@@ -111,11 +186,12 @@ func (mr *MockCoinServiceMockRecorder) ReceivedCoinsInfo(ctx, params interface{}
 }
 
 // SendCoins mocks base method.
-func (m *MockCoinService) SendCoins(ctx context.Context, params services.TransactionParams) error {
+func (m *MockCoinService) SendCoins(ctx context.Context, params services.TransactionParams) (int64, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SendCoins", ctx, params)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // SendCoins indicates an expected call of SendCoins.
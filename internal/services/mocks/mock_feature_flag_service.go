@@ -0,0 +1,175 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/flags.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	models "github.com/Blxssy/AvitoTest/internal/models"
+	services "github.com/Blxssy/AvitoTest/internal/services"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockFeatureFlags is a mock of FeatureFlags interface.
+type MockFeatureFlags struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeatureFlagsMockRecorder
+}
+
+// MockFeatureFlagsMockRecorder is the mock recorder for MockFeatureFlags.
+type MockFeatureFlagsMockRecorder struct {
+	mock *MockFeatureFlags
+}
+
+// NewMockFeatureFlags creates a new mock instance.
+func NewMockFeatureFlags(ctrl *gomock.Controller) *MockFeatureFlags {
+	mock := &MockFeatureFlags{ctrl: ctrl}
+	mock.recorder = &MockFeatureFlagsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeatureFlags) EXPECT() *MockFeatureFlagsMockRecorder {
+	return m.recorder
+}
+
+// Enabled mocks base method.
+func (m *MockFeatureFlags) Enabled(flag, username string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enabled", flag, username)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Enabled indicates an expected call of Enabled.
+func (mr *MockFeatureFlagsMockRecorder) Enabled(flag, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enabled", reflect.TypeOf((*MockFeatureFlags)(nil).Enabled), flag, username)
+}
+
+// MockFeatureFlagService is a mock of FeatureFlagService interface.
+type MockFeatureFlagService struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeatureFlagServiceMockRecorder
+}
+
+// MockFeatureFlagServiceMockRecorder is the mock recorder for MockFeatureFlagService.
+type MockFeatureFlagServiceMockRecorder struct {
+	mock *MockFeatureFlagService
+}
+
+// NewMockFeatureFlagService creates a new mock instance.
+func NewMockFeatureFlagService(ctrl *gomock.Controller) *MockFeatureFlagService {
+	mock := &MockFeatureFlagService{ctrl: ctrl}
+	mock.recorder = &MockFeatureFlagServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeatureFlagService) EXPECT() *MockFeatureFlagServiceMockRecorder {
+	return m.recorder
+}
+
+// DeleteFeatureFlag mocks base method.
+func (m *MockFeatureFlagService) DeleteFeatureFlag(ctx context.Context, params services.DeleteFeatureFlagParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFeatureFlag", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFeatureFlag indicates an expected call of DeleteFeatureFlag.
+func (mr *MockFeatureFlagServiceMockRecorder) DeleteFeatureFlag(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFeatureFlag", reflect.TypeOf((*MockFeatureFlagService)(nil).DeleteFeatureFlag), ctx, params)
+}
+
+// Enabled mocks base method.
+func (m *MockFeatureFlagService) Enabled(flag, username string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enabled", flag, username)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Enabled indicates an expected call of Enabled.
+func (mr *MockFeatureFlagServiceMockRecorder) Enabled(flag, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enabled", reflect.TypeOf((*MockFeatureFlagService)(nil).Enabled), flag, username)
+}
+
+// GetFeatures mocks base method.
+func (m *MockFeatureFlagService) GetFeatures(ctx context.Context, params services.GetFeaturesParams) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeatures", ctx, params)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeatures indicates an expected call of GetFeatures.
+func (mr *MockFeatureFlagServiceMockRecorder) GetFeatures(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeatures", reflect.TypeOf((*MockFeatureFlagService)(nil).GetFeatures), ctx, params)
+}
+
+// ListFeatureFlags mocks base method.
+func (m *MockFeatureFlagService) ListFeatureFlags(ctx context.Context, params services.ListFeatureFlagsParams) ([]models.FeatureFlag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFeatureFlags", ctx, params)
+	ret0, _ := ret[0].([]models.FeatureFlag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFeatureFlags indicates an expected call of ListFeatureFlags.
+func (mr *MockFeatureFlagServiceMockRecorder) ListFeatureFlags(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFeatureFlags", reflect.TypeOf((*MockFeatureFlagService)(nil).ListFeatureFlags), ctx, params)
+}
+
+// Refresh mocks base method.
+func (m *MockFeatureFlagService) Refresh(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockFeatureFlagServiceMockRecorder) Refresh(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockFeatureFlagService)(nil).Refresh), ctx)
+}
+
+// Run mocks base method.
+func (m *MockFeatureFlagService) Run(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Run", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockFeatureFlagServiceMockRecorder) Run(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockFeatureFlagService)(nil).Run), ctx)
+}
+
+// SetFeatureFlag mocks base method.
+func (m *MockFeatureFlagService) SetFeatureFlag(ctx context.Context, params services.SetFeatureFlagParams) (models.FeatureFlag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFeatureFlag", ctx, params)
+	ret0, _ := ret[0].(models.FeatureFlag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetFeatureFlag indicates an expected call of SetFeatureFlag.
+func (mr *MockFeatureFlagServiceMockRecorder) SetFeatureFlag(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFeatureFlag", reflect.TypeOf((*MockFeatureFlagService)(nil).SetFeatureFlag), ctx, params)
+}
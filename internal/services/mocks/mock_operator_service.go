@@ -0,0 +1,184 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/operator.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	models "github.com/Blxssy/AvitoTest/internal/models"
+	services "github.com/Blxssy/AvitoTest/internal/services"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockOperatorService is a mock of OperatorService interface.
+type MockOperatorService struct {
+	ctrl     *gomock.Controller
+	recorder *MockOperatorServiceMockRecorder
+}
+
+// MockOperatorServiceMockRecorder is the mock recorder for MockOperatorService.
+type MockOperatorServiceMockRecorder struct {
+	mock *MockOperatorService
+}
+
+// NewMockOperatorService creates a new mock instance.
+func NewMockOperatorService(ctrl *gomock.Controller) *MockOperatorService {
+	mock := &MockOperatorService{ctrl: ctrl}
+	mock.recorder = &MockOperatorServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOperatorService) EXPECT() *MockOperatorServiceMockRecorder {
+	return m.recorder
+}
+
+// AdjustBalance mocks base method.
+func (m *MockOperatorService) AdjustBalance(ctx context.Context, params services.AdjustBalanceParams) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustBalance", ctx, params)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdjustBalance indicates an expected call of AdjustBalance.
+func (mr *MockOperatorServiceMockRecorder) AdjustBalance(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustBalance", reflect.TypeOf((*MockOperatorService)(nil).AdjustBalance), ctx, params)
+}
+
+// CreateUser mocks base method.
+func (m *MockOperatorService) CreateUser(ctx context.Context, params services.CreateUserParams) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", ctx, params)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockOperatorServiceMockRecorder) CreateUser(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockOperatorService)(nil).CreateUser), ctx, params)
+}
+
+// DeleteItem mocks base method.
+func (m *MockOperatorService) DeleteItem(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteItem", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteItem indicates an expected call of DeleteItem.
+func (mr *MockOperatorServiceMockRecorder) DeleteItem(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteItem", reflect.TypeOf((*MockOperatorService)(nil).DeleteItem), ctx, name)
+}
+
+// GetAccount mocks base method.
+func (m *MockOperatorService) GetAccount(ctx context.Context, username string) (services.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccount", ctx, username)
+	ret0, _ := ret[0].(services.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccount indicates an expected call of GetAccount.
+func (mr *MockOperatorServiceMockRecorder) GetAccount(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockOperatorService)(nil).GetAccount), ctx, username)
+}
+
+// GetTransaction mocks base method.
+func (m *MockOperatorService) GetTransaction(ctx context.Context, id int64) (models.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransaction", ctx, id)
+	ret0, _ := ret[0].(models.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransaction indicates an expected call of GetTransaction.
+func (mr *MockOperatorServiceMockRecorder) GetTransaction(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransaction", reflect.TypeOf((*MockOperatorService)(nil).GetTransaction), ctx, id)
+}
+
+// ListItems mocks base method.
+func (m *MockOperatorService) ListItems(ctx context.Context) ([]models.Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListItems", ctx)
+	ret0, _ := ret[0].([]models.Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListItems indicates an expected call of ListItems.
+func (mr *MockOperatorServiceMockRecorder) ListItems(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListItems", reflect.TypeOf((*MockOperatorService)(nil).ListItems), ctx)
+}
+
+// ListTransactions mocks base method.
+func (m *MockOperatorService) ListTransactions(ctx context.Context, params services.ListTransactionsParams) ([]models.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransactions", ctx, params)
+	ret0, _ := ret[0].([]models.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTransactions indicates an expected call of ListTransactions.
+func (mr *MockOperatorServiceMockRecorder) ListTransactions(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransactions", reflect.TypeOf((*MockOperatorService)(nil).ListTransactions), ctx, params)
+}
+
+// SaveItem mocks base method.
+func (m *MockOperatorService) SaveItem(ctx context.Context, item models.Item) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveItem", ctx, item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveItem indicates an expected call of SaveItem.
+func (mr *MockOperatorServiceMockRecorder) SaveItem(ctx, item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveItem", reflect.TypeOf((*MockOperatorService)(nil).SaveItem), ctx, item)
+}
+
+// SetAccountFrozen mocks base method.
+func (m *MockOperatorService) SetAccountFrozen(ctx context.Context, params services.SetAccountFrozenParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAccountFrozen", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAccountFrozen indicates an expected call of SetAccountFrozen.
+func (mr *MockOperatorServiceMockRecorder) SetAccountFrozen(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAccountFrozen", reflect.TypeOf((*MockOperatorService)(nil).SetAccountFrozen), ctx, params)
+}
+
+// VerifyLedger mocks base method.
+func (m *MockOperatorService) VerifyLedger(ctx context.Context) ([]models.LedgerMismatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyLedger", ctx)
+	ret0, _ := ret[0].([]models.LedgerMismatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyLedger indicates an expected call of VerifyLedger.
+func (mr *MockOperatorServiceMockRecorder) VerifyLedger(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyLedger", reflect.TypeOf((*MockOperatorService)(nil).VerifyLedger), ctx)
+}
@@ -0,0 +1,309 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/repo"
+	"github.com/Blxssy/AvitoTest/pkg/token"
+	"net/mail"
+	"regexp"
+	"slices"
+)
+
+const (
+	defaultNotificationsLimit = 20
+	maxNotificationsLimit     = 100
+)
+
+// deliveredNotificationTypes are sent through the notifier on top of being
+// stored in the inbox.
+var deliveredNotificationTypes = []models.NotificationType{
+	models.NotificationCoinsReceived,
+	models.NotificationAdminMessage,
+}
+
+var localePattern = regexp.MustCompile(`^[a-z]{2}(-[A-Z]{2})?$`)
+
+type NotificationService interface {
+	GetNotifications(ctx context.Context, params GetNotificationsParams) (NotificationsPage, error)
+	MarkRead(ctx context.Context, params MarkNotificationReadParams) error
+	MarkAllRead(ctx context.Context, params MarkAllNotificationsReadParams) (int64, error)
+	GetPreferences(ctx context.Context, params GetNotificationPreferencesParams) ([]models.NotificationPreference, error)
+	UpdatePreferences(ctx context.Context, params UpdateNotificationPreferencesParams) error
+	UpdateContact(ctx context.Context, params UpdateNotificationContactParams) error
+	SendAdminMessage(ctx context.Context, params AdminMessageParams) error
+}
+
+type notificationService struct {
+	repo     repo.CoinRepository
+	tokenGen token.TokenGenerator
+	auth     Authenticator
+}
+
+type NotificationServiceConfig struct {
+	// Authenticator resolves access tokens and API keys; by default one over
+	// repo and tg.
+	Authenticator Authenticator
+}
+
+func NewNotificationService(repo repo.CoinRepository, tg token.TokenGenerator, cfg NotificationServiceConfig) NotificationService {
+	auth := cfg.Authenticator
+	if auth == nil {
+		auth = NewAuthenticator(repo, tg)
+	}
+
+	return &notificationService{
+		repo:     repo,
+		tokenGen: tg,
+		auth:     auth,
+	}
+}
+
+type NotificationsPage struct {
+	Notifications []models.Notification
+	UnreadCount   int
+	// NextCursor is zero when there are no more notifications.
+	NextCursor int64
+}
+
+func (s *notificationService) GetNotifications(ctx context.Context, params GetNotificationsParams) (NotificationsPage, error) {
+	username, err := s.auth.Authenticate(ctx, params.Token, ScopeNotificationsRead)
+	if err != nil {
+		return NotificationsPage{}, fmt.Errorf("s.auth.Authenticate: %w", err)
+	}
+
+	limit := params.Limit
+	if limit <= 0 {
+		limit = defaultNotificationsLimit
+	}
+	limit = min(limit, maxNotificationsLimit)
+
+	// One extra row tells whether there is a next page.
+	notifications, err := s.repo.GetNotifications(ctx, repo.GetNotificationsParams{
+		Username:   username,
+		BeforeID:   params.Cursor,
+		UnreadOnly: params.UnreadOnly,
+		Limit:      limit + 1,
+	})
+	if err != nil {
+		return NotificationsPage{}, fmt.Errorf("s.repo.GetNotifications: %w", err)
+	}
+
+	unread, err := s.repo.CountUnreadNotifications(ctx, username)
+	if err != nil {
+		return NotificationsPage{}, fmt.Errorf("s.repo.CountUnreadNotifications: %w", err)
+	}
+
+	page := NotificationsPage{
+		Notifications: notifications,
+		UnreadCount:   unread,
+	}
+	if len(notifications) > limit {
+		page.Notifications = notifications[:limit]
+		page.NextCursor = notifications[limit-1].ID
+	}
+
+	return page, nil
+}
+
+func (s *notificationService) MarkRead(ctx context.Context, params MarkNotificationReadParams) error {
+	username, err := s.auth.Authenticate(ctx, params.Token, ScopeNotificationsWrite)
+	if err != nil {
+		return fmt.Errorf("s.auth.Authenticate: %w", err)
+	}
+
+	err = s.repo.MarkNotificationRead(ctx, repo.MarkNotificationReadParams{
+		Username: username,
+		ID:       params.ID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return NotificationNotFoundError
+		}
+		return fmt.Errorf("s.repo.MarkNotificationRead: %w", err)
+	}
+
+	return nil
+}
+
+func (s *notificationService) MarkAllRead(ctx context.Context, params MarkAllNotificationsReadParams) (int64, error) {
+	username, err := s.auth.Authenticate(ctx, params.Token, ScopeNotificationsWrite)
+	if err != nil {
+		return 0, fmt.Errorf("s.auth.Authenticate: %w", err)
+	}
+
+	updated, err := s.repo.MarkAllNotificationsRead(ctx, username)
+	if err != nil {
+		return 0, fmt.Errorf("s.repo.MarkAllNotificationsRead: %w", err)
+	}
+
+	return updated, nil
+}
+
+// GetPreferences lists every notification type, including the ones the user
+// never changed.
+func (s *notificationService) GetPreferences(ctx context.Context, params GetNotificationPreferencesParams) ([]models.NotificationPreference, error) {
+	username, err := s.auth.Authenticate(ctx, params.Token, ScopeNotificationsRead)
+	if err != nil {
+		return nil, fmt.Errorf("s.auth.Authenticate: %w", err)
+	}
+
+	stored, err := s.repo.GetNotificationPreferences(ctx, username)
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.GetNotificationPreferences: %w", err)
+	}
+
+	muted := make(map[models.NotificationType]bool, len(stored))
+	for _, pref := range stored {
+		muted[pref.Type] = pref.Muted
+	}
+
+	preferences := make([]models.NotificationPreference, len(models.NotificationTypes))
+	for i, notificationType := range models.NotificationTypes {
+		preferences[i] = models.NotificationPreference{
+			Type:  notificationType,
+			Muted: muted[notificationType],
+		}
+	}
+
+	return preferences, nil
+}
+
+func (s *notificationService) UpdatePreferences(ctx context.Context, params UpdateNotificationPreferencesParams) error {
+	username, err := s.auth.Authenticate(ctx, params.Token, ScopeNotificationsWrite)
+	if err != nil {
+		return fmt.Errorf("s.auth.Authenticate: %w", err)
+	}
+
+	for _, pref := range params.Preferences {
+		if !isNotificationType(pref.Type) {
+			return fmt.Errorf("%w: %q", UnknownNotificationTypeError, pref.Type)
+		}
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			err = s.repo.RollbackTx(tx)
+			if err != nil {
+				err = fmt.Errorf("s.repo.RollbackTx: %w", err)
+			}
+		}
+	}()
+
+	for _, pref := range params.Preferences {
+		if err = s.repo.SetNotificationPreference(ctx, tx, repo.SetNotificationPreferenceParams{
+			Username: username,
+			Type:     pref.Type,
+			Muted:    pref.Muted,
+		}); err != nil {
+			return fmt.Errorf("s.repo.SetNotificationPreference: %w", err)
+		}
+	}
+
+	if err = s.repo.CommitTx(tx); err != nil {
+		return fmt.Errorf("s.repo.CommitTx: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateContact sets where and in which language delivered notifications are
+// sent. An empty email turns email delivery off.
+func (s *notificationService) UpdateContact(ctx context.Context, params UpdateNotificationContactParams) error {
+	username, err := s.auth.Authenticate(ctx, params.Token, ScopeNotificationsWrite)
+	if err != nil {
+		return fmt.Errorf("s.auth.Authenticate: %w", err)
+	}
+
+	email := params.Email
+	if email != "" {
+		address, err := mail.ParseAddress(email)
+		if err != nil {
+			return InvalidEmailError
+		}
+		// Deliveries send it as the recipient, which takes no display name.
+		email = address.Address
+	}
+	if !localePattern.MatchString(params.Locale) {
+		return InvalidLocaleError
+	}
+
+	err = s.repo.SetNotificationContact(ctx, repo.SetNotificationContactParams{
+		Username: username,
+		Email:    email,
+		Locale:   params.Locale,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return InvalidTokenError
+		}
+		return fmt.Errorf("s.repo.SetNotificationContact: %w", err)
+	}
+
+	return nil
+}
+
+// SendAdminMessage delivers a message from an admin to a user's inbox and
+// event stream.
+func (s *notificationService) SendAdminMessage(ctx context.Context, params AdminMessageParams) error {
+	adminUsername, err := authorizeAdmin(ctx, s.repo, s.tokenGen, params.Token)
+	if err != nil {
+		return err
+	}
+
+	if params.Message == "" {
+		return EmptyMessageError
+	}
+
+	_, err = s.repo.GetUserByUsername(ctx, params.ReceiverUsername)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ReceiverNotFoundError
+		}
+		return fmt.Errorf("s.repo.GetUserByUsername: %w", err)
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			err = s.repo.RollbackTx(tx)
+			if err != nil {
+				err = fmt.Errorf("s.repo.RollbackTx: %w", err)
+			}
+		}
+	}()
+
+	if err = saveEvents(ctx, s.repo, tx, repo.SaveEventParams{
+		Username: params.ReceiverUsername,
+		Type:     models.EventAdminMessage,
+		Payload:  models.AdminMessagePayload{FromUser: adminUsername, Message: params.Message},
+	}); err != nil {
+		return err
+	}
+
+	if err = s.repo.CommitTx(tx); err != nil {
+		return fmt.Errorf("s.repo.CommitTx: %w", err)
+	}
+
+	return nil
+}
+
+func isNotificationType(notificationType models.NotificationType) bool {
+	return slices.Contains(models.NotificationTypes, notificationType)
+}
+
+func isDeliveredNotificationType(notificationType models.NotificationType) bool {
+	return slices.Contains(deliveredNotificationTypes, notificationType)
+}
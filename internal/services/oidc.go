@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/repo"
+	"github.com/Blxssy/AvitoTest/pkg/logger"
+	"github.com/Blxssy/AvitoTest/pkg/oidc"
+	"go.uber.org/zap"
+	"time"
+)
+
+func (s *coinService) OIDCLoginURL(ctx context.Context) (string, error) {
+	if s.oidc == nil {
+		return "", OIDCDisabledError
+	}
+
+	state, err := newRandomToken()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := newRandomToken()
+	if err != nil {
+		return "", err
+	}
+	verifier, err := newRandomToken()
+	if err != nil {
+		return "", err
+	}
+
+	if err = s.repo.CreateOIDCLoginState(ctx, repo.CreateOIDCLoginStateParams{
+		StateHash:    hashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(s.oidcStateTTL),
+	}); err != nil {
+		return "", fmt.Errorf("s.repo.CreateOIDCLoginState: %w", err)
+	}
+
+	return s.oidc.AuthCodeURL(state, nonce, verifier), nil
+}
+
+func (s *coinService) OIDCCallback(ctx context.Context, params OIDCCallbackParams) (AuthResult, error) {
+	if s.oidc == nil {
+		return AuthResult{}, OIDCDisabledError
+	}
+
+	// The state is used up first, so a callback can't be replayed.
+	state, err := s.repo.UseOIDCLoginState(ctx, hashToken(params.State))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return AuthResult{}, InvalidOIDCStateError
+		}
+		return AuthResult{}, fmt.Errorf("s.repo.UseOIDCLoginState: %w", err)
+	}
+
+	identity, err := s.oidc.Exchange(ctx, params.Code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		s.log(ctx).Warn("identity provider login failed", zap.Error(err))
+		s.metrics.ObserveLogin("oidc", false)
+		return AuthResult{}, OIDCLoginFailedError
+	}
+
+	username, err := s.oidcUsername(ctx, identity)
+	if err != nil {
+		if errors.Is(err, OIDCLoginFailedError) {
+			s.metrics.ObserveLogin("oidc", false)
+		}
+		return AuthResult{}, err
+	}
+	logger.SetUsername(ctx, username)
+	s.metrics.ObserveLogin("oidc", true)
+
+	user, err := s.repo.GetUserByUsername(ctx, username)
+	if err != nil {
+		return AuthResult{}, fmt.Errorf("s.repo.GetUserByUsername: %w", err)
+	}
+	if user.FrozenAt != nil {
+		return AuthResult{}, AccountFrozenError
+	}
+
+	// The identity provider checks the password, not the second factor the
+	// user set up here.
+	if user.TOTPEnabled {
+		return s.newChallenge(ctx, user.Username)
+	}
+
+	accessToken, err := s.tokenGen.NewToken(user.Username)
+	if err != nil {
+		return AuthResult{}, fmt.Errorf("s.tokenGen.NewToken: %w", err)
+	}
+
+	return AuthResult{Token: accessToken}, nil
+}
+
+// oidcUsername returns the user an identity is linked to. An identity seen
+// for the first time is linked to the user named by its username claim,
+// who is registered if needed. Users with a password are never linked, or
+// whoever controls a matching claim at the provider would take them over.
+func (s *coinService) oidcUsername(ctx context.Context, identity oidc.Identity) (string, error) {
+	username, err := s.repo.GetOIDCIdentity(ctx, repo.GetOIDCIdentityParams{
+		Issuer:  identity.Issuer,
+		Subject: identity.Subject,
+	})
+	if err == nil {
+		return username, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("s.repo.GetOIDCIdentity: %w", err)
+	}
+
+	if identity.Username == "" || IsAPIKey(identity.Username) {
+		return "", OIDCLoginFailedError
+	}
+
+	user, err := s.repo.GetUserByUsername(ctx, identity.Username)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("s.repo.GetUserByUsername: %w", err)
+	}
+	if user != nil && (user.IsServiceAccount || user.PasswordHash != "") {
+		s.log(ctx).Warn("sso identity matches a user with other credentials",
+			zap.String("username", identity.Username),
+			zap.String("issuer", identity.Issuer),
+		)
+		return "", OIDCLoginFailedError
+	}
+
+	if err = s.repo.LinkOIDCIdentity(ctx, repo.LinkOIDCIdentityParams{
+		Issuer:   identity.Issuer,
+		Subject:  identity.Subject,
+		Username: identity.Username,
+		Balance:  s.startBalance,
+	}); err != nil {
+		// The user got a password since it was looked up.
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", OIDCLoginFailedError
+		}
+		return "", fmt.Errorf("s.repo.LinkOIDCIdentity: %w", err)
+	}
+
+	s.log(ctx).Info("sso identity linked",
+		zap.String("username", identity.Username),
+		zap.String("issuer", identity.Issuer),
+		zap.Bool("registered", user == nil),
+	)
+	return identity.Username, nil
+}
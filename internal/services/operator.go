@@ -0,0 +1,266 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/repo"
+	"github.com/Blxssy/AvitoTest/pkg/password"
+)
+
+const (
+	defaultTransactionsLimit = 50
+	maxTransactionsLimit     = 1000
+)
+
+// OperatorService does the maintenance operators run with coinctl. Its
+// callers already have access to the database, so it doesn't authenticate
+// them; Operator in the params only names them in the records.
+type OperatorService interface {
+	// CreateUser registers a user who logs in with password. Balance
+	// defaults to the configured starting balance when nil.
+	CreateUser(ctx context.Context, params CreateUserParams) (models.User, error)
+	GetAccount(ctx context.Context, username string) (Account, error)
+	// AdjustBalance adds params.Amount, which may be negative, to a balance
+	// and records the reason. It returns the new balance.
+	AdjustBalance(ctx context.Context, params AdjustBalanceParams) (int, error)
+	// SetAccountFrozen freezes or unfreezes an account. A frozen account
+	// can't log in, send coins or buy items, but still receives coins.
+	SetAccountFrozen(ctx context.Context, params SetAccountFrozenParams) error
+	ListTransactions(ctx context.Context, params ListTransactionsParams) ([]models.Transaction, error)
+	GetTransaction(ctx context.Context, id int64) (models.Transaction, error)
+	ListItems(ctx context.Context) ([]models.Item, error)
+	// SaveItem adds the item to the catalog or changes its price.
+	SaveItem(ctx context.Context, item models.Item) error
+	DeleteItem(ctx context.Context, name string) error
+	// VerifyLedger returns the accounts whose balance doesn't match their
+	// opening balance, transfers, purchases and adjustments.
+	VerifyLedger(ctx context.Context) ([]models.LedgerMismatch, error)
+}
+
+// Account is a user with the adjustments made to their balance.
+type Account struct {
+	User        models.User
+	Adjustments []models.BalanceAdjustment
+}
+
+type operatorService struct {
+	repo         repo.OperatorRepository
+	hasher       password.Hasher
+	startBalance int
+}
+
+type OperatorServiceConfig struct {
+	Hasher password.Hasher
+	// StartingBalance is given to created users unless another is set.
+	StartingBalance int
+}
+
+func NewOperatorService(repo repo.OperatorRepository, cfg OperatorServiceConfig) OperatorService {
+	return &operatorService{
+		repo:         repo,
+		hasher:       cfg.Hasher,
+		startBalance: cfg.StartingBalance,
+	}
+}
+
+func (s *operatorService) CreateUser(ctx context.Context, params CreateUserParams) (models.User, error) {
+	if params.Username == "" || IsAPIKey(params.Username) {
+		return models.User{}, InvalidUsernameError
+	}
+	if len(params.Password) < minPasswordLength {
+		return models.User{}, PasswordTooShortError
+	}
+	balance := s.startBalance
+	if params.Balance != nil {
+		balance = *params.Balance
+	}
+	if balance < 0 {
+		return models.User{}, InvalidBalanceError
+	}
+
+	_, err := s.repo.GetUserByUsername(ctx, params.Username)
+	if err == nil {
+		return models.User{}, UsernameTakenError
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, fmt.Errorf("s.repo.GetUserByUsername: %w", err)
+	}
+
+	passHash, algorithm, err := s.hasher.Hash(params.Password)
+	if err != nil {
+		return models.User{}, fmt.Errorf("s.hasher.Hash: %w", err)
+	}
+
+	if err = s.repo.CreateUser(ctx, repo.CreateUserParams{
+		Username:      params.Username,
+		PassHash:      passHash,
+		PassAlgorithm: algorithm,
+		Balance:       balance,
+		IsAdmin:       params.IsAdmin,
+	}); err != nil {
+		return models.User{}, fmt.Errorf("s.repo.CreateUser: %w", err)
+	}
+
+	return models.User{
+		Username: params.Username,
+		Balance:  balance,
+		IsAdmin:  params.IsAdmin,
+	}, nil
+}
+
+func (s *operatorService) GetAccount(ctx context.Context, username string) (Account, error) {
+	user, err := s.repo.GetUserByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Account{}, UserNotFoundError
+		}
+		return Account{}, fmt.Errorf("s.repo.GetUserByUsername: %w", err)
+	}
+
+	adjustments, err := s.repo.GetBalanceAdjustments(ctx, username)
+	if err != nil {
+		return Account{}, fmt.Errorf("s.repo.GetBalanceAdjustments: %w", err)
+	}
+
+	return Account{User: *user, Adjustments: adjustments}, nil
+}
+
+func (s *operatorService) AdjustBalance(ctx context.Context, params AdjustBalanceParams) (int, error) {
+	if params.Amount == 0 {
+		return 0, InvalidAdjustmentError
+	}
+	if params.Reason == "" {
+		return 0, ReasonRequiredError
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			err = s.repo.RollbackTx(tx)
+			if err != nil {
+				err = fmt.Errorf("s.repo.RollbackTx: %w", err)
+			}
+		}
+	}()
+
+	balance, err := s.repo.AdjustBalance(ctx, tx, repo.AdjustBalanceParams{
+		Username:  params.Username,
+		Amount:    params.Amount,
+		Reason:    params.Reason,
+		CreatedBy: params.Operator,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, UserNotFoundError
+		}
+		if errors.Is(err, repo.InsufficientFundsError) {
+			return 0, InsufficientFundsError
+		}
+		return 0, fmt.Errorf("s.repo.AdjustBalance: %w", err)
+	}
+
+	if err = saveEvents(ctx, s.repo, tx, repo.SaveEventParams{
+		Username: params.Username,
+		Type:     models.EventBalanceChanged,
+		Payload:  models.BalanceChangedPayload{Balance: balance, Delta: params.Amount},
+	}); err != nil {
+		return 0, err
+	}
+
+	if err = s.repo.CommitTx(tx); err != nil {
+		return 0, fmt.Errorf("s.repo.CommitTx: %w", err)
+	}
+
+	return balance, nil
+}
+
+func (s *operatorService) SetAccountFrozen(ctx context.Context, params SetAccountFrozenParams) error {
+	found, err := s.repo.SetUserFrozen(ctx, repo.SetUserFrozenParams{
+		Username: params.Username,
+		Frozen:   params.Frozen,
+	})
+	if err != nil {
+		return fmt.Errorf("s.repo.SetUserFrozen: %w", err)
+	}
+	if !found {
+		return UserNotFoundError
+	}
+	return nil
+}
+
+func (s *operatorService) ListTransactions(ctx context.Context, params ListTransactionsParams) ([]models.Transaction, error) {
+	limit := params.Limit
+	if limit <= 0 {
+		limit = defaultTransactionsLimit
+	}
+	limit = min(limit, maxTransactionsLimit)
+
+	transactions, err := s.repo.ListTransactions(ctx, repo.ListTransactionsParams{
+		Username: params.Username,
+		BeforeID: params.BeforeID,
+		Limit:    limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.ListTransactions: %w", err)
+	}
+	return transactions, nil
+}
+
+func (s *operatorService) GetTransaction(ctx context.Context, id int64) (models.Transaction, error) {
+	transaction, err := s.repo.GetTransaction(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Transaction{}, TransactionNotFoundError
+		}
+		return models.Transaction{}, fmt.Errorf("s.repo.GetTransaction: %w", err)
+	}
+	return transaction, nil
+}
+
+func (s *operatorService) ListItems(ctx context.Context) ([]models.Item, error) {
+	items, err := s.repo.GetItems(ctx, repo.GetItemsParams{})
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.GetItems: %w", err)
+	}
+	return items, nil
+}
+
+func (s *operatorService) SaveItem(ctx context.Context, item models.Item) error {
+	if item.Name == "" {
+		return InvalidItemNameError
+	}
+	if item.Price < 0 {
+		return InvalidPriceError
+	}
+
+	if err := s.repo.SaveItem(ctx, item); err != nil {
+		return fmt.Errorf("s.repo.SaveItem: %w", err)
+	}
+	return nil
+}
+
+func (s *operatorService) DeleteItem(ctx context.Context, name string) error {
+	deleted, err := s.repo.DeleteItem(ctx, name)
+	if err != nil {
+		return fmt.Errorf("s.repo.DeleteItem: %w", err)
+	}
+	if !deleted {
+		return ItemNotFoundError
+	}
+	return nil
+}
+
+func (s *operatorService) VerifyLedger(ctx context.Context) ([]models.LedgerMismatch, error) {
+	mismatches, err := s.repo.VerifyLedger(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.VerifyLedger: %w", err)
+	}
+	return mismatches, nil
+}
@@ -2,17 +2,30 @@ package services_test
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/events"
 	"github.com/Blxssy/AvitoTest/internal/models"
 	"github.com/Blxssy/AvitoTest/internal/repo"
 	"github.com/Blxssy/AvitoTest/internal/repo/mocks"
 	"github.com/Blxssy/AvitoTest/internal/services"
+	servicemocks "github.com/Blxssy/AvitoTest/internal/services/mocks"
+	"github.com/Blxssy/AvitoTest/pkg/oidc"
+	oidcmocks "github.com/Blxssy/AvitoTest/pkg/oidc/mocks"
+	"github.com/Blxssy/AvitoTest/pkg/oidc/oidctest"
+	"github.com/Blxssy/AvitoTest/pkg/password"
 	mocks2 "github.com/Blxssy/AvitoTest/pkg/token/mocks"
 	"github.com/golang/mock/gomock"
 	"github.com/jmoiron/sqlx"
+	"github.com/pquerna/otp/totp"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/crypto/bcrypt"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestGetBalance(t *testing.T) {
@@ -22,7 +35,7 @@ func TestGetBalance(t *testing.T) {
 	repoMock := mocks.NewMockCoinRepository(ctrl)
 	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
 
-	service := services.NewCoinService(repoMock, tokenGenMock)
+	service := services.NewCoinService(repoMock, tokenGenMock, services.CoinServiceConfig{})
 
 	ctx := context.Background()
 	tokenStr := "valid-token"
@@ -45,7 +58,7 @@ func TestAuth(t *testing.T) {
 	repoMock := mocks.NewMockCoinRepository(ctrl)
 	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
 
-	service := services.NewCoinService(repoMock, tokenGenMock)
+	service := services.NewCoinService(repoMock, tokenGenMock, services.CoinServiceConfig{Hasher: newHasher(t)})
 
 	ctx := context.Background()
 	params := services.AuthParams{Username: "testuser", Password: "password"}
@@ -54,17 +67,965 @@ func TestAuth(t *testing.T) {
 	repoMock.EXPECT().CreateUser(ctx, gomock.Any()).Return(nil)
 	tokenGenMock.EXPECT().NewToken(params.Username).Return("new-token", nil)
 
-	token, err := service.Auth(ctx, params)
+	result, err := service.Auth(ctx, params)
 	assert.NoError(t, err)
-	assert.Equal(t, "new-token", token)
+	assert.Equal(t, "new-token", result.Token)
 
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(params.Password), bcrypt.MinCost)
-	repoMock.EXPECT().GetUserByUsername(ctx, params.Username).Return(&models.User{Username: params.Username, PasswordHash: string(hashedPassword)}, nil)
+	repoMock.EXPECT().GetUserByUsername(ctx, params.Username).Return(&models.User{Username: params.Username, PasswordHash: string(hashedPassword), PasswordAlgorithm: password.Bcrypt}, nil)
 	tokenGenMock.EXPECT().NewToken(params.Username).Return("auth-token", nil)
 
-	token, err = service.Auth(ctx, params)
+	result, err = service.Auth(ctx, params)
 	assert.NoError(t, err)
-	assert.Equal(t, "auth-token", token)
+	assert.Equal(t, "auth-token", result.Token)
+}
+
+func newHasher(t *testing.T) password.Hasher {
+	hasher, err := password.NewHasher(password.Config{Algorithm: password.Bcrypt, BcryptCost: bcrypt.MinCost})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hasher
+}
+
+var loginProtection = services.LoginProtectionConfig{
+	UserMaxFailures: 5,
+	IPMaxFailures:   50,
+	LockoutDuration: 15 * time.Minute,
+	FailureWindow:   15 * time.Minute,
+	BaseDelay:       time.Second,
+	MaxDelay:        30 * time.Second,
+}
+
+func TestAuthLockedOut(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	service := services.NewCoinService(repoMock, tokenGenMock, services.CoinServiceConfig{Hasher: newHasher(t), LoginProtection: loginProtection})
+
+	ctx := context.Background()
+	params := services.AuthParams{Username: "testuser", Password: "password", ClientIP: "10.0.0.1"}
+	lockedUntil := time.Now().Add(10 * time.Minute)
+
+	repoMock.EXPECT().GetLoginAttempts(ctx, []string{"user:testuser", "ip:10.0.0.1"}).Return([]models.LoginAttempt{
+		{Key: "user:testuser", Failures: 5, LastFailureAt: time.Now(), LockedUntil: &lockedUntil},
+	}, nil)
+
+	_, err := service.Auth(ctx, params)
+	assert.ErrorIs(t, err, services.AccountLockedError)
+	assert.ErrorIs(t, err, services.TooManyRequestsError)
+
+	var domainErr *services.Error
+	if assert.ErrorAs(t, err, &domainErr) {
+		assert.InDelta(t, 10*time.Minute, domainErr.RetryAfter, float64(time.Second))
+	}
+}
+
+func TestAuthThrottled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	service := services.NewCoinService(repoMock, tokenGenMock, services.CoinServiceConfig{Hasher: newHasher(t), LoginProtection: loginProtection})
+
+	ctx := context.Background()
+	params := services.AuthParams{Username: "testuser", Password: "password", ClientIP: "10.0.0.1"}
+
+	// The third failure in a row means waiting 1s * 2 * 2.
+	repoMock.EXPECT().GetLoginAttempts(ctx, gomock.Any()).Return([]models.LoginAttempt{
+		{Key: "ip:10.0.0.1", Failures: 3, LastFailureAt: time.Now()},
+	}, nil)
+
+	_, err := service.Auth(ctx, params)
+	assert.ErrorIs(t, err, services.LoginThrottledError)
+
+	var domainErr *services.Error
+	if assert.ErrorAs(t, err, &domainErr) {
+		assert.InDelta(t, 4*time.Second, domainErr.RetryAfter, float64(time.Second))
+	}
+}
+
+func TestAuthCountsFailuresAndResetsOnSuccess(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	service := services.NewCoinService(repoMock, tokenGenMock, services.CoinServiceConfig{Hasher: newHasher(t), LoginProtection: loginProtection})
+
+	ctx := context.Background()
+	keys := []string{"user:testuser", "ip:10.0.0.1"}
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	user := &models.User{Username: "testuser", PasswordHash: string(hashedPassword), PasswordAlgorithm: password.Bcrypt}
+
+	repoMock.EXPECT().GetLoginAttempts(ctx, keys).Return(nil, nil)
+	repoMock.EXPECT().GetUserByUsername(ctx, "testuser").Return(user, nil)
+	repoMock.EXPECT().RecordLoginFailure(ctx, repo.RecordLoginFailureParams{
+		Key: "user:testuser", Window: 15 * time.Minute, LockAfter: 5, LockFor: 15 * time.Minute,
+	}).Return(models.LoginAttempt{Key: "user:testuser", Failures: 1, LastFailureAt: time.Now().Add(-time.Minute)}, nil)
+	repoMock.EXPECT().RecordLoginFailure(ctx, repo.RecordLoginFailureParams{
+		Key: "ip:10.0.0.1", Window: 15 * time.Minute, LockAfter: 50, LockFor: 15 * time.Minute,
+	}).Return(models.LoginAttempt{Key: "ip:10.0.0.1", Failures: 1, LastFailureAt: time.Now().Add(-time.Minute)}, nil)
+
+	_, err := service.Auth(ctx, services.AuthParams{Username: "testuser", Password: "wrong", ClientIP: "10.0.0.1"})
+	assert.ErrorIs(t, err, services.InvalidCredentialsError)
+
+	repoMock.EXPECT().GetLoginAttempts(ctx, keys).Return([]models.LoginAttempt{
+		{Key: "user:testuser", Failures: 1, LastFailureAt: time.Now().Add(-time.Minute)},
+	}, nil)
+	repoMock.EXPECT().GetUserByUsername(ctx, "testuser").Return(user, nil)
+	repoMock.EXPECT().ResetLoginAttempts(ctx, keys).Return(nil)
+	tokenGenMock.EXPECT().NewToken("testuser").Return("auth-token", nil)
+
+	result, err := service.Auth(ctx, services.AuthParams{Username: "testuser", Password: "password", ClientIP: "10.0.0.1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "auth-token", result.Token)
+}
+
+func TestAuthFrozenAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	service := services.NewCoinService(repoMock, tokenGenMock, services.CoinServiceConfig{Hasher: newHasher(t), LoginProtection: loginProtection})
+
+	ctx := context.Background()
+	frozenAt := time.Now()
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	user := &models.User{Username: "testuser", PasswordHash: string(hashedPassword), PasswordAlgorithm: password.Bcrypt, FrozenAt: &frozenAt}
+
+	repoMock.EXPECT().GetLoginAttempts(ctx, gomock.Any()).Return(nil, nil)
+	repoMock.EXPECT().GetUserByUsername(ctx, "testuser").Return(user, nil)
+
+	_, err := service.Auth(ctx, services.AuthParams{Username: "testuser", Password: "password", ClientIP: "10.0.0.1"})
+	assert.ErrorIs(t, err, services.AccountFrozenError)
+}
+
+func TestUnlockUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	service := services.NewAccountService(repoMock, tokenGenMock, services.AccountServiceConfig{})
+
+	ctx := context.Background()
+	params := services.UnlockUserParams{Token: "admin-token", Username: "bob"}
+
+	tokenGenMock.EXPECT().ParseToken(params.Token).Return("alice", nil)
+	repoMock.EXPECT().GetUserByUsername(ctx, "alice").Return(&models.User{Username: "alice", IsAdmin: true}, nil)
+	repoMock.EXPECT().GetUserByUsername(ctx, "bob").Return(&models.User{Username: "bob"}, nil)
+	repoMock.EXPECT().ResetLoginAttempts(ctx, []string{"user:bob"}).Return(nil)
+
+	assert.NoError(t, service.UnlockUser(ctx, params))
+}
+
+func TestReloadConfig(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+	reloaderMock := servicemocks.NewMockConfigReloader(ctrl)
+
+	service := services.NewAccountService(repoMock, tokenGenMock, services.AccountServiceConfig{Reloader: reloaderMock})
+
+	ctx := context.Background()
+	params := services.ReloadConfigParams{Token: "admin-token"}
+	tokenGenMock.EXPECT().ParseToken("admin-token").Return("alice", nil).AnyTimes()
+	repoMock.EXPECT().GetUserByUsername(ctx, "alice").Return(&models.User{Username: "alice", IsAdmin: true}, nil).AnyTimes()
+
+	t.Run("reloaded", func(t *testing.T) {
+		reloaded := services.ReloadedConfig{Applied: []string{"LOG_LEVEL"}, RestartRequired: []string{"SERVER_ADDR"}}
+		reloaderMock.EXPECT().Reload(ctx).Return(reloaded, nil)
+
+		got, err := service.ReloadConfig(ctx, params)
+		assert.NoError(t, err)
+		assert.Equal(t, reloaded, got)
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		reloaderMock.EXPECT().Reload(ctx).Return(services.ReloadedConfig{}, errors.New("TOKEN_TTL: must be between 1m0s and 24h0m0s"))
+
+		_, err := service.ReloadConfig(ctx, params)
+		assert.ErrorIs(t, err, services.InvalidConfigError)
+		assert.ErrorContains(t, err, "TOKEN_TTL")
+	})
+
+	t.Run("not an admin", func(t *testing.T) {
+		tokenGenMock.EXPECT().ParseToken("user-token").Return("bob", nil)
+		repoMock.EXPECT().GetUserByUsername(ctx, "bob").Return(&models.User{Username: "bob"}, nil)
+
+		_, err := service.ReloadConfig(ctx, services.ReloadConfigParams{Token: "user-token"})
+		assert.ErrorIs(t, err, services.AdminRequiredError)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		service := services.NewAccountService(repoMock, tokenGenMock, services.AccountServiceConfig{})
+
+		_, err := service.ReloadConfig(ctx, params)
+		assert.ErrorIs(t, err, services.ConfigReloadDisabledError)
+	})
+}
+
+func TestFeatureFlags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	service := services.NewFeatureFlagService(repoMock, tokenGenMock, services.FeatureFlagServiceConfig{})
+
+	ctx := context.Background()
+	tokenGenMock.EXPECT().ParseToken("admin-token").Return("alice", nil).AnyTimes()
+	repoMock.EXPECT().GetUserByUsername(ctx, "alice").Return(&models.User{Username: "alice", IsAdmin: true}, nil).AnyTimes()
+
+	repoMock.EXPECT().GetFeatureFlags(ctx).Return([]models.FeatureFlag{
+		{Name: "off", Enabled: false, RolloutPercent: 100, AllowList: []string{"bob"}},
+		{Name: "everyone", Enabled: true, RolloutPercent: 100},
+		{Name: "beta", Enabled: true, AllowList: []string{"bob"}},
+		{Name: "half", Enabled: true, RolloutPercent: 50},
+	}, nil)
+	assert.NoError(t, service.Refresh(ctx))
+
+	t.Run("evaluation", func(t *testing.T) {
+		assert.False(t, service.Enabled("off", "bob"))
+		assert.True(t, service.Enabled("everyone", "carol"))
+		assert.True(t, service.Enabled("beta", "bob"))
+		assert.False(t, service.Enabled("beta", "carol"))
+		assert.False(t, service.Enabled("unknown", "bob"))
+
+		enabled := 0
+		for i := range 1000 {
+			username := fmt.Sprintf("user%d", i)
+			if service.Enabled("half", username) {
+				enabled++
+			}
+			assert.Equal(t, service.Enabled("half", username), service.Enabled("half", username))
+		}
+		assert.InDelta(t, 500, enabled, 60)
+	})
+
+	t.Run("features of the caller", func(t *testing.T) {
+		tokenGenMock.EXPECT().ParseToken("bob-token").Return("bob", nil)
+
+		features, err := service.GetFeatures(ctx, services.GetFeaturesParams{Token: "bob-token"})
+		assert.NoError(t, err)
+		assert.Contains(t, features, "beta")
+		assert.Contains(t, features, "everyone")
+		assert.NotContains(t, features, "off")
+	})
+
+	t.Run("set applies at once", func(t *testing.T) {
+		repoMock.EXPECT().SaveFeatureFlag(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, params repo.SaveFeatureFlagParams) (models.FeatureFlag, error) {
+			assert.Equal(t, []string{"carol"}, params.AllowList)
+			assert.Equal(t, "alice", params.UpdatedBy)
+			return models.FeatureFlag{Name: params.Name, Enabled: params.Enabled, AllowList: params.AllowList}, nil
+		})
+
+		_, err := service.SetFeatureFlag(ctx, services.SetFeatureFlagParams{
+			Token:     "admin-token",
+			Name:      "beta",
+			Enabled:   true,
+			AllowList: []string{"carol", "carol"},
+		})
+		assert.NoError(t, err)
+		assert.True(t, service.Enabled("beta", "carol"))
+		assert.False(t, service.Enabled("beta", "bob"))
+	})
+
+	t.Run("invalid flag", func(t *testing.T) {
+		_, err := service.SetFeatureFlag(ctx, services.SetFeatureFlagParams{Token: "admin-token", Name: "Bad Name"})
+		assert.ErrorIs(t, err, services.InvalidFlagNameError)
+
+		_, err = service.SetFeatureFlag(ctx, services.SetFeatureFlagParams{Token: "admin-token", Name: "beta", RolloutPercent: 101})
+		assert.ErrorIs(t, err, services.InvalidRolloutError)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		repoMock.EXPECT().DeleteFeatureFlag(ctx, "everyone").Return(true, nil)
+		repoMock.EXPECT().DeleteFeatureFlag(ctx, "everyone").Return(false, nil)
+
+		err := service.DeleteFeatureFlag(ctx, services.DeleteFeatureFlagParams{Token: "admin-token", Name: "everyone"})
+		assert.NoError(t, err)
+		assert.False(t, service.Enabled("everyone", "carol"))
+
+		err = service.DeleteFeatureFlag(ctx, services.DeleteFeatureFlagParams{Token: "admin-token", Name: "everyone"})
+		assert.ErrorIs(t, err, services.FeatureFlagNotFoundError)
+	})
+
+	t.Run("not an admin", func(t *testing.T) {
+		tokenGenMock.EXPECT().ParseToken("user-token").Return("bob", nil)
+		repoMock.EXPECT().GetUserByUsername(ctx, "bob").Return(&models.User{Username: "bob"}, nil)
+
+		_, err := service.ListFeatureFlags(ctx, services.ListFeatureFlagsParams{Token: "user-token"})
+		assert.ErrorIs(t, err, services.AdminRequiredError)
+	})
+
+	t.Run("refresh failure keeps flags", func(t *testing.T) {
+		repoMock.EXPECT().GetFeatureFlags(ctx).Return(nil, errors.New("connection refused"))
+
+		assert.Error(t, service.Refresh(ctx))
+		assert.True(t, service.Enabled("beta", "carol"))
+	})
+}
+
+func TestAuthUpgradesPasswordHash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	hasher, err := password.NewHasher(password.Config{
+		Algorithm:     password.Argon2id,
+		Argon2Memory:  1024,
+		Argon2Time:    1,
+		Argon2Threads: 1,
+	})
+	assert.NoError(t, err)
+	service := services.NewCoinService(repoMock, tokenGenMock, services.CoinServiceConfig{Hasher: hasher})
+
+	ctx := context.Background()
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+
+	repoMock.EXPECT().GetUserByUsername(ctx, "testuser").
+		Return(&models.User{Username: "testuser", PasswordHash: string(hashedPassword), PasswordAlgorithm: password.Bcrypt}, nil)
+	repoMock.EXPECT().UpdatePassword(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, params repo.UpdatePasswordParams) error {
+		assert.Equal(t, "testuser", params.Username)
+		assert.Equal(t, password.Argon2id, params.PassAlgorithm)
+
+		ok, err := hasher.Verify("password", params.PassHash, params.PassAlgorithm)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		return nil
+	})
+	tokenGenMock.EXPECT().NewToken("testuser").Return("auth-token", nil)
+
+	result, err := service.Auth(ctx, services.AuthParams{Username: "testuser", Password: "password"})
+	assert.NoError(t, err)
+	assert.Equal(t, "auth-token", result.Token)
+}
+
+func TestChangePassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	service := services.NewAccountService(repoMock, tokenGenMock, services.AccountServiceConfig{Hasher: newHasher(t), LoginProtection: loginProtection})
+
+	ctx := context.Background()
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("old-password"), bcrypt.MinCost)
+	user := &models.User{Username: "testuser", PasswordHash: string(hashedPassword), PasswordAlgorithm: password.Bcrypt}
+	keys := []string{"user:testuser"}
+
+	tokenGenMock.EXPECT().ParseToken("valid-token").Return("testuser", nil).Times(4)
+
+	err := service.ChangePassword(ctx, services.ChangePasswordParams{
+		Token: "valid-token", CurrentPassword: "old-password", NewPassword: "short",
+	})
+	assert.ErrorIs(t, err, services.PasswordTooShortError)
+
+	repoMock.EXPECT().GetUserByUsername(ctx, "testuser").Return(user, nil).Times(3)
+
+	// Wrong guesses count against the user like failed logins.
+	repoMock.EXPECT().GetLoginAttempts(ctx, keys).Return(nil, nil)
+	repoMock.EXPECT().RecordLoginFailure(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, params repo.RecordLoginFailureParams) (models.LoginAttempt, error) {
+			assert.Equal(t, "user:testuser", params.Key)
+			return models.LoginAttempt{Key: params.Key, Failures: 1, LastFailureAt: time.Now()}, nil
+		})
+
+	err = service.ChangePassword(ctx, services.ChangePasswordParams{
+		Token: "valid-token", CurrentPassword: "wrong-password", NewPassword: "new-password",
+	})
+	assert.ErrorIs(t, err, services.InvalidCurrentPasswordError)
+
+	// Once locked, even the right password is refused.
+	lockedUntil := time.Now().Add(10 * time.Minute)
+	repoMock.EXPECT().GetLoginAttempts(ctx, keys).Return([]models.LoginAttempt{
+		{Key: "user:testuser", Failures: 5, LastFailureAt: time.Now(), LockedUntil: &lockedUntil},
+	}, nil)
+
+	err = service.ChangePassword(ctx, services.ChangePasswordParams{
+		Token: "valid-token", CurrentPassword: "old-password", NewPassword: "new-password",
+	})
+	assert.ErrorIs(t, err, services.AccountLockedError)
+
+	repoMock.EXPECT().GetLoginAttempts(ctx, keys).Return([]models.LoginAttempt{
+		{Key: "user:testuser", Failures: 1, LastFailureAt: time.Now().Add(-time.Minute)},
+	}, nil)
+	repoMock.EXPECT().UpdatePassword(ctx, gomock.Any()).Return(nil)
+	repoMock.EXPECT().ResetLoginAttempts(ctx, keys).Return(nil)
+
+	err = service.ChangePassword(ctx, services.ChangePasswordParams{
+		Token: "valid-token", CurrentPassword: "old-password", NewPassword: "new-password",
+	})
+	assert.NoError(t, err)
+}
+
+func TestChangePasswordWithoutPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	service := services.NewAccountService(repoMock, tokenGenMock, services.AccountServiceConfig{Hasher: newHasher(t), LoginProtection: loginProtection})
+
+	ctx := context.Background()
+	for _, user := range []*models.User{
+		{Username: "sso-user"},
+		{Username: "bot", IsServiceAccount: true},
+	} {
+		tokenGenMock.EXPECT().ParseToken("valid-token").Return(user.Username, nil)
+		repoMock.EXPECT().GetUserByUsername(ctx, user.Username).Return(user, nil)
+
+		err := service.ChangePassword(ctx, services.ChangePasswordParams{
+			Token: "valid-token", CurrentPassword: "", NewPassword: "new-password",
+		})
+		assert.ErrorIs(t, err, services.InvalidCurrentPasswordError, user.Username)
+	}
+}
+
+func TestPasswordReset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	service := services.NewAccountService(repoMock, tokenGenMock, services.AccountServiceConfig{
+		Hasher:        newHasher(t),
+		ResetTokenTTL: time.Hour,
+	})
+
+	ctx := context.Background()
+
+	var storedHash string
+	tokenGenMock.EXPECT().ParseToken("admin-token").Return("alice", nil)
+	repoMock.EXPECT().GetUserByUsername(ctx, "alice").Return(&models.User{Username: "alice", IsAdmin: true}, nil)
+	repoMock.EXPECT().GetUserByUsername(ctx, "bob").Return(&models.User{Username: "bob"}, nil)
+	repoMock.EXPECT().CreatePasswordReset(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, params repo.CreatePasswordResetParams) error {
+		assert.Equal(t, "bob", params.Username)
+		assert.Equal(t, "alice", params.CreatedBy)
+		storedHash = params.TokenHash
+		return nil
+	})
+
+	reset, err := service.IssuePasswordReset(ctx, services.IssuePasswordResetParams{Token: "admin-token", Username: "bob"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, reset.Token)
+	assert.NotEqual(t, reset.Token, storedHash, "only a hash of the token is stored")
+	assert.WithinDuration(t, time.Now().Add(time.Hour), reset.ExpiresAt, time.Minute)
+
+	repoMock.EXPECT().ResetPassword(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, params repo.ResetPasswordParams) (string, error) {
+		if params.TokenHash != storedHash {
+			return "", sql.ErrNoRows
+		}
+		return "bob", nil
+	}).Times(2)
+	repoMock.EXPECT().ResetLoginAttempts(ctx, []string{"user:bob"}).Return(nil)
+
+	err = service.ResetPassword(ctx, services.ResetPasswordParams{ResetToken: "guessed", NewPassword: "new-password"})
+	assert.ErrorIs(t, err, services.InvalidResetTokenError)
+
+	err = service.ResetPassword(ctx, services.ResetPasswordParams{ResetToken: reset.Token, NewPassword: "new-password"})
+	assert.NoError(t, err)
+}
+
+func TestAuthTwoFactor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	service := services.NewCoinService(repoMock, tokenGenMock, services.CoinServiceConfig{
+		Hasher:       newHasher(t),
+		ChallengeTTL: 5 * time.Minute,
+	})
+
+	ctx := context.Background()
+	secret := "JBSWY3DPEHPK3PXP"
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	user := &models.User{
+		Username:          "testuser",
+		PasswordHash:      string(hashedPassword),
+		PasswordAlgorithm: password.Bcrypt,
+		TOTPSecret:        secret,
+		TOTPEnabled:       true,
+	}
+
+	var challengeHash string
+	repoMock.EXPECT().GetUserByUsername(ctx, "testuser").Return(user, nil).Times(3)
+	repoMock.EXPECT().CreateAuthChallenge(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, params repo.CreateAuthChallengeParams) error {
+		assert.Equal(t, "testuser", params.Username)
+		challengeHash = params.TokenHash
+		return nil
+	})
+
+	result, err := service.Auth(ctx, services.AuthParams{Username: "testuser", Password: "password"})
+	assert.NoError(t, err)
+	assert.Empty(t, result.Token, "no access token before the second factor")
+	assert.NotEmpty(t, result.ChallengeToken)
+	assert.NotEqual(t, result.ChallengeToken, challengeHash, "only a hash of the challenge is stored")
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), result.ChallengeExpiresAt, time.Minute)
+
+	repoMock.EXPECT().GetAuthChallenge(ctx, challengeHash).Return("testuser", nil).Times(2)
+	repoMock.EXPECT().UseRecoveryCode(ctx, repo.UseRecoveryCodeParams{
+		Username: "testuser",
+		CodeHash: hashOf("000000"),
+	}).Return(false, nil)
+
+	_, err = service.CompleteTwoFactorAuth(ctx, services.TwoFactorAuthParams{ChallengeToken: result.ChallengeToken, Code: "000000"})
+	assert.ErrorIs(t, err, services.InvalidOTPCodeError)
+
+	code, err := totp.GenerateCode(secret, time.Now())
+	assert.NoError(t, err)
+	repoMock.EXPECT().UseTOTPCounter(ctx, gomock.Any()).Return(true, nil)
+	repoMock.EXPECT().DeleteAuthChallenge(ctx, challengeHash).Return(true, nil)
+	tokenGenMock.EXPECT().NewToken("testuser").Return("auth-token", nil)
+
+	accessToken, err := service.CompleteTwoFactorAuth(ctx, services.TwoFactorAuthParams{ChallengeToken: result.ChallengeToken, Code: code})
+	assert.NoError(t, err)
+	assert.Equal(t, "auth-token", accessToken)
+
+	repoMock.EXPECT().GetAuthChallenge(ctx, challengeHash).Return("", sql.ErrNoRows)
+
+	_, err = service.CompleteTwoFactorAuth(ctx, services.TwoFactorAuthParams{ChallengeToken: result.ChallengeToken, Code: code})
+	assert.ErrorIs(t, err, services.InvalidChallengeError)
+}
+
+func TestSendCoinsRequiresSecondFactor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	service := services.NewCoinService(repoMock, tokenGenMock, services.CoinServiceConfig{
+		LoginProtection: services.LoginProtectionConfig{UserMaxFailures: 5, LockoutDuration: time.Minute},
+	})
+
+	ctx := context.Background()
+	policy := models.TwoFactorPolicy{TransferThreshold: 100}
+
+	tokenGenMock.EXPECT().ParseToken("valid-token").Return("sender", nil).AnyTimes()
+	repoMock.EXPECT().GetUserByUsername(ctx, "receiver").Return(&models.User{Username: "receiver"}, nil).AnyTimes()
+	repoMock.EXPECT().GetTwoFactorPolicy(ctx).Return(policy, nil).AnyTimes()
+
+	repoMock.EXPECT().GetUserByUsername(ctx, "sender").Return(&models.User{Username: "sender"}, nil)
+
+	_, err := service.SendCoins(ctx, services.TransactionParams{Token: "valid-token", ReceiverUsername: "receiver", Amount: 500})
+	assert.ErrorIs(t, err, services.TwoFactorSetupRequiredError)
+
+	sender := &models.User{Username: "sender", TOTPSecret: "JBSWY3DPEHPK3PXP", TOTPEnabled: true}
+	repoMock.EXPECT().GetUserByUsername(ctx, "sender").Return(sender, nil).Times(2)
+
+	_, err = service.SendCoins(ctx, services.TransactionParams{Token: "valid-token", ReceiverUsername: "receiver", Amount: 500})
+	assert.ErrorIs(t, err, services.OTPRequiredError)
+
+	repoMock.EXPECT().GetLoginAttempts(ctx, []string{"user:sender"}).Return(nil, nil)
+	repoMock.EXPECT().UseRecoveryCode(ctx, gomock.Any()).Return(false, nil)
+	repoMock.EXPECT().RecordLoginFailure(ctx, repo.RecordLoginFailureParams{
+		Key:       "user:sender",
+		LockAfter: 5,
+		LockFor:   time.Minute,
+	}).Return(models.LoginAttempt{Key: "user:sender", Failures: 1, LastFailureAt: time.Now()}, nil)
+
+	_, err = service.SendCoins(ctx, services.TransactionParams{Token: "valid-token", ReceiverUsername: "receiver", Amount: 500, OTPCode: "000000"})
+	assert.ErrorIs(t, err, services.InvalidOTPCodeError)
+}
+
+func TestActivateTOTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	service := services.NewAccountService(repoMock, tokenGenMock, services.AccountServiceConfig{TOTPIssuer: "AvitoShop"})
+
+	ctx := context.Background()
+	tokenGenMock.EXPECT().ParseToken("valid-token").Return("testuser", nil).Times(2)
+
+	var secret string
+	repoMock.EXPECT().GetUserByUsername(ctx, "testuser").Return(&models.User{Username: "testuser"}, nil)
+	repoMock.EXPECT().SetTOTPSecret(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, params repo.SetTOTPSecretParams) error {
+		secret = params.Secret
+		return nil
+	})
+
+	enrollment, err := service.EnrollTOTP(ctx, services.EnrollTOTPParams{Token: "valid-token"})
+	assert.NoError(t, err)
+	assert.Equal(t, secret, enrollment.Secret)
+	assert.Contains(t, enrollment.URI, "otpauth://totp/AvitoShop:testuser")
+
+	code, err := totp.GenerateCode(secret, time.Now())
+	assert.NoError(t, err)
+
+	repoMock.EXPECT().GetUserByUsername(ctx, "testuser").Return(&models.User{Username: "testuser", TOTPSecret: secret}, nil)
+	repoMock.EXPECT().UseTOTPCounter(ctx, gomock.Any()).Return(true, nil)
+	repoMock.EXPECT().EnableTOTP(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, params repo.EnableTOTPParams) error {
+		assert.Len(t, params.RecoveryCodeHashes, 10)
+		return nil
+	})
+
+	codes, err := service.ActivateTOTP(ctx, services.ActivateTOTPParams{Token: "valid-token", Code: code})
+	assert.NoError(t, err)
+	assert.Len(t, codes, 10)
+	assert.Regexp(t, `^[a-z2-7]{5}-[a-z2-7]{5}$`, codes[0])
+}
+
+func TestDisableTOTPLockedOut(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	service := services.NewAccountService(repoMock, tokenGenMock, services.AccountServiceConfig{LoginProtection: loginProtection})
+
+	ctx := context.Background()
+	keys := []string{"user:testuser"}
+	secret := "JBSWY3DPEHPK3PXP"
+	user := &models.User{Username: "testuser", TOTPSecret: secret, TOTPEnabled: true}
+	tokenGenMock.EXPECT().ParseToken("valid-token").Return("testuser", nil).Times(2)
+	repoMock.EXPECT().GetUserByUsername(ctx, "testuser").Return(user, nil).Times(2)
+
+	// The fifth wrong code in a row locks the user.
+	lockedUntil := time.Now().Add(15 * time.Minute)
+	repoMock.EXPECT().GetLoginAttempts(ctx, keys).Return([]models.LoginAttempt{
+		{Key: "user:testuser", Failures: 4, LastFailureAt: time.Now().Add(-time.Minute)},
+	}, nil)
+	repoMock.EXPECT().UseRecoveryCode(ctx, gomock.Any()).Return(false, nil)
+	repoMock.EXPECT().RecordLoginFailure(ctx, gomock.Any()).Return(models.LoginAttempt{
+		Key: "user:testuser", Failures: 5, LastFailureAt: time.Now(), LockedUntil: &lockedUntil,
+	}, nil)
+
+	err := service.DisableTOTP(ctx, services.DisableTOTPParams{Token: "valid-token", Code: "000000"})
+	assert.ErrorIs(t, err, services.InvalidOTPCodeError)
+
+	// After that not even a valid code is tried.
+	repoMock.EXPECT().GetLoginAttempts(ctx, keys).Return([]models.LoginAttempt{
+		{Key: "user:testuser", Failures: 5, LastFailureAt: time.Now(), LockedUntil: &lockedUntil},
+	}, nil)
+
+	code, err := totp.GenerateCode(secret, time.Now())
+	assert.NoError(t, err)
+	err = service.DisableTOTP(ctx, services.DisableTOTPParams{Token: "valid-token", Code: code})
+	assert.ErrorIs(t, err, services.AccountLockedError)
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	auth := services.NewAuthenticator(repoMock, tokenGenMock)
+
+	ctx := context.Background()
+	id := "0123456789abcdef"
+	key := services.APIKeyPrefix + id + "_secret"
+	revokedAt := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name    string
+		key     models.APIKey
+		scope   services.Scope
+		wantErr error
+	}{
+		{
+			name:  "valid",
+			key:   models.APIKey{Username: "billing-bot", SecretHash: hashOf("secret"), Scopes: []string{"transfers:write"}, ExpiresAt: time.Now().Add(time.Hour)},
+			scope: services.ScopeTransfersWrite,
+		},
+		{
+			name:    "wrong secret",
+			key:     models.APIKey{Username: "billing-bot", SecretHash: hashOf("other"), Scopes: []string{"transfers:write"}, ExpiresAt: time.Now().Add(time.Hour)},
+			scope:   services.ScopeTransfersWrite,
+			wantErr: services.InvalidTokenError,
+		},
+		{
+			name:    "expired",
+			key:     models.APIKey{Username: "billing-bot", SecretHash: hashOf("secret"), Scopes: []string{"transfers:write"}, ExpiresAt: time.Now().Add(-time.Hour)},
+			scope:   services.ScopeTransfersWrite,
+			wantErr: services.InvalidTokenError,
+		},
+		{
+			name:    "revoked",
+			key:     models.APIKey{Username: "billing-bot", SecretHash: hashOf("secret"), Scopes: []string{"transfers:write"}, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt},
+			scope:   services.ScopeTransfersWrite,
+			wantErr: services.InvalidTokenError,
+		},
+		{
+			name:    "missing scope",
+			key:     models.APIKey{Username: "billing-bot", SecretHash: hashOf("secret"), Scopes: []string{"balance:read"}, ExpiresAt: time.Now().Add(time.Hour)},
+			scope:   services.ScopeTransfersWrite,
+			wantErr: services.InsufficientScopeError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoMock.EXPECT().GetAPIKey(ctx, id).Return(tt.key, nil)
+
+			username, err := auth.Authenticate(ctx, key, tt.scope)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "billing-bot", username)
+		})
+	}
+
+	tokenGenMock.EXPECT().ParseToken("jwt-token").Return("testuser", nil)
+
+	username, err := auth.Authenticate(ctx, "jwt-token", services.ScopeTransfersWrite)
+	assert.NoError(t, err)
+	assert.Equal(t, "testuser", username, "access tokens have every scope")
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	service := services.NewAccountService(repoMock, tokenGenMock, services.AccountServiceConfig{
+		APIKeyTTL:    24 * time.Hour,
+		APIKeyMaxTTL: 48 * time.Hour,
+	})
+
+	ctx := context.Background()
+	tokenGenMock.EXPECT().ParseToken("admin-token").Return("alice", nil).AnyTimes()
+	repoMock.EXPECT().GetUserByUsername(ctx, "alice").Return(&models.User{Username: "alice", IsAdmin: true}, nil).AnyTimes()
+
+	_, err := service.CreateAPIKey(ctx, services.CreateAPIKeyParams{Token: "admin-token", Username: "billing-bot", Scopes: []services.Scope{"coins:steal"}})
+	assert.ErrorIs(t, err, services.InvalidScopeError)
+
+	_, err = service.CreateAPIKey(ctx, services.CreateAPIKeyParams{
+		Token:     "admin-token",
+		Username:  "billing-bot",
+		Scopes:    []services.Scope{services.ScopeBalanceRead},
+		ExpiresAt: time.Now().Add(72 * time.Hour),
+	})
+	assert.ErrorIs(t, err, services.InvalidExpiryError)
+
+	repoMock.EXPECT().GetUserByUsername(ctx, "bob").Return(&models.User{Username: "bob"}, nil)
+
+	_, err = service.CreateAPIKey(ctx, services.CreateAPIKeyParams{Token: "admin-token", Username: "bob", Scopes: []services.Scope{services.ScopeBalanceRead}})
+	assert.ErrorIs(t, err, services.ServiceAccountRequiredError)
+
+	var stored repo.CreateAPIKeyParams
+	repoMock.EXPECT().GetUserByUsername(ctx, "billing-bot").Return(&models.User{Username: "billing-bot", IsServiceAccount: true}, nil)
+	repoMock.EXPECT().CreateAPIKey(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, params repo.CreateAPIKeyParams) error {
+		stored = params
+		return nil
+	})
+
+	issued, err := service.CreateAPIKey(ctx, services.CreateAPIKeyParams{
+		Token:    "admin-token",
+		Username: "billing-bot",
+		Scopes:   []services.Scope{services.ScopeTransfersWrite, services.ScopeBalanceRead, services.ScopeTransfersWrite},
+	})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(issued.Key, services.APIKeyPrefix+stored.ID+"_"))
+	assert.Equal(t, hashOf(strings.TrimPrefix(issued.Key, services.APIKeyPrefix+stored.ID+"_")), stored.SecretHash, "only a hash of the secret is stored")
+	assert.Equal(t, []string{"balance:read", "transfers:write"}, stored.Scopes)
+	assert.Equal(t, "alice", stored.CreatedBy)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), stored.ExpiresAt, time.Minute)
+}
+
+func TestAuthRejectsServiceAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	service := services.NewCoinService(repoMock, tokenGenMock, services.CoinServiceConfig{Hasher: newHasher(t)})
+
+	ctx := context.Background()
+	repoMock.EXPECT().GetUserByUsername(ctx, "billing-bot").Return(&models.User{Username: "billing-bot", IsServiceAccount: true}, nil)
+
+	_, err := service.Auth(ctx, services.AuthParams{Username: "billing-bot", Password: "password"})
+	assert.ErrorIs(t, err, services.InvalidCredentialsError)
+}
+
+func TestOIDCLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	ctx := context.Background()
+	idp := oidctest.NewServer(t)
+	provider, err := oidc.NewProvider(ctx, oidc.Config{
+		IssuerURL:     idp.URL,
+		ClientID:      "coin-service",
+		RedirectURL:   "http://localhost/api/auth/oidc/callback",
+		Scopes:        []string{"openid", "email"},
+		UsernameClaim: "email",
+	})
+	assert.NoError(t, err)
+
+	service := services.NewCoinService(repoMock, tokenGenMock, services.CoinServiceConfig{
+		OIDC:            provider,
+		OIDCStateTTL:    10 * time.Minute,
+		StartingBalance: 500,
+	})
+
+	var state models.OIDCLoginState
+	var stateHash string
+	repoMock.EXPECT().CreateOIDCLoginState(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, params repo.CreateOIDCLoginStateParams) error {
+		state = models.OIDCLoginState{Nonce: params.Nonce, CodeVerifier: params.CodeVerifier}
+		stateHash = params.StateHash
+		assert.WithinDuration(t, time.Now().Add(10*time.Minute), params.ExpiresAt, time.Minute)
+		return nil
+	})
+
+	loginURL, err := service.OIDCLoginURL(ctx)
+	assert.NoError(t, err)
+
+	code, stateToken := idp.Authorize(loginURL, map[string]any{"sub": "42", "email": "alice@example.com", "email_verified": true})
+	assert.Equal(t, hashOf(stateToken), stateHash, "only a hash of the state is stored")
+
+	// The first login registers the user with the starting balance.
+	repoMock.EXPECT().UseOIDCLoginState(ctx, stateHash).Return(state, nil)
+	repoMock.EXPECT().GetOIDCIdentity(ctx, repo.GetOIDCIdentityParams{Issuer: idp.URL, Subject: "42"}).Return("", sql.ErrNoRows)
+	repoMock.EXPECT().GetUserByUsername(ctx, "alice@example.com").Return(nil, sql.ErrNoRows)
+	repoMock.EXPECT().LinkOIDCIdentity(ctx, repo.LinkOIDCIdentityParams{
+		Issuer:   idp.URL,
+		Subject:  "42",
+		Username: "alice@example.com",
+		Balance:  500,
+	}).Return(nil)
+	repoMock.EXPECT().GetUserByUsername(ctx, "alice@example.com").Return(&models.User{Username: "alice@example.com"}, nil)
+	tokenGenMock.EXPECT().NewToken("alice@example.com").Return("token", nil)
+
+	result, err := service.OIDCCallback(ctx, services.OIDCCallbackParams{Code: code, State: stateToken})
+	assert.NoError(t, err)
+	assert.Equal(t, "token", result.Token)
+
+	// The state is used up, so the callback can't be replayed.
+	repoMock.EXPECT().UseOIDCLoginState(ctx, stateHash).Return(models.OIDCLoginState{}, sql.ErrNoRows)
+	_, err = service.OIDCCallback(ctx, services.OIDCCallbackParams{Code: code, State: stateToken})
+	assert.ErrorIs(t, err, services.InvalidOIDCStateError)
+}
+
+func TestOIDCCallback(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+	providerMock := oidcmocks.NewMockProvider(ctrl)
+
+	service := services.NewCoinService(repoMock, tokenGenMock, services.CoinServiceConfig{OIDC: providerMock})
+
+	ctx := context.Background()
+	state := models.OIDCLoginState{Nonce: "nonce", CodeVerifier: "verifier"}
+	identity := oidc.Identity{Issuer: "https://idp.example.com", Subject: "42", Username: "alice@example.com"}
+
+	t.Run("linked identity", func(t *testing.T) {
+		repoMock.EXPECT().UseOIDCLoginState(ctx, hashOf("state")).Return(state, nil)
+		providerMock.EXPECT().Exchange(ctx, "code", "verifier", "nonce").Return(identity, nil)
+		repoMock.EXPECT().GetOIDCIdentity(ctx, repo.GetOIDCIdentityParams{Issuer: identity.Issuer, Subject: "42"}).Return("alice", nil)
+		repoMock.EXPECT().GetUserByUsername(ctx, "alice").Return(&models.User{Username: "alice"}, nil)
+		tokenGenMock.EXPECT().NewToken("alice").Return("token", nil)
+
+		result, err := service.OIDCCallback(ctx, services.OIDCCallbackParams{Code: "code", State: "state"})
+		assert.NoError(t, err)
+		assert.Equal(t, "token", result.Token)
+	})
+
+	t.Run("service account", func(t *testing.T) {
+		repoMock.EXPECT().UseOIDCLoginState(ctx, hashOf("state")).Return(state, nil)
+		providerMock.EXPECT().Exchange(ctx, "code", "verifier", "nonce").Return(identity, nil)
+		repoMock.EXPECT().GetOIDCIdentity(ctx, gomock.Any()).Return("", sql.ErrNoRows)
+		repoMock.EXPECT().GetUserByUsername(ctx, "alice@example.com").Return(&models.User{Username: "alice@example.com", IsServiceAccount: true}, nil)
+
+		_, err := service.OIDCCallback(ctx, services.OIDCCallbackParams{Code: "code", State: "state"})
+		assert.ErrorIs(t, err, services.OIDCLoginFailedError)
+	})
+
+	t.Run("user with a password", func(t *testing.T) {
+		repoMock.EXPECT().UseOIDCLoginState(ctx, hashOf("state")).Return(state, nil)
+		providerMock.EXPECT().Exchange(ctx, "code", "verifier", "nonce").Return(identity, nil)
+		repoMock.EXPECT().GetOIDCIdentity(ctx, gomock.Any()).Return("", sql.ErrNoRows)
+		repoMock.EXPECT().GetUserByUsername(ctx, "alice@example.com").Return(&models.User{Username: "alice@example.com", PasswordHash: "hash"}, nil)
+
+		_, err := service.OIDCCallback(ctx, services.OIDCCallbackParams{Code: "code", State: "state"})
+		assert.ErrorIs(t, err, services.OIDCLoginFailedError)
+	})
+
+	t.Run("password set before linking", func(t *testing.T) {
+		repoMock.EXPECT().UseOIDCLoginState(ctx, hashOf("state")).Return(state, nil)
+		providerMock.EXPECT().Exchange(ctx, "code", "verifier", "nonce").Return(identity, nil)
+		repoMock.EXPECT().GetOIDCIdentity(ctx, gomock.Any()).Return("", sql.ErrNoRows)
+		repoMock.EXPECT().GetUserByUsername(ctx, "alice@example.com").Return(nil, sql.ErrNoRows)
+		repoMock.EXPECT().LinkOIDCIdentity(ctx, gomock.Any()).Return(sql.ErrNoRows)
+
+		_, err := service.OIDCCallback(ctx, services.OIDCCallbackParams{Code: "code", State: "state"})
+		assert.ErrorIs(t, err, services.OIDCLoginFailedError)
+	})
+
+	t.Run("exchange failed", func(t *testing.T) {
+		repoMock.EXPECT().UseOIDCLoginState(ctx, hashOf("state")).Return(state, nil)
+		providerMock.EXPECT().Exchange(ctx, "code", "verifier", "nonce").Return(oidc.Identity{}, oidc.ErrNonceMismatch)
+
+		_, err := service.OIDCCallback(ctx, services.OIDCCallbackParams{Code: "code", State: "state"})
+		assert.ErrorIs(t, err, services.OIDCLoginFailedError)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		disabled := services.NewCoinService(repoMock, tokenGenMock, services.CoinServiceConfig{})
+
+		_, err := disabled.OIDCLoginURL(ctx)
+		assert.ErrorIs(t, err, services.OIDCDisabledError)
+		_, err = disabled.OIDCCallback(ctx, services.OIDCCallbackParams{Code: "code", State: "state"})
+		assert.ErrorIs(t, err, services.OIDCDisabledError)
+	})
+}
+
+func TestAuthRejectsSSOUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	service := services.NewCoinService(repoMock, tokenGenMock, services.CoinServiceConfig{Hasher: newHasher(t)})
+
+	ctx := context.Background()
+	repoMock.EXPECT().GetUserByUsername(ctx, "alice@example.com").Return(&models.User{Username: "alice@example.com"}, nil)
+
+	_, err := service.Auth(ctx, services.AuthParams{Username: "alice@example.com", Password: ""})
+	assert.ErrorIs(t, err, services.InvalidCredentialsError)
+}
+
+func hashOf(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
 }
 
 func TestSendCoins(t *testing.T) {
@@ -74,7 +1035,7 @@ func TestSendCoins(t *testing.T) {
 	repoMock := mocks.NewMockCoinRepository(ctrl)
 	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
 
-	service := services.NewCoinService(repoMock, tokenGenMock)
+	service := services.NewCoinService(repoMock, tokenGenMock, services.CoinServiceConfig{})
 
 	ctx := context.Background()
 	params := services.TransactionParams{
@@ -87,25 +1048,172 @@ func TestSendCoins(t *testing.T) {
 	repoMock.EXPECT().GetUserByUsername(ctx, params.ReceiverUsername).
 		Return(&models.User{Username: params.ReceiverUsername}, nil)
 
-	repoMock.EXPECT().GetBalance(ctx, repo.GetBalanceParams{Username: senderUsername}).
-		Return(1000, nil)
+	repoMock.EXPECT().GetTwoFactorPolicy(ctx).Return(models.TwoFactorPolicy{}, nil)
 
 	tx := &sqlx.Tx{}
 	repoMock.EXPECT().BeginTx(ctx).Return(tx, nil)
 	repoMock.EXPECT().DecreaseBalance(ctx, tx, repo.ChangeBalanceParams{
 		Username: senderUsername, Amount: params.Amount,
-	}).Return(nil)
+	}).Return(500, nil)
 	repoMock.EXPECT().IncreaseBalance(ctx, tx, repo.ChangeBalanceParams{
 		Username: params.ReceiverUsername, Amount: params.Amount,
-	}).Return(nil)
-	repoMock.EXPECT().SaveTransaction(ctx, repo.SaveTransactionParams{
+	}).Return(1500, nil)
+	repoMock.EXPECT().SaveTransaction(ctx, tx, repo.SaveTransactionParams{
 		SenderUsername: senderUsername, ReceiverUsername: params.ReceiverUsername, Amount: params.Amount,
+	}).Return(int64(1), nil)
+	repoMock.EXPECT().SaveEvent(ctx, tx, repo.SaveEventParams{
+		Username: senderUsername,
+		Type:     models.EventCoinsSent,
+		Payload:  models.CoinsSentPayload{ToUser: params.ReceiverUsername, Amount: params.Amount},
+	}).Return(nil)
+	repoMock.EXPECT().CreateNotification(ctx, tx, repo.CreateNotificationParams{
+		Username: senderUsername,
+		Type:     models.NotificationCoinsSent,
+		Payload:  models.CoinsSentPayload{ToUser: params.ReceiverUsername, Amount: params.Amount},
+	}).Return(nil)
+	repoMock.EXPECT().SaveEvent(ctx, tx, repo.SaveEventParams{
+		Username: senderUsername,
+		Type:     models.EventBalanceChanged,
+		Payload:  models.BalanceChangedPayload{Balance: 500, Delta: -params.Amount},
+	}).Return(nil)
+	repoMock.EXPECT().SaveEvent(ctx, tx, repo.SaveEventParams{
+		Username: params.ReceiverUsername,
+		Type:     models.EventCoinsReceived,
+		Payload:  models.CoinsReceivedPayload{FromUser: senderUsername, Amount: params.Amount},
 	}).Return(nil)
+	repoMock.EXPECT().CreateNotification(ctx, tx, repo.CreateNotificationParams{
+		Username: params.ReceiverUsername,
+		Type:     models.NotificationCoinsReceived,
+		Payload:  models.CoinsReceivedPayload{FromUser: senderUsername, Amount: params.Amount},
+		Deliver:  true,
+	}).Return(nil)
+	repoMock.EXPECT().SaveEvent(ctx, tx, repo.SaveEventParams{
+		Username: params.ReceiverUsername,
+		Type:     models.EventBalanceChanged,
+		Payload:  models.BalanceChangedPayload{Balance: 1500, Delta: params.Amount},
+	}).Return(nil)
+
+	repoMock.EXPECT().CommitTx(tx).Return(nil)
+
+	_, err := service.SendCoins(ctx, params)
+	assert.NoError(t, err)
+}
+
+func TestSendCoinsOnBehalfOf(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	service := services.NewCoinService(repoMock, tokenGenMock, services.CoinServiceConfig{})
+
+	ctx := context.Background()
+	params := services.TransactionParams{
+		Token: "bot-token", ReceiverUsername: "receiver", Amount: 50, OnBehalfOf: "owner",
+	}
+	lockParams := repo.LockDelegationParams{OwnerUsername: "owner", ClientUsername: "kudos-bot"}
+
+	tx := &sqlx.Tx{}
+	tokenGenMock.EXPECT().ParseToken(params.Token).Return("kudos-bot", nil).Times(3)
+	repoMock.EXPECT().GetUserByUsername(ctx, params.ReceiverUsername).
+		Return(&models.User{Username: params.ReceiverUsername}, nil).Times(3)
+	repoMock.EXPECT().BeginTx(ctx).Return(tx, nil).Times(3)
+
+	repoMock.EXPECT().LockDelegation(ctx, tx, lockParams).Return(models.Delegation{}, sql.ErrNoRows)
+	repoMock.EXPECT().RollbackTx(tx).Return(nil)
+
+	_, err := service.SendCoins(ctx, params)
+	assert.ErrorIs(t, err, services.DelegationRequiredError)
+
+	repoMock.EXPECT().LockDelegation(ctx, tx, lockParams).Return(models.Delegation{DailyLimit: 100, SpentToday: 80}, nil)
+	repoMock.EXPECT().RollbackTx(tx).Return(nil)
 
+	_, err = service.SendCoins(ctx, params)
+	assert.ErrorIs(t, err, services.DelegationLimitExceededError)
+
+	repoMock.EXPECT().LockDelegation(ctx, tx, lockParams).Return(models.Delegation{DailyLimit: 100, SpentToday: 50}, nil)
+	repoMock.EXPECT().DecreaseBalance(ctx, tx, repo.ChangeBalanceParams{Username: "owner", Amount: 50}).Return(950, nil)
+	repoMock.EXPECT().IncreaseBalance(ctx, tx, repo.ChangeBalanceParams{Username: "receiver", Amount: 50}).Return(1050, nil)
+	repoMock.EXPECT().SaveTransaction(ctx, tx, repo.SaveTransactionParams{
+		SenderUsername: "owner", ReceiverUsername: "receiver", Amount: 50, ActorUsername: "kudos-bot",
+	}).Return(int64(1), nil)
+	repoMock.EXPECT().SaveEvent(ctx, tx, repo.SaveEventParams{
+		Username: "owner",
+		Type:     models.EventCoinsSent,
+		Payload:  models.CoinsSentPayload{ToUser: "receiver", Amount: 50, Actor: "kudos-bot"},
+	}).Return(nil)
+	repoMock.EXPECT().SaveEvent(ctx, tx, gomock.Any()).Return(nil).Times(3)
+	repoMock.EXPECT().CreateNotification(ctx, tx, gomock.Any()).Return(nil).Times(2)
 	repoMock.EXPECT().CommitTx(tx).Return(nil)
 
-	err := service.SendCoins(ctx, params)
+	_, err = service.SendCoins(ctx, params)
+	assert.NoError(t, err)
+}
+
+func TestGrantDelegation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	service := services.NewAccountService(repoMock, tokenGenMock, services.AccountServiceConfig{})
+
+	ctx := context.Background()
+	tokenGenMock.EXPECT().ParseToken("valid-token").Return("owner", nil).AnyTimes()
+	repoMock.EXPECT().GetUserByUsername(ctx, "owner").Return(&models.User{Username: "owner"}, nil).AnyTimes()
+
+	_, err := service.GrantDelegation(ctx, services.GrantDelegationParams{Token: "valid-token", ClientUsername: "kudos-bot"})
+	assert.ErrorIs(t, err, services.InvalidLimitError)
+
+	repoMock.EXPECT().GetUserByUsername(ctx, "bob").Return(&models.User{Username: "bob"}, nil)
+
+	_, err = service.GrantDelegation(ctx, services.GrantDelegationParams{Token: "valid-token", ClientUsername: "bob", DailyLimit: 100})
+	assert.ErrorIs(t, err, services.ServiceAccountRequiredError)
+
+	delegation := models.Delegation{ID: 1, OwnerUsername: "owner", ClientUsername: "kudos-bot", DailyLimit: 100}
+	repoMock.EXPECT().GetUserByUsername(ctx, "kudos-bot").Return(&models.User{Username: "kudos-bot", IsServiceAccount: true}, nil)
+	repoMock.EXPECT().GrantDelegation(ctx, repo.GrantDelegationParams{
+		OwnerUsername:  "owner",
+		ClientUsername: "kudos-bot",
+		DailyLimit:     100,
+	}).Return(delegation, nil)
+
+	result, err := service.GrantDelegation(ctx, services.GrantDelegationParams{Token: "valid-token", ClientUsername: "kudos-bot", DailyLimit: 100})
 	assert.NoError(t, err)
+	assert.Equal(t, delegation, result)
+
+	repoMock.EXPECT().RevokeDelegation(ctx, repo.RevokeDelegationParams{OwnerUsername: "owner", ID: 2}).Return(false, nil)
+
+	err = service.RevokeDelegation(ctx, services.RevokeDelegationParams{Token: "valid-token", ID: 2})
+	assert.ErrorIs(t, err, services.DelegationNotFoundError)
+}
+
+func TestSendCoinsRejectsInvalidTransfers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	service := services.NewCoinService(repoMock, tokenGenMock, services.CoinServiceConfig{})
+
+	ctx := context.Background()
+	tokenGenMock.EXPECT().ParseToken("valid-token").Return("sender", nil).Times(3)
+
+	tests := []struct {
+		params services.TransactionParams
+		err    error
+	}{
+		{services.TransactionParams{Token: "valid-token", ReceiverUsername: "receiver", Amount: 0}, services.InvalidAmountError},
+		{services.TransactionParams{Token: "valid-token", ReceiverUsername: "receiver", Amount: -10}, services.InvalidAmountError},
+		{services.TransactionParams{Token: "valid-token", ReceiverUsername: "sender", Amount: 10}, services.SelfTransferError},
+	}
+	for _, tt := range tests {
+		_, err := service.SendCoins(ctx, tt.params)
+		assert.ErrorIs(t, err, tt.err)
+	}
 }
 
 func TestSendCoinsInfo(t *testing.T) {
@@ -115,7 +1223,7 @@ func TestSendCoinsInfo(t *testing.T) {
 	repoMock := mocks.NewMockCoinRepository(ctrl)
 	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
 
-	service := services.NewCoinService(repoMock, tokenGenMock)
+	service := services.NewCoinService(repoMock, tokenGenMock, services.CoinServiceConfig{})
 
 	ctx := context.Background()
 	params := services.GetTransactionsParams{Token: "valid-token"}
@@ -138,7 +1246,7 @@ func TestReceivedCoinsInfo(t *testing.T) {
 	repoMock := mocks.NewMockCoinRepository(ctrl)
 	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
 
-	service := services.NewCoinService(repoMock, tokenGenMock)
+	service := services.NewCoinService(repoMock, tokenGenMock, services.CoinServiceConfig{})
 
 	ctx := context.Background()
 	params := services.GetTransactionsParams{Token: "valid-token"}
@@ -161,7 +1269,7 @@ func TestGetPurchases(t *testing.T) {
 	repoMock := mocks.NewMockCoinRepository(ctrl)
 	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
 
-	service := services.NewCoinService(repoMock, tokenGenMock)
+	service := services.NewCoinService(repoMock, tokenGenMock, services.CoinServiceConfig{})
 
 	ctx := context.Background()
 	params := services.GetPurchasesParams{Token: "valid-token"}
@@ -184,7 +1292,7 @@ func TestBuyItem(t *testing.T) {
 	repoMock := mocks.NewMockCoinRepository(ctrl)
 	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
 
-	service := services.NewCoinService(repoMock, tokenGenMock)
+	service := services.NewCoinService(repoMock, tokenGenMock, services.CoinServiceConfig{})
 
 	ctx := context.Background()
 	params := services.BuyItemParams{Token: "valid-token", Item: "item1"}
@@ -192,8 +1300,432 @@ func TestBuyItem(t *testing.T) {
 
 	tokenGenMock.EXPECT().ParseToken(params.Token).Return(username, nil)
 	repoMock.EXPECT().GetItem(ctx, params.Item).Return(models.Item{Name: "item1", Price: 100}, nil)
-	repoMock.EXPECT().BuyItem(ctx, repo.BuyItemParams{Username: username, Item: "item1", Price: 100}).Return(nil)
+	repoMock.EXPECT().GetTwoFactorPolicy(ctx).Return(models.TwoFactorPolicy{}, nil)
+	tx := &sqlx.Tx{}
+	repoMock.EXPECT().BeginTx(ctx).Return(tx, nil)
+	repoMock.EXPECT().BuyItem(ctx, tx, repo.BuyItemParams{Username: username, Item: "item1", Price: 100}).Return(900, nil)
+	repoMock.EXPECT().SaveEvent(ctx, tx, repo.SaveEventParams{
+		Username: username,
+		Type:     models.EventPurchaseCompleted,
+		Payload:  models.PurchaseCompletedPayload{Item: "item1", Price: 100},
+	}).Return(nil)
+	repoMock.EXPECT().CreateNotification(ctx, tx, repo.CreateNotificationParams{
+		Username: username,
+		Type:     models.NotificationPurchaseCompleted,
+		Payload:  models.PurchaseCompletedPayload{Item: "item1", Price: 100},
+	}).Return(nil)
+	repoMock.EXPECT().SaveEvent(ctx, tx, repo.SaveEventParams{
+		Username: username,
+		Type:     models.EventBalanceChanged,
+		Payload:  models.BalanceChangedPayload{Balance: 900, Delta: -100},
+	}).Return(nil)
+	repoMock.EXPECT().CommitTx(tx).Return(nil)
 
 	err := service.BuyItem(ctx, params)
 	assert.NoError(t, err)
 }
+
+func TestSubscribe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+	hub := events.NewHub(events.HubConfig{})
+
+	service := services.NewEventService(repoMock, hub, tokenGenMock, services.EventServiceConfig{})
+
+	ctx := context.Background()
+	params := services.SubscribeParams{Token: "valid-token", LastEventID: 10}
+	username := "testuser"
+
+	tokenGenMock.EXPECT().ParseToken(params.Token).Return(username, nil)
+	repoMock.EXPECT().GetEvents(ctx, gomock.Any()).Return([]models.Event{
+		{ID: 11, Username: username, Type: models.EventCoinsReceived},
+	}, nil)
+
+	stream, err := service.Subscribe(ctx, params)
+	assert.NoError(t, err)
+	defer stream.Close()
+	assert.Len(t, stream.Backlog, 1)
+
+	hub.Publish(models.Event{ID: 11, Username: username, Type: models.EventCoinsReceived})
+	hub.Publish(models.Event{ID: 12, Username: username, Type: models.EventBalanceChanged})
+
+	assert.True(t, stream.Replayed(<-stream.Events()))
+	assert.False(t, stream.Replayed(<-stream.Events()))
+}
+
+func TestSubscribeUnauthorized(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	service := services.NewEventService(repoMock, events.NewHub(events.HubConfig{}), tokenGenMock, services.EventServiceConfig{})
+
+	tokenGenMock.EXPECT().ParseToken("bad-token").Return("", errors.New("invalid token"))
+
+	_, err := service.Subscribe(context.Background(), services.SubscribeParams{Token: "bad-token"})
+	assert.ErrorIs(t, err, services.UnauthorizedError)
+}
+
+func TestGetNotifications(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	service := services.NewNotificationService(repoMock, tokenGenMock, services.NotificationServiceConfig{})
+
+	ctx := context.Background()
+	params := services.GetNotificationsParams{Token: "valid-token", Limit: 2, UnreadOnly: true}
+	username := "testuser"
+
+	tokenGenMock.EXPECT().ParseToken(params.Token).Return(username, nil)
+	repoMock.EXPECT().GetNotifications(ctx, repo.GetNotificationsParams{
+		Username: username, UnreadOnly: true, Limit: 3,
+	}).Return([]models.Notification{{ID: 9}, {ID: 7}, {ID: 4}}, nil)
+	repoMock.EXPECT().CountUnreadNotifications(ctx, username).Return(5, nil)
+
+	page, err := service.GetNotifications(ctx, params)
+	assert.NoError(t, err)
+	assert.Len(t, page.Notifications, 2)
+	assert.Equal(t, int64(7), page.NextCursor)
+	assert.Equal(t, 5, page.UnreadCount)
+}
+
+func TestMarkNotificationReadNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	service := services.NewNotificationService(repoMock, tokenGenMock, services.NotificationServiceConfig{})
+
+	ctx := context.Background()
+	params := services.MarkNotificationReadParams{Token: "valid-token", ID: 42}
+
+	tokenGenMock.EXPECT().ParseToken(params.Token).Return("testuser", nil)
+	repoMock.EXPECT().MarkNotificationRead(ctx, repo.MarkNotificationReadParams{
+		Username: "testuser", ID: 42,
+	}).Return(sql.ErrNoRows)
+
+	err := service.MarkRead(ctx, params)
+	assert.ErrorIs(t, err, services.NotFoundError)
+}
+
+func TestGetTransfer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	service := services.NewCoinService(repoMock, tokenGenMock, services.CoinServiceConfig{})
+
+	ctx := context.Background()
+	transaction := models.Transaction{ID: 7, SenderUsername: "alice", ReceiverUsername: "bob", Amount: 10}
+	repoMock.EXPECT().GetTransaction(ctx, int64(7)).Return(transaction, nil).Times(2)
+	repoMock.EXPECT().GetTransaction(ctx, int64(8)).Return(models.Transaction{}, fmt.Errorf("r.db.GetContext: %w", sql.ErrNoRows))
+
+	tokenGenMock.EXPECT().ParseToken("bob-token").Return("bob", nil).Times(2)
+	transfer, err := service.GetTransfer(ctx, services.GetTransferParams{Token: "bob-token", ID: 7})
+	assert.NoError(t, err)
+	assert.Equal(t, services.Transfer{Transaction: transaction, Received: true}, transfer)
+
+	_, err = service.GetTransfer(ctx, services.GetTransferParams{Token: "bob-token", ID: 8})
+	assert.ErrorIs(t, err, services.TransactionNotFoundError)
+
+	// Someone else's transfer looks like a missing one.
+	tokenGenMock.EXPECT().ParseToken("carol-token").Return("carol", nil)
+	_, err = service.GetTransfer(ctx, services.GetTransferParams{Token: "carol-token", ID: 7})
+	assert.ErrorIs(t, err, services.TransactionNotFoundError)
+}
+
+func TestUpdateNotificationContact(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	service := services.NewNotificationService(repoMock, tokenGenMock, services.NotificationServiceConfig{})
+
+	ctx := context.Background()
+	tokenGenMock.EXPECT().ParseToken("valid-token").Return("bob", nil).Times(2)
+
+	// Only the address is kept; it is sent as the SMTP recipient.
+	repoMock.EXPECT().SetNotificationContact(ctx, repo.SetNotificationContactParams{
+		Username: "bob", Email: "bob@example.com", Locale: "en",
+	}).Return(nil)
+	err := service.UpdateContact(ctx, services.UpdateNotificationContactParams{
+		Token: "valid-token", Email: "Bob <bob@example.com>", Locale: "en",
+	})
+	assert.NoError(t, err)
+
+	err = service.UpdateContact(ctx, services.UpdateNotificationContactParams{
+		Token: "valid-token", Email: "bob at example.com", Locale: "en",
+	})
+	assert.ErrorIs(t, err, services.InvalidEmailError)
+}
+
+func TestSendAdminMessageForbidden(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	service := services.NewNotificationService(repoMock, tokenGenMock, services.NotificationServiceConfig{})
+
+	ctx := context.Background()
+	params := services.AdminMessageParams{Token: "valid-token", ReceiverUsername: "bob", Message: "hi"}
+
+	tokenGenMock.EXPECT().ParseToken(params.Token).Return("alice", nil)
+	repoMock.EXPECT().GetUserByUsername(ctx, "alice").Return(&models.User{Username: "alice"}, nil)
+
+	err := service.SendAdminMessage(ctx, params)
+	assert.ErrorIs(t, err, services.ForbiddenError)
+}
+
+func TestGetItems(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	service := services.NewCoinService(repoMock, tokenGenMock, services.CoinServiceConfig{})
+
+	ctx := context.Background()
+	params := services.GetItemsParams{Token: "valid-token"}
+
+	tokenGenMock.EXPECT().ParseToken(params.Token).Return("testuser", nil)
+	repoMock.EXPECT().GetItems(ctx, repo.GetItemsParams{}).Return([]models.Item{{Name: "cup", Price: 20}}, nil)
+
+	items, err := service.GetItems(ctx, params)
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+}
+
+func TestOperatorService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockOperatorRepository(ctrl)
+	service := services.NewOperatorService(repoMock, services.OperatorServiceConfig{Hasher: newHasher(t), StartingBalance: 1000})
+
+	ctx := context.Background()
+
+	t.Run("create user", func(t *testing.T) {
+		repoMock.EXPECT().GetUserByUsername(ctx, "alice").Return(nil, sql.ErrNoRows)
+		repoMock.EXPECT().CreateUser(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, params repo.CreateUserParams) error {
+			assert.Equal(t, 1000, params.Balance)
+			assert.True(t, params.IsAdmin)
+			ok, err := newHasher(t).Verify("long-password", params.PassHash, params.PassAlgorithm)
+			assert.NoError(t, err)
+			assert.True(t, ok)
+			return nil
+		})
+
+		user, err := service.CreateUser(ctx, services.CreateUserParams{Username: "alice", Password: "long-password", IsAdmin: true})
+		assert.NoError(t, err)
+		assert.Equal(t, models.User{Username: "alice", Balance: 1000, IsAdmin: true}, user)
+	})
+
+	t.Run("create existing user", func(t *testing.T) {
+		repoMock.EXPECT().GetUserByUsername(ctx, "alice").Return(&models.User{Username: "alice"}, nil)
+
+		_, err := service.CreateUser(ctx, services.CreateUserParams{Username: "alice", Password: "long-password"})
+		assert.ErrorIs(t, err, services.UsernameTakenError)
+	})
+
+	t.Run("adjust balance", func(t *testing.T) {
+		tx := &sqlx.Tx{}
+		repoMock.EXPECT().BeginTx(ctx).Return(tx, nil)
+		repoMock.EXPECT().AdjustBalance(ctx, tx, repo.AdjustBalanceParams{
+			Username: "alice", Amount: 50, Reason: "compensation", CreatedBy: "ops",
+		}).Return(1050, nil)
+		repoMock.EXPECT().SaveEvent(ctx, tx, repo.SaveEventParams{
+			Username: "alice",
+			Type:     models.EventBalanceChanged,
+			Payload:  models.BalanceChangedPayload{Balance: 1050, Delta: 50},
+		}).Return(nil)
+		repoMock.EXPECT().CommitTx(tx).Return(nil)
+
+		balance, err := service.AdjustBalance(ctx, services.AdjustBalanceParams{
+			Operator: "ops", Username: "alice", Amount: 50, Reason: "compensation",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 1050, balance)
+	})
+
+	t.Run("adjustment without a reason", func(t *testing.T) {
+		_, err := service.AdjustBalance(ctx, services.AdjustBalanceParams{Username: "alice", Amount: 50})
+		assert.ErrorIs(t, err, services.ReasonRequiredError)
+	})
+
+	t.Run("adjustment below zero", func(t *testing.T) {
+		tx := &sqlx.Tx{}
+		repoMock.EXPECT().BeginTx(ctx).Return(tx, nil)
+		repoMock.EXPECT().AdjustBalance(ctx, tx, gomock.Any()).Return(0, repo.InsufficientFundsError)
+		repoMock.EXPECT().RollbackTx(tx).Return(nil)
+
+		_, err := service.AdjustBalance(ctx, services.AdjustBalanceParams{Username: "alice", Amount: -5000, Reason: "chargeback"})
+		assert.ErrorIs(t, err, services.InsufficientFundsError)
+	})
+
+	t.Run("freeze unknown user", func(t *testing.T) {
+		repoMock.EXPECT().SetUserFrozen(ctx, repo.SetUserFrozenParams{Username: "nobody", Frozen: true}).Return(false, nil)
+
+		err := service.SetAccountFrozen(ctx, services.SetAccountFrozenParams{Username: "nobody", Frozen: true})
+		assert.ErrorIs(t, err, services.UserNotFoundError)
+	})
+}
+
+func TestSendCoinsFromFrozenAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	service := services.NewCoinService(repoMock, tokenGenMock, services.CoinServiceConfig{})
+
+	ctx := context.Background()
+	tx := &sqlx.Tx{}
+	tokenGenMock.EXPECT().ParseToken("valid-token").Return("sender", nil)
+	repoMock.EXPECT().GetTwoFactorPolicy(ctx).Return(models.TwoFactorPolicy{}, nil).AnyTimes()
+	repoMock.EXPECT().GetUserByUsername(ctx, gomock.Any()).Return(&models.User{Username: "receiver"}, nil).AnyTimes()
+	repoMock.EXPECT().BeginTx(ctx).Return(tx, nil)
+	repoMock.EXPECT().DecreaseBalance(ctx, tx, gomock.Any()).Return(0, repo.AccountFrozenError)
+	repoMock.EXPECT().RollbackTx(tx).Return(nil)
+
+	_, err := service.SendCoins(ctx, services.TransactionParams{Token: "valid-token", ReceiverUsername: "receiver", Amount: 100})
+	assert.ErrorIs(t, err, services.AccountFrozenError)
+}
+
+func TestSendCoinsInsufficientFunds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+	service := services.NewCoinService(repoMock, tokenGenMock, services.CoinServiceConfig{})
+
+	ctx := context.Background()
+	tx := &sqlx.Tx{}
+	tokenGenMock.EXPECT().ParseToken("valid-token").Return("sender", nil)
+	repoMock.EXPECT().GetTwoFactorPolicy(ctx).Return(models.TwoFactorPolicy{}, nil).AnyTimes()
+	repoMock.EXPECT().GetUserByUsername(ctx, "receiver").Return(&models.User{Username: "receiver"}, nil)
+	repoMock.EXPECT().BeginTx(ctx).Return(tx, nil)
+	repoMock.EXPECT().DecreaseBalance(ctx, tx, repo.ChangeBalanceParams{Username: "sender", Amount: 100}).
+		Return(0, repo.InsufficientFundsError)
+	repoMock.EXPECT().RollbackTx(tx).Return(nil)
+
+	_, err := service.SendCoins(ctx, services.TransactionParams{Token: "valid-token", ReceiverUsername: "receiver", Amount: 100})
+	assert.ErrorIs(t, err, services.InsufficientFundsError)
+}
+
+func TestSendCoinsWithMemo(t *testing.T) {
+	ctx := context.Background()
+	params := services.TransactionParams{
+		Token: "valid-token", ReceiverUsername: "receiver", Amount: 100, Memo: "for lunch",
+	}
+
+	t.Run("flag off", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repoMock := mocks.NewMockCoinRepository(ctrl)
+		tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+		flagsMock := servicemocks.NewMockFeatureFlags(ctrl)
+
+		service := services.NewCoinService(repoMock, tokenGenMock, services.CoinServiceConfig{Flags: flagsMock})
+
+		tokenGenMock.EXPECT().ParseToken(params.Token).Return("sender", nil)
+		flagsMock.EXPECT().Enabled(services.FlagTransferMemos, "sender").Return(false)
+
+		_, err := service.SendCoins(ctx, params)
+		assert.ErrorIs(t, err, services.FeatureDisabledError)
+	})
+
+	t.Run("no flags", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repoMock := mocks.NewMockCoinRepository(ctrl)
+		tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+
+		service := services.NewCoinService(repoMock, tokenGenMock, services.CoinServiceConfig{})
+
+		tokenGenMock.EXPECT().ParseToken(params.Token).Return("sender", nil)
+
+		_, err := service.SendCoins(ctx, params)
+		assert.ErrorIs(t, err, services.FeatureDisabledError)
+	})
+
+	t.Run("too long", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repoMock := mocks.NewMockCoinRepository(ctrl)
+		tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+		flagsMock := servicemocks.NewMockFeatureFlags(ctrl)
+
+		service := services.NewCoinService(repoMock, tokenGenMock, services.CoinServiceConfig{Flags: flagsMock})
+
+		tokenGenMock.EXPECT().ParseToken(params.Token).Return("sender", nil)
+		flagsMock.EXPECT().Enabled(services.FlagTransferMemos, "sender").Return(true)
+
+		long := params
+		long.Memo = strings.Repeat("я", 201)
+		_, err := service.SendCoins(ctx, long)
+		assert.ErrorIs(t, err, services.InvalidMemoError)
+	})
+
+	t.Run("flag on", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repoMock := mocks.NewMockCoinRepository(ctrl)
+		tokenGenMock := mocks2.NewMockTokenGenerator(ctrl)
+		flagsMock := servicemocks.NewMockFeatureFlags(ctrl)
+
+		service := services.NewCoinService(repoMock, tokenGenMock, services.CoinServiceConfig{Flags: flagsMock})
+
+		tx := &sqlx.Tx{}
+		tokenGenMock.EXPECT().ParseToken(params.Token).Return("sender", nil)
+		flagsMock.EXPECT().Enabled(services.FlagTransferMemos, "sender").Return(true)
+		repoMock.EXPECT().GetTwoFactorPolicy(ctx).Return(models.TwoFactorPolicy{}, nil)
+		repoMock.EXPECT().GetUserByUsername(ctx, "receiver").Return(&models.User{Username: "receiver"}, nil)
+		repoMock.EXPECT().BeginTx(ctx).Return(tx, nil)
+		repoMock.EXPECT().DecreaseBalance(ctx, tx, gomock.Any()).Return(900, nil)
+		repoMock.EXPECT().IncreaseBalance(ctx, tx, gomock.Any()).Return(1100, nil)
+		repoMock.EXPECT().SaveTransaction(ctx, tx, gomock.Any()).Return(int64(7), nil)
+		repoMock.EXPECT().SaveEvent(ctx, tx, repo.SaveEventParams{
+			Username: "sender",
+			Type:     models.EventCoinsSent,
+			Payload:  models.CoinsSentPayload{ToUser: "receiver", Amount: 100, Memo: "for lunch"},
+		}).Return(nil)
+		repoMock.EXPECT().SaveEvent(ctx, tx, repo.SaveEventParams{
+			Username: "receiver",
+			Type:     models.EventCoinsReceived,
+			Payload:  models.CoinsReceivedPayload{FromUser: "sender", Amount: 100, Memo: "for lunch"},
+		}).Return(nil)
+		repoMock.EXPECT().SaveEvent(ctx, tx, gomock.Any()).Return(nil).Times(2)
+		repoMock.EXPECT().CreateNotification(ctx, tx, repo.CreateNotificationParams{
+			Username: "sender",
+			Type:     models.NotificationCoinsSent,
+			Payload:  models.CoinsSentPayload{ToUser: "receiver", Amount: 100, Memo: "for lunch"},
+		}).Return(nil)
+		repoMock.EXPECT().CreateNotification(ctx, tx, repo.CreateNotificationParams{
+			Username: "receiver",
+			Type:     models.NotificationCoinsReceived,
+			Payload:  models.CoinsReceivedPayload{FromUser: "sender", Amount: 100, Memo: "for lunch"},
+			Deliver:  true,
+		}).Return(nil)
+		repoMock.EXPECT().CommitTx(tx).Return(nil)
+
+		id, err := service.SendCoins(ctx, params)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7), id)
+	})
+}
@@ -0,0 +1,344 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/repo"
+	"github.com/Blxssy/AvitoTest/pkg/logger"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/hotp"
+	"github.com/pquerna/otp/totp"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+const (
+	totpPeriod = 30
+	// totpSkew accepts codes from one time step before and after the current
+	// one to tolerate clock drift on the phone.
+	totpSkew          = 1
+	recoveryCodeCount = 10
+)
+
+// TOTPEnrollment is shown once to the user to set up an authenticator app.
+type TOTPEnrollment struct {
+	Secret string
+	// URI is the otpauth:// provisioning URI, usually rendered as a QR code.
+	URI string
+}
+
+// matchTOTP returns the time step code was generated for.
+func matchTOTP(secret, code string, now time.Time) (int64, bool) {
+	if secret == "" || code == "" {
+		return 0, false
+	}
+
+	current := now.Unix() / totpPeriod
+	for counter := current - totpSkew; counter <= current+totpSkew; counter++ {
+		ok, err := hotp.ValidateCustom(code, uint64(counter), secret, hotp.ValidateOpts{
+			Digits:    otp.DigitsSix,
+			Algorithm: otp.AlgorithmSHA1,
+		})
+		if err == nil && ok {
+			return counter, true
+		}
+	}
+	return 0, false
+}
+
+// useTOTP accepts a TOTP code once; replaying it, or an older code, fails.
+func useTOTP(ctx context.Context, r repo.CoinRepository, user *models.User, code string, now time.Time) (bool, error) {
+	counter, ok := matchTOTP(user.TOTPSecret, code, now)
+	if !ok {
+		return false, nil
+	}
+
+	used, err := r.UseTOTPCounter(ctx, repo.UseTOTPCounterParams{
+		Username: user.Username,
+		Counter:  counter,
+	})
+	if err != nil {
+		return false, fmt.Errorf("r.UseTOTPCounter: %w", err)
+	}
+	return used, nil
+}
+
+// useSecondFactor accepts a TOTP code or one of the user's recovery codes.
+func useSecondFactor(ctx context.Context, r repo.CoinRepository, user *models.User, code string, now time.Time) (bool, error) {
+	code = strings.TrimSpace(code)
+	if code == "" || !user.TOTPEnabled {
+		return false, nil
+	}
+
+	if ok, err := useTOTP(ctx, r, user, code, now); ok || err != nil {
+		return ok, err
+	}
+
+	used, err := r.UseRecoveryCode(ctx, repo.UseRecoveryCodeParams{
+		Username: user.Username,
+		CodeHash: hashToken(normalizeRecoveryCode(code)),
+	})
+	if err != nil {
+		return false, fmt.Errorf("r.UseRecoveryCode: %w", err)
+	}
+	return used, nil
+}
+
+// newRecoveryCodes returns codes like "k4d2m-9xq7p" and the hashes to store.
+func newRecoveryCodes() ([]string, []string, error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		raw := make([]byte, 7)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, fmt.Errorf("rand.Read: %w", err)
+		}
+		code := strings.ToLower(encoding.EncodeToString(raw))[:10]
+		codes[i] = code[:5] + "-" + code[5:]
+		hashes[i] = hashToken(code)
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(code, "-", ""))
+}
+
+// requireSecondFactor enforces the admin policy for an operation worth
+// amount coins; threshold picks the policy limit that applies.
+func (s *coinService) requireSecondFactor(ctx context.Context, username string, amount int, threshold func(models.TwoFactorPolicy) int, code string) error {
+	policy, err := s.repo.GetTwoFactorPolicy(ctx)
+	if err != nil {
+		return fmt.Errorf("s.repo.GetTwoFactorPolicy: %w", err)
+	}
+	if limit := threshold(policy); limit == 0 || amount <= limit {
+		return nil
+	}
+
+	user, err := s.repo.GetUserByUsername(ctx, username)
+	if err != nil {
+		return fmt.Errorf("s.repo.GetUserByUsername: %w", err)
+	}
+	// Service accounts have no second factor; their API keys are scoped instead.
+	if user.IsServiceAccount {
+		return nil
+	}
+	if !user.TOTPEnabled {
+		return TwoFactorSetupRequiredError
+	}
+	if code == "" {
+		return OTPRequiredError
+	}
+
+	// Guessing codes with a stolen access token is throttled like logins.
+	keys := loginKeys(username, "")
+	attempts, err := s.logins.check(ctx, keys)
+	if err != nil {
+		return err
+	}
+
+	ok, err := useSecondFactor(ctx, s.repo, user, code, time.Now())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if err = s.logins.fail(ctx, username, ""); err != nil {
+			return err
+		}
+		return InvalidOTPCodeError
+	}
+
+	if len(attempts) > 0 {
+		return s.logins.reset(ctx, keys)
+	}
+	return nil
+}
+
+func transferThreshold(policy models.TwoFactorPolicy) int {
+	return policy.TransferThreshold
+}
+
+func purchaseThreshold(policy models.TwoFactorPolicy) int {
+	return policy.PurchaseThreshold
+}
+
+func (s *accountService) EnrollTOTP(ctx context.Context, params EnrollTOTPParams) (TOTPEnrollment, error) {
+	user, err := s.userFromToken(ctx, params.Token)
+	if err != nil {
+		return TOTPEnrollment{}, err
+	}
+	if user.TOTPEnabled {
+		return TOTPEnrollment{}, TwoFactorEnabledError
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      s.totpIssuer,
+		AccountName: user.Username,
+		Period:      totpPeriod,
+		Digits:      otp.DigitsSix,
+		Algorithm:   otp.AlgorithmSHA1,
+	})
+	if err != nil {
+		return TOTPEnrollment{}, fmt.Errorf("totp.Generate: %w", err)
+	}
+
+	if err = s.repo.SetTOTPSecret(ctx, repo.SetTOTPSecretParams{
+		Username: user.Username,
+		Secret:   key.Secret(),
+	}); err != nil {
+		return TOTPEnrollment{}, fmt.Errorf("s.repo.SetTOTPSecret: %w", err)
+	}
+
+	return TOTPEnrollment{Secret: key.Secret(), URI: key.URL()}, nil
+}
+
+// ActivateTOTP turns 2FA on once the user proves the authenticator works and
+// returns recovery codes. They are shown only this once.
+func (s *accountService) ActivateTOTP(ctx context.Context, params ActivateTOTPParams) ([]string, error) {
+	user, err := s.userFromToken(ctx, params.Token)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, TwoFactorEnabledError
+	}
+	if user.TOTPSecret == "" {
+		return nil, TwoFactorNotEnrolledError
+	}
+
+	keys := loginKeys(user.Username, "")
+	attempts, err := s.logins.check(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := useTOTP(ctx, s.repo, user, strings.TrimSpace(params.Code), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if err = s.logins.fail(ctx, user.Username, ""); err != nil {
+			return nil, err
+		}
+		return nil, InvalidOTPCodeError
+	}
+	if len(attempts) > 0 {
+		if err = s.logins.reset(ctx, keys); err != nil {
+			return nil, err
+		}
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.repo.EnableTOTP(ctx, repo.EnableTOTPParams{
+		Username:           user.Username,
+		RecoveryCodeHashes: hashes,
+	}); err != nil {
+		return nil, fmt.Errorf("s.repo.EnableTOTP: %w", err)
+	}
+
+	s.log(ctx).Info("two-factor authentication enabled", zap.String("username", user.Username))
+	return codes, nil
+}
+
+func (s *accountService) DisableTOTP(ctx context.Context, params DisableTOTPParams) error {
+	user, err := s.userFromToken(ctx, params.Token)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return TwoFactorNotEnrolledError
+	}
+
+	// Guessing codes with a stolen access token is throttled like logins.
+	keys := loginKeys(user.Username, "")
+	attempts, err := s.logins.check(ctx, keys)
+	if err != nil {
+		return err
+	}
+
+	ok, err := useSecondFactor(ctx, s.repo, user, params.Code, time.Now())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if err = s.logins.fail(ctx, user.Username, ""); err != nil {
+			return err
+		}
+		return InvalidOTPCodeError
+	}
+	if len(attempts) > 0 {
+		if err = s.logins.reset(ctx, keys); err != nil {
+			return err
+		}
+	}
+
+	if err = s.repo.DisableTOTP(ctx, user.Username); err != nil {
+		return fmt.Errorf("s.repo.DisableTOTP: %w", err)
+	}
+
+	s.log(ctx).Info("two-factor authentication disabled", zap.String("username", user.Username))
+	return nil
+}
+
+func (s *accountService) GetTwoFactorPolicy(ctx context.Context, params GetTwoFactorPolicyParams) (models.TwoFactorPolicy, error) {
+	if _, err := authorizeAdmin(ctx, s.repo, s.tokenGen, params.Token); err != nil {
+		return models.TwoFactorPolicy{}, err
+	}
+
+	policy, err := s.repo.GetTwoFactorPolicy(ctx)
+	if err != nil {
+		return models.TwoFactorPolicy{}, fmt.Errorf("s.repo.GetTwoFactorPolicy: %w", err)
+	}
+	return policy, nil
+}
+
+func (s *accountService) SetTwoFactorPolicy(ctx context.Context, params SetTwoFactorPolicyParams) error {
+	adminUsername, err := authorizeAdmin(ctx, s.repo, s.tokenGen, params.Token)
+	if err != nil {
+		return err
+	}
+
+	if params.Policy.TransferThreshold < 0 || params.Policy.PurchaseThreshold < 0 {
+		return InvalidThresholdError
+	}
+
+	if err = s.repo.SetTwoFactorPolicy(ctx, params.Policy); err != nil {
+		return fmt.Errorf("s.repo.SetTwoFactorPolicy: %w", err)
+	}
+
+	s.log(ctx).Info("two-factor policy changed",
+		zap.String("admin", adminUsername),
+		zap.Int("transfer_threshold", params.Policy.TransferThreshold),
+		zap.Int("purchase_threshold", params.Policy.PurchaseThreshold),
+	)
+	return nil
+}
+
+func (s *accountService) userFromToken(ctx context.Context, accessToken string) (*models.User, error) {
+	username, err := s.tokenGen.ParseToken(accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("s.tokenGen.ParseToken: %w: %w", InvalidTokenError, err)
+	}
+	logger.SetUsername(ctx, username)
+
+	user, err := s.repo.GetUserByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, InvalidTokenError
+		}
+		return nil, fmt.Errorf("s.repo.GetUserByUsername: %w", err)
+	}
+	return user, nil
+}
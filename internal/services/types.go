@@ -1,5 +1,10 @@
 package services
 
+import (
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"time"
+)
+
 type GetBalanceParams struct {
 	Token string
 }
@@ -7,18 +12,39 @@ type GetBalanceParams struct {
 type AuthParams struct {
 	Username string
 	Password string
+	// ClientIP is counted towards failed login attempts; empty skips the per-IP check.
+	ClientIP string
 }
 
 type TransactionParams struct {
 	Token            string
 	ReceiverUsername string
 	Amount           int
+	// OTPCode is a TOTP or recovery code, needed when the policy asks for one.
+	OTPCode string
+	// OnBehalfOf names the account to send from when the caller is a client
+	// the owner delegated transfers to. Empty sends from the caller's account.
+	OnBehalfOf string
+	// Memo is a note for the receiver, available under FlagTransferMemos.
+	Memo string
 }
 
 type GetTransactionsParams struct {
 	Token string
 }
 
+type GetTransferParams struct {
+	Token string
+	ID    int64
+}
+
+// Transfer is a transaction as one of its parties sees it.
+type Transfer struct {
+	models.Transaction
+	// Received is set when the caller is the receiver.
+	Received bool
+}
+
 type GetPurchasesParams struct {
 	Token string
 }
@@ -26,4 +52,214 @@ type GetPurchasesParams struct {
 type BuyItemParams struct {
 	Token string
 	Item  string
+	// OTPCode is a TOTP or recovery code, needed when the policy asks for one.
+	OTPCode string
+}
+
+type GetItemsParams struct {
+	Token string
+	// Names limits the result to these items; nil returns the whole catalog.
+	Names []string
+}
+
+type SubscribeParams struct {
+	Token       string
+	LastEventID int64
+}
+
+type GetNotificationsParams struct {
+	Token      string
+	Cursor     int64
+	Limit      int
+	UnreadOnly bool
+}
+
+type MarkNotificationReadParams struct {
+	Token string
+	ID    int64
+}
+
+type MarkAllNotificationsReadParams struct {
+	Token string
+}
+
+type GetNotificationPreferencesParams struct {
+	Token string
+}
+
+type UpdateNotificationPreferencesParams struct {
+	Token       string
+	Preferences []models.NotificationPreference
+}
+
+type UpdateNotificationContactParams struct {
+	Token  string
+	Email  string
+	Locale string
+}
+
+type AdminMessageParams struct {
+	Token            string
+	ReceiverUsername string
+	Message          string
+}
+
+type UnlockUserParams struct {
+	Token    string
+	Username string
+}
+
+type ReloadConfigParams struct {
+	Token string
+}
+
+// ReloadedConfig lists the settings a reload changed: Applied took effect,
+// RestartRequired only apply after a restart.
+type ReloadedConfig struct {
+	Applied         []string
+	RestartRequired []string
+}
+
+type ChangePasswordParams struct {
+	Token           string
+	CurrentPassword string
+	NewPassword     string
+}
+
+type IssuePasswordResetParams struct {
+	Token    string
+	Username string
+}
+
+type ResetPasswordParams struct {
+	ResetToken  string
+	NewPassword string
+}
+
+type TwoFactorAuthParams struct {
+	ChallengeToken string
+	// Code is a TOTP or recovery code.
+	Code     string
+	ClientIP string
+}
+
+// OIDCCallbackParams carries the query of the identity provider's redirect
+// back to the service.
+type OIDCCallbackParams struct {
+	Code  string
+	State string
+}
+
+type EnrollTOTPParams struct {
+	Token string
+}
+
+type ActivateTOTPParams struct {
+	Token string
+	Code  string
+}
+
+type DisableTOTPParams struct {
+	Token string
+	// Code is a TOTP or recovery code.
+	Code string
+}
+
+type GetTwoFactorPolicyParams struct {
+	Token string
+}
+
+type SetTwoFactorPolicyParams struct {
+	Token  string
+	Policy models.TwoFactorPolicy
+}
+
+type CreateServiceAccountParams struct {
+	Token    string
+	Username string
+	// Balance is the coins the account starts with.
+	Balance int
+}
+
+type CreateAPIKeyParams struct {
+	Token    string
+	Username string
+	Scopes   []Scope
+	// ExpiresAt defaults to the configured key lifetime when zero.
+	ExpiresAt time.Time
+}
+
+type ListAPIKeysParams struct {
+	Token    string
+	Username string
+}
+
+type RevokeAPIKeyParams struct {
+	Token string
+	ID    string
+}
+
+type GrantDelegationParams struct {
+	Token          string
+	ClientUsername string
+	DailyLimit     int
+}
+
+type ListDelegationsParams struct {
+	Token string
+}
+
+type RevokeDelegationParams struct {
+	Token string
+	ID    int64
+}
+
+type GetFeaturesParams struct {
+	Token string
+}
+
+type ListFeatureFlagsParams struct {
+	Token string
+}
+
+type SetFeatureFlagParams struct {
+	Token          string
+	Name           string
+	Description    string
+	Enabled        bool
+	RolloutPercent int
+	AllowList      []string
+}
+
+type DeleteFeatureFlagParams struct {
+	Token string
+	Name  string
+}
+
+type CreateUserParams struct {
+	Username string
+	Password string
+	// Balance defaults to the configured starting balance when nil.
+	Balance *int
+	IsAdmin bool
+}
+
+type AdjustBalanceParams struct {
+	Operator string
+	Username string
+	Amount   int
+	Reason   string
+}
+
+type SetAccountFrozenParams struct {
+	Username string
+	Frozen   bool
+}
+
+// ListTransactionsParams pages through transactions from the newest. An
+// empty Username lists everyone's.
+type ListTransactionsParams struct {
+	Username string
+	BeforeID int64
+	Limit    int
 }
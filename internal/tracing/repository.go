@@ -0,0 +1,398 @@
+package tracing
+
+import (
+	"context"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/repo"
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type coinRepository struct {
+	next   repo.CoinRepository
+	tracer trace.Tracer
+}
+
+// NewCoinRepository wraps next to start a span for each of its calls.
+func NewCoinRepository(next repo.CoinRepository) repo.CoinRepository {
+	return &coinRepository{
+		next:   next,
+		tracer: otel.Tracer(InstrumentationName),
+	}
+}
+
+// start traces queries made while serving a traced request. Background
+// workers poll the database all the time and would only add noise.
+func (r *coinRepository) start(ctx context.Context, name string) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+	return start(ctx, r.tracer, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL),
+	)
+}
+
+func (r *coinRepository) GetBalance(ctx context.Context, params repo.GetBalanceParams) (_ int, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.GetBalance")
+	defer func() { end(span, err) }()
+	return r.next.GetBalance(ctx, params)
+}
+
+func (r *coinRepository) CreateUser(ctx context.Context, params repo.CreateUserParams) (err error) {
+	ctx, span := r.start(ctx, "CoinRepository.CreateUser")
+	defer func() { end(span, err) }()
+	return r.next.CreateUser(ctx, params)
+}
+
+func (r *coinRepository) UpdatePassword(ctx context.Context, params repo.UpdatePasswordParams) (err error) {
+	ctx, span := r.start(ctx, "CoinRepository.UpdatePassword")
+	defer func() { end(span, err) }()
+	return r.next.UpdatePassword(ctx, params)
+}
+
+func (r *coinRepository) CreatePasswordReset(ctx context.Context, params repo.CreatePasswordResetParams) (err error) {
+	ctx, span := r.start(ctx, "CoinRepository.CreatePasswordReset")
+	defer func() { end(span, err) }()
+	return r.next.CreatePasswordReset(ctx, params)
+}
+
+func (r *coinRepository) ResetPassword(ctx context.Context, params repo.ResetPasswordParams) (_ string, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.ResetPassword")
+	defer func() { end(span, err) }()
+	return r.next.ResetPassword(ctx, params)
+}
+
+func (r *coinRepository) GetUserByUsername(ctx context.Context, username string) (_ *models.User, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.GetUserByUsername")
+	defer func() { end(span, err) }()
+	return r.next.GetUserByUsername(ctx, username)
+}
+
+func (r *coinRepository) BeginTx(ctx context.Context) (_ *sqlx.Tx, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.BeginTx")
+	defer func() { end(span, err) }()
+	return r.next.BeginTx(ctx)
+}
+
+func (r *coinRepository) DecreaseBalance(ctx context.Context, tx *sqlx.Tx, params repo.ChangeBalanceParams) (_ int, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.DecreaseBalance")
+	defer func() { end(span, err) }()
+	return r.next.DecreaseBalance(ctx, tx, params)
+}
+
+func (r *coinRepository) IncreaseBalance(ctx context.Context, tx *sqlx.Tx, params repo.ChangeBalanceParams) (_ int, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.IncreaseBalance")
+	defer func() { end(span, err) }()
+	return r.next.IncreaseBalance(ctx, tx, params)
+}
+
+func (r *coinRepository) SaveTransaction(ctx context.Context, tx *sqlx.Tx, params repo.SaveTransactionParams) (_ int64, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.SaveTransaction")
+	defer func() { end(span, err) }()
+	return r.next.SaveTransaction(ctx, tx, params)
+}
+
+func (r *coinRepository) GetTransaction(ctx context.Context, id int64) (_ models.Transaction, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.GetTransaction")
+	defer func() { end(span, err) }()
+	return r.next.GetTransaction(ctx, id)
+}
+
+func (r *coinRepository) GetTransactions(ctx context.Context, username string) (_ []models.Transaction, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.GetTransactions")
+	defer func() { end(span, err) }()
+	return r.next.GetTransactions(ctx, username)
+}
+
+func (r *coinRepository) ReceivedCoinsInfo(ctx context.Context, username string) (_ []models.Transaction, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.ReceivedCoinsInfo")
+	defer func() { end(span, err) }()
+	return r.next.ReceivedCoinsInfo(ctx, username)
+}
+
+func (r *coinRepository) GetPurchases(ctx context.Context, username string) (_ []models.PurchaseItem, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.GetPurchases")
+	defer func() { end(span, err) }()
+	return r.next.GetPurchases(ctx, username)
+}
+
+func (r *coinRepository) BuyItem(ctx context.Context, tx *sqlx.Tx, params repo.BuyItemParams) (_ int, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.BuyItem")
+	defer func() { end(span, err) }()
+	return r.next.BuyItem(ctx, tx, params)
+}
+
+func (r *coinRepository) GetItem(ctx context.Context, itemName string) (_ models.Item, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.GetItem")
+	defer func() { end(span, err) }()
+	return r.next.GetItem(ctx, itemName)
+}
+
+func (r *coinRepository) GetItems(ctx context.Context, params repo.GetItemsParams) (_ []models.Item, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.GetItems")
+	defer func() { end(span, err) }()
+	return r.next.GetItems(ctx, params)
+}
+
+func (r *coinRepository) SaveEvent(ctx context.Context, tx *sqlx.Tx, params repo.SaveEventParams) (err error) {
+	ctx, span := r.start(ctx, "CoinRepository.SaveEvent")
+	defer func() { end(span, err) }()
+	return r.next.SaveEvent(ctx, tx, params)
+}
+
+func (r *coinRepository) GetEvents(ctx context.Context, params repo.GetEventsParams) (_ []models.Event, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.GetEvents")
+	defer func() { end(span, err) }()
+	return r.next.GetEvents(ctx, params)
+}
+
+func (r *coinRepository) CommitTx(tx *sqlx.Tx) error {
+	return r.next.CommitTx(tx)
+}
+
+func (r *coinRepository) RollbackTx(tx *sqlx.Tx) error {
+	return r.next.RollbackTx(tx)
+}
+
+func (r *coinRepository) CreateNotification(ctx context.Context, tx *sqlx.Tx, params repo.CreateNotificationParams) (err error) {
+	ctx, span := r.start(ctx, "CoinRepository.CreateNotification")
+	defer func() { end(span, err) }()
+	return r.next.CreateNotification(ctx, tx, params)
+}
+
+func (r *coinRepository) GetNotifications(ctx context.Context, params repo.GetNotificationsParams) (_ []models.Notification, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.GetNotifications")
+	defer func() { end(span, err) }()
+	return r.next.GetNotifications(ctx, params)
+}
+
+func (r *coinRepository) CountUnreadNotifications(ctx context.Context, username string) (_ int, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.CountUnreadNotifications")
+	defer func() { end(span, err) }()
+	return r.next.CountUnreadNotifications(ctx, username)
+}
+
+func (r *coinRepository) MarkNotificationRead(ctx context.Context, params repo.MarkNotificationReadParams) (err error) {
+	ctx, span := r.start(ctx, "CoinRepository.MarkNotificationRead")
+	defer func() { end(span, err) }()
+	return r.next.MarkNotificationRead(ctx, params)
+}
+
+func (r *coinRepository) MarkAllNotificationsRead(ctx context.Context, username string) (_ int64, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.MarkAllNotificationsRead")
+	defer func() { end(span, err) }()
+	return r.next.MarkAllNotificationsRead(ctx, username)
+}
+
+func (r *coinRepository) GetNotificationPreferences(ctx context.Context, username string) (_ []models.NotificationPreference, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.GetNotificationPreferences")
+	defer func() { end(span, err) }()
+	return r.next.GetNotificationPreferences(ctx, username)
+}
+
+func (r *coinRepository) SetNotificationPreference(ctx context.Context, tx *sqlx.Tx, params repo.SetNotificationPreferenceParams) (err error) {
+	ctx, span := r.start(ctx, "CoinRepository.SetNotificationPreference")
+	defer func() { end(span, err) }()
+	return r.next.SetNotificationPreference(ctx, tx, params)
+}
+
+func (r *coinRepository) SetNotificationContact(ctx context.Context, params repo.SetNotificationContactParams) (err error) {
+	ctx, span := r.start(ctx, "CoinRepository.SetNotificationContact")
+	defer func() { end(span, err) }()
+	return r.next.SetNotificationContact(ctx, params)
+}
+
+func (r *coinRepository) ClaimNotificationDeliveries(ctx context.Context, params repo.ClaimNotificationDeliveriesParams) (_ []models.NotificationDelivery, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.ClaimNotificationDeliveries")
+	defer func() { end(span, err) }()
+	return r.next.ClaimNotificationDeliveries(ctx, params)
+}
+
+func (r *coinRepository) CompleteNotificationDelivery(ctx context.Context, id int64) (err error) {
+	ctx, span := r.start(ctx, "CoinRepository.CompleteNotificationDelivery")
+	defer func() { end(span, err) }()
+	return r.next.CompleteNotificationDelivery(ctx, id)
+}
+
+func (r *coinRepository) FailNotificationDelivery(ctx context.Context, params repo.FailNotificationDeliveryParams) (err error) {
+	ctx, span := r.start(ctx, "CoinRepository.FailNotificationDelivery")
+	defer func() { end(span, err) }()
+	return r.next.FailNotificationDelivery(ctx, params)
+}
+
+func (r *coinRepository) GetLoginAttempts(ctx context.Context, keys []string) (_ []models.LoginAttempt, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.GetLoginAttempts")
+	defer func() { end(span, err) }()
+	return r.next.GetLoginAttempts(ctx, keys)
+}
+
+func (r *coinRepository) RecordLoginFailure(ctx context.Context, params repo.RecordLoginFailureParams) (_ models.LoginAttempt, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.RecordLoginFailure")
+	defer func() { end(span, err) }()
+	return r.next.RecordLoginFailure(ctx, params)
+}
+
+func (r *coinRepository) ResetLoginAttempts(ctx context.Context, keys []string) (err error) {
+	ctx, span := r.start(ctx, "CoinRepository.ResetLoginAttempts")
+	defer func() { end(span, err) }()
+	return r.next.ResetLoginAttempts(ctx, keys)
+}
+
+func (r *coinRepository) SetTOTPSecret(ctx context.Context, params repo.SetTOTPSecretParams) (err error) {
+	ctx, span := r.start(ctx, "CoinRepository.SetTOTPSecret")
+	defer func() { end(span, err) }()
+	return r.next.SetTOTPSecret(ctx, params)
+}
+
+func (r *coinRepository) EnableTOTP(ctx context.Context, params repo.EnableTOTPParams) (err error) {
+	ctx, span := r.start(ctx, "CoinRepository.EnableTOTP")
+	defer func() { end(span, err) }()
+	return r.next.EnableTOTP(ctx, params)
+}
+
+func (r *coinRepository) DisableTOTP(ctx context.Context, username string) (err error) {
+	ctx, span := r.start(ctx, "CoinRepository.DisableTOTP")
+	defer func() { end(span, err) }()
+	return r.next.DisableTOTP(ctx, username)
+}
+
+func (r *coinRepository) UseTOTPCounter(ctx context.Context, params repo.UseTOTPCounterParams) (_ bool, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.UseTOTPCounter")
+	defer func() { end(span, err) }()
+	return r.next.UseTOTPCounter(ctx, params)
+}
+
+func (r *coinRepository) UseRecoveryCode(ctx context.Context, params repo.UseRecoveryCodeParams) (_ bool, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.UseRecoveryCode")
+	defer func() { end(span, err) }()
+	return r.next.UseRecoveryCode(ctx, params)
+}
+
+func (r *coinRepository) CreateAuthChallenge(ctx context.Context, params repo.CreateAuthChallengeParams) (err error) {
+	ctx, span := r.start(ctx, "CoinRepository.CreateAuthChallenge")
+	defer func() { end(span, err) }()
+	return r.next.CreateAuthChallenge(ctx, params)
+}
+
+func (r *coinRepository) GetAuthChallenge(ctx context.Context, tokenHash string) (_ string, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.GetAuthChallenge")
+	defer func() { end(span, err) }()
+	return r.next.GetAuthChallenge(ctx, tokenHash)
+}
+
+func (r *coinRepository) DeleteAuthChallenge(ctx context.Context, tokenHash string) (_ bool, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.DeleteAuthChallenge")
+	defer func() { end(span, err) }()
+	return r.next.DeleteAuthChallenge(ctx, tokenHash)
+}
+
+func (r *coinRepository) GetTwoFactorPolicy(ctx context.Context) (_ models.TwoFactorPolicy, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.GetTwoFactorPolicy")
+	defer func() { end(span, err) }()
+	return r.next.GetTwoFactorPolicy(ctx)
+}
+
+func (r *coinRepository) SetTwoFactorPolicy(ctx context.Context, policy models.TwoFactorPolicy) (err error) {
+	ctx, span := r.start(ctx, "CoinRepository.SetTwoFactorPolicy")
+	defer func() { end(span, err) }()
+	return r.next.SetTwoFactorPolicy(ctx, policy)
+}
+
+func (r *coinRepository) CreateServiceAccount(ctx context.Context, params repo.CreateServiceAccountParams) (_ bool, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.CreateServiceAccount")
+	defer func() { end(span, err) }()
+	return r.next.CreateServiceAccount(ctx, params)
+}
+
+func (r *coinRepository) CreateAPIKey(ctx context.Context, params repo.CreateAPIKeyParams) (err error) {
+	ctx, span := r.start(ctx, "CoinRepository.CreateAPIKey")
+	defer func() { end(span, err) }()
+	return r.next.CreateAPIKey(ctx, params)
+}
+
+func (r *coinRepository) GetAPIKey(ctx context.Context, id string) (_ models.APIKey, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.GetAPIKey")
+	defer func() { end(span, err) }()
+	return r.next.GetAPIKey(ctx, id)
+}
+
+func (r *coinRepository) ListAPIKeys(ctx context.Context, username string) (_ []models.APIKey, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.ListAPIKeys")
+	defer func() { end(span, err) }()
+	return r.next.ListAPIKeys(ctx, username)
+}
+
+func (r *coinRepository) RevokeAPIKey(ctx context.Context, id string) (_ bool, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.RevokeAPIKey")
+	defer func() { end(span, err) }()
+	return r.next.RevokeAPIKey(ctx, id)
+}
+
+func (r *coinRepository) GrantDelegation(ctx context.Context, params repo.GrantDelegationParams) (_ models.Delegation, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.GrantDelegation")
+	defer func() { end(span, err) }()
+	return r.next.GrantDelegation(ctx, params)
+}
+
+func (r *coinRepository) ListDelegations(ctx context.Context, ownerUsername string) (_ []models.Delegation, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.ListDelegations")
+	defer func() { end(span, err) }()
+	return r.next.ListDelegations(ctx, ownerUsername)
+}
+
+func (r *coinRepository) RevokeDelegation(ctx context.Context, params repo.RevokeDelegationParams) (_ bool, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.RevokeDelegation")
+	defer func() { end(span, err) }()
+	return r.next.RevokeDelegation(ctx, params)
+}
+
+func (r *coinRepository) LockDelegation(ctx context.Context, tx *sqlx.Tx, params repo.LockDelegationParams) (_ models.Delegation, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.LockDelegation")
+	defer func() { end(span, err) }()
+	return r.next.LockDelegation(ctx, tx, params)
+}
+
+func (r *coinRepository) CreateOIDCLoginState(ctx context.Context, params repo.CreateOIDCLoginStateParams) (err error) {
+	ctx, span := r.start(ctx, "CoinRepository.CreateOIDCLoginState")
+	defer func() { end(span, err) }()
+	return r.next.CreateOIDCLoginState(ctx, params)
+}
+
+func (r *coinRepository) UseOIDCLoginState(ctx context.Context, stateHash string) (_ models.OIDCLoginState, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.UseOIDCLoginState")
+	defer func() { end(span, err) }()
+	return r.next.UseOIDCLoginState(ctx, stateHash)
+}
+
+func (r *coinRepository) GetOIDCIdentity(ctx context.Context, params repo.GetOIDCIdentityParams) (_ string, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.GetOIDCIdentity")
+	defer func() { end(span, err) }()
+	return r.next.GetOIDCIdentity(ctx, params)
+}
+
+func (r *coinRepository) LinkOIDCIdentity(ctx context.Context, params repo.LinkOIDCIdentityParams) (err error) {
+	ctx, span := r.start(ctx, "CoinRepository.LinkOIDCIdentity")
+	defer func() { end(span, err) }()
+	return r.next.LinkOIDCIdentity(ctx, params)
+}
+
+func (r *coinRepository) GetFeatureFlags(ctx context.Context) (_ []models.FeatureFlag, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.GetFeatureFlags")
+	defer func() { end(span, err) }()
+	return r.next.GetFeatureFlags(ctx)
+}
+
+func (r *coinRepository) SaveFeatureFlag(ctx context.Context, params repo.SaveFeatureFlagParams) (_ models.FeatureFlag, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.SaveFeatureFlag")
+	defer func() { end(span, err) }()
+	return r.next.SaveFeatureFlag(ctx, params)
+}
+
+func (r *coinRepository) DeleteFeatureFlag(ctx context.Context, name string) (_ bool, err error) {
+	ctx, span := r.start(ctx, "CoinRepository.DeleteFeatureFlag")
+	defer func() { end(span, err) }()
+	return r.next.DeleteFeatureFlag(ctx, name)
+}
@@ -0,0 +1,98 @@
+package tracing
+
+import (
+	"context"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type coinService struct {
+	next   services.CoinService
+	tracer trace.Tracer
+}
+
+// NewCoinService wraps next to start a span for each of its calls.
+func NewCoinService(next services.CoinService) services.CoinService {
+	return &coinService{
+		next:   next,
+		tracer: otel.Tracer(InstrumentationName),
+	}
+}
+
+func (s *coinService) start(ctx context.Context, name string) (context.Context, trace.Span) {
+	return start(ctx, s.tracer, name)
+}
+
+func (s *coinService) GetBalance(ctx context.Context, params services.GetBalanceParams) (_ int, err error) {
+	ctx, span := s.start(ctx, "CoinService.GetBalance")
+	defer func() { end(span, err) }()
+	return s.next.GetBalance(ctx, params)
+}
+
+func (s *coinService) Auth(ctx context.Context, params services.AuthParams) (_ services.AuthResult, err error) {
+	ctx, span := s.start(ctx, "CoinService.Auth")
+	defer func() { end(span, err) }()
+	return s.next.Auth(ctx, params)
+}
+
+func (s *coinService) CompleteTwoFactorAuth(ctx context.Context, params services.TwoFactorAuthParams) (_ string, err error) {
+	ctx, span := s.start(ctx, "CoinService.CompleteTwoFactorAuth")
+	defer func() { end(span, err) }()
+	return s.next.CompleteTwoFactorAuth(ctx, params)
+}
+
+func (s *coinService) OIDCLoginURL(ctx context.Context) (_ string, err error) {
+	ctx, span := s.start(ctx, "CoinService.OIDCLoginURL")
+	defer func() { end(span, err) }()
+	return s.next.OIDCLoginURL(ctx)
+}
+
+func (s *coinService) OIDCCallback(ctx context.Context, params services.OIDCCallbackParams) (_ services.AuthResult, err error) {
+	ctx, span := s.start(ctx, "CoinService.OIDCCallback")
+	defer func() { end(span, err) }()
+	return s.next.OIDCCallback(ctx, params)
+}
+
+func (s *coinService) SendCoins(ctx context.Context, params services.TransactionParams) (_ int64, err error) {
+	ctx, span := s.start(ctx, "CoinService.SendCoins")
+	defer func() { end(span, err) }()
+	return s.next.SendCoins(ctx, params)
+}
+
+func (s *coinService) GetTransfer(ctx context.Context, params services.GetTransferParams) (_ services.Transfer, err error) {
+	ctx, span := s.start(ctx, "CoinService.GetTransfer")
+	defer func() { end(span, err) }()
+	return s.next.GetTransfer(ctx, params)
+}
+
+func (s *coinService) SendCoinsInfo(ctx context.Context, params services.GetTransactionsParams) (_ []models.Transaction, err error) {
+	ctx, span := s.start(ctx, "CoinService.SendCoinsInfo")
+	defer func() { end(span, err) }()
+	return s.next.SendCoinsInfo(ctx, params)
+}
+
+func (s *coinService) ReceivedCoinsInfo(ctx context.Context, params services.GetTransactionsParams) (_ []models.Transaction, err error) {
+	ctx, span := s.start(ctx, "CoinService.ReceivedCoinsInfo")
+	defer func() { end(span, err) }()
+	return s.next.ReceivedCoinsInfo(ctx, params)
+}
+
+func (s *coinService) GetPurchases(ctx context.Context, params services.GetPurchasesParams) (_ []models.PurchaseItem, err error) {
+	ctx, span := s.start(ctx, "CoinService.GetPurchases")
+	defer func() { end(span, err) }()
+	return s.next.GetPurchases(ctx, params)
+}
+
+func (s *coinService) BuyItem(ctx context.Context, params services.BuyItemParams) (err error) {
+	ctx, span := s.start(ctx, "CoinService.BuyItem")
+	defer func() { end(span, err) }()
+	return s.next.BuyItem(ctx, params)
+}
+
+func (s *coinService) GetItems(ctx context.Context, params services.GetItemsParams) (_ []models.Item, err error) {
+	ctx, span := s.start(ctx, "CoinService.GetItems")
+	defer func() { end(span, err) }()
+	return s.next.GetItems(ctx, params)
+}
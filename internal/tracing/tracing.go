@@ -0,0 +1,114 @@
+// Package tracing sets up OpenTelemetry tracing and traces the coin service
+// and its repository.
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/repo"
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName names the tracer of every span the service creates.
+const InstrumentationName = "github.com/Blxssy/AvitoTest"
+
+// RequestIDKey is the attribute that ties spans to the X-Request-ID of the
+// HTTP request they served.
+const RequestIDKey = attribute.Key("request.id")
+
+// Config selects the exporter: "otlp" sends spans over OTLP/HTTP to
+// OTLPEndpoint, "stdout" prints them for local runs and "none" only
+// propagates the trace context of incoming requests.
+type Config struct {
+	Exporter     string
+	OTLPEndpoint string
+	OTLPInsecure bool
+	ServiceName  string
+	// SampleRatio is the share of new traces recorded; traces started by
+	// callers keep their sampling decision.
+	SampleRatio float64
+}
+
+// Setup installs the global tracer provider and the W3C trace context
+// propagator. The returned function flushes buffered spans on shutdown.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case "otlp":
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.OTLPEndpoint)}
+		if cfg.OTLPInsecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("creating %s exporter: %w", cfg.Exporter, err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(cfg.ServiceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+type requestIDKey struct{}
+
+// ContextWithRequestID stores the request ID for the spans started under ctx.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the request ID stored by ContextWithRequestID.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+func start(ctx context.Context, tracer trace.Tracer, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if requestID := RequestID(ctx); requestID != "" {
+		opts = append(opts, trace.WithAttributes(RequestIDKey.String(requestID)))
+	}
+	return tracer.Start(ctx, name, opts...)
+}
+
+// end records err on span. Errors returned to the client, such as a wrong
+// password, and the answers of queries, such as missing rows, are recorded as
+// events only; the span fails for the rest.
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		var serviceErr *services.Error
+		if !errors.As(err, &serviceErr) && !errors.Is(err, sql.ErrNoRows) && !errors.Is(err, repo.InsufficientFundsError) {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
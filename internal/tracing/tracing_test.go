@@ -0,0 +1,151 @@
+package tracing_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/repo"
+	"github.com/Blxssy/AvitoTest/internal/repo/mocks"
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"github.com/Blxssy/AvitoTest/internal/tracing"
+	"github.com/Blxssy/AvitoTest/internal/transport/http/middleware"
+	tokenmocks "github.com/Blxssy/AvitoTest/pkg/token/mocks"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	_, err := tracing.Setup(context.Background(), tracing.Config{Exporter: "none"})
+	require.NoError(t, err)
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	return recorder
+}
+
+func TestTracing(t *testing.T) {
+	recorder := newRecorder(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	tokenGen := tokenmocks.NewMockTokenGenerator(ctrl)
+
+	coinService := tracing.NewCoinService(services.NewCoinService(tracing.NewCoinRepository(repoMock), tokenGen, services.CoinServiceConfig{}))
+
+	tokenGen.EXPECT().ParseToken("token").Return("alice", nil)
+	repoMock.EXPECT().GetBalance(gomock.Any(), repo.GetBalanceParams{Username: "alice"}).Return(1000, nil)
+
+	app := fiber.New()
+	app.Use(requestid.New())
+	app.Use(middleware.NewTracing())
+	app.Get("/api/balance/:user", func(ctx *fiber.Ctx) error {
+		balance, err := coinService.GetBalance(ctx.UserContext(), services.GetBalanceParams{Token: "token"})
+		if err != nil {
+			return err
+		}
+		return ctx.JSON(fiber.Map{"coins": balance})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/balance/alice", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set(fiber.HeaderXRequestID, "req-1")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	repoSpan, serviceSpan, serverSpan := spans[0], spans[1], spans[2]
+
+	assert.Equal(t, "GET /api/balance/:user", serverSpan.Name())
+	assert.Equal(t, trace.SpanKindServer, serverSpan.SpanKind())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", serverSpan.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", serverSpan.Parent().SpanID().String(), "the caller's span is the parent")
+
+	assert.Equal(t, "CoinService.GetBalance", serviceSpan.Name())
+	assert.Equal(t, serverSpan.SpanContext().SpanID(), serviceSpan.Parent().SpanID())
+
+	assert.Equal(t, "CoinRepository.GetBalance", repoSpan.Name())
+	assert.Equal(t, serviceSpan.SpanContext().SpanID(), repoSpan.Parent().SpanID())
+
+	for _, span := range spans {
+		assert.Contains(t, span.Attributes(), tracing.RequestIDKey.String("req-1"), span.Name())
+	}
+}
+
+func TestRepositoryNeedsParentSpan(t *testing.T) {
+	recorder := newRecorder(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	coinRepo := tracing.NewCoinRepository(repoMock)
+
+	ctx := context.Background()
+	repoMock.EXPECT().GetItems(ctx, repo.GetItemsParams{}).Return(nil, nil)
+
+	_, err := coinRepo.GetItems(ctx, repo.GetItemsParams{})
+	require.NoError(t, err)
+	assert.Empty(t, recorder.Ended(), "background queries are not traced")
+}
+
+func TestServiceErrors(t *testing.T) {
+	recorder := newRecorder(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tokenGen := tokenmocks.NewMockTokenGenerator(ctrl)
+	tokenGen.EXPECT().ParseToken("expired").Return("", assert.AnError)
+
+	coinService := tracing.NewCoinService(services.NewCoinService(nil, tokenGen, services.CoinServiceConfig{}))
+
+	_, err := coinService.GetBalance(context.Background(), services.GetBalanceParams{Token: "expired"})
+	assert.ErrorIs(t, err, services.InvalidTokenError)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	// Client errors are recorded but do not fail the span.
+	assert.Equal(t, "Unset", spans[0].Status().Code.String())
+	require.Len(t, spans[0].Events(), 1)
+	assert.Equal(t, "exception", spans[0].Events()[0].Name)
+}
+
+func TestRepositoryMissingRows(t *testing.T) {
+	recorder := newRecorder(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := mocks.NewMockCoinRepository(ctrl)
+	coinRepo := tracing.NewCoinRepository(repoMock)
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	repoMock.EXPECT().GetUserByUsername(gomock.Any(), "bob").Return(nil, fmt.Errorf("r.db.GetContext: %w", sql.ErrNoRows))
+
+	_, err := coinRepo.GetUserByUsername(ctx, "bob")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "CoinRepository.GetUserByUsername", spans[0].Name())
+	// A missing row is an answer, not a failed query.
+	assert.Equal(t, "Unset", spans[0].Status().Code.String())
+	require.Len(t, spans[0].Events(), 1)
+}
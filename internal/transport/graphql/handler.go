@@ -0,0 +1,197 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/graphql-go/graphql"
+	"go.uber.org/zap"
+	"strings"
+)
+
+const (
+	defaultMaxDepth      = 6
+	defaultMaxComplexity = 200
+)
+
+type Handler struct {
+	coinService   services.CoinService
+	authenticator services.Authenticator
+	logger        *zap.Logger
+
+	maxDepth      int
+	maxComplexity int
+
+	schema graphql.Schema
+}
+
+type HandlerConfig struct {
+	CoinService   services.CoinService
+	Authenticator services.Authenticator
+	Logger        *zap.Logger
+
+	// MaxDepth and MaxComplexity bound the queries the handler executes.
+	// Zero values fall back to defaults.
+	MaxDepth      int
+	MaxComplexity int
+}
+
+func NewHandler(cfg HandlerConfig) (*Handler, error) {
+	h := &Handler{
+		coinService:   cfg.CoinService,
+		authenticator: cfg.Authenticator,
+		logger:        cfg.Logger,
+		maxDepth:      cfg.MaxDepth,
+		maxComplexity: cfg.MaxComplexity,
+	}
+	if h.maxDepth <= 0 {
+		h.maxDepth = defaultMaxDepth
+	}
+	if h.maxComplexity <= 0 {
+		h.maxComplexity = defaultMaxComplexity
+	}
+
+	schema, err := h.newSchema()
+	if err != nil {
+		return nil, fmt.Errorf("h.newSchema: %w", err)
+	}
+	h.schema = schema
+
+	return h, nil
+}
+
+func (h *Handler) Init(router fiber.Router) {
+	router.Get("/graphql", h.Query)
+	router.Post("/graphql", h.Query)
+}
+
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Query executes a GraphQL request. POST takes a JSON body, GET takes the
+// query and operationName as query parameters.
+func (h *Handler) Query(ctx *fiber.Ctx) error {
+	var req Request
+	if ctx.Method() == fiber.MethodPost {
+		if err := ctx.BodyParser(&req); err != nil {
+			return fiber.NewError(
+				fiber.StatusBadRequest,
+				fmt.Errorf("ctx.BodyParser: %w", err).Error(),
+			)
+		}
+	} else {
+		req.Query = ctx.Query("query")
+		req.OperationName = ctx.Query("operationName")
+	}
+	if req.Query == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "missing query")
+	}
+
+	accessToken, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+	username, err := h.authenticator.Authenticate(ctx.UserContext(), accessToken, "")
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+
+	if err = checkLimits(&h.schema, req.Query, req.OperationName, req.Variables, h.maxDepth, h.maxComplexity); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"errors": []fiber.Map{{"message": err.Error()}},
+		})
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:         h.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        h.newRequestContext(ctx.UserContext(), accessToken, username),
+	})
+
+	return ctx.JSON(result)
+}
+
+type stateKey struct{}
+
+// requestState is shared by the resolvers of one request.
+type requestState struct {
+	token    string
+	username string
+	items    *loader[string, models.Item]
+}
+
+func (h *Handler) newRequestContext(ctx context.Context, accessToken, username string) context.Context {
+	state := &requestState{
+		token:    accessToken,
+		username: username,
+	}
+	state.items = newLoader(func(names []string) (map[string]models.Item, error) {
+		items, err := h.coinService.GetItems(ctx, services.GetItemsParams{
+			Token: accessToken,
+			Names: names,
+		})
+		if err != nil {
+			return nil, h.resolveError("h.coinService.GetItems", err)
+		}
+
+		byName := make(map[string]models.Item, len(items))
+		for _, item := range items {
+			byName[item.Name] = item
+		}
+		return byName, nil
+	})
+
+	return context.WithValue(ctx, stateKey{}, state)
+}
+
+func stateFromContext(ctx context.Context) *requestState {
+	return ctx.Value(stateKey{}).(*requestState)
+}
+
+// resolveError maps service errors to entries of the "errors" list of the
+// response, with the stable error code in extensions. Unexpected errors are
+// logged and reported without details.
+func (h *Handler) resolveError(op string, err error) error {
+	if category, code, message := services.Classify(err); category != nil {
+		return &resolverError{message: message, code: code}
+	}
+
+	if h.logger != nil {
+		h.logger.Error(op, zap.Error(err))
+	}
+	return &resolverError{message: "internal error", code: "internal"}
+}
+
+type resolverError struct {
+	message string
+	code    string
+}
+
+func (e *resolverError) Error() string {
+	return e.message
+}
+
+func (e *resolverError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.code}
+}
+
+func getToken(ctx *fiber.Ctx) (string, error) {
+	authHeader := ctx.Get("Authorization")
+	if authHeader == "" {
+		return "", fiber.NewError(fiber.StatusUnauthorized, "missing authorization header")
+	}
+
+	accessToken := strings.TrimPrefix(authHeader, "Bearer ")
+	if accessToken == authHeader {
+		return "", fiber.NewError(fiber.StatusUnauthorized, "invalid authorization header")
+	}
+
+	return accessToken, nil
+}
@@ -0,0 +1,230 @@
+package graphql_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"github.com/Blxssy/AvitoTest/internal/services/mocks"
+	"github.com/Blxssy/AvitoTest/internal/transport/graphql"
+	tokenmocks "github.com/Blxssy/AvitoTest/pkg/token/mocks"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type response struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func newApp(t *testing.T, coinService services.CoinService, tokenGen *tokenmocks.MockTokenGenerator, maxDepth, maxComplexity int) *fiber.App {
+	handler, err := graphql.NewHandler(graphql.HandlerConfig{
+		CoinService:   coinService,
+		Authenticator: services.NewAuthenticator(nil, tokenGen),
+		MaxDepth:      maxDepth,
+		MaxComplexity: maxComplexity,
+	})
+	require.NoError(t, err)
+
+	app := fiber.New()
+	handler.Init(app)
+	return app
+}
+
+func doQuery(t *testing.T, app *fiber.App, query string, variables map[string]interface{}) (int, response) {
+	body, err := json.Marshal(graphql.Request{Query: query, Variables: variables})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/graphql", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer valid_token")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var res response
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	return resp.StatusCode, res
+}
+
+func TestQueryRequiresToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tokenGen := tokenmocks.NewMockTokenGenerator(ctrl)
+	app := newApp(t, mocks.NewMockCoinService(ctrl), tokenGen, 0, 0)
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/graphql?query={me{username}}", nil)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	tokenGen.EXPECT().ParseToken("bad_token").Return("", errors.New("invalid token"))
+
+	req = httptest.NewRequest(http.MethodGet, "http://localhost:8080/graphql?query={me{username}}", nil)
+	req.Header.Set("Authorization", "Bearer bad_token")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestQueryMeInOneRoundTrip(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockCoinService(ctrl)
+	tokenGen := tokenmocks.NewMockTokenGenerator(ctrl)
+	app := newApp(t, mockService, tokenGen, 0, 0)
+
+	createdAt := time.Date(2025, 2, 1, 12, 0, 0, 0, time.UTC)
+	tokenGen.EXPECT().ParseToken("valid_token").Return("test", nil)
+	mockService.EXPECT().GetBalance(gomock.Any(), services.GetBalanceParams{Token: "valid_token"}).Return(900, nil)
+	mockService.EXPECT().ReceivedCoinsInfo(gomock.Any(), services.GetTransactionsParams{Token: "valid_token"}).
+		Return([]models.Transaction{
+			{ID: 1, SenderUsername: "a", ReceiverUsername: "test", Amount: 10, CreatedAt: createdAt},
+			{ID: 2, SenderUsername: "b", ReceiverUsername: "test", Amount: 20, CreatedAt: createdAt},
+			{ID: 3, SenderUsername: "c", ReceiverUsername: "test", Amount: 30, CreatedAt: createdAt},
+		}, nil)
+	mockService.EXPECT().GetItems(gomock.Any(), services.GetItemsParams{Token: "valid_token"}).
+		Return([]models.Item{{Name: "pen", Price: 10}, {Name: "cup", Price: 20}}, nil)
+
+	code, res := doQuery(t, app, `{
+		me { username coins received(last: 2) { fromUser amount createdAt } }
+		items { name price }
+	}`, nil)
+	require.Equal(t, http.StatusOK, code)
+	require.Empty(t, res.Errors)
+
+	me := res.Data["me"].(map[string]interface{})
+	assert.Equal(t, "test", me["username"])
+	assert.Equal(t, float64(900), me["coins"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"fromUser": "b", "amount": float64(20), "createdAt": "2025-02-01T12:00:00Z"},
+		map[string]interface{}{"fromUser": "c", "amount": float64(30), "createdAt": "2025-02-01T12:00:00Z"},
+	}, me["received"])
+	assert.Len(t, res.Data["items"], 2)
+}
+
+func TestInventoryItemsAreBatched(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockCoinService(ctrl)
+	tokenGen := tokenmocks.NewMockTokenGenerator(ctrl)
+	app := newApp(t, mockService, tokenGen, 0, 0)
+
+	tokenGen.EXPECT().ParseToken("valid_token").Return("test", nil)
+	mockService.EXPECT().GetPurchases(gomock.Any(), services.GetPurchasesParams{Token: "valid_token"}).
+		Return([]models.PurchaseItem{{Item: "pen", Count: 2}, {Item: "cup", Count: 1}, {Item: "hoody", Count: 1}}, nil)
+	mockService.EXPECT().GetItems(gomock.Any(), services.GetItemsParams{
+		Token: "valid_token",
+		Names: []string{"pen", "cup", "hoody"},
+	}).Return([]models.Item{{Name: "pen", Price: 10}, {Name: "cup", Price: 20}, {Name: "hoody", Price: 300}}, nil).Times(1)
+
+	code, res := doQuery(t, app, `{ me { inventory { quantity item { name price } } } }`, nil)
+	require.Equal(t, http.StatusOK, code)
+	require.Empty(t, res.Errors)
+
+	inventory := res.Data["me"].(map[string]interface{})["inventory"].([]interface{})
+	require.Len(t, inventory, 3)
+	assert.Equal(t, map[string]interface{}{
+		"quantity": float64(1),
+		"item":     map[string]interface{}{"name": "hoody", "price": float64(300)},
+	}, inventory[2])
+}
+
+func TestMutations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockCoinService(ctrl)
+	tokenGen := tokenmocks.NewMockTokenGenerator(ctrl)
+	app := newApp(t, mockService, tokenGen, 0, 0)
+
+	tokenGen.EXPECT().ParseToken("valid_token").Return("test", nil).Times(2)
+	gomock.InOrder(
+		mockService.EXPECT().SendCoins(gomock.Any(), services.TransactionParams{
+			Token: "valid_token", ReceiverUsername: "friend", Amount: 50, Memo: "for lunch",
+		}).Return(int64(1), nil),
+		mockService.EXPECT().GetBalance(gomock.Any(), services.GetBalanceParams{Token: "valid_token"}).Return(950, nil),
+	)
+
+	code, res := doQuery(t, app, `mutation($to: String!, $amount: Int!) {
+		sendCoin(toUser: $to, amount: $amount, memo: "for lunch") { coins }
+	}`, map[string]interface{}{"to": "friend", "amount": 50})
+	require.Equal(t, http.StatusOK, code)
+	require.Empty(t, res.Errors)
+	assert.Equal(t, float64(950), res.Data["sendCoin"].(map[string]interface{})["coins"])
+
+	mockService.EXPECT().BuyItem(gomock.Any(), services.BuyItemParams{Token: "valid_token", Item: "pen"}).
+		Return(errors.New("pq: connection refused"))
+
+	code, res = doQuery(t, app, `mutation { buyItem(item: "pen") { coins } }`, nil)
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, res.Errors, 1)
+	assert.Equal(t, "internal error", res.Errors[0].Message)
+}
+
+func TestQueryLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tokenGen := tokenmocks.NewMockTokenGenerator(ctrl)
+	app := newApp(t, mocks.NewMockCoinService(ctrl), tokenGen, 3, 20)
+
+	tokenGen.EXPECT().ParseToken("valid_token").Return("test", nil).AnyTimes()
+
+	code, res := doQuery(t, app, `{ me { inventory { item { name } } } }`, nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	require.Len(t, res.Errors, 1)
+	assert.Contains(t, res.Errors[0].Message, "depth 4")
+
+	code, res = doQuery(t, app, `query($n: Int) { me { received(last: $n) { fromUser amount } } }`,
+		map[string]interface{}{"n": 100})
+	assert.Equal(t, http.StatusBadRequest, code)
+	require.Len(t, res.Errors, 1)
+	assert.Contains(t, res.Errors[0].Message, "complexity 202")
+
+	code, res = doQuery(t, app, `fragment F on User { sent(last: 10) { fromUser amount } } { me { ...F } }`, nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	require.Len(t, res.Errors, 1)
+	assert.Contains(t, res.Errors[0].Message, "complexity 22")
+
+	// Lists without "last" count as ten elements.
+	code, res = doQuery(t, app, `{ items { name price } me { inventory { quantity } } }`, nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	require.Len(t, res.Errors, 1)
+	assert.Contains(t, res.Errors[0].Message, "complexity 33")
+}
+
+func TestIntrospectionLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tokenGen := tokenmocks.NewMockTokenGenerator(ctrl)
+	app := newApp(t, mocks.NewMockCoinService(ctrl), tokenGen, 3, 20)
+
+	tokenGen.EXPECT().ParseToken("valid_token").Return("test", nil).AnyTimes()
+
+	code, res := doQuery(t, app, `{ __schema { types { name fields { name type { name ofType { name } } } } } }`, nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, res.Errors)
+	assert.NotNil(t, res.Data["__schema"])
+
+	query := `{ __type(name: "User") { fields { type { ` + strings.Repeat("ofType { ", 14) + `name` +
+		strings.Repeat(" }", 14) + ` } } } }`
+	code, res = doQuery(t, app, query, nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	require.Len(t, res.Errors, 1)
+	assert.Contains(t, res.Errors[0].Message, "introspection depth 18")
+}
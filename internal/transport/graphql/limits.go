@@ -0,0 +1,197 @@
+package graphql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// listSizeArgument is the argument that bounds how many elements a list field
+// returns. It multiplies the cost of everything selected below the field.
+const listSizeArgument = "last"
+
+// defaultListSize is the multiplier of list fields called without "last",
+// which return the whole list.
+const defaultListSize = 10
+
+// maxIntrospectionDepth bounds schema introspection, which the regular limits
+// don't cover. It fits the introspection query of GraphiQL and graphql-js,
+// whose type references nest ofType seven levels deep.
+const maxIntrospectionDepth = 15
+
+var errLimitExceeded = errors.New("query is too complex")
+
+// checkLimits rejects queries nested deeper than maxDepth or costing more than
+// maxComplexity before they are executed. Every field costs one point, and the
+// cost of a list field's selection is multiplied by its "last" argument, or by
+// defaultListSize without one. Schema
+// introspection fields are not counted so tools can still load the schema,
+// but they may nest no deeper than maxIntrospectionDepth.
+func checkLimits(schema *graphql.Schema, query, operationName string, variables map[string]interface{}, maxDepth, maxComplexity int) error {
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		// Let the executor report syntax errors in the usual format.
+		return nil
+	}
+
+	fragments := make(map[string]*ast.FragmentDefinition)
+	var operation *ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		switch d := definition.(type) {
+		case *ast.FragmentDefinition:
+			fragments[d.Name.Value] = d
+		case *ast.OperationDefinition:
+			if operationName == "" || (d.Name != nil && d.Name.Value == operationName) {
+				operation = d
+			}
+		}
+	}
+	if operation == nil {
+		return nil
+	}
+
+	var root graphql.Type = schema.QueryType()
+	if operation.Operation == ast.OperationTypeMutation {
+		root = schema.MutationType()
+	}
+
+	a := analyzer{schema: schema, fragments: fragments, variables: variables, visiting: make(map[string]bool)}
+	depth, complexity := a.selectionSet(operation.SelectionSet, root)
+
+	if depth > maxDepth {
+		return fmt.Errorf("%w: depth %d exceeds the limit of %d", errLimitExceeded, depth, maxDepth)
+	}
+	if complexity > maxComplexity {
+		return fmt.Errorf("%w: complexity %d exceeds the limit of %d", errLimitExceeded, complexity, maxComplexity)
+	}
+	if a.introspectionDepth > maxIntrospectionDepth {
+		return fmt.Errorf("%w: introspection depth %d exceeds the limit of %d",
+			errLimitExceeded, a.introspectionDepth, maxIntrospectionDepth)
+	}
+	return nil
+}
+
+type analyzer struct {
+	schema    *graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	visiting  map[string]bool
+
+	// introspectionDepth is the depth of the deepest introspection field.
+	introspectionDepth int
+}
+
+// selectionSet measures a selection set on the parent type. Fields the schema
+// doesn't have are measured as scalars and left to validation to reject.
+func (a *analyzer) selectionSet(set *ast.SelectionSet, parent graphql.Type) (depth, complexity int) {
+	if set == nil {
+		return 0, 0
+	}
+
+	for _, selection := range set.Selections {
+		var d, c int
+		switch s := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(s.Name.Value, "__") {
+				d, _ = a.selectionSet(s.SelectionSet, nil)
+				a.introspectionDepth = max(a.introspectionDepth, d+1)
+				continue
+			}
+			typ := fieldType(parent, s.Name.Value)
+			d, c = a.selectionSet(s.SelectionSet, namedType(typ))
+			d, c = d+1, 1+c*a.listSize(s, typ)
+		case *ast.InlineFragment:
+			d, c = a.selectionSet(s.SelectionSet, a.typeCondition(s.TypeCondition, parent))
+		case *ast.FragmentSpread:
+			fragment, ok := a.fragments[s.Name.Value]
+			// Cyclic spreads are rejected by validation later on.
+			if !ok || a.visiting[s.Name.Value] {
+				continue
+			}
+			a.visiting[s.Name.Value] = true
+			d, c = a.selectionSet(fragment.SelectionSet, a.typeCondition(fragment.TypeCondition, parent))
+			a.visiting[s.Name.Value] = false
+		}
+		depth = max(depth, d)
+		complexity += c
+	}
+
+	return depth, complexity
+}
+
+// typeCondition returns the type a fragment applies to, parent if it names none.
+func (a *analyzer) typeCondition(condition *ast.Named, parent graphql.Type) graphql.Type {
+	if condition == nil || a.schema == nil {
+		return parent
+	}
+	return a.schema.Type(condition.Name.Value)
+}
+
+// fieldType returns the type of the named field of parent, nil if it has none.
+func fieldType(parent graphql.Type, name string) graphql.Type {
+	fielder, ok := parent.(interface {
+		Fields() graphql.FieldDefinitionMap
+	})
+	if !ok {
+		return nil
+	}
+	field, ok := fielder.Fields()[name]
+	if !ok {
+		return nil
+	}
+	return field.Type
+}
+
+// namedType strips the list and non-null wrappers off typ.
+func namedType(typ graphql.Type) graphql.Type {
+	for {
+		switch t := typ.(type) {
+		case *graphql.NonNull:
+			typ = t.OfType
+		case *graphql.List:
+			typ = t.OfType
+		default:
+			return typ
+		}
+	}
+}
+
+// listSize is how many elements a field of type typ returns: one for fields
+// that aren't lists.
+func (a *analyzer) listSize(field *ast.Field, typ graphql.Type) int {
+	if nonNull, ok := typ.(*graphql.NonNull); ok {
+		typ = nonNull.OfType
+	}
+	if _, ok := typ.(*graphql.List); !ok {
+		return 1
+	}
+
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != listSizeArgument {
+			continue
+		}
+
+		switch v := argument.Value.(type) {
+		case *ast.IntValue:
+			var n int
+			if _, err := fmt.Sscan(v.Value, &n); err == nil && n > 0 {
+				return n
+			}
+		case *ast.Variable:
+			switch n := a.variables[v.Name.Value].(type) {
+			case float64:
+				return max(int(n), 1)
+			case int:
+				return max(n, 1)
+			}
+		}
+	}
+	return defaultListSize
+}
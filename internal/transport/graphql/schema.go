@@ -0,0 +1,272 @@
+package graphql
+
+import (
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"github.com/graphql-go/graphql"
+)
+
+// viewer is the source of the User type: the authenticated caller.
+type viewer struct {
+	username string
+}
+
+func (h *Handler) newSchema() (graphql.Schema, error) {
+	itemType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Item",
+		Fields: graphql.Fields{
+			"name": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(models.Item).Name, nil
+				},
+			},
+			"price": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Int),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(models.Item).Price, nil
+				},
+			},
+		},
+	})
+
+	purchaseType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Purchase",
+		Fields: graphql.Fields{
+			"item": &graphql.Field{
+				Type: itemType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return stateFromContext(p.Context).items.Load(p.Source.(models.PurchaseItem).Item), nil
+				},
+			},
+			"quantity": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Int),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(models.PurchaseItem).Count, nil
+				},
+			},
+		},
+	})
+
+	transferType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Transfer",
+		Fields: graphql.Fields{
+			"fromUser": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(models.Transaction).SenderUsername, nil
+				},
+			},
+			"toUser": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(models.Transaction).ReceiverUsername, nil
+				},
+			},
+			"amount": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Int),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(models.Transaction).Amount, nil
+				},
+			},
+			"actor": &graphql.Field{
+				Type:        graphql.String,
+				Description: "The client that sent the coins on the sender's behalf.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if actor := p.Source.(models.Transaction).ActorUsername; actor != "" {
+						return actor, nil
+					}
+					return nil, nil
+				},
+			},
+			"createdAt": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.DateTime),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(models.Transaction).CreatedAt, nil
+				},
+			},
+		},
+	})
+
+	lastArgs := graphql.FieldConfigArgument{
+		listSizeArgument: &graphql.ArgumentConfig{
+			Type:        graphql.Int,
+			Description: "Return only the most recent transfers.",
+		},
+	}
+
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"username": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(viewer).username, nil
+				},
+			},
+			"coins": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Int),
+				Resolve: h.resolveCoins,
+			},
+			"inventory": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(purchaseType))),
+				Resolve: h.resolveInventory,
+			},
+			"received": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(transferType))),
+				Args:    lastArgs,
+				Resolve: h.resolveReceived,
+			},
+			"sent": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(transferType))),
+				Args:    lastArgs,
+				Resolve: h.resolveSent,
+			},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"me": &graphql.Field{
+				Type: graphql.NewNonNull(userType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return viewer{username: stateFromContext(p.Context).username}, nil
+				},
+			},
+			"items": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(itemType))),
+				Resolve: h.resolveItems,
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"sendCoin": &graphql.Field{
+				Type: graphql.NewNonNull(userType),
+				Args: graphql.FieldConfigArgument{
+					"toUser":     &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"amount":     &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"otp":        &graphql.ArgumentConfig{Type: graphql.String},
+					"onBehalfOf": &graphql.ArgumentConfig{Type: graphql.String},
+					"memo":       &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: h.resolveSendCoin,
+			},
+			"buyItem": &graphql.Field{
+				Type: graphql.NewNonNull(userType),
+				Args: graphql.FieldConfigArgument{
+					"item": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"otp":  &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: h.resolveBuyItem,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    query,
+		Mutation: mutation,
+	})
+}
+
+func (h *Handler) resolveCoins(p graphql.ResolveParams) (interface{}, error) {
+	balance, err := h.coinService.GetBalance(p.Context, services.GetBalanceParams{
+		Token: stateFromContext(p.Context).token,
+	})
+	if err != nil {
+		return nil, h.resolveError("h.coinService.GetBalance", err)
+	}
+	return balance, nil
+}
+
+func (h *Handler) resolveInventory(p graphql.ResolveParams) (interface{}, error) {
+	purchases, err := h.coinService.GetPurchases(p.Context, services.GetPurchasesParams{
+		Token: stateFromContext(p.Context).token,
+	})
+	if err != nil {
+		return nil, h.resolveError("h.coinService.GetPurchases", err)
+	}
+	return purchases, nil
+}
+
+func (h *Handler) resolveReceived(p graphql.ResolveParams) (interface{}, error) {
+	transactions, err := h.coinService.ReceivedCoinsInfo(p.Context, services.GetTransactionsParams{
+		Token: stateFromContext(p.Context).token,
+	})
+	if err != nil {
+		return nil, h.resolveError("h.coinService.ReceivedCoinsInfo", err)
+	}
+	return lastTransactions(transactions, p.Args), nil
+}
+
+func (h *Handler) resolveSent(p graphql.ResolveParams) (interface{}, error) {
+	transactions, err := h.coinService.SendCoinsInfo(p.Context, services.GetTransactionsParams{
+		Token: stateFromContext(p.Context).token,
+	})
+	if err != nil {
+		return nil, h.resolveError("h.coinService.SendCoinsInfo", err)
+	}
+	return lastTransactions(transactions, p.Args), nil
+}
+
+func (h *Handler) resolveItems(p graphql.ResolveParams) (interface{}, error) {
+	items, err := h.coinService.GetItems(p.Context, services.GetItemsParams{
+		Token: stateFromContext(p.Context).token,
+	})
+	if err != nil {
+		return nil, h.resolveError("h.coinService.GetItems", err)
+	}
+	return items, nil
+}
+
+func (h *Handler) resolveSendCoin(p graphql.ResolveParams) (interface{}, error) {
+	state := stateFromContext(p.Context)
+	onBehalfOf, _ := p.Args["onBehalfOf"].(string)
+	memo, _ := p.Args["memo"].(string)
+
+	_, err := h.coinService.SendCoins(p.Context, services.TransactionParams{
+		Token:            state.token,
+		ReceiverUsername: p.Args["toUser"].(string),
+		Amount:           p.Args["amount"].(int),
+		OTPCode:          otpArgument(p.Args),
+		OnBehalfOf:       onBehalfOf,
+		Memo:             memo,
+	})
+	if err != nil {
+		return nil, h.resolveError("h.coinService.SendCoins", err)
+	}
+	return viewer{username: state.username}, nil
+}
+
+func (h *Handler) resolveBuyItem(p graphql.ResolveParams) (interface{}, error) {
+	state := stateFromContext(p.Context)
+
+	err := h.coinService.BuyItem(p.Context, services.BuyItemParams{
+		Token:   state.token,
+		Item:    p.Args["item"].(string),
+		OTPCode: otpArgument(p.Args),
+	})
+	if err != nil {
+		return nil, h.resolveError("h.coinService.BuyItem", err)
+	}
+	return viewer{username: state.username}, nil
+}
+
+// otpArgument returns the optional second factor of a mutation.
+func otpArgument(args map[string]interface{}) string {
+	code, _ := args["otp"].(string)
+	return code
+}
+
+// lastTransactions keeps the newest transactions when the "last" argument is
+// set. The service returns them oldest first.
+func lastTransactions(transactions []models.Transaction, args map[string]interface{}) []models.Transaction {
+	last, ok := args[listSizeArgument].(int)
+	if !ok || last < 0 || last >= len(transactions) {
+		return transactions
+	}
+	return transactions[len(transactions)-last:]
+}
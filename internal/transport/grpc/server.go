@@ -0,0 +1,93 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/services"
+	v1 "github.com/Blxssy/AvitoTest/internal/transport/grpc/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"net"
+)
+
+type Server struct {
+	addr string
+
+	coinService   services.CoinService
+	authenticator services.Authenticator
+
+	logger *zap.Logger
+	server *grpc.Server
+}
+
+type ServerConfig struct {
+	Addr string
+
+	CoinService   services.CoinService
+	Authenticator services.Authenticator
+
+	Logger *zap.Logger
+}
+
+func NewServer(cfg ServerConfig) *Server {
+	server := &Server{
+		addr:          cfg.Addr,
+		coinService:   cfg.CoinService,
+		authenticator: cfg.Authenticator,
+		logger:        cfg.Logger,
+		server:        nil,
+	}
+
+	server.server = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(v1.AuthInterceptor(cfg.Authenticator)),
+	)
+
+	server.setHandlers()
+
+	return server
+}
+
+func (s *Server) Run() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("listen gRPC server: %w", err)
+	}
+
+	return s.Serve(listener)
+}
+
+// Serve accepts connections on listener. Tests use it with an in-memory listener.
+func (s *Server) Serve(listener net.Listener) error {
+	if err := s.server.Serve(listener); err != nil {
+		return fmt.Errorf("serving gRPC server: %w", err)
+	}
+	return nil
+}
+
+// Shutdown stops accepting new RPCs and waits for the running ones to finish.
+// RPCs still running when ctx is done are cancelled.
+func (s *Server) Shutdown(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		return fmt.Errorf("shutdown gRPC server: %w", ctx.Err())
+	}
+}
+
+func (s *Server) setHandlers() {
+	handlerV1 := v1.NewHandler(v1.HandlerConfig{
+		CoinService: s.coinService,
+		Logger:      s.logger,
+	})
+	{
+		handlerV1.Init(s.server)
+	}
+}
@@ -0,0 +1,77 @@
+package v1
+
+import (
+	"context"
+	coinv1 "github.com/Blxssy/AvitoTest/api/coin/v1"
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"net"
+	"strings"
+)
+
+type tokenKey struct{}
+
+// publicMethods can be called without an access token.
+var publicMethods = map[string]bool{
+	coinv1.CoinService_Auth_FullMethodName:                  true,
+	coinv1.CoinService_CompleteTwoFactorAuth_FullMethodName: true,
+}
+
+// AuthInterceptor rejects calls without a valid access token or API key
+// before they reach a handler, and hands the credential to the handler through
+// the context. Scopes of API keys are checked by the services.
+func AuthInterceptor(auth services.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if publicMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		accessToken, err := getToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = auth.Authenticate(ctx, accessToken, ""); err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+
+		return handler(context.WithValue(ctx, tokenKey{}, accessToken), req)
+	}
+}
+
+func getToken(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return "", status.Error(codes.Unauthenticated, "missing authorization metadata")
+	}
+
+	accessToken := strings.TrimPrefix(values[0], "Bearer ")
+	if accessToken == values[0] {
+		return "", status.Error(codes.Unauthenticated, "invalid authorization metadata")
+	}
+
+	return accessToken, nil
+}
+
+func tokenFromContext(ctx context.Context) string {
+	accessToken, _ := ctx.Value(tokenKey{}).(string)
+	return accessToken
+}
+
+// clientIP returns the IP of the peer that sent the request, or an empty
+// string for peers without one, such as unix sockets.
+func clientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return ""
+	}
+	return host
+}
@@ -0,0 +1,161 @@
+package v1
+
+import (
+	"context"
+	coinv1 "github.com/Blxssy/AvitoTest/api/coin/v1"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (h *Handler) Auth(ctx context.Context, req *coinv1.AuthRequest) (*coinv1.AuthResponse, error) {
+	result, err := h.coinService.Auth(ctx, services.AuthParams{
+		Username: req.GetUsername(),
+		Password: req.GetPassword(),
+		ClientIP: clientIP(ctx),
+	})
+	if err != nil {
+		return nil, h.toStatus("h.coinService.Auth", err)
+	}
+
+	if result.ChallengeToken != "" {
+		return &coinv1.AuthResponse{
+			ChallengeToken:     result.ChallengeToken,
+			ChallengeExpiresAt: timestamppb.New(result.ChallengeExpiresAt),
+		}, nil
+	}
+	return &coinv1.AuthResponse{Token: result.Token}, nil
+}
+
+func (h *Handler) CompleteTwoFactorAuth(ctx context.Context, req *coinv1.CompleteTwoFactorAuthRequest) (*coinv1.AuthResponse, error) {
+	accessToken, err := h.coinService.CompleteTwoFactorAuth(ctx, services.TwoFactorAuthParams{
+		ChallengeToken: req.GetChallengeToken(),
+		Code:           req.GetCode(),
+		ClientIP:       clientIP(ctx),
+	})
+	if err != nil {
+		return nil, h.toStatus("h.coinService.CompleteTwoFactorAuth", err)
+	}
+
+	return &coinv1.AuthResponse{Token: accessToken}, nil
+}
+
+func (h *Handler) GetBalance(ctx context.Context, _ *coinv1.GetBalanceRequest) (*coinv1.GetBalanceResponse, error) {
+	balance, err := h.coinService.GetBalance(ctx, services.GetBalanceParams{
+		Token: tokenFromContext(ctx),
+	})
+	if err != nil {
+		return nil, h.toStatus("h.coinService.GetBalance", err)
+	}
+
+	return &coinv1.GetBalanceResponse{Coins: int64(balance)}, nil
+}
+
+func (h *Handler) SendCoins(ctx context.Context, req *coinv1.SendCoinsRequest) (*coinv1.SendCoinsResponse, error) {
+	_, err := h.coinService.SendCoins(ctx, services.TransactionParams{
+		Token:            tokenFromContext(ctx),
+		ReceiverUsername: req.GetToUser(),
+		Amount:           int(req.GetAmount()),
+		OTPCode:          req.GetOtpCode(),
+		OnBehalfOf:       req.GetOnBehalfOf(),
+		Memo:             req.GetMemo(),
+	})
+	if err != nil {
+		return nil, h.toStatus("h.coinService.SendCoins", err)
+	}
+
+	return &coinv1.SendCoinsResponse{}, nil
+}
+
+func (h *Handler) GetHistory(ctx context.Context, _ *coinv1.GetHistoryRequest) (*coinv1.GetHistoryResponse, error) {
+	params := services.GetTransactionsParams{
+		Token: tokenFromContext(ctx),
+	}
+
+	received, err := h.coinService.ReceivedCoinsInfo(ctx, params)
+	if err != nil {
+		return nil, h.toStatus("h.coinService.ReceivedCoinsInfo", err)
+	}
+
+	sent, err := h.coinService.SendCoinsInfo(ctx, params)
+	if err != nil {
+		return nil, h.toStatus("h.coinService.SendCoinsInfo", err)
+	}
+
+	return &coinv1.GetHistoryResponse{
+		Received: transfersResponse(received),
+		Sent:     transfersResponse(sent),
+	}, nil
+}
+
+func (h *Handler) ListItems(ctx context.Context, _ *coinv1.ListItemsRequest) (*coinv1.ListItemsResponse, error) {
+	items, err := h.coinService.GetItems(ctx, services.GetItemsParams{
+		Token: tokenFromContext(ctx),
+	})
+	if err != nil {
+		return nil, h.toStatus("h.coinService.GetItems", err)
+	}
+
+	pbItems := make([]*coinv1.Item, len(items))
+	for i, item := range items {
+		pbItems[i] = &coinv1.Item{
+			Name:  item.Name,
+			Price: int64(item.Price),
+		}
+	}
+
+	return &coinv1.ListItemsResponse{Items: pbItems}, nil
+}
+
+func (h *Handler) BuyItem(ctx context.Context, req *coinv1.BuyItemRequest) (*coinv1.BuyItemResponse, error) {
+	err := h.coinService.BuyItem(ctx, services.BuyItemParams{
+		Token:   tokenFromContext(ctx),
+		Item:    req.GetItem(),
+		OTPCode: req.GetOtpCode(),
+	})
+	if err != nil {
+		return nil, h.toStatus("h.coinService.BuyItem", err)
+	}
+
+	return &coinv1.BuyItemResponse{}, nil
+}
+
+func transfersResponse(transactions []models.Transaction) []*coinv1.Transfer {
+	transfers := make([]*coinv1.Transfer, len(transactions))
+	for i, t := range transactions {
+		transfers[i] = &coinv1.Transfer{
+			FromUser:  t.SenderUsername,
+			ToUser:    t.ReceiverUsername,
+			Amount:    int64(t.Amount),
+			CreatedAt: timestamppb.New(t.CreatedAt),
+			Actor:     t.ActorUsername,
+		}
+	}
+	return transfers
+}
+
+var categoryCodes = map[error]codes.Code{
+	services.InvalidParamsError:   codes.InvalidArgument,
+	services.UnauthorizedError:    codes.Unauthenticated,
+	services.ForbiddenError:       codes.PermissionDenied,
+	services.NotFoundError:        codes.NotFound,
+	services.ConflictError:        codes.Aborted,
+	services.UnprocessableError:   codes.FailedPrecondition,
+	services.TooManyRequestsError: codes.ResourceExhausted,
+}
+
+// toStatus maps service errors to gRPC codes. Unexpected errors are logged and
+// reported as Internal without details, so clients never see SQL errors.
+func (h *Handler) toStatus(op string, err error) error {
+	if category, _, message := services.Classify(err); category != nil {
+		return status.Error(categoryCodes[category], message)
+	}
+
+	if h.logger != nil {
+		h.logger.Error(op, zap.Error(err))
+	}
+	return status.Error(codes.Internal, "internal error")
+}
@@ -0,0 +1,165 @@
+package v1_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	coinv1 "github.com/Blxssy/AvitoTest/api/coin/v1"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"github.com/Blxssy/AvitoTest/internal/services/mocks"
+	grpcserver "github.com/Blxssy/AvitoTest/internal/transport/grpc"
+	tokenmocks "github.com/Blxssy/AvitoTest/pkg/token/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newClient(t *testing.T, coinService services.CoinService, tokenGen *tokenmocks.MockTokenGenerator) coinv1.CoinServiceClient {
+	listener := bufconn.Listen(1024 * 1024)
+
+	server := grpcserver.NewServer(grpcserver.ServerConfig{
+		CoinService:   coinService,
+		Authenticator: services.NewAuthenticator(nil, tokenGen),
+	})
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(func() { _ = server.Shutdown(context.Background()) })
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return coinv1.NewCoinServiceClient(conn)
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestAuthRPC(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockCoinService(ctrl)
+	client := newClient(t, mockService, tokenmocks.NewMockTokenGenerator(ctrl))
+
+	mockService.EXPECT().Auth(gomock.Any(), services.AuthParams{
+		Username: "test",
+		Password: "123",
+	}).Return(services.AuthResult{Token: "valid_token"}, nil)
+
+	resp, err := client.Auth(context.Background(), &coinv1.AuthRequest{Username: "test", Password: "123"})
+	require.NoError(t, err)
+	assert.Equal(t, "valid_token", resp.GetToken())
+}
+
+func TestAuthTwoFactorRPC(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockCoinService(ctrl)
+	client := newClient(t, mockService, tokenmocks.NewMockTokenGenerator(ctrl))
+
+	expiresAt := time.Now().Add(5 * time.Minute).UTC()
+	mockService.EXPECT().Auth(gomock.Any(), services.AuthParams{
+		Username: "test",
+		Password: "123",
+	}).Return(services.AuthResult{ChallengeToken: "challenge", ChallengeExpiresAt: expiresAt}, nil)
+
+	resp, err := client.Auth(context.Background(), &coinv1.AuthRequest{Username: "test", Password: "123"})
+	require.NoError(t, err)
+	assert.Empty(t, resp.GetToken())
+	assert.Equal(t, "challenge", resp.GetChallengeToken())
+	assert.True(t, expiresAt.Equal(resp.GetChallengeExpiresAt().AsTime()))
+
+	mockService.EXPECT().CompleteTwoFactorAuth(gomock.Any(), services.TwoFactorAuthParams{
+		ChallengeToken: "challenge",
+		Code:           "123456",
+	}).Return("valid_token", nil)
+
+	resp, err = client.CompleteTwoFactorAuth(context.Background(), &coinv1.CompleteTwoFactorAuthRequest{
+		ChallengeToken: "challenge",
+		Code:           "123456",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "valid_token", resp.GetToken())
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tokenGen := tokenmocks.NewMockTokenGenerator(ctrl)
+	client := newClient(t, mocks.NewMockCoinService(ctrl), tokenGen)
+
+	_, err := client.GetBalance(context.Background(), &coinv1.GetBalanceRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	tokenGen.EXPECT().ParseToken("bad_token").Return("", errors.New("invalid token"))
+
+	_, err = client.GetBalance(withToken("bad_token"), &coinv1.GetBalanceRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestSendCoinsRPC(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockCoinService(ctrl)
+	tokenGen := tokenmocks.NewMockTokenGenerator(ctrl)
+	client := newClient(t, mockService, tokenGen)
+
+	tokenGen.EXPECT().ParseToken("valid_token").Return("alice", nil).Times(3)
+	mockService.EXPECT().SendCoins(gomock.Any(), services.TransactionParams{
+		Token:            "valid_token",
+		ReceiverUsername: "Bill",
+		Amount:           100,
+		Memo:             "for lunch",
+	}).Return(int64(1), nil)
+	mockService.EXPECT().SendCoins(gomock.Any(), gomock.Any()).Return(int64(0), services.InsufficientFundsError)
+	mockService.EXPECT().SendCoins(gomock.Any(), gomock.Any()).
+		Return(int64(0), errors.New("s.repo.DecreaseBalance: pq: connection refused"))
+
+	_, err := client.SendCoins(withToken("valid_token"), &coinv1.SendCoinsRequest{ToUser: "Bill", Amount: 100, Memo: "for lunch"})
+	require.NoError(t, err)
+
+	_, err = client.SendCoins(withToken("valid_token"), &coinv1.SendCoinsRequest{ToUser: "Bill", Amount: 100})
+	assert.Equal(t, codes.Aborted, status.Code(err))
+
+	_, err = client.SendCoins(withToken("valid_token"), &coinv1.SendCoinsRequest{ToUser: "Bill", Amount: 100})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.NotContains(t, err.Error(), "pq:")
+}
+
+func TestListItemsRPC(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockCoinService(ctrl)
+	tokenGen := tokenmocks.NewMockTokenGenerator(ctrl)
+	client := newClient(t, mockService, tokenGen)
+
+	tokenGen.EXPECT().ParseToken("valid_token").Return("alice", nil)
+	mockService.EXPECT().GetItems(gomock.Any(), services.GetItemsParams{Token: "valid_token"}).
+		Return([]models.Item{{Name: "cup", Price: 20}, {Name: "hoody", Price: 300}}, nil)
+
+	resp, err := client.ListItems(withToken("valid_token"), &coinv1.ListItemsRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.GetItems(), 2)
+	assert.Equal(t, "hoody", resp.GetItems()[1].GetName())
+	assert.Equal(t, int64(300), resp.GetItems()[1].GetPrice())
+}
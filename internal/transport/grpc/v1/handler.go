@@ -0,0 +1,31 @@
+package v1
+
+import (
+	coinv1 "github.com/Blxssy/AvitoTest/api/coin/v1"
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+type Handler struct {
+	coinv1.UnimplementedCoinServiceServer
+
+	coinService services.CoinService
+	logger      *zap.Logger
+}
+
+type HandlerConfig struct {
+	CoinService services.CoinService
+	Logger      *zap.Logger
+}
+
+func NewHandler(cfg HandlerConfig) *Handler {
+	return &Handler{
+		logger:      cfg.Logger,
+		coinService: cfg.CoinService,
+	}
+}
+
+func (h *Handler) Init(server *grpc.Server) {
+	coinv1.RegisterCoinServiceServer(server, h)
+}
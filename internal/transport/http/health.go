@@ -0,0 +1,62 @@
+package http
+
+import (
+	"time"
+
+	"github.com/Blxssy/AvitoTest/pkg/buildinfo"
+	"github.com/gofiber/fiber/v2"
+)
+
+type readinessResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+type infoResponse struct {
+	Version   string    `json:"version"`
+	Commit    string    `json:"commit"`
+	GoVersion string    `json:"goVersion"`
+	StartedAt time.Time `json:"startedAt"`
+	Uptime    string    `json:"uptime"`
+}
+
+// setHealthHandlers registers probes ahead of the rest of the middleware, so
+// they are not rate limited or written to the access log.
+func (s *Server) setHealthHandlers() {
+	s.app.Get("/healthz", s.liveness)
+	s.app.Get("/readyz", s.readiness)
+	s.app.Get("/debug/info", s.info)
+}
+
+func (s *Server) liveness(ctx *fiber.Ctx) error {
+	return ctx.JSON(fiber.Map{"status": "ok"})
+}
+
+func (s *Server) readiness(ctx *fiber.Ctx) error {
+	ready, results := s.health.Ready(ctx.Context())
+
+	resp := readinessResponse{Status: "ok", Checks: make(map[string]string, len(results))}
+	for _, result := range results {
+		resp.Checks[result.Name] = "ok"
+		if result.Err != nil {
+			resp.Checks[result.Name] = result.Err.Error()
+		}
+	}
+	if !ready {
+		resp.Status = "unavailable"
+		return ctx.Status(fiber.StatusServiceUnavailable).JSON(resp)
+	}
+	return ctx.JSON(resp)
+}
+
+func (s *Server) info(ctx *fiber.Ctx) error {
+	build := buildinfo.Get()
+	startedAt := s.health.StartedAt()
+	return ctx.JSON(infoResponse{
+		Version:   build.Version,
+		Commit:    build.Commit,
+		GoVersion: build.GoVersion,
+		StartedAt: startedAt.UTC(),
+		Uptime:    time.Since(startedAt).Round(time.Second).String(),
+	})
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"github.com/Blxssy/AvitoTest/pkg/logger"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"sync/atomic"
+	"time"
+)
+
+type AccessLogConfig struct {
+	Logger *zap.Logger
+
+	// SampledRoutes lists noisy routes as "METHOD /route/:pattern". Only
+	// every SampleEvery-th of their successful requests is logged; failed
+	// ones always are.
+	SampledRoutes []string
+	SampleEvery   int
+}
+
+// NewAccessLog logs every request with its route, status, latency, user and
+// request ID. It also puts a logger with the request ID into the user
+// context, so services log with the same fields. It must run after the
+// request ID and tracing middleware. The query string is not logged: it may
+// hold an access token.
+func NewAccessLog(cfg AccessLogConfig) fiber.Handler {
+	sampler := newRouteSampler(cfg.SampledRoutes, cfg.SampleEvery)
+
+	return func(ctx *fiber.Ctx) error {
+		start := time.Now()
+
+		// Strings of fiber.Ctx are reused after the request, while the logger
+		// may outlive it in a streaming response.
+		fields := []zap.Field{
+			zap.String("request_id", utils.CopyString(ctx.GetRespHeader(fiber.HeaderXRequestID))),
+			zap.String("method", utils.CopyString(ctx.Method())),
+			zap.String("path", utils.CopyString(ctx.Path())),
+		}
+		if span := trace.SpanContextFromContext(ctx.UserContext()); span.IsValid() {
+			fields = append(fields, zap.String("trace_id", span.TraceID().String()))
+		}
+		requestLogger := cfg.Logger.With(fields...)
+		ctx.SetUserContext(logger.WithContext(ctx.UserContext(), requestLogger))
+
+		if err := ctx.Next(); err != nil {
+			if err = ctx.App().ErrorHandler(ctx, err); err != nil {
+				_ = ctx.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		route := routeOf(ctx)
+		status := ctx.Response().StatusCode()
+		if status < fiber.StatusBadRequest && !sampler.keep(ctx.Method()+" "+route) {
+			return nil
+		}
+
+		level := zap.InfoLevel
+		if status >= fiber.StatusInternalServerError {
+			level = zap.ErrorLevel
+		}
+		requestLogger.Log(level, "request",
+			zap.String("route", route),
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("username", logger.Username(ctx.UserContext())),
+			zap.String("ip", utils.CopyString(ctx.IP())),
+		)
+		return nil
+	}
+}
+
+// routeSampler keeps one of every n requests of each listed route. The map
+// is only read after construction, so it needs no lock.
+type routeSampler struct {
+	every  uint64
+	counts map[string]*atomic.Uint64
+}
+
+func newRouteSampler(routes []string, every int) *routeSampler {
+	sampler := &routeSampler{
+		every:  uint64(max(every, 1)),
+		counts: make(map[string]*atomic.Uint64, len(routes)),
+	}
+	for _, route := range routes {
+		sampler.counts[route] = new(atomic.Uint64)
+	}
+	return sampler
+}
+
+func (s *routeSampler) keep(route string) bool {
+	count, ok := s.counts[route]
+	if !ok {
+		return true
+	}
+	return (count.Add(1)-1)%s.every == 0
+}
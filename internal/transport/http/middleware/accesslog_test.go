@@ -0,0 +1,98 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"github.com/Blxssy/AvitoTest/internal/transport/http/middleware"
+	"github.com/Blxssy/AvitoTest/pkg/logger"
+	tokenmocks "github.com/Blxssy/AvitoTest/pkg/token/mocks"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestAccessLog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tokenGen := tokenmocks.NewMockTokenGenerator(ctrl)
+	tokenGen.EXPECT().ParseToken("alice_token").Return("alice", nil).AnyTimes()
+	authenticator := services.NewAuthenticator(nil, tokenGen)
+
+	core, logs := observer.New(zap.InfoLevel)
+
+	app := fiber.New(fiber.Config{
+		ErrorHandler: middleware.NewErrorHandler(middleware.ErrorHandlerConfig{}),
+	})
+	app.Use(requestid.New())
+	app.Use(middleware.NewAccessLog(middleware.AccessLogConfig{
+		Logger:        zap.New(core),
+		SampledRoutes: []string{"GET /api/info"},
+		SampleEvery:   2,
+	}))
+	app.Get("/api/info", func(ctx *fiber.Ctx) error {
+		if ctx.Get(fiber.HeaderAuthorization) == "" {
+			return services.InvalidTokenError
+		}
+		return ctx.SendStatus(fiber.StatusOK)
+	})
+	app.Post("/api/sendCoin", func(ctx *fiber.Ctx) error {
+		if _, err := authenticator.Authenticate(ctx.UserContext(), "alice_token", ""); err != nil {
+			return err
+		}
+		logger.FromContext(ctx.UserContext(), nil).Info("coins sent")
+		return ctx.SendStatus(fiber.StatusOK)
+	})
+
+	send := func(method, target, requestID, token string) {
+		req := httptest.NewRequest(method, "http://localhost:8080"+target, nil)
+		req.Header.Set(fiber.HeaderXRequestID, requestID)
+		if token != "" {
+			req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+	}
+
+	send(http.MethodPost, "/api/sendCoin?access_token=alice_token", "req-1", "")
+
+	entries := logs.TakeAll()
+	require.Len(t, entries, 2)
+	// The service logs with the fields of the request.
+	assert.Equal(t, "coins sent", entries[0].Message)
+	assert.Equal(t, "req-1", entries[0].ContextMap()["request_id"])
+
+	assert.Equal(t, "request", entries[1].Message)
+	assert.Equal(t, map[string]any{
+		"request_id": "req-1",
+		"method":     "POST",
+		"path":       "/api/sendCoin",
+		"route":      "/api/sendCoin",
+		"status":     int64(200),
+		"latency":    entries[1].ContextMap()["latency"],
+		"username":   "alice",
+		"ip":         "0.0.0.0",
+	}, entries[1].ContextMap(), "the query string, which may hold a token, is not logged")
+
+	// Every second successful request of a sampled route is logged, failed
+	// ones always are.
+	for i := 0; i < 4; i++ {
+		send(http.MethodGet, "/api/info", "ok", "alice_token")
+	}
+	send(http.MethodGet, "/api/info", "failed", "")
+
+	entries = logs.TakeAll()
+	require.Len(t, entries, 3)
+	assert.Equal(t, "ok", entries[0].ContextMap()["request_id"])
+	assert.Equal(t, "ok", entries[1].ContextMap()["request_id"])
+	assert.Equal(t, "failed", entries[2].ContextMap()["request_id"])
+	assert.Equal(t, int64(401), entries[2].ContextMap()["status"])
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"github.com/Blxssy/AvitoTest/pkg/logger"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"go.uber.org/zap"
+	"strconv"
+	"strings"
+)
+
+// ErrorResponse is the body of every error returned by the HTTP API. Code is
+// stable and meant for programs; Errors is a human-readable message.
+type ErrorResponse struct {
+	Errors string `json:"errors"`
+	Code   string `json:"code"`
+}
+
+type ErrorHandlerConfig struct {
+	Logger *zap.Logger
+}
+
+var categoryStatuses = map[error]int{
+	services.InvalidParamsError:   fiber.StatusBadRequest,
+	services.UnauthorizedError:    fiber.StatusUnauthorized,
+	services.ForbiddenError:       fiber.StatusForbidden,
+	services.NotFoundError:        fiber.StatusNotFound,
+	services.ConflictError:        fiber.StatusConflict,
+	services.UnprocessableError:   fiber.StatusUnprocessableEntity,
+	services.TooManyRequestsError: fiber.StatusTooManyRequests,
+}
+
+// NewErrorHandler turns errors returned by handlers into ErrorResponse bodies.
+// Service errors are mapped by category, *fiber.Error keeps its status, and
+// anything else is logged and answered with a bare 500 so internals such as
+// SQL errors never reach clients.
+func NewErrorHandler(cfg ErrorHandlerConfig) fiber.ErrorHandler {
+	return func(ctx *fiber.Ctx, err error) error {
+		status, body := errorResponse(err)
+		var domainErr *services.Error
+		if errors.As(err, &domainErr) && domainErr.RetryAfter > 0 {
+			ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(ceilSeconds(domainErr.RetryAfter)))
+		}
+		if status >= fiber.StatusInternalServerError && cfg.Logger != nil {
+			// The request logger of the access log already has these fields.
+			fallback := cfg.Logger.With(zap.String("method", ctx.Method()), zap.String("path", ctx.Path()))
+			logger.FromContext(ctx.UserContext(), fallback).Error("request failed", zap.Error(err))
+		}
+
+		return ctx.Status(status).JSON(body)
+	}
+}
+
+func errorResponse(err error) (int, ErrorResponse) {
+	if category, code, message := services.Classify(err); category != nil {
+		return categoryStatuses[category], ErrorResponse{Errors: message, Code: code}
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) && fiberErr.Code < fiber.StatusInternalServerError {
+		return fiberErr.Code, ErrorResponse{
+			Errors: fiberErr.Message,
+			Code:   statusCode(fiberErr.Code),
+		}
+	}
+
+	return fiber.StatusInternalServerError, ErrorResponse{Errors: "internal error", Code: "internal"}
+}
+
+// statusCode derives a code from the status text, e.g. "upgrade_required".
+func statusCode(status int) string {
+	return strings.ReplaceAll(strings.ToLower(utils.StatusMessage(status)), " ", "_")
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"github.com/Blxssy/AvitoTest/internal/metrics"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"time"
+)
+
+type MetricsConfig struct {
+	Metrics *metrics.Metrics
+}
+
+// NewMetrics records the duration of every request by route pattern and
+// status. Errors are rendered here, like the logger middleware of Fiber does,
+// so the recorded status is the one the client gets.
+func NewMetrics(cfg MetricsConfig) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		start := time.Now()
+
+		if err := ctx.Next(); err != nil {
+			if err = ctx.App().ErrorHandler(ctx, err); err != nil {
+				_ = ctx.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		// Label values are kept by the registry, strings of fiber.Ctx are
+		// reused after the request.
+		cfg.Metrics.ObserveHTTPRequest(utils.CopyString(ctx.Method()), routeOf(ctx), ctx.Response().StatusCode(), time.Since(start))
+		return nil
+	}
+}
+
+// routeOf returns the pattern of the route that handled the request. Requests
+// that no route matched end in a middleware and are grouped together.
+func routeOf(ctx *fiber.Ctx) string {
+	route := ctx.Route()
+	if route == nil || route.Path == "/" {
+		return "unmatched"
+	}
+	return route.Path
+}
@@ -0,0 +1,60 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Blxssy/AvitoTest/internal/metrics"
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"github.com/Blxssy/AvitoTest/internal/transport/http/middleware"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	m := metrics.New(metrics.Config{})
+
+	app := fiber.New(fiber.Config{
+		ErrorHandler: middleware.NewErrorHandler(middleware.ErrorHandlerConfig{}),
+	})
+	app.Use(middleware.NewMetrics(middleware.MetricsConfig{Metrics: m}))
+	app.Get("/api/buy/:item", func(ctx *fiber.Ctx) error {
+		if ctx.Params("item") == "sword" {
+			return services.ItemNotFoundError
+		}
+		return ctx.SendStatus(fiber.StatusOK)
+	})
+
+	for _, target := range []string{"/api/buy/t-shirt", "/api/buy/cup", "/api/buy/sword", "/missing"} {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "http://localhost:8080"+target, nil))
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+	}
+
+	families, err := m.Registry().Gather()
+	require.NoError(t, err)
+
+	counts := map[string]uint64{}
+	for _, family := range families {
+		if family.GetName() != "coin_http_request_duration_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			counts[labels["method"]+" "+labels["route"]+" "+labels["status"]] = metric.GetHistogram().GetSampleCount()
+		}
+	}
+
+	// Routes are recorded by pattern, and the status of errors is the one
+	// the error handler renders.
+	assert.Equal(t, map[string]uint64{
+		"GET /api/buy/:item 200": 2,
+		"GET /api/buy/:item 404": 1,
+		"GET unmatched 404":      1,
+	}, counts)
+}
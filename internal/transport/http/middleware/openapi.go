@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+	"net/http"
+	"strings"
+)
+
+type OpenAPIConfig struct {
+	Spec *openapi3.T
+}
+
+// ValidationDetail describes one part of a request that does not match the spec.
+type ValidationDetail struct {
+	In     string `json:"in"`
+	Field  string `json:"field,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// NewOpenAPIValidator checks requests against the spec before they reach the
+// handlers and answers 400 with every mismatch it finds. Requests to paths
+// missing from the spec are passed through. Authentication is left to the
+// handlers so a missing token is still reported as 401.
+func NewOpenAPIValidator(cfg OpenAPIConfig) (fiber.Handler, error) {
+	router, err := gorillamux.NewRouter(cfg.Spec)
+	if err != nil {
+		return nil, fmt.Errorf("gorillamux.NewRouter: %w", err)
+	}
+	templates := pathTemplates(cfg.Spec)
+
+	options := &openapi3filter.Options{
+		MultiError:         true,
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+	}
+
+	return func(ctx *fiber.Ctx) error {
+		var req http.Request
+		if err := fasthttpadaptor.ConvertRequest(ctx.Context(), &req, true); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("fasthttpadaptor.ConvertRequest: %v", err))
+		}
+
+		route, pathParams, err := router.FindRoute(&req)
+		if errors.Is(err, routers.ErrPathNotFound) {
+			// Fiber routes ignore case and a trailing slash, the spec router
+			// doesn't. Validate such requests as if they were spelled like the spec.
+			if path, ok := canonicalPath(templates, req.URL.Path); ok {
+				req.URL.Path, req.URL.RawPath = path, ""
+				route, pathParams, err = router.FindRoute(&req)
+			}
+		}
+		if err != nil {
+			if errors.Is(err, routers.ErrPathNotFound) || errors.Is(err, routers.ErrMethodNotAllowed) {
+				return ctx.Next()
+			}
+			return fmt.Errorf("router.FindRoute: %w", err)
+		}
+
+		err = openapi3filter.ValidateRequest(ctx.Context(), &openapi3filter.RequestValidationInput{
+			Request:    &req,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    options,
+		})
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"errors":  "request does not match the API specification",
+				"code":    "validation_failed",
+				"details": validationDetails(err),
+			})
+		}
+
+		return ctx.Next()
+	}, nil
+}
+
+// pathTemplates splits the paths of the spec into segments.
+func pathTemplates(spec *openapi3.T) [][]string {
+	var templates [][]string
+	for path := range spec.Paths.Map() {
+		templates = append(templates, strings.Split(path, "/"))
+	}
+	return templates
+}
+
+// canonicalPath spells path like the spec path it matches regardless of case
+// and a trailing slash. Path parameters are kept as they are.
+func canonicalPath(templates [][]string, path string) (string, bool) {
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	segments := strings.Split(path, "/")
+
+templates:
+	for _, template := range templates {
+		if len(template) != len(segments) {
+			continue
+		}
+
+		canonical := make([]string, len(segments))
+		for i, segment := range template {
+			switch {
+			case strings.HasPrefix(segment, "{"):
+				canonical[i] = segments[i]
+			case strings.EqualFold(segment, segments[i]):
+				canonical[i] = segment
+			default:
+				continue templates
+			}
+		}
+		return strings.Join(canonical, "/"), true
+	}
+	return "", false
+}
+
+func validationDetails(err error) []ValidationDetail {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		var details []ValidationDetail
+		for _, err := range e {
+			details = append(details, validationDetails(err)...)
+		}
+		return details
+	case *openapi3filter.RequestError:
+		detail := ValidationDetail{In: "body", Reason: e.Reason}
+		if e.Parameter != nil {
+			detail.In = e.Parameter.In
+			detail.Field = e.Parameter.Name
+		}
+
+		// A body may fail several schema checks at once.
+		if nested, ok := e.Err.(openapi3.MultiError); ok {
+			details := make([]ValidationDetail, len(nested))
+			for i, err := range nested {
+				details[i] = schemaDetail(detail, err)
+			}
+			return details
+		}
+		return []ValidationDetail{schemaDetail(detail, e.Err)}
+	}
+	return []ValidationDetail{{In: "body", Reason: err.Error()}}
+}
+
+func schemaDetail(detail ValidationDetail, err error) ValidationDetail {
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		if pointer := schemaErr.JSONPointer(); len(pointer) > 0 && detail.In == "body" {
+			detail.Field = strings.Join(pointer, ".")
+		}
+		detail.Reason = schemaErr.Reason
+	} else if detail.Reason == "" && err != nil {
+		detail.Reason = err.Error()
+	}
+	return detail
+}
@@ -0,0 +1,119 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Blxssy/AvitoTest/api/openapi"
+	"github.com/Blxssy/AvitoTest/internal/transport/http/middleware"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newApp(t *testing.T) *fiber.App {
+	spec, err := openapi.Load()
+	require.NoError(t, err)
+
+	validator, err := middleware.NewOpenAPIValidator(middleware.OpenAPIConfig{Spec: spec})
+	require.NoError(t, err)
+
+	app := fiber.New()
+	app.Use(validator)
+	ok := func(ctx *fiber.Ctx) error { return ctx.SendStatus(fiber.StatusOK) }
+	app.Post("/api/sendCoin", ok)
+	app.Get("/api/notifications", ok)
+	app.Get("/unknown", ok)
+	return app
+}
+
+func TestOpenAPIValidator(t *testing.T) {
+	app := newApp(t)
+
+	tests := []struct {
+		name    string
+		method  string
+		target  string
+		body    string
+		code    int
+		details []middleware.ValidationDetail
+	}{
+		{
+			name:   "valid transfer",
+			method: http.MethodPost, target: "/api/sendCoin",
+			body: `{"toUser": "friend", "amount": 10}`,
+			code: http.StatusOK,
+		},
+		{
+			name:   "negative amount and empty receiver",
+			method: http.MethodPost, target: "/api/sendCoin",
+			body: `{"toUser": "", "amount": -5}`,
+			code: http.StatusBadRequest,
+			details: []middleware.ValidationDetail{
+				{In: "body", Field: "toUser", Reason: "minimum string length is 1"},
+				{In: "body", Field: "amount", Reason: "number must be at least 1"},
+			},
+		},
+		{
+			name:   "missing amount",
+			method: http.MethodPost, target: "/api/sendCoin",
+			body: `{"toUser": "friend"}`,
+			code: http.StatusBadRequest,
+			details: []middleware.ValidationDetail{
+				{In: "body", Field: "amount", Reason: `property "amount" is missing`},
+			},
+		},
+		{
+			name:   "query parameter out of range",
+			method: http.MethodGet, target: "/api/notifications?limit=1000",
+			code: http.StatusBadRequest,
+			details: []middleware.ValidationDetail{
+				{In: "query", Field: "limit", Reason: "number must be at most 100"},
+			},
+		},
+		{
+			name:   "path spelled unlike the spec",
+			method: http.MethodPost, target: "/API/SENDCOIN/",
+			body: `{"toUser": "", "amount": -5}`,
+			code: http.StatusBadRequest,
+			details: []middleware.ValidationDetail{
+				{In: "body", Field: "toUser", Reason: "minimum string length is 1"},
+				{In: "body", Field: "amount", Reason: "number must be at least 1"},
+			},
+		},
+		{
+			name:   "path outside the spec",
+			method: http.MethodGet, target: "/unknown",
+			code: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "http://localhost:8080"+tt.target, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer valid_token")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, tt.code, resp.StatusCode)
+
+			if tt.code != http.StatusBadRequest {
+				return
+			}
+			var body struct {
+				Errors  string                        `json:"errors"`
+				Code    string                        `json:"code"`
+				Details []middleware.ValidationDetail `json:"details"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			assert.NotEmpty(t, body.Errors)
+			assert.Equal(t, "validation_failed", body.Code)
+			assert.ElementsMatch(t, tt.details, body.Details)
+		})
+	}
+}
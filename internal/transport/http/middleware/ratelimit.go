@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/ratelimit"
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRateLimitRule names the limit of requests that match no rule.
+const DefaultRateLimitRule = "default"
+
+// RateLimitRule limits the routes listed as "METHOD /path". Routes of one
+// rule share buckets, so a limit on transfers covers v1 and v2 alike.
+type RateLimitRule struct {
+	Name   string
+	Routes []string
+}
+
+type RateLimitConfig struct {
+	Store         ratelimit.Store
+	Authenticator services.Authenticator
+	Logger        *zap.Logger
+
+	Rules []RateLimitRule
+	// Limits holds the limit of every rule by name, and of the rest of the
+	// requests under DefaultRateLimitRule. A zero limit means unlimited.
+	// Changes to Limits apply to the next request.
+	Limits *ratelimit.Limits
+}
+
+// NewRateLimiter counts requests in token buckets keyed by the username behind
+// the bearer token or API key, or by client IP for anonymous requests. Limited requests
+// get 429 with Retry-After; every counted response carries the RateLimit-*
+// headers. The credential is resolved once per request: the user context
+// passed on to the handlers keeps the result for their own Authenticate. If the store fails the request is let through and the error is
+// logged, so an outage of the store does not take the API down.
+func NewRateLimiter(cfg RateLimitConfig) fiber.Handler {
+	rules := make(map[string]RateLimitRule)
+	for _, rule := range cfg.Rules {
+		for _, route := range rule.Routes {
+			method, path, _ := strings.Cut(route, " ")
+			rules[routeKey(method, path)] = rule
+		}
+	}
+	defaultRule := RateLimitRule{Name: DefaultRateLimitRule}
+
+	return func(ctx *fiber.Ctx) error {
+		ctx.SetUserContext(services.WithCredentialCache(ctx.UserContext()))
+
+		rule, ok := rules[routeKey(ctx.Method(), ctx.Path())]
+		if !ok {
+			rule = defaultRule
+		}
+		limit := cfg.Limits.Get(rule.Name)
+		if limit.IsZero() {
+			return ctx.Next()
+		}
+
+		key := rule.Name + ":" + clientKey(ctx, cfg.Authenticator)
+		result, err := cfg.Store.Take(ctx.Context(), key, limit)
+		if err != nil {
+			if cfg.Logger != nil {
+				cfg.Logger.Error("rate limit store failed", zap.String("key", key), zap.Error(err))
+			}
+			return ctx.Next()
+		}
+
+		ctx.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Burst, ceilSeconds(limit.Period())))
+		ctx.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		ctx.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		ctx.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+		if !result.Allowed {
+			ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			return fiber.NewError(fiber.StatusTooManyRequests, "too many requests")
+		}
+
+		return ctx.Next()
+	}
+}
+
+// routeKey matches paths the way the router does, which ignores case and a
+// trailing slash, so "/API/auth/" can't slip past the limit of "/api/auth".
+func routeKey(method, path string) string {
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return method + " " + strings.ToLower(path)
+}
+
+func clientKey(ctx *fiber.Ctx, auth services.Authenticator) string {
+	if auth != nil {
+		accessToken := strings.TrimPrefix(ctx.Get(fiber.HeaderAuthorization), "Bearer ")
+		if accessToken != "" {
+			if username, err := auth.Authenticate(ctx.UserContext(), accessToken, ""); err == nil {
+				return "user:" + username
+			}
+		}
+	}
+	return "ip:" + ctx.IP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
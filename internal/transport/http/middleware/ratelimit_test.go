@@ -0,0 +1,151 @@
+package middleware_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/ratelimit"
+	repomocks "github.com/Blxssy/AvitoTest/internal/repo/mocks"
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"github.com/Blxssy/AvitoTest/internal/transport/http/middleware"
+	tokenmocks "github.com/Blxssy/AvitoTest/pkg/token/mocks"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tokenGen := tokenmocks.NewMockTokenGenerator(ctrl)
+	tokenGen.EXPECT().ParseToken("alice_token").Return("alice", nil).AnyTimes()
+	tokenGen.EXPECT().ParseToken("bad_token").Return("", errors.New("invalid token")).AnyTimes()
+
+	app := fiber.New(fiber.Config{
+		ErrorHandler: middleware.NewErrorHandler(middleware.ErrorHandlerConfig{}),
+	})
+	limits := ratelimit.NewLimits(map[string]ratelimit.Limit{
+		"transfer": {Rate: 1.0 / 60, Burst: 2},
+	})
+	app.Use(middleware.NewRateLimiter(middleware.RateLimitConfig{
+		Store:         ratelimit.NewMemoryStore(),
+		Authenticator: services.NewAuthenticator(nil, tokenGen),
+		Rules: []middleware.RateLimitRule{
+			{Name: "transfer", Routes: []string{"POST /api/sendCoin", "POST /v2/transfers"}},
+		},
+		Limits: limits,
+	}))
+	ok := func(ctx *fiber.Ctx) error { return ctx.SendStatus(fiber.StatusOK) }
+	app.Post("/api/sendCoin", ok)
+	app.Post("/v2/transfers", ok)
+	app.Get("/api/info", ok)
+
+	send := func(method, target, token string) *http.Response {
+		req := httptest.NewRequest(method, "http://localhost:8080"+target, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := send(http.MethodPost, "/api/sendCoin", "alice_token")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "2", resp.Header.Get("RateLimit-Limit"))
+	assert.Equal(t, "1", resp.Header.Get("RateLimit-Remaining"))
+	assert.Equal(t, "2;w=120", resp.Header.Get("RateLimit-Policy"))
+
+	// v1 and v2 transfers share the bucket.
+	resp = send(http.MethodPost, "/v2/transfers", "alice_token")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = send(http.MethodPost, "/api/sendCoin", "alice_token")
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "60", resp.Header.Get("Retry-After"))
+	assert.Equal(t, "0", resp.Header.Get("RateLimit-Remaining"))
+
+	// The router ignores case and a trailing slash, and so does the limit.
+	for _, target := range []string{"/api/sendCoin/", "/API/SENDCOIN", "/V2/Transfers/"} {
+		resp = send(http.MethodPost, target, "alice_token")
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode, target)
+	}
+
+	// Requests without a valid token are limited by IP, apart from alice.
+	resp = send(http.MethodPost, "/api/sendCoin", "bad_token")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Routes without a rule fall back to the default, which is unlimited here.
+	resp = send(http.MethodGet, "/api/info", "alice_token")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("RateLimit-Limit"))
+
+	// New limits apply to the next request; buckets keep their tokens.
+	limits.Set(map[string]ratelimit.Limit{
+		"transfer":                      {Rate: 1.0 / 60, Burst: 5},
+		middleware.DefaultRateLimitRule: {Rate: 1, Burst: 100},
+	})
+	resp = send(http.MethodPost, "/api/sendCoin", "bad_token")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "5", resp.Header.Get("RateLimit-Limit"))
+	resp = send(http.MethodGet, "/api/info", "alice_token")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "100", resp.Header.Get("RateLimit-Limit"))
+}
+
+func TestRateLimiterResolvesCredentialOnce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := "0123456789abcdef"
+	key := services.APIKeyPrefix + id + "_secret"
+	sum := sha256.Sum256([]byte("secret"))
+
+	repoMock := repomocks.NewMockCoinRepository(ctrl)
+	repoMock.EXPECT().GetAPIKey(gomock.Any(), id).Return(models.APIKey{
+		Username:   "billing-bot",
+		SecretHash: hex.EncodeToString(sum[:]),
+		Scopes:     []string{string(services.ScopeBalanceRead)},
+		ExpiresAt:  time.Now().Add(time.Hour),
+	}, nil).Times(1)
+	auth := services.NewAuthenticator(repoMock, nil)
+
+	app := fiber.New(fiber.Config{
+		ErrorHandler: middleware.NewErrorHandler(middleware.ErrorHandlerConfig{}),
+	})
+	app.Use(middleware.NewRateLimiter(middleware.RateLimitConfig{
+		Store:         ratelimit.NewMemoryStore(),
+		Authenticator: auth,
+		Limits:        ratelimit.NewLimits(map[string]ratelimit.Limit{middleware.DefaultRateLimitRule: {Rate: 1, Burst: 10}}),
+	}))
+	app.Get("/balance", func(ctx *fiber.Ctx) error {
+		// The handler authenticates with the key the limiter has resolved,
+		// and the scope is still checked.
+		if _, err := auth.Authenticate(ctx.UserContext(), key, services.ScopeTransfersWrite); !errors.Is(err, services.InsufficientScopeError) {
+			return fmt.Errorf("want insufficient scope, got %v", err)
+		}
+		username, err := auth.Authenticate(ctx.UserContext(), key, services.ScopeBalanceRead)
+		if err != nil {
+			return err
+		}
+		return ctx.SendString(username)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/balance", nil)
+	req.Header.Set("Authorization", "Bearer "+key)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Equal(t, "billing-bot", string(body))
+}
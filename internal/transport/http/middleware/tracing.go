@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"github.com/Blxssy/AvitoTest/internal/tracing"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+)
+
+// NewTracing starts a server span for every request, continuing the trace of
+// the caller's traceparent header. Handlers get the span and the request ID
+// through ctx.UserContext(), so it must run after the request ID middleware.
+func NewTracing() fiber.Handler {
+	tracer := otel.Tracer(tracing.InstrumentationName)
+
+	return func(ctx *fiber.Ctx) error {
+		// fasthttp canonicalizes header names, propagators look them up in
+		// lower case.
+		carrier := propagation.MapCarrier{}
+		ctx.Request().Header.VisitAll(func(key, value []byte) {
+			carrier.Set(strings.ToLower(string(key)), string(value))
+		})
+		parent := otel.GetTextMapPropagator().Extract(ctx.UserContext(), carrier)
+
+		// Spans are exported after the request, when strings of fiber.Ctx
+		// have been reused.
+		method := utils.CopyString(ctx.Method())
+		requestID := utils.CopyString(ctx.GetRespHeader(fiber.HeaderXRequestID))
+		spanCtx, span := tracer.Start(tracing.ContextWithRequestID(parent, requestID), method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(method),
+				semconv.URLPath(utils.CopyString(ctx.Path())),
+				tracing.RequestIDKey.String(requestID),
+			),
+		)
+		defer span.End()
+		ctx.SetUserContext(spanCtx)
+
+		if err := ctx.Next(); err != nil {
+			if err = ctx.App().ErrorHandler(ctx, err); err != nil {
+				_ = ctx.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		route := routeOf(ctx)
+		status := ctx.Response().StatusCode()
+		span.SetName(method + " " + route)
+		span.SetAttributes(
+			semconv.HTTPRoute(route),
+			semconv.HTTPResponseStatusCode(status),
+		)
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, "")
+		}
+		return nil
+	}
+}
@@ -1,19 +1,46 @@
 package http
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"github.com/Blxssy/AvitoTest/api/openapi"
+	"github.com/Blxssy/AvitoTest/internal/health"
+	"github.com/Blxssy/AvitoTest/internal/metrics"
+	"github.com/Blxssy/AvitoTest/internal/ratelimit"
 	"github.com/Blxssy/AvitoTest/internal/services"
+	"github.com/Blxssy/AvitoTest/internal/transport/graphql"
+	"github.com/Blxssy/AvitoTest/internal/transport/http/middleware"
 	v1 "github.com/Blxssy/AvitoTest/internal/transport/http/v1"
+	v2 "github.com/Blxssy/AvitoTest/internal/transport/http/v2"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"go.uber.org/zap"
+	"net"
 )
 
 type Server struct {
 	addr string
 
-	coinService services.CoinService
+	coinService         services.CoinService
+	eventService        services.EventService
+	notificationService services.NotificationService
+	accountService      services.AccountService
+	featureFlagService  services.FeatureFlagService
+	authenticator       services.Authenticator
+
+	graphQLMaxDepth      int
+	graphQLMaxComplexity int
+
+	rateLimitStore ratelimit.Store
+	rateLimits     *ratelimit.Limits
+
+	metrics *metrics.Metrics
+	health  *health.Checker
+
+	accessLogSampledRoutes []string
+	accessLogSampleEvery   int
 
 	logger *zap.Logger
 	app    *fiber.App
@@ -22,24 +49,90 @@ type Server struct {
 type ServerConfig struct {
 	Addr string
 
-	CoinService services.CoinService
+	// ProxyHeader holds the client IP on requests from TrustedProxies; the
+	// IP of the connection is used otherwise.
+	ProxyHeader    string
+	TrustedProxies []string
+
+	CoinService         services.CoinService
+	EventService        services.EventService
+	NotificationService services.NotificationService
+	AccountService      services.AccountService
+	FeatureFlagService  services.FeatureFlagService
+	Authenticator       services.Authenticator
+
+	// GraphQLMaxDepth and GraphQLMaxComplexity limit queries to /graphql.
+	GraphQLMaxDepth      int
+	GraphQLMaxComplexity int
+
+	// RateLimitStore enables rate limiting when set. RateLimits holds the
+	// limits of the rules "auth" for login, "transfer" for coin transfers,
+	// and "default" for every other route; they may change at runtime.
+	RateLimitStore ratelimit.Store
+	RateLimits     *ratelimit.Limits
+
+	// Metrics records request durations when set.
+	Metrics *metrics.Metrics
+
+	// Health backs /readyz and /debug/info. Without it the server is always
+	// ready.
+	Health *health.Checker
+
+	// AccessLogSampledRoutes are logged only every AccessLogSampleEvery-th
+	// successful request.
+	AccessLogSampledRoutes []string
+	AccessLogSampleEvery   int
 
 	Logger *zap.Logger
 }
 
-func NewServer(cfg ServerConfig) *Server {
+func NewServer(cfg ServerConfig) (*Server, error) {
 	server := &Server{
-		addr:        cfg.Addr,
-		logger:      cfg.Logger,
-		coinService: cfg.CoinService,
-		app:         nil,
+		addr:                cfg.Addr,
+		logger:              cfg.Logger,
+		coinService:         cfg.CoinService,
+		eventService:        cfg.EventService,
+		notificationService: cfg.NotificationService,
+		accountService:      cfg.AccountService,
+		featureFlagService:  cfg.FeatureFlagService,
+		authenticator:       cfg.Authenticator,
+
+		graphQLMaxDepth:      cfg.GraphQLMaxDepth,
+		graphQLMaxComplexity: cfg.GraphQLMaxComplexity,
+
+		rateLimitStore: cfg.RateLimitStore,
+		rateLimits:     cfg.RateLimits,
+
+		metrics: cfg.Metrics,
+		health:  cfg.Health,
+
+		accessLogSampledRoutes: cfg.AccessLogSampledRoutes,
+		accessLogSampleEvery:   cfg.AccessLogSampleEvery,
+
+		app: nil,
+	}
+	if server.rateLimits == nil {
+		server.rateLimits = ratelimit.NewLimits(nil)
+	}
+	if server.health == nil {
+		server.health = health.NewChecker(health.Config{})
 	}
 
-	server.app = fiber.New(fiber.Config{})
+	server.app = fiber.New(fiber.Config{
+		ProxyHeader:             cfg.ProxyHeader,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          cfg.TrustedProxies,
+		EnableIPValidation:      true,
+		ErrorHandler: middleware.NewErrorHandler(middleware.ErrorHandlerConfig{
+			Logger: cfg.Logger,
+		}),
+	})
 
-	server.init()
+	if err := server.init(); err != nil {
+		return nil, err
+	}
 
-	return server
+	return server, nil
 }
 
 func (s *Server) Run() error {
@@ -49,30 +142,122 @@ func (s *Server) Run() error {
 	return nil
 }
 
-func (s *Server) Shutdown() error {
-	if err := s.app.Shutdown(); err != nil {
+// Serve accepts connections on listener. Tests use it with a random port.
+func (s *Server) Serve(listener net.Listener) error {
+	if err := s.app.Listener(listener); err != nil {
+		return fmt.Errorf("serving HTTP server: %w", err)
+	}
+	return nil
+}
+
+// Shutdown stops accepting connections and waits for the requests in flight
+// until ctx is done.
+func (s *Server) Shutdown(ctx context.Context) error {
+	if err := s.app.ShutdownWithContext(ctx); err != nil {
 		return fmt.Errorf("shutdown HTTP server: %w", err)
 	}
 	return nil
 }
 
-func (s *Server) init() {
+func (s *Server) init() error {
+	// Metrics go first so rejected requests are measured too.
+	if s.metrics != nil {
+		s.app.Use(middleware.NewMetrics(middleware.MetricsConfig{Metrics: s.metrics}))
+	}
+	s.setHealthHandlers()
 	s.app.Use(cors.New())
 	s.app.Use(requestid.New())
+	s.app.Use(middleware.NewTracing())
+	if s.logger != nil {
+		s.app.Use(middleware.NewAccessLog(middleware.AccessLogConfig{
+			Logger:        s.logger,
+			SampledRoutes: s.accessLogSampledRoutes,
+			SampleEvery:   s.accessLogSampleEvery,
+		}))
+	}
 	s.app.Use(func(ctx *fiber.Ctx) error {
 		ctx.Response().Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
 		return ctx.Next()
 	})
 
-	s.setHandlers()
+	if s.rateLimitStore != nil {
+		s.app.Use(middleware.NewRateLimiter(middleware.RateLimitConfig{
+			Store:         s.rateLimitStore,
+			Authenticator: s.authenticator,
+			Logger:        s.logger,
+			Rules: []middleware.RateLimitRule{
+				{
+					Name: "auth",
+					Routes: []string{
+						"POST /api/auth",
+						"POST /api/auth/2fa",
+						"GET /api/auth/oidc/login",
+						"GET /api/auth/oidc/callback",
+						"POST /api/password/reset",
+					},
+				},
+				{
+					Name:   "transfer",
+					Routes: []string{"POST /api/sendCoin", "POST /v2/transfers"},
+				},
+			},
+			Limits: s.rateLimits,
+		}))
+	}
+
+	spec, err := openapi.Load()
+	if err != nil {
+		return fmt.Errorf("openapi.Load: %w", err)
+	}
+	specJSON, err := json.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+	s.app.Get("/openapi.json", func(ctx *fiber.Ctx) error {
+		return ctx.Type("json").Send(specJSON)
+	})
+
+	validator, err := middleware.NewOpenAPIValidator(middleware.OpenAPIConfig{Spec: spec})
+	if err != nil {
+		return fmt.Errorf("middleware.NewOpenAPIValidator: %w", err)
+	}
+	s.app.Use(validator)
+
+	return s.setHandlers()
 }
 
-func (s *Server) setHandlers() {
+func (s *Server) setHandlers() error {
 	handlerV1 := v1.NewHandler(v1.HandlerConfig{
+		CoinService:         s.coinService,
+		EventService:        s.eventService,
+		NotificationService: s.notificationService,
+		AccountService:      s.accountService,
+		FeatureFlagService:  s.featureFlagService,
+		Logger:              s.logger,
+	})
+	{
+		handlerV1.Init(s.app)
+	}
+
+	handlerV2 := v2.NewHandler(v2.HandlerConfig{
 		CoinService: s.coinService,
 		Logger:      s.logger,
 	})
 	{
-		handlerV1.Init(s.app)
+		handlerV2.Init(s.app)
 	}
+
+	handlerGraphQL, err := graphql.NewHandler(graphql.HandlerConfig{
+		CoinService:   s.coinService,
+		Authenticator: s.authenticator,
+		Logger:        s.logger,
+		MaxDepth:      s.graphQLMaxDepth,
+		MaxComplexity: s.graphQLMaxComplexity,
+	})
+	if err != nil {
+		return fmt.Errorf("graphql.NewHandler: %w", err)
+	}
+	handlerGraphQL.Init(s.app)
+
+	return nil
 }
@@ -0,0 +1,146 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/Blxssy/AvitoTest/api/openapi"
+	"github.com/Blxssy/AvitoTest/internal/health"
+	"github.com/Blxssy/AvitoTest/internal/ratelimit"
+	"github.com/Blxssy/AvitoTest/internal/services/mocks"
+	"github.com/Blxssy/AvitoTest/internal/transport/http/middleware"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var routeParam = regexp.MustCompile(`:(\w+)`)
+
+// TestRoutesMatchSpec keeps openapi.yaml in sync with the router: every route
+// must be described, and every described operation must be routed.
+func TestRoutesMatchSpec(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Optional route groups are registered only with their service.
+	server, err := NewServer(ServerConfig{
+		FeatureFlagService: mocks.NewMockFeatureFlagService(ctrl),
+	})
+	require.NoError(t, err)
+
+	spec, err := openapi.Load()
+	require.NoError(t, err)
+
+	routed := make(map[string]bool)
+	for _, route := range server.app.GetRoutes(true) {
+		// Fiber registers HEAD for every GET; middleware is mounted on "/".
+		if route.Method == "HEAD" || route.Path == "/" {
+			continue
+		}
+		path := routeParam.ReplaceAllString(route.Path, "{$1}")
+		routed[route.Method+" "+path] = true
+
+		item := spec.Paths.Find(path)
+		if !assert.NotNil(t, item, "route %s %s is missing from the spec", route.Method, route.Path) {
+			continue
+		}
+		assert.NotNil(t, item.GetOperation(route.Method), "route %s %s is missing from the spec", route.Method, route.Path)
+	}
+
+	for path, item := range spec.Paths.Map() {
+		for method := range item.Operations() {
+			assert.True(t, routed[method+" "+path], "spec operation %s %s has no route", method, path)
+		}
+	}
+}
+
+func TestServeSpec(t *testing.T) {
+	server, err := NewServer(ServerConfig{})
+	require.NoError(t, err)
+
+	resp, err := server.app.Test(httptest.NewRequest("GET", "http://localhost:8080/openapi.json", nil))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	var spec struct {
+		OpenAPI string         `json:"openapi"`
+		Paths   map[string]any `json:"paths"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&spec))
+	assert.Equal(t, "3.0.3", spec.OpenAPI)
+	assert.Contains(t, spec.Paths, "/api/sendCoin")
+}
+
+func TestHealthEndpoints(t *testing.T) {
+	checker := health.NewChecker(health.Config{})
+	server, err := NewServer(ServerConfig{Health: checker})
+	require.NoError(t, err)
+
+	get := func(path string) (int, map[string]any) {
+		resp, err := server.app.Test(httptest.NewRequest("GET", "http://localhost:8080"+path, nil))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return resp.StatusCode, body
+	}
+
+	status, body := get("/healthz")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "ok", body["status"])
+
+	status, body = get("/debug/info")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "dev", body["version"])
+	assert.NotEmpty(t, body["uptime"])
+
+	status, body = get("/readyz")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "ok", body["status"])
+
+	checker.Drain()
+	status, body = get("/readyz")
+	assert.Equal(t, 503, status)
+	assert.Equal(t, "unavailable", body["status"])
+	assert.Equal(t, map[string]any{"shutdown": "shutting down"}, body["checks"])
+
+	status, _ = get("/healthz")
+	assert.Equal(t, 200, status, "liveness holds while draining")
+}
+
+func TestTrustedProxyHeader(t *testing.T) {
+	newServer := func(trusted ...string) *Server {
+		server, err := NewServer(ServerConfig{
+			ProxyHeader:    "X-Real-IP",
+			TrustedProxies: trusted,
+			RateLimitStore: ratelimit.NewMemoryStore(),
+			RateLimits: ratelimit.NewLimits(map[string]ratelimit.Limit{
+				middleware.DefaultRateLimitRule: {Rate: 1.0 / 60, Burst: 1},
+			}),
+		})
+		require.NoError(t, err)
+		return server
+	}
+	send := func(server *Server, clientIP string) int {
+		req := httptest.NewRequest("GET", "http://localhost:8080/missing", nil)
+		req.Header.Set("X-Real-IP", clientIP)
+		resp, err := server.app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	// app.Test connects from 0.0.0.0.
+	server := newServer("0.0.0.0")
+	assert.NotEqual(t, 429, send(server, "10.0.0.1"))
+	assert.Equal(t, 429, send(server, "10.0.0.1"))
+	assert.NotEqual(t, 429, send(server, "10.0.0.2"), "clients behind the proxy have buckets of their own")
+
+	server = newServer("192.0.2.1")
+	assert.NotEqual(t, 429, send(server, "10.0.0.1"))
+	assert.Equal(t, 429, send(server, "10.0.0.2"), "the header of an untrusted peer is ignored")
+}
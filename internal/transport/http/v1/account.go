@@ -0,0 +1,412 @@
+package v1
+
+import (
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"time"
+)
+
+func (h *Handler) initAccountRoutes(router fiber.Router) {
+	accountRoute := router.Group("/api")
+	{
+		accountRoute.Post("password", h.ChangePassword)
+		accountRoute.Post("password/reset", h.ResetPassword)
+		accountRoute.Post("admin/users/:username/unlock", h.UnlockUser)
+		accountRoute.Post("admin/users/:username/password-reset", h.IssuePasswordReset)
+		accountRoute.Post("2fa/enroll", h.EnrollTOTP)
+		accountRoute.Post("2fa/activate", h.ActivateTOTP)
+		accountRoute.Post("2fa/disable", h.DisableTOTP)
+		accountRoute.Get("admin/2fa-policy", h.GetTwoFactorPolicy)
+		accountRoute.Put("admin/2fa-policy", h.SetTwoFactorPolicy)
+		accountRoute.Post("admin/service-accounts", h.CreateServiceAccount)
+		accountRoute.Post("admin/service-accounts/:username/keys", h.CreateAPIKey)
+		accountRoute.Get("admin/service-accounts/:username/keys", h.ListAPIKeys)
+		accountRoute.Delete("admin/api-keys/:id", h.RevokeAPIKey)
+		accountRoute.Post("admin/config/reload", h.ReloadConfig)
+	}
+}
+
+func (h *Handler) UnlockUser(ctx *fiber.Ctx) error {
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = h.accountService.UnlockUser(ctx.UserContext(), services.UnlockUserParams{
+		Token:    token,
+		Username: ctx.Params("username"),
+	})
+	if err != nil {
+		return fmt.Errorf("h.accountService.UnlockUser: %w", err)
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+func (h *Handler) ChangePassword(ctx *fiber.Ctx) error {
+	var req ChangePasswordRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(
+			fiber.StatusBadRequest,
+			fmt.Errorf("ctx.BodyParser: %w", err).Error(),
+		)
+	}
+
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = h.accountService.ChangePassword(ctx.UserContext(), services.ChangePasswordParams{
+		Token:           token,
+		CurrentPassword: req.CurrentPassword,
+		NewPassword:     req.NewPassword,
+	})
+	if err != nil {
+		return fmt.Errorf("h.accountService.ChangePassword: %w", err)
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+type PasswordResetResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+func (h *Handler) IssuePasswordReset(ctx *fiber.Ctx) error {
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	reset, err := h.accountService.IssuePasswordReset(ctx.UserContext(), services.IssuePasswordResetParams{
+		Token:    token,
+		Username: ctx.Params("username"),
+	})
+	if err != nil {
+		return fmt.Errorf("h.accountService.IssuePasswordReset: %w", err)
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(PasswordResetResponse{
+		Token:     reset.Token,
+		ExpiresAt: reset.ExpiresAt,
+	})
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"newPassword"`
+}
+
+func (h *Handler) ResetPassword(ctx *fiber.Ctx) error {
+	var req ResetPasswordRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(
+			fiber.StatusBadRequest,
+			fmt.Errorf("ctx.BodyParser: %w", err).Error(),
+		)
+	}
+
+	err := h.accountService.ResetPassword(ctx.UserContext(), services.ResetPasswordParams{
+		ResetToken:  req.Token,
+		NewPassword: req.NewPassword,
+	})
+	if err != nil {
+		return fmt.Errorf("h.accountService.ResetPassword: %w", err)
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *Handler) EnrollTOTP(ctx *fiber.Ctx) error {
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	enrollment, err := h.accountService.EnrollTOTP(ctx.UserContext(), services.EnrollTOTPParams{
+		Token: token,
+	})
+	if err != nil {
+		return fmt.Errorf("h.accountService.EnrollTOTP: %w", err)
+	}
+
+	return ctx.JSON(fiber.Map{
+		"secret": enrollment.Secret,
+		"uri":    enrollment.URI,
+	})
+}
+
+type TOTPCodeRequest struct {
+	Code string `json:"code"`
+}
+
+func (h *Handler) ActivateTOTP(ctx *fiber.Ctx) error {
+	var req TOTPCodeRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(
+			fiber.StatusBadRequest,
+			fmt.Errorf("ctx.BodyParser: %w", err).Error(),
+		)
+	}
+
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	codes, err := h.accountService.ActivateTOTP(ctx.UserContext(), services.ActivateTOTPParams{
+		Token: token,
+		Code:  req.Code,
+	})
+	if err != nil {
+		return fmt.Errorf("h.accountService.ActivateTOTP: %w", err)
+	}
+
+	return ctx.JSON(fiber.Map{
+		"recoveryCodes": codes,
+	})
+}
+
+func (h *Handler) DisableTOTP(ctx *fiber.Ctx) error {
+	var req TOTPCodeRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(
+			fiber.StatusBadRequest,
+			fmt.Errorf("ctx.BodyParser: %w", err).Error(),
+		)
+	}
+
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = h.accountService.DisableTOTP(ctx.UserContext(), services.DisableTOTPParams{
+		Token: token,
+		Code:  req.Code,
+	})
+	if err != nil {
+		return fmt.Errorf("h.accountService.DisableTOTP: %w", err)
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+type TwoFactorPolicy struct {
+	TransferThreshold int `json:"transferThreshold"`
+	PurchaseThreshold int `json:"purchaseThreshold"`
+}
+
+func (h *Handler) GetTwoFactorPolicy(ctx *fiber.Ctx) error {
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	policy, err := h.accountService.GetTwoFactorPolicy(ctx.UserContext(), services.GetTwoFactorPolicyParams{
+		Token: token,
+	})
+	if err != nil {
+		return fmt.Errorf("h.accountService.GetTwoFactorPolicy: %w", err)
+	}
+
+	return ctx.JSON(TwoFactorPolicy(policy))
+}
+
+func (h *Handler) SetTwoFactorPolicy(ctx *fiber.Ctx) error {
+	var req TwoFactorPolicy
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(
+			fiber.StatusBadRequest,
+			fmt.Errorf("ctx.BodyParser: %w", err).Error(),
+		)
+	}
+
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = h.accountService.SetTwoFactorPolicy(ctx.UserContext(), services.SetTwoFactorPolicyParams{
+		Token:  token,
+		Policy: models.TwoFactorPolicy(req),
+	})
+	if err != nil {
+		return fmt.Errorf("h.accountService.SetTwoFactorPolicy: %w", err)
+	}
+
+	return ctx.JSON(req)
+}
+
+type CreateServiceAccountRequest struct {
+	Username string `json:"username"`
+	Balance  int    `json:"balance"`
+}
+
+func (h *Handler) CreateServiceAccount(ctx *fiber.Ctx) error {
+	var req CreateServiceAccountRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(
+			fiber.StatusBadRequest,
+			fmt.Errorf("ctx.BodyParser: %w", err).Error(),
+		)
+	}
+
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = h.accountService.CreateServiceAccount(ctx.UserContext(), services.CreateServiceAccountParams{
+		Token:    token,
+		Username: req.Username,
+		Balance:  req.Balance,
+	})
+	if err != nil {
+		return fmt.Errorf("h.accountService.CreateServiceAccount: %w", err)
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(req)
+}
+
+type CreateAPIKeyRequest struct {
+	Scopes    []string  `json:"scopes"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type APIKey struct {
+	ID        string     `json:"id"`
+	Username  string     `json:"username"`
+	Scopes    []string   `json:"scopes"`
+	CreatedBy string     `json:"createdBy"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	RevokedAt *time.Time `json:"revokedAt"`
+}
+
+func newAPIKey(key models.APIKey) APIKey {
+	return APIKey{
+		ID:        key.ID,
+		Username:  key.Username,
+		Scopes:    key.Scopes,
+		CreatedBy: key.CreatedBy,
+		CreatedAt: key.CreatedAt,
+		ExpiresAt: key.ExpiresAt,
+		RevokedAt: key.RevokedAt,
+	}
+}
+
+type IssuedAPIKeyResponse struct {
+	APIKey
+	// Key is the secret to give to the integration; it is shown only once.
+	Key string `json:"key"`
+}
+
+func (h *Handler) CreateAPIKey(ctx *fiber.Ctx) error {
+	var req CreateAPIKeyRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(
+			fiber.StatusBadRequest,
+			fmt.Errorf("ctx.BodyParser: %w", err).Error(),
+		)
+	}
+
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	scopes := make([]services.Scope, len(req.Scopes))
+	for i, scope := range req.Scopes {
+		scopes[i] = services.Scope(scope)
+	}
+
+	issued, err := h.accountService.CreateAPIKey(ctx.UserContext(), services.CreateAPIKeyParams{
+		Token:     token,
+		Username:  ctx.Params("username"),
+		Scopes:    scopes,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("h.accountService.CreateAPIKey: %w", err)
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(IssuedAPIKeyResponse{
+		APIKey: newAPIKey(issued.APIKey),
+		Key:    issued.Key,
+	})
+}
+
+func (h *Handler) ListAPIKeys(ctx *fiber.Ctx) error {
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys, err := h.accountService.ListAPIKeys(ctx.UserContext(), services.ListAPIKeysParams{
+		Token:    token,
+		Username: ctx.Params("username"),
+	})
+	if err != nil {
+		return fmt.Errorf("h.accountService.ListAPIKeys: %w", err)
+	}
+
+	fKeys := make([]APIKey, len(keys))
+	for i, key := range keys {
+		fKeys[i] = newAPIKey(key)
+	}
+
+	return ctx.JSON(fiber.Map{
+		"keys": fKeys,
+	})
+}
+
+func (h *Handler) RevokeAPIKey(ctx *fiber.Ctx) error {
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = h.accountService.RevokeAPIKey(ctx.UserContext(), services.RevokeAPIKeyParams{
+		Token: token,
+		ID:    ctx.Params("id"),
+	})
+	if err != nil {
+		return fmt.Errorf("h.accountService.RevokeAPIKey: %w", err)
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+type ReloadedConfigResponse struct {
+	Applied         []string `json:"applied"`
+	RestartRequired []string `json:"restartRequired"`
+}
+
+func (h *Handler) ReloadConfig(ctx *fiber.Ctx) error {
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	reloaded, err := h.accountService.ReloadConfig(ctx.UserContext(), services.ReloadConfigParams{
+		Token: token,
+	})
+	if err != nil {
+		return fmt.Errorf("h.accountService.ReloadConfig: %w", err)
+	}
+
+	return ctx.JSON(ReloadedConfigResponse{
+		Applied:         append([]string{}, reloaded.Applied...),
+		RestartRequired: append([]string{}, reloaded.RestartRequired...),
+	})
+}
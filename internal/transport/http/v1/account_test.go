@@ -0,0 +1,304 @@
+package v1_test
+
+import (
+	"encoding/json"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"github.com/Blxssy/AvitoTest/internal/services/mocks"
+	"github.com/Blxssy/AvitoTest/internal/transport/http/middleware"
+	"github.com/Blxssy/AvitoTest/internal/transport/http/v1"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUnlockUserHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockAccountService(ctrl)
+
+	app := newApp()
+	handler := v1.NewHandler(v1.HandlerConfig{
+		AccountService: mockService,
+	})
+	handler.Init(app)
+
+	mockService.EXPECT().UnlockUser(gomock.Any(), services.UnlockUserParams{
+		Token:    "admin-token",
+		Username: "bob",
+	}).Return(nil)
+	mockService.EXPECT().UnlockUser(gomock.Any(), services.UnlockUserParams{
+		Token:    "user-token",
+		Username: "bob",
+	}).Return(services.AdminRequiredError)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/users/bob/unlock", nil)
+	req.Header.Set("Authorization", "Bearer admin-token")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/admin/users/bob/unlock", nil)
+	req.Header.Set("Authorization", "Bearer user-token")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestPasswordResetHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockAccountService(ctrl)
+
+	app := newApp()
+	handler := v1.NewHandler(v1.HandlerConfig{
+		AccountService: mockService,
+	})
+	handler.Init(app)
+
+	expiresAt := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	mockService.EXPECT().IssuePasswordReset(gomock.Any(), services.IssuePasswordResetParams{
+		Token:    "admin-token",
+		Username: "bob",
+	}).Return(services.PasswordReset{Token: "reset-token", ExpiresAt: expiresAt}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/users/bob/password-reset", nil)
+	req.Header.Set("Authorization", "Bearer admin-token")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var reset v1.PasswordResetResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&reset))
+	assert.Equal(t, "reset-token", reset.Token)
+	assert.True(t, expiresAt.Equal(reset.ExpiresAt))
+
+	mockService.EXPECT().ResetPassword(gomock.Any(), services.ResetPasswordParams{
+		ResetToken:  "used-token",
+		NewPassword: "new-password",
+	}).Return(services.InvalidResetTokenError)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/password/reset", strings.NewReader(`{"token":"used-token","newPassword":"new-password"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	var body middleware.ErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "invalid_reset_token", body.Code)
+}
+
+func TestTwoFactorHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockAccountService(ctrl)
+
+	app := newApp()
+	handler := v1.NewHandler(v1.HandlerConfig{
+		AccountService: mockService,
+	})
+	handler.Init(app)
+
+	mockService.EXPECT().EnrollTOTP(gomock.Any(), services.EnrollTOTPParams{Token: "user-token"}).
+		Return(services.TOTPEnrollment{Secret: "SECRET", URI: "otpauth://totp/AvitoShop:bob?secret=SECRET"}, nil)
+	mockService.EXPECT().ActivateTOTP(gomock.Any(), services.ActivateTOTPParams{Token: "user-token", Code: "123456"}).
+		Return([]string{"abcde-fghij"}, nil)
+	mockService.EXPECT().ActivateTOTP(gomock.Any(), services.ActivateTOTPParams{Token: "user-token", Code: "000000"}).
+		Return(nil, services.InvalidOTPCodeError)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/2fa/enroll", nil)
+	req.Header.Set("Authorization", "Bearer user-token")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var enrollment struct {
+		Secret string `json:"secret"`
+		URI    string `json:"uri"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&enrollment))
+	assert.Equal(t, "SECRET", enrollment.Secret)
+	assert.Equal(t, "otpauth://totp/AvitoShop:bob?secret=SECRET", enrollment.URI)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/2fa/activate", strings.NewReader(`{"code":"123456"}`))
+	req.Header.Set("Authorization", "Bearer user-token")
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var activated struct {
+		RecoveryCodes []string `json:"recoveryCodes"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&activated))
+	assert.Equal(t, []string{"abcde-fghij"}, activated.RecoveryCodes)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/2fa/activate", strings.NewReader(`{"code":"000000"}`))
+	req.Header.Set("Authorization", "Bearer user-token")
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	var body middleware.ErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "invalid_otp_code", body.Code)
+}
+
+func TestTwoFactorPolicyHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockAccountService(ctrl)
+
+	app := newApp()
+	handler := v1.NewHandler(v1.HandlerConfig{
+		AccountService: mockService,
+	})
+	handler.Init(app)
+
+	policy := models.TwoFactorPolicy{TransferThreshold: 500, PurchaseThreshold: 0}
+	mockService.EXPECT().SetTwoFactorPolicy(gomock.Any(), services.SetTwoFactorPolicyParams{
+		Token:  "admin-token",
+		Policy: policy,
+	}).Return(nil)
+	mockService.EXPECT().GetTwoFactorPolicy(gomock.Any(), services.GetTwoFactorPolicyParams{Token: "admin-token"}).
+		Return(policy, nil)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/admin/2fa-policy", strings.NewReader(`{"transferThreshold":500,"purchaseThreshold":0}`))
+	req.Header.Set("Authorization", "Bearer admin-token")
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/admin/2fa-policy", nil)
+	req.Header.Set("Authorization", "Bearer admin-token")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body v1.TwoFactorPolicy
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, v1.TwoFactorPolicy{TransferThreshold: 500}, body)
+}
+
+func TestAPIKeyHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockAccountService(ctrl)
+
+	app := newApp()
+	handler := v1.NewHandler(v1.HandlerConfig{
+		AccountService: mockService,
+	})
+	handler.Init(app)
+
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockService.EXPECT().CreateServiceAccount(gomock.Any(), services.CreateServiceAccountParams{
+		Token:    "admin-token",
+		Username: "billing-bot",
+		Balance:  100,
+	}).Return(nil)
+	mockService.EXPECT().CreateAPIKey(gomock.Any(), services.CreateAPIKeyParams{
+		Token:     "admin-token",
+		Username:  "billing-bot",
+		Scopes:    []services.Scope{services.ScopeTransfersWrite},
+		ExpiresAt: expiresAt,
+	}).Return(services.IssuedAPIKey{
+		Key: "avk_0123456789abcdef_secret",
+		APIKey: models.APIKey{
+			ID:        "0123456789abcdef",
+			Username:  "billing-bot",
+			Scopes:    []string{"transfers:write"},
+			CreatedBy: "alice",
+			ExpiresAt: expiresAt,
+		},
+	}, nil)
+	mockService.EXPECT().RevokeAPIKey(gomock.Any(), services.RevokeAPIKeyParams{
+		Token: "admin-token",
+		ID:    "unknown",
+	}).Return(services.APIKeyNotFoundError)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/service-accounts", strings.NewReader(`{"username":"billing-bot","balance":100}`))
+	req.Header.Set("Authorization", "Bearer admin-token")
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/admin/service-accounts/billing-bot/keys",
+		strings.NewReader(`{"scopes":["transfers:write"],"expiresAt":"2030-01-01T00:00:00Z"}`))
+	req.Header.Set("Authorization", "Bearer admin-token")
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var body v1.IssuedAPIKeyResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "avk_0123456789abcdef_secret", body.Key)
+	assert.Equal(t, "0123456789abcdef", body.ID)
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/admin/api-keys/unknown", nil)
+	req.Header.Set("Authorization", "Bearer admin-token")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestDelegationHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockAccountService(ctrl)
+
+	app := newApp()
+	handler := v1.NewHandler(v1.HandlerConfig{
+		AccountService: mockService,
+	})
+	handler.Init(app)
+
+	mockService.EXPECT().GrantDelegation(gomock.Any(), services.GrantDelegationParams{
+		Token:          "valid-token",
+		ClientUsername: "kudos-bot",
+		DailyLimit:     100,
+	}).Return(models.Delegation{ID: 1, OwnerUsername: "owner", ClientUsername: "kudos-bot", DailyLimit: 100}, nil)
+	mockService.EXPECT().RevokeDelegation(gomock.Any(), services.RevokeDelegationParams{
+		Token: "valid-token",
+		ID:    1,
+	}).Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/delegations", strings.NewReader(`{"client":"kudos-bot","dailyLimit":100}`))
+	req.Header.Set("Authorization", "Bearer valid-token")
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var body v1.Delegation
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, v1.Delegation{ID: 1, Client: "kudos-bot", DailyLimit: 100}, body)
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/delegations/1", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/delegations/abc", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
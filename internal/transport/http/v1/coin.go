@@ -1,11 +1,11 @@
 package v1
 
 import (
-	"errors"
 	"fmt"
 	"github.com/Blxssy/AvitoTest/internal/services"
 	"github.com/gofiber/fiber/v2"
 	"strings"
+	"time"
 )
 
 func (h *Handler) initCoinRoutes(router fiber.Router) {
@@ -13,9 +13,28 @@ func (h *Handler) initCoinRoutes(router fiber.Router) {
 	_ = coinRoute
 	{
 		coinRoute.Post("auth", h.Auth)
-		coinRoute.Post("sendCoin", h.Transaction)
-		coinRoute.Get("info", h.Info)
-		coinRoute.Get("buy/:item", h.BuyItem)
+		coinRoute.Post("auth/2fa", h.CompleteTwoFactorAuth)
+		coinRoute.Get("auth/oidc/login", h.OIDCLogin)
+		coinRoute.Get("auth/oidc/callback", h.OIDCCallback)
+		coinRoute.Post("sendCoin", deprecated("/v2/transfers"), h.Transaction)
+		coinRoute.Get("info", deprecated("/v2/me"), h.Info)
+		coinRoute.Get("buy/:item", deprecated("/v2/purchases"), h.BuyItem)
+	}
+}
+
+// deprecatedSince is when the coin routes were superseded by the v2 API.
+var deprecatedSince = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+// deprecated marks responses of a route replaced by successor with the
+// Deprecation (RFC 9745) and Link headers. The route keeps working.
+func deprecated(successor string) fiber.Handler {
+	deprecation := fmt.Sprintf("@%d", deprecatedSince.Unix())
+	link := fmt.Sprintf(`<%s>; rel="successor-version"`, successor)
+
+	return func(ctx *fiber.Ctx) error {
+		ctx.Set("Deprecation", deprecation)
+		ctx.Set(fiber.HeaderLink, link)
+		return ctx.Next()
 	}
 }
 
@@ -33,22 +52,83 @@ func (h *Handler) Auth(ctx *fiber.Ctx) error {
 		)
 	}
 
-	accessToken, err := h.coinService.Auth(ctx.Context(), services.AuthParams{
+	result, err := h.coinService.Auth(ctx.UserContext(), services.AuthParams{
 		Username: req.Username,
 		Password: req.Password,
+		ClientIP: ctx.IP(),
 	})
 	if err != nil {
-		if errors.Is(err, services.UnauthorizedError) {
-			return fiber.NewError(
-				fiber.StatusUnauthorized,
-				fmt.Errorf("unauthorized").Error())
-		}
+		return fmt.Errorf("h.coinService.Auth: %w", err)
+	}
+
+	return authResponse(ctx, result)
+}
+
+func authResponse(ctx *fiber.Ctx, result services.AuthResult) error {
+	if result.ChallengeToken != "" {
+		return ctx.JSON(fiber.Map{
+			"twoFactorRequired": true,
+			"challengeToken":    result.ChallengeToken,
+			"expiresAt":         result.ChallengeExpiresAt,
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"token": result.Token,
+	})
+}
+
+// OIDCLogin redirects the user to the identity provider, which sends them
+// back to OIDCCallback.
+func (h *Handler) OIDCLogin(ctx *fiber.Ctx) error {
+	loginURL, err := h.coinService.OIDCLoginURL(ctx.UserContext())
+	if err != nil {
+		return fmt.Errorf("h.coinService.OIDCLoginURL: %w", err)
+	}
+
+	return ctx.Redirect(loginURL, fiber.StatusFound)
+}
+
+func (h *Handler) OIDCCallback(ctx *fiber.Ctx) error {
+	// The user declined or the provider failed before issuing a code.
+	if providerErr := ctx.Query("error"); providerErr != "" {
+		return fmt.Errorf("identity provider returned %q: %w", providerErr, services.OIDCLoginFailedError)
+	}
+
+	result, err := h.coinService.OIDCCallback(ctx.UserContext(), services.OIDCCallbackParams{
+		Code:  ctx.Query("code"),
+		State: ctx.Query("state"),
+	})
+	if err != nil {
+		return fmt.Errorf("h.coinService.OIDCCallback: %w", err)
+	}
+
+	return authResponse(ctx, result)
+}
+
+type TwoFactorAuthRequest struct {
+	ChallengeToken string `json:"challengeToken"`
+	Code           string `json:"code"`
+}
+
+func (h *Handler) CompleteTwoFactorAuth(ctx *fiber.Ctx) error {
+	var req TwoFactorAuthRequest
+	if err := ctx.BodyParser(&req); err != nil {
 		return fiber.NewError(
-			fiber.StatusInternalServerError,
-			fmt.Errorf("h.coinService.Auth: %w", err).Error(),
+			fiber.StatusBadRequest,
+			fmt.Errorf("ctx.BodyParser: %w", err).Error(),
 		)
 	}
 
+	accessToken, err := h.coinService.CompleteTwoFactorAuth(ctx.UserContext(), services.TwoFactorAuthParams{
+		ChallengeToken: req.ChallengeToken,
+		Code:           req.Code,
+		ClientIP:       ctx.IP(),
+	})
+	if err != nil {
+		return fmt.Errorf("h.coinService.CompleteTwoFactorAuth: %w", err)
+	}
+
 	return ctx.JSON(fiber.Map{
 		"token": accessToken,
 	})
@@ -57,6 +137,9 @@ func (h *Handler) Auth(ctx *fiber.Ctx) error {
 type SendCoinRequest struct {
 	ReceiverUsername string `json:"toUser"`
 	Amount           int    `json:"amount"`
+	// OnBehalfOf lets a client with a delegation send from the owner's account.
+	OnBehalfOf string `json:"onBehalfOf"`
+	Memo       string `json:"memo"`
 }
 
 func (h *Handler) Transaction(ctx *fiber.Ctx) error {
@@ -73,22 +156,16 @@ func (h *Handler) Transaction(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	err = h.coinService.SendCoins(ctx.Context(), services.TransactionParams{
+	_, err = h.coinService.SendCoins(ctx.UserContext(), services.TransactionParams{
 		Token:            token,
 		ReceiverUsername: req.ReceiverUsername,
 		Amount:           req.Amount,
+		OTPCode:          ctx.Get(otpCodeHeader),
+		OnBehalfOf:       req.OnBehalfOf,
+		Memo:             req.Memo,
 	})
 	if err != nil {
-		if errors.Is(err, services.UnauthorizedError) {
-			return fiber.NewError(
-				fiber.StatusUnauthorized,
-				fmt.Errorf("unauthorized").Error(),
-			)
-		}
-		return fiber.NewError(
-			fiber.StatusInternalServerError,
-			fmt.Errorf("h.coinService.Transaction: %w", err).Error(),
-		)
+		return fmt.Errorf("h.coinService.SendCoins: %w", err)
 	}
 
 	return ctx.SendStatus(fiber.StatusOK)
@@ -100,21 +177,18 @@ func (h *Handler) Info(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	balance, err := h.coinService.GetBalance(ctx.Context(), services.GetBalanceParams{
+	balance, err := h.coinService.GetBalance(ctx.UserContext(), services.GetBalanceParams{
 		Token: token,
 	})
 	if err != nil {
-		if errors.Is(err, services.UnauthorizedError) {
-			return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
-		}
-		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.coinService.GetBalance: %v", err))
+		return fmt.Errorf("h.coinService.GetBalance: %w", err)
 	}
 
-	purchases, err := h.coinService.GetPurchases(ctx.Context(), services.GetPurchasesParams{
+	purchases, err := h.coinService.GetPurchases(ctx.UserContext(), services.GetPurchasesParams{
 		Token: token,
 	})
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.coinService.GetPurchases: %v", err))
+		return fmt.Errorf("h.coinService.GetPurchases: %w", err)
 	}
 
 	fInventory := make([]fiber.Map, len(purchases))
@@ -125,11 +199,11 @@ func (h *Handler) Info(ctx *fiber.Ctx) error {
 		}
 	}
 
-	sentCoins, err := h.coinService.SendCoinsInfo(ctx.Context(), services.GetTransactionsParams{
+	sentCoins, err := h.coinService.SendCoinsInfo(ctx.UserContext(), services.GetTransactionsParams{
 		Token: token,
 	})
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.coinService.GetTransactions: %v", err))
+		return fmt.Errorf("h.coinService.SendCoinsInfo: %w", err)
 	}
 
 	fSentCoins := make([]fiber.Map, len(sentCoins))
@@ -140,11 +214,11 @@ func (h *Handler) Info(ctx *fiber.Ctx) error {
 		}
 	}
 
-	receivedCoins, err := h.coinService.ReceivedCoinsInfo(ctx.Context(), services.GetTransactionsParams{
+	receivedCoins, err := h.coinService.ReceivedCoinsInfo(ctx.UserContext(), services.GetTransactionsParams{
 		Token: token,
 	})
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.coinService.ReceivedCoinsInfo: %v", err))
+		return fmt.Errorf("h.coinService.ReceivedCoinsInfo: %w", err)
 	}
 
 	fReceivedCoins := make([]fiber.Map, len(receivedCoins))
@@ -172,20 +246,22 @@ func (h *Handler) BuyItem(ctx *fiber.Ctx) error {
 	}
 	item := ctx.Params("item")
 
-	err = h.coinService.BuyItem(ctx.Context(), services.BuyItemParams{
-		Token: token,
-		Item:  item,
+	err = h.coinService.BuyItem(ctx.UserContext(), services.BuyItemParams{
+		Token:   token,
+		Item:    item,
+		OTPCode: ctx.Get(otpCodeHeader),
 	})
 	if err != nil {
-		if errors.Is(err, services.UnauthorizedError) {
-			return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
-		}
-		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.coinService.BuyItem: %v", err))
+		return fmt.Errorf("h.coinService.BuyItem: %w", err)
 	}
 
 	return ctx.SendStatus(fiber.StatusOK)
 }
 
+// otpCodeHeader carries the second factor for operations the 2FA policy
+// covers.
+const otpCodeHeader = "X-OTP-Code"
+
 func getToken(ctx *fiber.Ctx) (string, error) {
 	authHeader := ctx.Get("Authorization")
 	if authHeader == "" {
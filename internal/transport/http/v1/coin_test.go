@@ -1,8 +1,12 @@
 package v1_test
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/Blxssy/AvitoTest/internal/services"
 	"github.com/Blxssy/AvitoTest/internal/services/mocks"
+	"github.com/Blxssy/AvitoTest/internal/transport/http/middleware"
 	"github.com/Blxssy/AvitoTest/internal/transport/http/v1"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang/mock/gomock"
@@ -12,15 +16,22 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
+func newApp() *fiber.App {
+	return fiber.New(fiber.Config{
+		ErrorHandler: middleware.NewErrorHandler(middleware.ErrorHandlerConfig{}),
+	})
+}
+
 func TestAuthHandler(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockService := mocks.NewMockCoinService(ctrl)
 
-	app := fiber.New()
+	app := newApp()
 	handler := v1.NewHandler(v1.HandlerConfig{
 		CoinService: mockService,
 		Logger:      nil,
@@ -32,7 +43,8 @@ func TestAuthHandler(t *testing.T) {
 	mockService.EXPECT().Auth(gomock.Any(), services.AuthParams{
 		Username: "test",
 		Password: "123",
-	}).Return("valid_token", nil)
+		ClientIP: "0.0.0.0",
+	}).Return(services.AuthResult{Token: "valid_token"}, nil)
 
 	req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/api/auth", strings.NewReader(requestBody))
 	req.Header.Set("Content-Type", "application/json")
@@ -41,13 +53,93 @@ func TestAuthHandler(t *testing.T) {
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 }
 
+func TestAuthHandlerLocked(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockCoinService(ctrl)
+
+	app := newApp()
+	handler := v1.NewHandler(v1.HandlerConfig{
+		CoinService: mockService,
+		Logger:      nil,
+	})
+	handler.Init(app)
+
+	mockService.EXPECT().Auth(gomock.Any(), gomock.Any()).
+		Return(services.AuthResult{}, fmt.Errorf("s.logins.check: %w", services.AccountLockedError.WithRetryAfter(90*time.Second+time.Millisecond)))
+
+	req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/api/auth", strings.NewReader(`{"username":"test","password":"123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "91", resp.Header.Get("Retry-After"))
+
+	var body middleware.ErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "account_locked", body.Code)
+}
+
+func TestAuthHandlerTwoFactor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockCoinService(ctrl)
+
+	app := newApp()
+	handler := v1.NewHandler(v1.HandlerConfig{
+		CoinService: mockService,
+		Logger:      nil,
+	})
+	handler.Init(app)
+
+	expiresAt := time.Date(2026, time.October, 19, 12, 5, 0, 0, time.UTC)
+	mockService.EXPECT().Auth(gomock.Any(), gomock.Any()).
+		Return(services.AuthResult{ChallengeToken: "challenge", ChallengeExpiresAt: expiresAt}, nil)
+	mockService.EXPECT().CompleteTwoFactorAuth(gomock.Any(), services.TwoFactorAuthParams{
+		ChallengeToken: "challenge",
+		Code:           "123456",
+		ClientIP:       "0.0.0.0",
+	}).Return("valid_token", nil)
+
+	req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/api/auth", strings.NewReader(`{"username":"test","password":"123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var challenge struct {
+		TwoFactorRequired bool      `json:"twoFactorRequired"`
+		ChallengeToken    string    `json:"challengeToken"`
+		ExpiresAt         time.Time `json:"expiresAt"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&challenge))
+	assert.True(t, challenge.TwoFactorRequired)
+	assert.Equal(t, "challenge", challenge.ChallengeToken)
+	assert.True(t, expiresAt.Equal(challenge.ExpiresAt))
+
+	req = httptest.NewRequest(http.MethodPost, "http://localhost:8080/api/auth/2fa", strings.NewReader(`{"challengeToken":"challenge","code":"123456"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "valid_token", body.Token)
+}
+
 func TestBuyItemHandler(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockService := mocks.NewMockCoinService(ctrl)
 
-	app := fiber.New()
+	app := newApp()
 	handler := v1.NewHandler(v1.HandlerConfig{
 		CoinService: mockService,
 		Logger:      nil,
@@ -67,6 +159,8 @@ func TestBuyItemHandler(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "@1792368000", resp.Header.Get("Deprecation"))
+	assert.Equal(t, `</v2/purchases>; rel="successor-version"`, resp.Header.Get("Link"))
 }
 
 func TestSendCoinsHandler(t *testing.T) {
@@ -75,7 +169,7 @@ func TestSendCoinsHandler(t *testing.T) {
 
 	mockService := mocks.NewMockCoinService(ctrl)
 
-	app := fiber.New()
+	app := newApp()
 	handler := v1.NewHandler(v1.HandlerConfig{
 		CoinService: mockService,
 		Logger:      nil,
@@ -88,7 +182,7 @@ func TestSendCoinsHandler(t *testing.T) {
 		Token:            "valid_token",
 		ReceiverUsername: "Bill",
 		Amount:           100,
-	}).Return(nil)
+	}).Return(int64(1), nil)
 
 	req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/api/sendCoin", strings.NewReader(requestBody))
 	req.Header.Set("Authorization", "Bearer valid_token")
@@ -96,4 +190,95 @@ func TestSendCoinsHandler(t *testing.T) {
 	resp, _ := app.Test(req)
 
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	mockService.EXPECT().SendCoins(gomock.Any(), services.TransactionParams{
+		Token:            "valid_token",
+		ReceiverUsername: "Bill",
+		Amount:           100,
+		OTPCode:          "123456",
+	}).Return(int64(1), nil)
+
+	req = httptest.NewRequest(http.MethodPost, "http://localhost:8080/api/sendCoin", strings.NewReader(requestBody))
+	req.Header.Set("Authorization", "Bearer valid_token")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-OTP-Code", "123456")
+	resp, _ = app.Test(req)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestSendCoinsHandlerErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockCoinService(ctrl)
+
+	app := newApp()
+	handler := v1.NewHandler(v1.HandlerConfig{
+		CoinService: mockService,
+		Logger:      nil,
+	})
+	handler.Init(app)
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		body   middleware.ErrorResponse
+	}{
+		{
+			name:   "insufficient funds",
+			err:    services.InsufficientFundsError,
+			status: http.StatusConflict,
+			body:   middleware.ErrorResponse{Errors: "insufficient funds", Code: "insufficient_funds"},
+		},
+		{
+			name:   "receiver not found",
+			err:    services.ReceiverNotFoundError,
+			status: http.StatusNotFound,
+			body:   middleware.ErrorResponse{Errors: "receiver not found", Code: "receiver_not_found"},
+		},
+		{
+			name:   "second factor required",
+			err:    services.OTPRequiredError,
+			status: http.StatusForbidden,
+			body:   middleware.ErrorResponse{Errors: "two-factor code required for this operation", Code: "otp_required"},
+		},
+		{
+			name:   "self transfer",
+			err:    services.SelfTransferError,
+			status: http.StatusUnprocessableEntity,
+			body:   middleware.ErrorResponse{Errors: "cannot send coins to yourself", Code: "self_transfer"},
+		},
+		{
+			name:   "expired token",
+			err:    fmt.Errorf("s.tokenGen.ParseToken: %w: %w", services.InvalidTokenError, errors.New("token is expired")),
+			status: http.StatusUnauthorized,
+			body:   middleware.ErrorResponse{Errors: "invalid or expired token", Code: "invalid_token"},
+		},
+		{
+			name:   "internal error",
+			err:    fmt.Errorf("s.repo.GetBalance: %w", errors.New(`pq: relation "users" does not exist`)),
+			status: http.StatusInternalServerError,
+			body:   middleware.ErrorResponse{Errors: "internal error", Code: "internal"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.EXPECT().SendCoins(gomock.Any(), gomock.Any()).Return(int64(0), tt.err)
+
+			req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/api/sendCoin", strings.NewReader(`{"toUser": "Bill", "amount": 100}`))
+			req.Header.Set("Authorization", "Bearer valid_token")
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			var body middleware.ErrorResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			assert.Equal(t, tt.body, body)
+		})
+	}
 }
@@ -0,0 +1,112 @@
+package v1
+
+import (
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"time"
+)
+
+func (h *Handler) initDelegationRoutes(router fiber.Router) {
+	delegationRoute := router.Group("/api")
+	{
+		delegationRoute.Get("delegations", h.ListDelegations)
+		delegationRoute.Post("delegations", h.GrantDelegation)
+		delegationRoute.Delete("delegations/:id", h.RevokeDelegation)
+	}
+}
+
+type Delegation struct {
+	ID         int64     `json:"id"`
+	Client     string    `json:"client"`
+	DailyLimit int       `json:"dailyLimit"`
+	SpentToday int       `json:"spentToday"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+func newDelegation(delegation models.Delegation) Delegation {
+	return Delegation{
+		ID:         delegation.ID,
+		Client:     delegation.ClientUsername,
+		DailyLimit: delegation.DailyLimit,
+		SpentToday: delegation.SpentToday,
+		CreatedAt:  delegation.CreatedAt,
+	}
+}
+
+type GrantDelegationRequest struct {
+	Client     string `json:"client"`
+	DailyLimit int    `json:"dailyLimit"`
+}
+
+func (h *Handler) GrantDelegation(ctx *fiber.Ctx) error {
+	var req GrantDelegationRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(
+			fiber.StatusBadRequest,
+			fmt.Errorf("ctx.BodyParser: %w", err).Error(),
+		)
+	}
+
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	delegation, err := h.accountService.GrantDelegation(ctx.UserContext(), services.GrantDelegationParams{
+		Token:          token,
+		ClientUsername: req.Client,
+		DailyLimit:     req.DailyLimit,
+	})
+	if err != nil {
+		return fmt.Errorf("h.accountService.GrantDelegation: %w", err)
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(newDelegation(delegation))
+}
+
+func (h *Handler) ListDelegations(ctx *fiber.Ctx) error {
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	delegations, err := h.accountService.ListDelegations(ctx.UserContext(), services.ListDelegationsParams{
+		Token: token,
+	})
+	if err != nil {
+		return fmt.Errorf("h.accountService.ListDelegations: %w", err)
+	}
+
+	fDelegations := make([]Delegation, len(delegations))
+	for i, delegation := range delegations {
+		fDelegations[i] = newDelegation(delegation)
+	}
+
+	return ctx.JSON(fiber.Map{
+		"delegations": fDelegations,
+	})
+}
+
+func (h *Handler) RevokeDelegation(ctx *fiber.Ctx) error {
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid delegation id")
+	}
+
+	err = h.accountService.RevokeDelegation(ctx.UserContext(), services.RevokeDelegationParams{
+		Token: token,
+		ID:    int64(id),
+	})
+	if err != nil {
+		return fmt.Errorf("h.accountService.RevokeDelegation: %w", err)
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,257 @@
+package v1
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+const (
+	wsWriteWait  = 10 * time.Second
+	wsPongWait   = 60 * time.Second
+	wsPingPeriod = wsPongWait * 9 / 10
+
+	sseHeartbeatPeriod = 15 * time.Second
+	sseRetry           = 3 * time.Second
+
+	eventStreamLocal = "eventStream"
+	eventLoggerLocal = "eventLogger"
+)
+
+func (h *Handler) initEventRoutes(router fiber.Router) {
+	eventRoute := router.Group("/api")
+	{
+		eventRoute.Get("ws", h.UpgradeEvents, websocket.New(h.EventsWS))
+		eventRoute.Get("events", h.EventsSSE)
+	}
+}
+
+// UpgradeEvents authenticates the client and opens its event stream before the
+// connection is upgraded, so auth failures are reported as plain HTTP errors.
+// Browsers cannot set headers on WebSocket requests, so the token may also be
+// passed as the access_token query parameter.
+func (h *Handler) UpgradeEvents(ctx *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(ctx) {
+		return fiber.ErrUpgradeRequired
+	}
+
+	token := ctx.Query("access_token")
+	if token == "" {
+		var err error
+		if token, err = getToken(ctx); err != nil {
+			return err
+		}
+	}
+
+	lastEventID, err := parseLastEventID(ctx.Query("lastEventId"))
+	if err != nil {
+		return err
+	}
+
+	stream, err := h.eventService.Subscribe(ctx.UserContext(), services.SubscribeParams{
+		Token:       token,
+		LastEventID: lastEventID,
+	})
+	if err != nil {
+		return fmt.Errorf("h.eventService.Subscribe: %w", err)
+	}
+
+	ctx.Locals(eventStreamLocal, stream)
+	ctx.Locals(eventLoggerLocal, h.log(ctx))
+	if err = ctx.Next(); err != nil {
+		stream.Close()
+		return err
+	}
+
+	return nil
+}
+
+// EventsWS pushes the stream to the client as JSON text frames and keeps the
+// connection alive with pings. When the stream ends the connection is closed
+// with 1013 (try again later); the client should reconnect passing the ID of
+// the last event it received as lastEventId.
+func (h *Handler) EventsWS(conn *websocket.Conn) {
+	stream := conn.Locals(eventStreamLocal).(*services.EventStream)
+	defer stream.Close()
+	log := conn.Locals(eventLoggerLocal).(*zap.Logger)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		readPump(conn)
+	}()
+
+	for _, event := range stream.Backlog {
+		if err := writeEvent(conn, event); err != nil {
+			return
+		}
+	}
+
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-stream.Events():
+			if !ok {
+				log.Info("event stream lagged, closing websocket")
+				writeClose(conn, websocket.CloseTryAgainLater, "event stream lagged, resume from last event")
+				return
+			}
+			if stream.Replayed(event) {
+				continue
+			}
+			if err := writeEvent(conn, event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// EventsSSE is a Server-Sent Events fallback for clients behind proxies that
+// drop WebSockets. EventSource reconnects on its own and sends the last
+// received id back in the Last-Event-ID header, which resumes the stream.
+func (h *Handler) EventsSSE(ctx *fiber.Ctx) error {
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	lastEventID, err := parseLastEventID(ctx.Get("Last-Event-ID"))
+	if err != nil {
+		return err
+	}
+
+	stream, err := h.eventService.Subscribe(ctx.UserContext(), services.SubscribeParams{
+		Token:       token,
+		LastEventID: lastEventID,
+	})
+	if err != nil {
+		return fmt.Errorf("h.eventService.Subscribe: %w", err)
+	}
+
+	ctx.Set(fiber.HeaderContentType, "text/event-stream")
+	ctx.Set(fiber.HeaderCacheControl, "no-cache")
+	ctx.Set(fiber.HeaderConnection, "keep-alive")
+	ctx.Set("X-Accel-Buffering", "no")
+
+	log := h.log(ctx)
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer stream.Close()
+
+		if _, err := fmt.Fprintf(w, "retry: %d\n\n", sseRetry.Milliseconds()); err != nil {
+			return
+		}
+		for _, event := range stream.Backlog {
+			if err := writeSSEEvent(w, event); err != nil {
+				return
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		ticker := time.NewTicker(sseHeartbeatPeriod)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case event, ok := <-stream.Events():
+				if !ok {
+					log.Info("event stream lagged, closing sse stream")
+					return
+				}
+				if stream.Replayed(event) {
+					continue
+				}
+				if err := writeSSEEvent(w, event); err != nil {
+					return
+				}
+			case <-ticker.C:
+				if _, err := w.WriteString(": ping\n\n"); err != nil {
+					return
+				}
+			}
+			// A failed flush means the client has gone away.
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+func writeSSEEvent(w *bufio.Writer, event models.Event) error {
+	message, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, message)
+	return err
+}
+
+// readPump consumes client frames so control frames are processed, and returns
+// once the client goes away or stops answering pings.
+func readPump(conn *websocket.Conn) {
+	if err := conn.SetReadDeadline(time.Now().Add(wsPongWait)); err != nil {
+		return
+	}
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+func writeEvent(conn *websocket.Conn, event models.Event) error {
+	message, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	if err = conn.SetWriteDeadline(time.Now().Add(wsWriteWait)); err != nil {
+		return err
+	}
+	return conn.WriteMessage(websocket.TextMessage, message)
+}
+
+func writeClose(conn *websocket.Conn, code int, text string) {
+	if err := conn.WriteControl(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(code, text),
+		time.Now().Add(wsWriteWait),
+	); err != nil {
+		return
+	}
+}
+
+func parseLastEventID(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return 0, fiber.NewError(fiber.StatusBadRequest, "invalid last event id")
+	}
+	return id, nil
+}
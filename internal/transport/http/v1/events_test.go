@@ -0,0 +1,70 @@
+package v1_test
+
+import (
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"github.com/Blxssy/AvitoTest/internal/services/mocks"
+	"github.com/Blxssy/AvitoTest/internal/transport/http/v1"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEventsSSEHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockEventService(ctrl)
+
+	app := newApp()
+	handler := v1.NewHandler(v1.HandlerConfig{
+		EventService: mockService,
+		Logger:       nil,
+	})
+	handler.Init(app)
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/api/events", nil)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	req = httptest.NewRequest(http.MethodGet, "http://localhost:8080/api/events", nil)
+	req.Header.Set("Authorization", "Bearer valid_token")
+	req.Header.Set("Last-Event-ID", "abc")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	mockService.EXPECT().Subscribe(gomock.Any(), services.SubscribeParams{
+		Token:       "expired_token",
+		LastEventID: 42,
+	}).Return(nil, fmt.Errorf("s.tokenGen.ParseToken: %w", services.UnauthorizedError))
+
+	req = httptest.NewRequest(http.MethodGet, "http://localhost:8080/api/events", nil)
+	req.Header.Set("Authorization", "Bearer expired_token")
+	req.Header.Set("Last-Event-ID", "42")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestEventsWSRequiresUpgrade(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	app := newApp()
+	handler := v1.NewHandler(v1.HandlerConfig{
+		EventService: mocks.NewMockEventService(ctrl),
+		Logger:       nil,
+	})
+	handler.Init(app)
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/api/ws", nil)
+	req.Header.Set("Authorization", "Bearer valid_token")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUpgradeRequired, resp.StatusCode)
+}
@@ -0,0 +1,140 @@
+package v1
+
+import (
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"time"
+)
+
+// initFeatureRoutes registers nothing without a feature flag service.
+func (h *Handler) initFeatureRoutes(router fiber.Router) {
+	if h.featureFlagService == nil {
+		return
+	}
+
+	featureRoute := router.Group("/api")
+	{
+		featureRoute.Get("features", h.GetFeatures)
+		featureRoute.Get("admin/feature-flags", h.ListFeatureFlags)
+		featureRoute.Put("admin/feature-flags/:name", h.SetFeatureFlag)
+		featureRoute.Delete("admin/feature-flags/:name", h.DeleteFeatureFlag)
+	}
+}
+
+type FeatureFlag struct {
+	Name           string    `json:"name"`
+	Description    string    `json:"description"`
+	Enabled        bool      `json:"enabled"`
+	RolloutPercent int       `json:"rolloutPercent"`
+	AllowList      []string  `json:"allowList"`
+	UpdatedBy      string    `json:"updatedBy"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+func newFeatureFlag(flag models.FeatureFlag) FeatureFlag {
+	return FeatureFlag{
+		Name:           flag.Name,
+		Description:    flag.Description,
+		Enabled:        flag.Enabled,
+		RolloutPercent: flag.RolloutPercent,
+		AllowList:      append([]string{}, flag.AllowList...),
+		UpdatedBy:      flag.UpdatedBy,
+		UpdatedAt:      flag.UpdatedAt,
+	}
+}
+
+func (h *Handler) GetFeatures(ctx *fiber.Ctx) error {
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	features, err := h.featureFlagService.GetFeatures(ctx.UserContext(), services.GetFeaturesParams{
+		Token: token,
+	})
+	if err != nil {
+		return fmt.Errorf("h.featureFlagService.GetFeatures: %w", err)
+	}
+
+	return ctx.JSON(fiber.Map{
+		"features": features,
+	})
+}
+
+func (h *Handler) ListFeatureFlags(ctx *fiber.Ctx) error {
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	flags, err := h.featureFlagService.ListFeatureFlags(ctx.UserContext(), services.ListFeatureFlagsParams{
+		Token: token,
+	})
+	if err != nil {
+		return fmt.Errorf("h.featureFlagService.ListFeatureFlags: %w", err)
+	}
+
+	fFlags := make([]FeatureFlag, len(flags))
+	for i, flag := range flags {
+		fFlags[i] = newFeatureFlag(flag)
+	}
+
+	return ctx.JSON(fiber.Map{
+		"flags": fFlags,
+	})
+}
+
+type SetFeatureFlagRequest struct {
+	Description    string   `json:"description"`
+	Enabled        bool     `json:"enabled"`
+	RolloutPercent int      `json:"rolloutPercent"`
+	AllowList      []string `json:"allowList"`
+}
+
+func (h *Handler) SetFeatureFlag(ctx *fiber.Ctx) error {
+	var req SetFeatureFlagRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(
+			fiber.StatusBadRequest,
+			fmt.Errorf("ctx.BodyParser: %w", err).Error(),
+		)
+	}
+
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	flag, err := h.featureFlagService.SetFeatureFlag(ctx.UserContext(), services.SetFeatureFlagParams{
+		Token:          token,
+		Name:           ctx.Params("name"),
+		Description:    req.Description,
+		Enabled:        req.Enabled,
+		RolloutPercent: req.RolloutPercent,
+		AllowList:      req.AllowList,
+	})
+	if err != nil {
+		return fmt.Errorf("h.featureFlagService.SetFeatureFlag: %w", err)
+	}
+
+	return ctx.JSON(newFeatureFlag(flag))
+}
+
+func (h *Handler) DeleteFeatureFlag(ctx *fiber.Ctx) error {
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = h.featureFlagService.DeleteFeatureFlag(ctx.UserContext(), services.DeleteFeatureFlagParams{
+		Token: token,
+		Name:  ctx.Params("name"),
+	})
+	if err != nil {
+		return fmt.Errorf("h.featureFlagService.DeleteFeatureFlag: %w", err)
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,51 @@
+package v1_test
+
+import (
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"github.com/Blxssy/AvitoTest/internal/services/mocks"
+	"github.com/Blxssy/AvitoTest/internal/transport/http/v1"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetFeaturesHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockFeatureFlagService(ctrl)
+
+	app := newApp()
+	handler := v1.NewHandler(v1.HandlerConfig{
+		FeatureFlagService: mockService,
+	})
+	handler.Init(app)
+
+	mockService.EXPECT().GetFeatures(gomock.Any(), services.GetFeaturesParams{Token: "valid_token"}).
+		Return([]string{"new-checkout"}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/api/features", nil)
+	req.Header.Set("Authorization", "Bearer valid_token")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.JSONEq(t, `{"features":["new-checkout"]}`, string(body))
+}
+
+func TestFeatureRoutesNeedService(t *testing.T) {
+	app := newApp()
+	handler := v1.NewHandler(v1.HandlerConfig{})
+	handler.Init(app)
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/api/features", nil)
+	req.Header.Set("Authorization", "Bearer valid_token")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
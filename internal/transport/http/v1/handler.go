@@ -2,27 +2,55 @@ package v1
 
 import (
 	"github.com/Blxssy/AvitoTest/internal/services"
+	"github.com/Blxssy/AvitoTest/pkg/logger"
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 )
 
 type Handler struct {
-	coinService services.CoinService
-	logger      *zap.Logger
+	coinService         services.CoinService
+	eventService        services.EventService
+	notificationService services.NotificationService
+	accountService      services.AccountService
+	featureFlagService  services.FeatureFlagService
+	logger              *zap.Logger
 }
 
 type HandlerConfig struct {
-	CoinService services.CoinService
-	Logger      *zap.Logger
+	CoinService         services.CoinService
+	EventService        services.EventService
+	NotificationService services.NotificationService
+	AccountService      services.AccountService
+	FeatureFlagService  services.FeatureFlagService
+	Logger              *zap.Logger
 }
 
 func NewHandler(cfg HandlerConfig) *Handler {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
 	return &Handler{
-		logger:      cfg.Logger,
-		coinService: cfg.CoinService,
+		logger:              logger,
+		coinService:         cfg.CoinService,
+		eventService:        cfg.EventService,
+		notificationService: cfg.NotificationService,
+		accountService:      cfg.AccountService,
+		featureFlagService:  cfg.FeatureFlagService,
 	}
 }
 
 func (h *Handler) Init(router fiber.Router) {
 	h.initCoinRoutes(router)
+	h.initEventRoutes(router)
+	h.initNotificationRoutes(router)
+	h.initAccountRoutes(router)
+	h.initDelegationRoutes(router)
+	h.initFeatureRoutes(router)
+}
+
+// log returns the logger of the request, set up by the access log middleware.
+func (h *Handler) log(ctx *fiber.Ctx) *zap.Logger {
+	return logger.FromContext(ctx.UserContext(), h.logger)
 }
@@ -0,0 +1,241 @@
+package v1
+
+import (
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+)
+
+func (h *Handler) initNotificationRoutes(router fiber.Router) {
+	notificationRoute := router.Group("/api")
+	{
+		notificationRoute.Get("notifications", h.GetNotifications)
+		notificationRoute.Post("notifications/read-all", h.MarkAllNotificationsRead)
+		notificationRoute.Post("notifications/:id/read", h.MarkNotificationRead)
+		notificationRoute.Get("notifications/preferences", h.GetNotificationPreferences)
+		notificationRoute.Put("notifications/preferences", h.UpdateNotificationPreferences)
+		notificationRoute.Put("notifications/contact", h.UpdateNotificationContact)
+		notificationRoute.Post("admin/notifications", h.SendAdminMessage)
+	}
+}
+
+func (h *Handler) GetNotifications(ctx *fiber.Ctx) error {
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	var cursor int64
+	if value := ctx.Query("cursor"); value != "" {
+		if cursor, err = strconv.ParseInt(value, 10, 64); err != nil || cursor < 0 {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid cursor")
+		}
+	}
+
+	page, err := h.notificationService.GetNotifications(ctx.UserContext(), services.GetNotificationsParams{
+		Token:      token,
+		Cursor:     cursor,
+		Limit:      ctx.QueryInt("limit"),
+		UnreadOnly: ctx.QueryBool("unread"),
+	})
+	if err != nil {
+		return fmt.Errorf("h.notificationService.GetNotifications: %w", err)
+	}
+
+	fNotifications := make([]fiber.Map, len(page.Notifications))
+	for i, n := range page.Notifications {
+		fNotifications[i] = fiber.Map{
+			"id":        n.ID,
+			"type":      n.Type,
+			"payload":   n.Payload,
+			"read":      n.ReadAt != nil,
+			"createdAt": n.CreatedAt,
+		}
+	}
+
+	var nextCursor any
+	if page.NextCursor != 0 {
+		nextCursor = page.NextCursor
+	}
+
+	return ctx.JSON(fiber.Map{
+		"notifications": fNotifications,
+		"unreadCount":   page.UnreadCount,
+		"nextCursor":    nextCursor,
+	})
+}
+
+func (h *Handler) MarkNotificationRead(ctx *fiber.Ctx) error {
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid notification id")
+	}
+
+	err = h.notificationService.MarkRead(ctx.UserContext(), services.MarkNotificationReadParams{
+		Token: token,
+		ID:    int64(id),
+	})
+	if err != nil {
+		return fmt.Errorf("h.notificationService.MarkRead: %w", err)
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+func (h *Handler) MarkAllNotificationsRead(ctx *fiber.Ctx) error {
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	updated, err := h.notificationService.MarkAllRead(ctx.UserContext(), services.MarkAllNotificationsReadParams{
+		Token: token,
+	})
+	if err != nil {
+		return fmt.Errorf("h.notificationService.MarkAllRead: %w", err)
+	}
+
+	return ctx.JSON(fiber.Map{
+		"updated": updated,
+	})
+}
+
+func (h *Handler) GetNotificationPreferences(ctx *fiber.Ctx) error {
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	preferences, err := h.notificationService.GetPreferences(ctx.UserContext(), services.GetNotificationPreferencesParams{
+		Token: token,
+	})
+	if err != nil {
+		return fmt.Errorf("h.notificationService.GetPreferences: %w", err)
+	}
+
+	return ctx.JSON(fiber.Map{
+		"preferences": preferencesResponse(preferences),
+	})
+}
+
+type NotificationPreference struct {
+	Type  string `json:"type"`
+	Muted bool   `json:"muted"`
+}
+
+type UpdateNotificationPreferencesRequest struct {
+	Preferences []NotificationPreference `json:"preferences"`
+}
+
+func (h *Handler) UpdateNotificationPreferences(ctx *fiber.Ctx) error {
+	var req UpdateNotificationPreferencesRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(
+			fiber.StatusBadRequest,
+			fmt.Errorf("ctx.BodyParser: %w", err).Error(),
+		)
+	}
+
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	preferences := make([]models.NotificationPreference, len(req.Preferences))
+	for i, pref := range req.Preferences {
+		preferences[i] = models.NotificationPreference{
+			Type:  models.NotificationType(pref.Type),
+			Muted: pref.Muted,
+		}
+	}
+
+	err = h.notificationService.UpdatePreferences(ctx.UserContext(), services.UpdateNotificationPreferencesParams{
+		Token:       token,
+		Preferences: preferences,
+	})
+	if err != nil {
+		return fmt.Errorf("h.notificationService.UpdatePreferences: %w", err)
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+type UpdateNotificationContactRequest struct {
+	Email  string `json:"email"`
+	Locale string `json:"locale"`
+}
+
+func (h *Handler) UpdateNotificationContact(ctx *fiber.Ctx) error {
+	var req UpdateNotificationContactRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(
+			fiber.StatusBadRequest,
+			fmt.Errorf("ctx.BodyParser: %w", err).Error(),
+		)
+	}
+
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = h.notificationService.UpdateContact(ctx.UserContext(), services.UpdateNotificationContactParams{
+		Token:  token,
+		Email:  req.Email,
+		Locale: req.Locale,
+	})
+	if err != nil {
+		return fmt.Errorf("h.notificationService.UpdateContact: %w", err)
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+type AdminMessageRequest struct {
+	ReceiverUsername string `json:"toUser"`
+	Message          string `json:"message"`
+}
+
+func (h *Handler) SendAdminMessage(ctx *fiber.Ctx) error {
+	var req AdminMessageRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(
+			fiber.StatusBadRequest,
+			fmt.Errorf("ctx.BodyParser: %w", err).Error(),
+		)
+	}
+
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = h.notificationService.SendAdminMessage(ctx.UserContext(), services.AdminMessageParams{
+		Token:            token,
+		ReceiverUsername: req.ReceiverUsername,
+		Message:          req.Message,
+	})
+	if err != nil {
+		return fmt.Errorf("h.notificationService.SendAdminMessage: %w", err)
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+func preferencesResponse(preferences []models.NotificationPreference) []fiber.Map {
+	fPreferences := make([]fiber.Map, len(preferences))
+	for i, pref := range preferences {
+		fPreferences[i] = fiber.Map{
+			"type":  pref.Type,
+			"muted": pref.Muted,
+		}
+	}
+	return fPreferences
+}
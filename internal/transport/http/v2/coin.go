@@ -0,0 +1,260 @@
+package v2
+
+import (
+	"cmp"
+	"fmt"
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"slices"
+	"time"
+)
+
+const (
+	directionSent     = "sent"
+	directionReceived = "received"
+)
+
+// otpCodeHeader carries the second factor for transfers and purchases the
+// 2FA policy covers.
+const otpCodeHeader = "X-OTP-Code"
+
+func (h *Handler) initCoinRoutes(router fiber.Router) {
+	coinRoute := router.Group("/v2")
+	{
+		coinRoute.Get("me", h.GetMe)
+		coinRoute.Get("me/inventory", h.GetInventory)
+		coinRoute.Get("me/transfers", h.GetTransfers)
+		coinRoute.Get("me/transfers/:id", h.GetTransfer)
+		coinRoute.Post("transfers", h.CreateTransfer)
+		coinRoute.Post("purchases", h.CreatePurchase)
+	}
+}
+
+type Me struct {
+	Coins int `json:"coins"`
+}
+
+func (h *Handler) GetMe(ctx *fiber.Ctx) error {
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	balance, err := h.coinService.GetBalance(ctx.UserContext(), services.GetBalanceParams{
+		Token: token,
+	})
+	if err != nil {
+		return fmt.Errorf("h.coinService.GetBalance: %w", err)
+	}
+
+	return ctx.JSON(Response{Data: Me{Coins: balance}})
+}
+
+type InventoryItem struct {
+	Item     string `json:"item"`
+	Quantity int    `json:"quantity"`
+}
+
+func (h *Handler) GetInventory(ctx *fiber.Ctx) error {
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	purchases, err := h.coinService.GetPurchases(ctx.UserContext(), services.GetPurchasesParams{
+		Token: token,
+	})
+	if err != nil {
+		return fmt.Errorf("h.coinService.GetPurchases: %w", err)
+	}
+
+	inventory := make([]InventoryItem, len(purchases))
+	for i, p := range purchases {
+		inventory[i] = InventoryItem{Item: p.Item, Quantity: p.Count}
+	}
+
+	return ctx.JSON(Response{Data: inventory})
+}
+
+type Transfer struct {
+	ID        uint32 `json:"id"`
+	Direction string `json:"direction"`
+	FromUser  string `json:"fromUser"`
+	ToUser    string `json:"toUser"`
+	Amount    int    `json:"amount"`
+	// Actor is the client that sent the coins on FromUser's behalf.
+	Actor     string    `json:"actor,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// GetTransfers lists the caller's transfers, newest first. The direction
+// query parameter narrows the list to "sent" or "received" transfers.
+func (h *Handler) GetTransfers(ctx *fiber.Ctx) error {
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	direction := ctx.Query("direction")
+	if direction != "" && direction != directionSent && direction != directionReceived {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid direction")
+	}
+
+	params := services.GetTransactionsParams{
+		Token: token,
+	}
+	transfers := make([]Transfer, 0)
+
+	if direction != directionReceived {
+		sent, err := h.coinService.SendCoinsInfo(ctx.UserContext(), params)
+		if err != nil {
+			return fmt.Errorf("h.coinService.SendCoinsInfo: %w", err)
+		}
+		transfers = appendTransfers(transfers, directionSent, sent)
+	}
+
+	if direction != directionSent {
+		received, err := h.coinService.ReceivedCoinsInfo(ctx.UserContext(), params)
+		if err != nil {
+			return fmt.Errorf("h.coinService.ReceivedCoinsInfo: %w", err)
+		}
+		transfers = appendTransfers(transfers, directionReceived, received)
+	}
+
+	slices.SortFunc(transfers, func(a, b Transfer) int {
+		return cmp.Compare(b.ID, a.ID)
+	})
+
+	return ctx.JSON(Response{Data: transfers})
+}
+
+// GetTransfer returns one of the caller's transfers.
+func (h *Handler) GetTransfer(ctx *fiber.Ctx) error {
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid transfer id")
+	}
+
+	transfer, err := h.coinService.GetTransfer(ctx.UserContext(), services.GetTransferParams{
+		Token: token,
+		ID:    int64(id),
+	})
+	if err != nil {
+		return fmt.Errorf("h.coinService.GetTransfer: %w", err)
+	}
+
+	direction := directionSent
+	if transfer.Received {
+		direction = directionReceived
+	}
+	return ctx.JSON(Response{Data: newTransfer(direction, transfer.Transaction)})
+}
+
+type CreateTransferRequest struct {
+	ToUser     string `json:"toUser"`
+	Amount     int    `json:"amount"`
+	OnBehalfOf string `json:"onBehalfOf"`
+	Memo       string `json:"memo"`
+}
+
+type CreatedTransfer struct {
+	ID     int64  `json:"id"`
+	ToUser string `json:"toUser"`
+	Amount int    `json:"amount"`
+}
+
+func (h *Handler) CreateTransfer(ctx *fiber.Ctx) error {
+	var req CreateTransferRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(
+			fiber.StatusBadRequest,
+			fmt.Errorf("ctx.BodyParser: %w", err).Error(),
+		)
+	}
+
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	id, err := h.coinService.SendCoins(ctx.UserContext(), services.TransactionParams{
+		Token:            token,
+		ReceiverUsername: req.ToUser,
+		Amount:           req.Amount,
+		OTPCode:          ctx.Get(otpCodeHeader),
+		OnBehalfOf:       req.OnBehalfOf,
+		Memo:             req.Memo,
+	})
+	if err != nil {
+		return fmt.Errorf("h.coinService.SendCoins: %w", err)
+	}
+
+	ctx.Location(fmt.Sprintf("/v2/me/transfers/%d", id))
+	return ctx.Status(fiber.StatusCreated).JSON(Response{Data: CreatedTransfer{
+		ID:     id,
+		ToUser: req.ToUser,
+		Amount: req.Amount,
+	}})
+}
+
+type CreatePurchaseRequest struct {
+	Item string `json:"item"`
+}
+
+type CreatedPurchase struct {
+	Item string `json:"item"`
+}
+
+func (h *Handler) CreatePurchase(ctx *fiber.Ctx) error {
+	var req CreatePurchaseRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(
+			fiber.StatusBadRequest,
+			fmt.Errorf("ctx.BodyParser: %w", err).Error(),
+		)
+	}
+
+	token, err := getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = h.coinService.BuyItem(ctx.UserContext(), services.BuyItemParams{
+		Token:   token,
+		Item:    req.Item,
+		OTPCode: ctx.Get(otpCodeHeader),
+	})
+	if err != nil {
+		return fmt.Errorf("h.coinService.BuyItem: %w", err)
+	}
+
+	ctx.Location("/v2/me/inventory")
+	return ctx.Status(fiber.StatusCreated).JSON(Response{Data: CreatedPurchase{
+		Item: req.Item,
+	}})
+}
+
+func appendTransfers(transfers []Transfer, direction string, transactions []models.Transaction) []Transfer {
+	for _, t := range transactions {
+		transfers = append(transfers, newTransfer(direction, t))
+	}
+	return transfers
+}
+
+func newTransfer(direction string, t models.Transaction) Transfer {
+	return Transfer{
+		ID:        t.ID,
+		Direction: direction,
+		FromUser:  t.SenderUsername,
+		ToUser:    t.ReceiverUsername,
+		Amount:    t.Amount,
+		Actor:     t.ActorUsername,
+		CreatedAt: t.CreatedAt,
+	}
+}
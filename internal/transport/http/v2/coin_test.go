@@ -0,0 +1,193 @@
+package v2_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Blxssy/AvitoTest/internal/models"
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"github.com/Blxssy/AvitoTest/internal/services/mocks"
+	"github.com/Blxssy/AvitoTest/internal/transport/http/middleware"
+	"github.com/Blxssy/AvitoTest/internal/transport/http/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newApp(coinService services.CoinService) *fiber.App {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: middleware.NewErrorHandler(middleware.ErrorHandlerConfig{}),
+	})
+	v2.NewHandler(v2.HandlerConfig{CoinService: coinService}).Init(app)
+	return app
+}
+
+func doRequest(t *testing.T, app *fiber.App, method, target, body string) *http.Response {
+	req := httptest.NewRequest(method, "http://localhost:8080"+target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer valid_token")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+func TestCreateTransfer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockCoinService(ctrl)
+	app := newApp(mockService)
+
+	mockService.EXPECT().SendCoins(gomock.Any(), services.TransactionParams{
+		Token:            "valid_token",
+		ReceiverUsername: "Bill",
+		Amount:           100,
+	}).Return(int64(42), nil)
+
+	resp := doRequest(t, app, http.MethodPost, "/v2/transfers", `{"toUser": "Bill", "amount": 100}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "/v2/me/transfers/42", resp.Header.Get("Location"))
+
+	var body struct {
+		Data v2.CreatedTransfer `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, v2.CreatedTransfer{ID: 42, ToUser: "Bill", Amount: 100}, body.Data)
+}
+
+func TestGetTransfer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockCoinService(ctrl)
+	app := newApp(mockService)
+
+	createdAt := time.Date(2025, 2, 1, 12, 0, 0, 0, time.UTC)
+	mockService.EXPECT().GetTransfer(gomock.Any(), services.GetTransferParams{Token: "valid_token", ID: 42}).
+		Return(services.Transfer{
+			Transaction: models.Transaction{ID: 42, SenderUsername: "Bill", ReceiverUsername: "test", Amount: 100, CreatedAt: createdAt},
+			Received:    true,
+		}, nil)
+	mockService.EXPECT().GetTransfer(gomock.Any(), services.GetTransferParams{Token: "valid_token", ID: 43}).
+		Return(services.Transfer{}, services.TransactionNotFoundError)
+
+	resp := doRequest(t, app, http.MethodGet, "/v2/me/transfers/42", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Data v2.Transfer `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, v2.Transfer{
+		ID: 42, Direction: "received", FromUser: "Bill", ToUser: "test", Amount: 100, CreatedAt: createdAt,
+	}, body.Data)
+
+	resp = doRequest(t, app, http.MethodGet, "/v2/me/transfers/43", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp = doRequest(t, app, http.MethodGet, "/v2/me/transfers/abc", "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestCreatePurchase(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockCoinService(ctrl)
+	app := newApp(mockService)
+
+	mockService.EXPECT().BuyItem(gomock.Any(), services.BuyItemParams{
+		Token: "valid_token",
+		Item:  "pink-hoody",
+	}).Return(nil)
+
+	resp := doRequest(t, app, http.MethodPost, "/v2/purchases", `{"item": "pink-hoody"}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "/v2/me/inventory", resp.Header.Get("Location"))
+
+	mockService.EXPECT().BuyItem(gomock.Any(), gomock.Any()).Return(services.InsufficientFundsError)
+
+	resp = doRequest(t, app, http.MethodPost, "/v2/purchases", `{"item": "pink-hoody"}`)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	var body middleware.ErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "insufficient_funds", body.Code)
+}
+
+func TestGetMe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockCoinService(ctrl)
+	app := newApp(mockService)
+
+	mockService.EXPECT().GetBalance(gomock.Any(), services.GetBalanceParams{Token: "valid_token"}).Return(750, nil)
+	mockService.EXPECT().GetPurchases(gomock.Any(), services.GetPurchasesParams{Token: "valid_token"}).
+		Return([]models.PurchaseItem{{Item: "cup", Count: 2}}, nil)
+
+	resp := doRequest(t, app, http.MethodGet, "/v2/me", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var me struct {
+		Data v2.Me `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&me))
+	assert.Equal(t, v2.Me{Coins: 750}, me.Data)
+
+	resp = doRequest(t, app, http.MethodGet, "/v2/me/inventory", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var inventory struct {
+		Data []v2.InventoryItem `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&inventory))
+	assert.Equal(t, []v2.InventoryItem{{Item: "cup", Quantity: 2}}, inventory.Data)
+}
+
+func TestGetTransfers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockCoinService(ctrl)
+	app := newApp(mockService)
+
+	createdAt := time.Date(2025, 2, 1, 12, 0, 0, 0, time.UTC)
+	params := services.GetTransactionsParams{Token: "valid_token"}
+	mockService.EXPECT().SendCoinsInfo(gomock.Any(), params).Return([]models.Transaction{
+		{ID: 2, SenderUsername: "me", ReceiverUsername: "Bill", Amount: 10, CreatedAt: createdAt},
+	}, nil).Times(2)
+	mockService.EXPECT().ReceivedCoinsInfo(gomock.Any(), params).Return([]models.Transaction{
+		{ID: 1, SenderUsername: "Ann", ReceiverUsername: "me", Amount: 20, CreatedAt: createdAt},
+		{ID: 3, SenderUsername: "Bob", ReceiverUsername: "me", Amount: 30, CreatedAt: createdAt},
+	}, nil)
+
+	resp := doRequest(t, app, http.MethodGet, "/v2/me/transfers", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Data []v2.Transfer `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Len(t, body.Data, 3)
+	assert.Equal(t, []uint32{3, 2, 1}, []uint32{body.Data[0].ID, body.Data[1].ID, body.Data[2].ID})
+	assert.Equal(t, "received", body.Data[0].Direction)
+	assert.Equal(t, "sent", body.Data[1].Direction)
+
+	resp = doRequest(t, app, http.MethodGet, "/v2/me/transfers?direction=sent", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body.Data = nil
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Len(t, body.Data, 1)
+
+	resp = doRequest(t, app, http.MethodGet, "/v2/me/transfers?direction=both", "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
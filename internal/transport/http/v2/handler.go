@@ -0,0 +1,49 @@
+package v2
+
+import (
+	"github.com/Blxssy/AvitoTest/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"strings"
+)
+
+type Handler struct {
+	coinService services.CoinService
+	logger      *zap.Logger
+}
+
+type HandlerConfig struct {
+	CoinService services.CoinService
+	Logger      *zap.Logger
+}
+
+func NewHandler(cfg HandlerConfig) *Handler {
+	return &Handler{
+		coinService: cfg.CoinService,
+		logger:      cfg.Logger,
+	}
+}
+
+func (h *Handler) Init(router fiber.Router) {
+	h.initCoinRoutes(router)
+}
+
+// Response is the envelope of every successful v2 response. Errors use the
+// {"errors", "code"} body shared with v1.
+type Response struct {
+	Data any `json:"data"`
+}
+
+func getToken(ctx *fiber.Ctx) (string, error) {
+	authHeader := ctx.Get("Authorization")
+	if authHeader == "" {
+		return "", fiber.NewError(fiber.StatusUnauthorized, "missing authorization header")
+	}
+
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+	if token == authHeader {
+		return "", fiber.NewError(fiber.StatusUnauthorized, "invalid authorization header")
+	}
+
+	return token, nil
+}
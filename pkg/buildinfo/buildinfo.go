@@ -0,0 +1,39 @@
+// Package buildinfo describes the running build. Version and Commit are set
+// at link time:
+//
+//	go build -ldflags "-X github.com/Blxssy/AvitoTest/pkg/buildinfo.Version=v1.2.0 -X github.com/Blxssy/AvitoTest/pkg/buildinfo.Commit=$(git rev-parse HEAD)"
+//
+// Without them the commit is taken from the VCS stamp of the Go toolchain.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+var (
+	Version = "dev"
+	Commit  = ""
+)
+
+type Info struct {
+	Version   string
+	Commit    string
+	GoVersion string
+}
+
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, GoVersion: runtime.Version()}
+	if info.Commit != "" {
+		return info
+	}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range build.Settings {
+			if setting.Key == "vcs.revision" {
+				info.Commit = setting.Value
+			}
+		}
+	}
+	return info
+}
@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"sync"
+)
+
+type scopeKey struct{}
+
+// scope is shared by everything serving one request.
+type scope struct {
+	logger *zap.Logger
+
+	mu       sync.Mutex
+	username string
+}
+
+// WithContext returns ctx carrying l, usually a logger with the fields of the
+// request being served, such as its ID.
+func WithContext(ctx context.Context, l *zap.Logger) context.Context {
+	return context.WithValue(ctx, scopeKey{}, &scope{logger: l})
+}
+
+// FromContext returns the logger stored by WithContext, or fallback outside
+// of a request.
+func FromContext(ctx context.Context, fallback *zap.Logger) *zap.Logger {
+	if s, ok := ctx.Value(scopeKey{}).(*scope); ok {
+		return s.logger
+	}
+	return fallback
+}
+
+// SetUsername records who made the request once they are authenticated, for
+// the access log. It does nothing outside of a request.
+func SetUsername(ctx context.Context, username string) {
+	if s, ok := ctx.Value(scopeKey{}).(*scope); ok {
+		s.mu.Lock()
+		s.username = username
+		s.mu.Unlock()
+	}
+}
+
+// Username returns the name recorded by SetUsername.
+func Username(ctx context.Context) string {
+	if s, ok := ctx.Value(scopeKey{}).(*scope); ok {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.username
+	}
+	return ""
+}
@@ -7,7 +7,19 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
-func New(logLevel string) *zap.Logger {
+// ParseLevel parses levels like "info". Unknown levels, like "dev", mean
+// debug.
+func ParseLevel(logLevel string) zapcore.Level {
+	level, err := zapcore.ParseLevel(logLevel)
+	if err != nil {
+		return zap.DebugLevel
+	}
+	return level
+}
+
+// New returns a logger that writes entries of level and above. Changing
+// level applies to the logger and everything derived from it at once.
+func New(level zap.AtomicLevel) *zap.Logger {
 	config := zap.NewProductionEncoderConfig()
 
 	config.MessageKey = "message"
@@ -17,14 +29,9 @@ func New(logLevel string) *zap.Logger {
 
 	consoleEncoder := zapcore.NewJSONEncoder(config)
 
-	level, err := zapcore.ParseLevel(logLevel)
-	if err != nil {
-		return zap.New(zapcore.NewCore(consoleEncoder, os.Stdout, zap.DebugLevel))
-	}
-
 	core := zapcore.NewTee(
 		zapcore.NewCore(consoleEncoder, zapcore.AddSync(os.Stdout), level),
 	)
 
-	return zap.New(core, zap.AddCaller())
+	return zap.New(redactCore{core}, zap.AddCaller())
 }
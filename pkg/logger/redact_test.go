@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRedact(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	log := zap.New(redactCore{core}).With(zap.String("access_token", "eyJhbGciOi"))
+
+	log.Info("login",
+		zap.String("username", "alice"),
+		zap.String("password", "hunter22"),
+		zap.String("resetToken", "r3set"),
+		zap.String("header", "Bearer eyJhbGciOi"),
+		zap.Int("attempts", 3),
+	)
+
+	entries := logs.TakeAll()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, map[string]any{
+		"access_token": redacted,
+		"username":     "alice",
+		"password":     redacted,
+		"resetToken":   redacted,
+		"header":       redacted,
+		"attempts":     int64(3),
+	}, entries[0].ContextMap())
+}
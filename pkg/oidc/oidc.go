@@ -0,0 +1,135 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+var (
+	ErrNoIDToken        = errors.New("token response has no id_token")
+	ErrNonceMismatch    = errors.New("id token nonce does not match the login")
+	ErrNoUsername       = errors.New("id token has no username claim")
+	ErrEmailNotVerified = errors.New("identity provider has not verified the email")
+)
+
+// Identity is the user an ID token was issued for.
+type Identity struct {
+	Issuer  string
+	Subject string
+	// Username is the value of the configured username claim.
+	Username string
+}
+
+type Provider interface {
+	// AuthCodeURL returns the identity provider page to send the user to.
+	// state and nonce tie the callback to this login, verifier is its PKCE
+	// secret.
+	AuthCodeURL(state, nonce, verifier string) string
+	// Exchange redeems an authorization code and returns the identity from
+	// the verified ID token.
+	Exchange(ctx context.Context, code, verifier, nonce string) (Identity, error)
+}
+
+type OIDCProvider struct {
+	oauth2        oauth2.Config
+	verifier      *gooidc.IDTokenVerifier
+	usernameClaim string
+}
+
+// Config describes the client registered at the identity provider.
+// UsernameClaim names the ID token claim that becomes the username, such as
+// "email", "preferred_username" or "sub"; emails are accepted only once the
+// provider has verified them.
+type Config struct {
+	IssuerURL     string
+	ClientID      string
+	ClientSecret  string
+	RedirectURL   string
+	Scopes        []string
+	UsernameClaim string
+}
+
+// NewProvider fetches the provider metadata from its discovery document.
+func NewProvider(ctx context.Context, cfg Config) (Provider, error) {
+	if cfg.UsernameClaim == "" {
+		return nil, errors.New("username claim must be set")
+	}
+
+	provider, err := gooidc.NewProvider(ctx, cfg.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("gooidc.NewProvider: %w", err)
+	}
+
+	return &OIDCProvider{
+		oauth2: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       cfg.Scopes,
+		},
+		verifier:      provider.Verifier(&gooidc.Config{ClientID: cfg.ClientID}),
+		usernameClaim: cfg.UsernameClaim,
+	}, nil
+}
+
+func (p *OIDCProvider) AuthCodeURL(state, nonce, verifier string) string {
+	return p.oauth2.AuthCodeURL(state, gooidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier))
+}
+
+func (p *OIDCProvider) Exchange(ctx context.Context, code, verifier, nonce string) (Identity, error) {
+	token, err := p.oauth2.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return Identity{}, fmt.Errorf("p.oauth2.Exchange: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return Identity{}, ErrNoIDToken
+	}
+
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return Identity{}, fmt.Errorf("p.verifier.Verify: %w", err)
+	}
+	if idToken.Nonce != nonce {
+		return Identity{}, ErrNonceMismatch
+	}
+
+	var claims map[string]any
+	if err = idToken.Claims(&claims); err != nil {
+		return Identity{}, fmt.Errorf("idToken.Claims: %w", err)
+	}
+
+	username, err := p.username(idToken.Subject, claims)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	return Identity{
+		Issuer:   idToken.Issuer,
+		Subject:  idToken.Subject,
+		Username: username,
+	}, nil
+}
+
+func (p *OIDCProvider) username(subject string, claims map[string]any) (string, error) {
+	if p.usernameClaim == "sub" {
+		return subject, nil
+	}
+
+	username, _ := claims[p.usernameClaim].(string)
+	if username == "" {
+		return "", ErrNoUsername
+	}
+	// Anyone can put someone else's address into an unverified email claim.
+	if p.usernameClaim == "email" {
+		if verified, _ := claims["email_verified"].(bool); !verified {
+			return "", ErrEmailNotVerified
+		}
+	}
+	return username, nil
+}
@@ -0,0 +1,89 @@
+package oidc_test
+
+import (
+	"context"
+	"github.com/Blxssy/AvitoTest/pkg/oidc"
+	"github.com/Blxssy/AvitoTest/pkg/oidc/oidctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestProvider(t *testing.T) {
+	idp := oidctest.NewServer(t)
+	ctx := context.Background()
+
+	provider, err := oidc.NewProvider(ctx, oidc.Config{
+		IssuerURL:     idp.URL,
+		ClientID:      "coin-service",
+		ClientSecret:  "secret",
+		RedirectURL:   "http://localhost/api/auth/oidc/callback",
+		Scopes:        []string{"openid", "email"},
+		UsernameClaim: "email",
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		claims   map[string]any
+		verifier string
+		nonce    string
+		want     oidc.Identity
+		wantErr  error
+	}{
+		{
+			name:   "verified email",
+			claims: map[string]any{"sub": "42", "email": "alice@example.com", "email_verified": true},
+			want:   oidc.Identity{Issuer: idp.URL, Subject: "42", Username: "alice@example.com"},
+		},
+		{
+			name:    "unverified email",
+			claims:  map[string]any{"sub": "42", "email": "alice@example.com"},
+			wantErr: oidc.ErrEmailNotVerified,
+		},
+		{
+			name:    "missing email",
+			claims:  map[string]any{"sub": "42"},
+			wantErr: oidc.ErrNoUsername,
+		},
+		{
+			name:    "another login's nonce",
+			claims:  map[string]any{"sub": "42", "email": "alice@example.com", "email_verified": true},
+			nonce:   "another-login",
+			wantErr: oidc.ErrNonceMismatch,
+		},
+		{
+			name:     "wrong verifier",
+			claims:   map[string]any{"sub": "42", "email": "alice@example.com", "email_verified": true},
+			verifier: "stolen-code-without-its-verifier-0123456789",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := "verifier-for-this-login-0123456789abcdefghijk"
+			nonce := "nonce-" + tt.name
+
+			code, state := idp.Authorize(provider.AuthCodeURL("state-"+tt.name, nonce, verifier), tt.claims)
+			assert.Equal(t, "state-"+tt.name, state)
+
+			if tt.verifier != "" {
+				verifier = tt.verifier
+			}
+			if tt.nonce != "" {
+				nonce = tt.nonce
+			}
+
+			identity, err := provider.Exchange(ctx, code, verifier, nonce)
+			switch {
+			case tt.wantErr != nil:
+				assert.ErrorIs(t, err, tt.wantErr)
+			case tt.want == oidc.Identity{}:
+				assert.Error(t, err)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, identity)
+			}
+		})
+	}
+}
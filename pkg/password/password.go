@@ -0,0 +1,175 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+)
+
+// Algorithms a hash can be produced with. The algorithm is stored next to
+// the hash so that old hashes keep verifying after the configuration changes.
+const (
+	Bcrypt   = "bcrypt"
+	Argon2id = "argon2id"
+)
+
+var (
+	ErrUnknownAlgorithm = errors.New("unknown password hashing algorithm")
+	ErrMalformedHash    = errors.New("malformed password hash")
+)
+
+type Hasher interface {
+	// Hash hashes password with the configured algorithm.
+	Hash(password string) (hash, algorithm string, err error)
+	// Verify reports whether password matches a hash made with algorithm.
+	Verify(password, hash, algorithm string) (bool, error)
+	// NeedsRehash reports whether hash was made with another algorithm or
+	// weaker parameters than the configured ones.
+	NeedsRehash(hash, algorithm string) bool
+}
+
+type PasswordHasher struct {
+	cfg Config
+}
+
+// Config selects the algorithm new hashes are made with and its cost.
+// Argon2Memory is in KiB.
+type Config struct {
+	Algorithm     string
+	BcryptCost    int
+	Argon2Memory  uint32
+	Argon2Time    uint32
+	Argon2Threads uint8
+}
+
+func NewHasher(cfg Config) (Hasher, error) {
+	switch cfg.Algorithm {
+	case Bcrypt:
+		if cfg.BcryptCost < bcrypt.MinCost || cfg.BcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	case Argon2id:
+		if cfg.Argon2Memory == 0 || cfg.Argon2Time == 0 || cfg.Argon2Threads == 0 {
+			return nil, errors.New("argon2id memory, time and threads must be positive")
+		}
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownAlgorithm, cfg.Algorithm)
+	}
+
+	return &PasswordHasher{cfg: cfg}, nil
+}
+
+func (h *PasswordHasher) Hash(password string) (string, string, error) {
+	switch h.cfg.Algorithm {
+	case Bcrypt:
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cfg.BcryptCost)
+		if err != nil {
+			return "", "", err
+		}
+		return string(hash), Bcrypt, nil
+	case Argon2id:
+		params := argon2Params{
+			memory:  h.cfg.Argon2Memory,
+			time:    h.cfg.Argon2Time,
+			threads: h.cfg.Argon2Threads,
+			salt:    make([]byte, 16),
+		}
+		if _, err := rand.Read(params.salt); err != nil {
+			return "", "", err
+		}
+		return params.encode(params.key(password)), Argon2id, nil
+	}
+	return "", "", ErrUnknownAlgorithm
+}
+
+func (h *PasswordHasher) Verify(password, hash, algorithm string) (bool, error) {
+	switch algorithm {
+	case Bcrypt:
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		return err == nil, err
+	case Argon2id:
+		params, key, err := decodeArgon2(hash)
+		if err != nil {
+			return false, err
+		}
+		return subtle.ConstantTimeCompare(key, params.key(password)) == 1, nil
+	}
+	return false, fmt.Errorf("%w: %q", ErrUnknownAlgorithm, algorithm)
+}
+
+func (h *PasswordHasher) NeedsRehash(hash, algorithm string) bool {
+	if algorithm != h.cfg.Algorithm {
+		return true
+	}
+
+	switch algorithm {
+	case Bcrypt:
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost != h.cfg.BcryptCost
+	case Argon2id:
+		params, _, err := decodeArgon2(hash)
+		return err != nil ||
+			params.memory != h.cfg.Argon2Memory ||
+			params.time != h.cfg.Argon2Time ||
+			params.threads != h.cfg.Argon2Threads
+	}
+	return true
+}
+
+const argon2KeyLength = 32
+
+type argon2Params struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+	salt    []byte
+}
+
+func (p argon2Params) key(password string) []byte {
+	return argon2.IDKey([]byte(password), p.salt, p.time, p.memory, p.threads, argon2KeyLength)
+}
+
+// encode formats the hash in the PHC string format used by the reference
+// implementation: $argon2id$v=19$m=19456,t=2,p=1$<salt>$<key>.
+func (p argon2Params) encode(key []byte) string {
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.memory, p.time, p.threads,
+		base64.RawStdEncoding.EncodeToString(p.salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+}
+
+func decodeArgon2(hash string) (argon2Params, []byte, error) {
+	var params argon2Params
+
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != Argon2id {
+		return params, nil, ErrMalformedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, ErrMalformedHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads); err != nil {
+		return params, nil, ErrMalformedHash
+	}
+
+	var err error
+	if params.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return params, nil, ErrMalformedHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) != argon2KeyLength {
+		return params, nil, ErrMalformedHash
+	}
+	return params, key, nil
+}
@@ -0,0 +1,94 @@
+package password_test
+
+import (
+	"github.com/Blxssy/AvitoTest/pkg/password"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"testing"
+)
+
+var argon2Config = password.Config{
+	Algorithm:     password.Argon2id,
+	Argon2Memory:  1024,
+	Argon2Time:    1,
+	Argon2Threads: 1,
+}
+
+func TestHashAndVerify(t *testing.T) {
+	configs := []password.Config{
+		{Algorithm: password.Bcrypt, BcryptCost: bcrypt.MinCost},
+		argon2Config,
+	}
+
+	for _, cfg := range configs {
+		t.Run(cfg.Algorithm, func(t *testing.T) {
+			hasher, err := password.NewHasher(cfg)
+			require.NoError(t, err)
+
+			hash, algorithm, err := hasher.Hash("secret")
+			require.NoError(t, err)
+			assert.Equal(t, cfg.Algorithm, algorithm)
+
+			ok, err := hasher.Verify("secret", hash, algorithm)
+			require.NoError(t, err)
+			assert.True(t, ok)
+
+			ok, err = hasher.Verify("wrong", hash, algorithm)
+			require.NoError(t, err)
+			assert.False(t, ok)
+
+			assert.False(t, hasher.NeedsRehash(hash, algorithm))
+		})
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	bcryptHasher, err := password.NewHasher(password.Config{Algorithm: password.Bcrypt, BcryptCost: bcrypt.MinCost})
+	require.NoError(t, err)
+	argon2Hasher, err := password.NewHasher(argon2Config)
+	require.NoError(t, err)
+
+	bcryptHash, _, err := bcryptHasher.Hash("secret")
+	require.NoError(t, err)
+	assert.True(t, argon2Hasher.NeedsRehash(bcryptHash, password.Bcrypt))
+
+	// Old hashes keep verifying after the algorithm changes.
+	ok, err := argon2Hasher.Verify("secret", bcryptHash, password.Bcrypt)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	strongerBcrypt, err := password.NewHasher(password.Config{Algorithm: password.Bcrypt, BcryptCost: bcrypt.MinCost + 1})
+	require.NoError(t, err)
+	assert.True(t, strongerBcrypt.NeedsRehash(bcryptHash, password.Bcrypt))
+
+	strongerArgon2 := argon2Config
+	strongerArgon2.Argon2Time = 2
+	stronger, err := password.NewHasher(strongerArgon2)
+	require.NoError(t, err)
+	argon2Hash, _, err := argon2Hasher.Hash("secret")
+	require.NoError(t, err)
+	assert.True(t, stronger.NeedsRehash(argon2Hash, password.Argon2id))
+}
+
+func TestVerifyMalformedHash(t *testing.T) {
+	hasher, err := password.NewHasher(argon2Config)
+	require.NoError(t, err)
+
+	_, err = hasher.Verify("secret", "$argon2id$v=19$m=1024", password.Argon2id)
+	assert.ErrorIs(t, err, password.ErrMalformedHash)
+
+	_, err = hasher.Verify("secret", "hash", "md5")
+	assert.ErrorIs(t, err, password.ErrUnknownAlgorithm)
+}
+
+func TestNewHasherRejectsBadConfig(t *testing.T) {
+	_, err := password.NewHasher(password.Config{Algorithm: "md5"})
+	assert.ErrorIs(t, err, password.ErrUnknownAlgorithm)
+
+	_, err = password.NewHasher(password.Config{Algorithm: password.Bcrypt, BcryptCost: 1})
+	assert.Error(t, err)
+
+	_, err = password.NewHasher(password.Config{Algorithm: password.Argon2id})
+	assert.Error(t, err)
+}
@@ -0,0 +1,54 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/jmoiron/sqlx"
+	"io/fs"
+)
+
+// LatestMigration returns the version of the newest migration in path, the
+// schema version this build expects.
+func LatestMigration(path string) (uint, error) {
+	driver, err := source.Open("file://" + path)
+	if err != nil {
+		return 0, fmt.Errorf("source.Open: %w", err)
+	}
+	defer driver.Close()
+
+	version, err := driver.First()
+	if err != nil {
+		return 0, fmt.Errorf("driver.First: %w", err)
+	}
+	for {
+		next, err := driver.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("driver.Next: %w", err)
+		}
+		version = next
+	}
+}
+
+// MigrationVersion returns the version the schema is migrated to, zero when
+// every migration was rolled back, and whether the last migration failed
+// halfway.
+func MigrationVersion(ctx context.Context, db *sqlx.DB) (uint, bool, error) {
+	var row struct {
+		Version uint `db:"version"`
+		Dirty   bool `db:"dirty"`
+	}
+	err := db.GetContext(ctx, &row, "select version, dirty from "+applicationSchema+".schema_migrations limit 1")
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("db.GetContext: %w", err)
+	}
+	return row.Version, row.Dirty, nil
+}
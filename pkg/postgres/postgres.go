@@ -31,29 +31,59 @@ func New(cfg config.PostgresConfig) (*sqlx.DB, error) {
 }
 
 func RunMigrations(instance *sql.DB, cfg config.PostgresConfig) (uint, error) {
-	if _, err := instance.Exec("create schema if not exists " + applicationSchema); err != nil {
-		return 0, fmt.Errorf("create schema: %w", err)
+	migrateInst, err := newMigrate(instance, cfg)
+	if err != nil {
+		return 0, err
 	}
 
-	driver, err := postgres.WithInstance(instance, &postgres.Config{
-		SchemaName: applicationSchema,
-	})
+	if err = migrateInst.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return 0, fmt.Errorf("up migrations: %w", err)
+	}
+
+	version, _, err := migrateInst.Version()
 	if err != nil {
-		return 0, fmt.Errorf("create driver with instance: %w", err)
+		return 0, fmt.Errorf("migrate: %w", err)
 	}
+	return version, nil
+}
 
-	migrateInst, err := migrate.NewWithDatabaseInstance("file://"+cfg.PathToMigrations, driverName, driver)
+// RollbackMigrations reverts the last steps migrations and returns the
+// version left, zero when none are.
+func RollbackMigrations(instance *sql.DB, cfg config.PostgresConfig, steps int) (uint, error) {
+	migrateInst, err := newMigrate(instance, cfg)
 	if err != nil {
-		return 0, fmt.Errorf("create migrate instance: %w", err)
+		return 0, err
 	}
 
-	if err = migrateInst.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		return 0, fmt.Errorf("up migrations: %w", err)
+	if err = migrateInst.Steps(-steps); err != nil {
+		return 0, fmt.Errorf("down migrations: %w", err)
 	}
 
 	version, _, err := migrateInst.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, nil
+	}
 	if err != nil {
 		return 0, fmt.Errorf("migrate: %w", err)
 	}
 	return version, nil
 }
+
+func newMigrate(instance *sql.DB, cfg config.PostgresConfig) (*migrate.Migrate, error) {
+	if _, err := instance.Exec("create schema if not exists " + applicationSchema); err != nil {
+		return nil, fmt.Errorf("create schema: %w", err)
+	}
+
+	driver, err := postgres.WithInstance(instance, &postgres.Config{
+		SchemaName: applicationSchema,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create driver with instance: %w", err)
+	}
+
+	migrateInst, err := migrate.NewWithDatabaseInstance("file://"+cfg.PathToMigrations, driverName, driver)
+	if err != nil {
+		return nil, fmt.Errorf("create migrate instance: %w", err)
+	}
+	return migrateInst, nil
+}